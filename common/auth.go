@@ -2,11 +2,13 @@ package common
 
 import (
 	"crypto/rsa"
+	"crypto/subtle"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dgrijalva/jwt-go"
@@ -209,3 +211,52 @@ func WithAuthorize(w http.ResponseWriter, r *http.Request, next http.HandlerFunc
 	
 	next(w, r)
 }
+
+// Role of the services authenticated with the credentials from config
+const ServiceRole = "Service"
+
+// The secret of the service from config value ServiceCredentials being name:secret,...
+func serviceSecret(name string) (secret string, found bool) {
+	for _, c := range strings.Split(AppConfig.ServiceCredentials, ",") {
+		if kv := strings.SplitN(strings.TrimSpace(c), ":", 2); len(kv) == 2 && kv[0] != "" && kv[0] == name {
+			return kv[1], true
+		}
+	}
+
+	return "", false
+}
+
+//
+// Middleware for the services authenticated with basic credentials instead
+// of JWT tokens of the users, it loads the name of the service as the user
+//
+func WithServiceAuthorize(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	log.Printf("Start service Authorize on metod: %s", r.Method)
+
+	if r.Method == "OPTIONS" {
+		log.Printf("Service Authorize skip: OPTIONS received, quiting")
+		next(w, r)
+		return
+	}
+
+	name, password, ok := r.BasicAuth()
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Basic realm="sam-api"`)
+		DisplayAppError(w, AuthorizationError, "Service credentials not found", http.StatusUnauthorized)
+		return
+	}
+
+	secret, found := serviceSecret(name)
+	if !found || subtle.ConstantTimeCompare([]byte(secret), []byte(password)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="sam-api"`)
+		DisplayAppError(w, AuthorizationError, "Invalid service credentials of: " + name, http.StatusUnauthorized)
+		return
+	}
+
+	r.Header.Set("user", name)
+	r.Header.Set("role", ServiceRole)
+
+	log.Printf("Service Authorize successful: %s", name)
+
+	next(w, r)
+}
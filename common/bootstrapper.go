@@ -5,6 +5,17 @@ func StartUp() {
 	// Initialize private/public keys for JWT authentication
 	initKeys()
 
-	// Start a SQL DB session to e used by repositories
-	createOracleDbSession()
+	StartUpRepository()
+}
+
+// start up of the repository only, used by the command line without the keys
+func StartUpRepository() {
+	// Start a SQL DB session to e used by repositories, memory backend needs none
+	switch AppConfig.Backend {
+	case BackendMemory:
+	case BackendPostgres:
+		createPostgresDbSession()
+	default:
+		createOracleDbSession()
+	}
 }
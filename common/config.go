@@ -16,18 +16,51 @@ type (
 		OracleDBUser,
 		OracleDBPassword,
 		OracleServiceName,
+		PostgresDBHost,
+		PostgresDBPort,
+		PostgresDBUser,
+		PostgresDBPassword,
+		PostgresDBName,
+		PostgresDBSSLMode,
 		AlertMailAddress,
 		AlertMailServerAddress,
 		AlertMailSenderAddress,
 		JWTTokenValidHours,
+		MaxPageLimit,
 		LdapBase,
 		LdapHost,
 		LdapPort,
 		LdapBindDN,
+		Backend,
+		ReleaseApproval,
+		UserMailDomain,
+		AccountReference,
+		OrderStubs,
+		ServiceCredentials,
+		DeliveryTarget,
+		DeliveryFormat,
+		DeliveryRetries,
+		DeliveryRetryDelay,
+		DeliveryInsecureHostKey,
+		SapImportProfiles,
 		Testing string
 	}
 )
 
+// Supported repository backends
+const (
+	BackendOracle   = "oracle"
+	BackendPostgres = "postgres"
+	BackendMemory   = "memory"
+)
+
+// Checks of the dictionary references of accounts
+const (
+	AccountReferenceStrict = "strict"
+	AccountReferenceStaged = "staged"
+	AccountReferenceNone   = "none"
+)
+
 // AppConfig holds the configuration values from config.json file
 var AppConfig configuration
 
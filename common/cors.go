@@ -11,6 +11,7 @@ import (
 func SetupCorsResponse(w *http.ResponseWriter, preflight bool) {
 	// all cases, even methods not OPTIONS must have Origin set
 	(*w).Header().Set("Access-Control-Allow-Origin", "*")
+	(*w).Header().Set("Access-Control-Expose-Headers", "ETag")
 	// just OPTIONS requred
 	if preflight {
 		allowedMethods := []string{
@@ -31,6 +32,7 @@ func SetupCorsResponse(w *http.ResponseWriter, preflight bool) {
 			"X-Request-ID",
 			"Content-Type",
 			"Content-Encoding",
+			"If-Match",
 			"Access-Control-Allow-Headers",
 			"Access-Control-Request-Method",
 			"Access-Control-Request-Headers",
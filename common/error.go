@@ -28,6 +28,8 @@ var EncoderCsvError = errors.New("Encoder CSV error")
 var RepositoryNewError = errors.New("Repository creation error")
 var RepositoryRunError = errors.New("Repository runtime error")
 var ControllerError = errors.New("Controller error")
+var VersionError = errors.New("Record version error")
+var QueryParamError = errors.New("Query parameter error")
 
 //
 // Return json error feedback to the the client
@@ -44,7 +44,12 @@ func createOracleDbSession() (err error) {
 //
 func GetDbSession() (dbsession *sql.DB, err error) {
 	if db == nil {
-		if err = createOracleDbSession(); err != nil {
+		if AppConfig.Backend == BackendPostgres {
+			err = createPostgresDbSession()
+		} else {
+			err = createOracleDbSession()
+		}
+		if err != nil {
 			return
 		}
 	} 
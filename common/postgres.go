@@ -0,0 +1,41 @@
+package common
+
+import (
+	"fmt"
+	"log"
+
+	"database/sql"
+	_ "github.com/lib/pq"
+)
+
+//
+// Allocates PostgreSQL db session shared by the repositories
+//
+func createPostgresDbSession() (err error) {
+	connectString := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		AppConfig.PostgresDBHost,
+		AppConfig.PostgresDBPort,
+		AppConfig.PostgresDBUser,
+		AppConfig.PostgresDBPassword,
+		AppConfig.PostgresDBName,
+		AppConfig.PostgresDBSSLMode)
+
+	// open initial connection to the PostgreSQL DB
+	log.Println("Connecting to PostgreSQL DB")
+	db, err = sql.Open("postgres", connectString)
+	if err != nil {
+		log.Fatalf("Can not create postgres session: %s\n", err)
+		return
+	}
+
+	// is connection alive?
+	err = db.Ping()
+	if err != nil {
+		log.Fatalf("Can not ping to PostgreSQL: %s\n", err)
+		return
+	} else {
+		log.Println("Ping done to PostgreSQL DB")
+	}
+
+	return
+}
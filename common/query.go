@@ -0,0 +1,151 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+//
+// Paging, sorting and filtering of the list endpoints given with url query:
+//
+//   ?limit=100&offset=200&sort=-validFromDate,bscsAccount&entryOwner=john&validFromDate>=2020-01-01
+//
+// The attributes are the json names of the model, they are mapped to the
+// columns and bound to SQL by the repository layer.
+//
+type (
+	ListQuery struct {
+		Limit   int
+		Offset  int
+		Sort    []SortKey
+		Filters []Filter
+		params  []string
+	}
+
+	SortKey struct {
+		Attribute string
+		Desc      bool
+	}
+
+	Filter struct {
+		Attribute string
+		Op        string
+		Value     string
+	}
+)
+
+// Query parameter not applicable to the resource
+type InvalidQueryError struct {
+	Reason string
+}
+
+func (e InvalidQueryError) Error() string {
+	return e.Reason
+}
+
+//
+// Get paging, sorting and filters from the url query of the request
+//
+func GetListQuery(r *http.Request) (q *ListQuery, err error) {
+	q = &ListQuery{}
+	if r.URL.RawQuery == "" {
+		return
+	}
+
+	for _, term := range strings.Split(r.URL.RawQuery, "&") {
+		if term == "" {
+			continue
+		}
+
+		// the operator starts at the first of its characters
+		var f Filter
+		i := strings.IndexAny(term, "<>!=")
+		if i <= 0 {
+			return nil, fmt.Errorf("Invalid query parameter: %s", term)
+		}
+		f.Op = term[i : i+1]
+		if f.Op != "=" && strings.HasPrefix(term[i+1:], "=") {
+			f.Op += "="
+		}
+		if f.Op == "!" {
+			return nil, fmt.Errorf("Invalid query parameter: %s", term)
+		}
+		f.Attribute, f.Value = term[:i], term[i+len(f.Op):]
+		if f.Attribute, err = url.QueryUnescape(f.Attribute); err != nil {
+			return nil, fmt.Errorf("Invalid query parameter: %s", term)
+		}
+		if f.Value, err = url.QueryUnescape(f.Value); err != nil {
+			return nil, fmt.Errorf("Invalid query parameter value: %s", term)
+		}
+
+		switch f.Attribute {
+		case "limit", "offset":
+			n, err := strconv.Atoi(f.Value)
+			if err != nil || n < 0 || f.Op != "=" {
+				return nil, fmt.Errorf("Invalid query parameter %s: %s", f.Attribute, f.Value)
+			}
+			if f.Attribute == "limit" {
+				q.Limit = n
+			} else {
+				q.Offset = n
+			}
+
+		case "sort":
+			for _, s := range strings.Split(f.Value, ",") {
+				key := SortKey{Attribute: strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+"), Desc: strings.HasPrefix(s, "-")}
+				if key.Attribute == "" {
+					return nil, fmt.Errorf("Invalid query parameter sort: %s", f.Value)
+				}
+				q.Sort = append(q.Sort, key)
+			}
+
+		default:
+			q.Filters = append(q.Filters, f)
+			q.params = append(q.params, term)
+		}
+	}
+
+	if max := maxPageLimit(); q.Limit > max {
+		q.Limit = max
+	}
+
+	return
+}
+
+// page size limit from config, invalid value means no limit
+func maxPageLimit() int {
+	max, err := strconv.Atoi(AppConfig.MaxPageLimit)
+	if err != nil || max <= 0 {
+		return int(^uint(0) >> 1)
+	}
+
+	return max
+}
+
+//
+// Link to the next page of the result set, empty on the last one
+//
+func (q *ListQuery) NextPage(path string, total int64) string {
+	if q == nil || q.Limit == 0 || int64(q.Offset+q.Limit) >= total {
+		return ""
+	}
+
+	params := append([]string{}, q.params...)
+	if len(q.Sort) > 0 {
+		keys := make([]string, len(q.Sort))
+		for i, k := range q.Sort {
+			if k.Desc {
+				keys[i] = "-" + k.Attribute
+			} else {
+				keys[i] = k.Attribute
+			}
+		}
+		params = append(params, "sort="+url.QueryEscape(strings.Join(keys, ",")))
+	}
+	params = append(params, fmt.Sprintf("limit=%d", q.Limit), fmt.Sprintf("offset=%d", q.Offset+q.Limit))
+
+	return path + "?" + strings.Join(params, "&")
+}
@@ -5,11 +5,13 @@ import (
     "compress/gzip"	
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/http/httputil"
 	"strconv"
+	"strings"
 
 	"github.com/gorilla/mux"
 )
@@ -83,6 +85,37 @@ func PathVariableInt64(r *http.Request, label string, mandatory bool) (value int
 	return value, err
 }
 
+// Strong entity tag of a single record made of its REC_VERSION
+func ETag(version int) string {
+	return fmt.Sprintf("\"%d\"", version)
+}
+
+// Weak entity tag of a result set made of the keys and versions of all records
+func WeakETag(parts []string) string {
+	h := fnv.New64a()
+	for _, p := range parts {
+		h.Write([]byte(p))
+		h.Write([]byte{0})
+	}
+
+	return fmt.Sprintf("W/\"%x\"", h.Sum64())
+}
+
+// Get record version from If-Match header, no header or * matches any version
+func IfMatchVersion(r *http.Request) (version int, ok bool, err error) {
+	tag := strings.TrimSpace(r.Header.Get("If-Match"))
+	if tag == "" || tag == "*" {
+		return
+	}
+
+	version, err = strconv.Atoi(strings.Trim(strings.TrimPrefix(tag, "W/"), "\""))
+	if err != nil {
+		return 0, false, fmt.Errorf("Invalid If-Match header value: %s", tag)
+	}
+
+	return version, true, nil
+}
+
 func ReadPayload(r *http.Request) (body []byte, err error) {
 	body, err = ioutil.ReadAll(r.Body)
 	if err != nil {
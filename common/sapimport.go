@@ -0,0 +1,97 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+//
+// Layout of the SAP master Excel file loaded into the dictionary: the sheets
+// with the name prefix, the header row found by the titles of the columns,
+// the account code made of the account columns and the description column,
+// or with the account cells set the columns taken by the position counted
+// from 0 below the header rows
+//
+type SapImportProfile struct {
+	Name              string   `json:"name"`
+	SheetPrefix       string   `json:"sheetPrefix"`
+	AccountColumns    []string `json:"accountColumns"`
+	AccountSeparator  string   `json:"accountSeparator"`
+	DescriptionColumn string   `json:"descriptionColumn"`
+	HeaderRows        int      `json:"headerRows"`
+	AccountCells      []int    `json:"accountCells"`
+	DescriptionCell   int      `json:"descriptionCell"`
+}
+
+// The layout of the IKOS master used when no profiles are configured, the
+// account in cells AC to AM and the description in AT below 2 header rows
+var sapImportProfileDefault = SapImportProfile{
+	Name:            "ikos",
+	SheetPrefix:     "IKOS_AC_Master",
+	HeaderRows:      2,
+	AccountCells:    []int{28, 29, 30, 31, 32, 33, 34, 35, 36, 37, 38},
+	DescriptionCell: 45,
+}
+
+// The columns are taken by the position instead of the header titles
+func (p *SapImportProfile) Positional() bool {
+	return len(p.AccountCells) > 0
+}
+
+//
+// Profiles from the json file of configuration SapImportProfiles, relative
+// to the run path, or the built-in one if it is not set
+//
+func SapImportProfiles() (profiles []SapImportProfile, err error) {
+	if AppConfig.SapImportProfiles == "" {
+		return []SapImportProfile{sapImportProfileDefault}, nil
+	}
+
+	name := AppConfig.SapImportProfiles
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(AppConfig.RunPath, name)
+	}
+	file, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("Can't open import profiles: %s", err.Error())
+	}
+	defer file.Close()
+
+	if err = json.NewDecoder(file).Decode(&profiles); err != nil {
+		return nil, fmt.Errorf("Can't decode import profiles: %s", err.Error())
+	}
+
+	for _, p := range profiles {
+		if p.Name == "" || !p.Positional() && (len(p.AccountColumns) == 0 || p.DescriptionColumn == "") {
+			return nil, fmt.Errorf("Import profile needs name, account columns and description column or account cells: %#v", p)
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("No import profiles in: %s", name)
+	}
+
+	return
+}
+
+//
+// Profile with the name or the first one if the name is empty
+//
+func GetSapImportProfile(name string) (*SapImportProfile, error) {
+	profiles, err := SapImportProfiles()
+	if err != nil {
+		return nil, err
+	}
+
+	if name == "" {
+		return &profiles[0], nil
+	}
+	for i := range profiles {
+		if profiles[i].Name == name {
+			return &profiles[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("Unknown import profile: %s", name)
+}
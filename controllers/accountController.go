@@ -12,6 +12,7 @@ The operations On Account are:
 
   CreateOne
   ReadSome
+  ReadOne
   UpdateOne
   DeleteOne
 
@@ -75,6 +76,33 @@ func getAccountPathVars4LogAccess(r *http.Request) (account string, err error) {
 	return
 }
 
+// Weak entity tag of the result set made of keys and record versions
+func accountsETag(accounts []models.Account) string {
+	parts := make([]string, len(accounts))
+	for i, a := range accounts {
+		parts[i] = fmt.Sprintf("%s/%s/%s/%d", a.Status, a.ReleaseId, a.BscsAccount, a.RecVersion)
+	}
+
+	return common.WeakETag(parts)
+}
+
+// Compare the record version read by the client with the current one
+func checkAccountVersion(w http.ResponseWriter, r *http.Request, repo repository.AccountRepository, key *models.Account, version, status int) (ok bool) {
+	current, count, err := repo.ReadVersionByPrimaryKey(key)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read of record version - " + err.Error(), http.StatusInternalServerError)
+		return false
+	} else if count == 0 {
+		common.DisplayAppError(w, common.ControllerError, "Error in repository read of record version, no matching record found on: " + r.URL.Path, http.StatusNotFound)
+		return false
+	} else if current != version {
+		common.DisplayAppError(w, common.VersionError, fmt.Sprintf("Record was changed since read, version expected: %d, current: %d", version, current), status)
+		return false
+	}
+
+	return true
+}
+
 //
 // Create entity in the backend, no parameters,
 // body only used containing json image with values
@@ -93,15 +121,40 @@ func AccountCreateOne(w http.ResponseWriter, r *http.Request) {
 
 	// Do creation of an object
  	account := &dataRequestResource.Data
+
+	// Codes must be found in the dictionaries
+	if !checkAccountReferences(w, r, account.BscsAccount, account.OfiSapAccount) {
+		return
+	}
+
 	user := r.Header.Get("user")
-	repo, err := repository.NewAccountRepository(user, false); 
+	uow, err := repository.NewUnitOfWork(user)
 	if err != nil {
-		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating unit of work - " + err.Error(), http.StatusInternalServerError)
 		return		
 	}
-	defer repo.Close()
+	defer uow.Close()
 
-	if err := repo.Create(account); err != nil {
+	if err := uow.Accounts().Create(account); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error while creating account - " + err.Error(), http.StatusInternalServerError)
+		uow.Rollback()
+		return
+	}
+
+	// Orders of all segments are created with the account
+	if common.AppConfig.OrderStubs == "Y" {
+		segments, err := readSegments(user)
+		if err == nil {
+			_, err = createOrderStubs(uow, segments, account)
+		}
+		if err != nil {
+			common.DisplayAppError(w, common.RepositoryRunError, "Error while creating order stubs - " + err.Error(), http.StatusInternalServerError)
+			uow.Rollback()
+			return
+		}
+	}
+
+	if err := uow.Commit(); err != nil {
 		common.DisplayAppError(w, common.RepositoryRunError, "Error while creating account - " + err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -127,6 +180,13 @@ func AccountReadActiveAll(w http.ResponseWriter, r *http.Request) {
 	// Perform repository parameteric read using query parameters provided
 	var err error
 
+	// Paging, sorting and filters of the list
+	query, err := common.GetListQuery(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Do bulk read with the key from path variables
 	user := r.Header.Get("user")
 	repo, err := repository.NewAccountRepository(user, false);
@@ -138,11 +198,12 @@ func AccountReadActiveAll(w http.ResponseWriter, r *http.Request) {
 	
 	// Do select on account with key pattern
 	account := &models.Account{}
-	accounts, err := repo.ReadBulkByPartialKey(account)
+	accounts, total, err := repo.ReadBulkByPartialKey(account, query)
 	if err != nil {
-		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		displayListReadError(w, err)
 		return	
 	}
+	w.Header().Set("ETag", accountsETag(accounts))
 
 	switch ct := r.Header.Get("Content-Type"); ct {
 	case "application/csv":
@@ -160,13 +221,15 @@ func AccountReadActiveAll(w http.ResponseWriter, r *http.Request) {
 			common.DisplayAppError(w, common.EncoderExcelError, "Error in payload make - " + err.Error(), http.StatusInternalServerError)
 			return	
 		} else {
-			WriteResponse(w, http.StatusNotImplemented, payload, ct)
+			WriteResponse(w, http.StatusOK, payload, ct)
 		}
 		
 	default:
 		// Return selection result set with headers and appropriate status
 		var dataReplyResource = resources.AccountsReplyResource{
 			Count: int64(len(accounts)),
+			Total: total,
+			Next:  query.NextPage(r.URL.Path, total),
 			Data:  accounts,
 		}
 		if j, err := json.Marshal(dataReplyResource); err != nil {
@@ -195,6 +258,13 @@ func AccountReadSome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Paging, sorting and filters of the list
+	query, err := common.GetListQuery(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Do bulk read with the key from path variables
 	user := r.Header.Get("user")
 	repo, err := repository.NewAccountRepository(user, false);
@@ -215,15 +285,18 @@ func AccountReadSome(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	// Do select on account with key pattern
-	accounts, err := repo.ReadBulkByPartialKey(account)
+	accounts, total, err := repo.ReadBulkByPartialKey(account, query)
 	if err != nil {
-		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		displayListReadError(w, err)
 		return	
 	}
+	w.Header().Set("ETag", accountsETag(accounts))
 
 	// Return selection result set with headers and appropriate status
 	var dataReplyResource = resources.AccountsReplyResource{
 		Count: int64(len(accounts)),
+		Total: total,
+		Next:  query.NextPage(r.URL.Path, total),
 		Data:  accounts,
 	}
 	if j, err := json.Marshal(dataReplyResource); err != nil {
@@ -236,6 +309,60 @@ func AccountReadSome(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Read from account, status: %d", http.StatusOK)
 }
 
+//
+// Read single entity from backend by primary key: {status}/{release}/{account},
+// the record version is returned as ETag to be used in If-Match of the modifications
+//
+func AccountReadOne(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	var err error
+	account := &models.Account{}
+	if account.Status, account.ReleaseId, account.BscsAccount, err = getAccountPathVars4KeyAccess(r); err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	// Obtain last release with helper
+	if account.ReleaseId == "last" {
+		if id, err := repo.GetMaxRelease(); err != nil {
+			common.DisplayAppError(w, common.RepositoryRunError, "Error in obtaining max release - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			account.ReleaseId = fmt.Sprintf("%d", id)
+		}
+	}
+
+	count, err := repo.ReadByPrimaryKey(account)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else if count == 0 {
+		common.DisplayAppError(w, common.ControllerError, "Error in repository read, no matching record found on: " + r.URL.Path, http.StatusNotFound)
+		return
+	}
+
+	// Return the record with its version
+	w.Header().Set("ETag", common.ETag(account.RecVersion))
+	var dataReplyResource = resources.AccountReplyResource{Data: *account}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Read from account, status: %d", http.StatusOK)
+}
+
 //
 // Makes modifications to a single record accessed
 // by primary key: {statu}/{release}/{account}
@@ -243,6 +370,13 @@ func AccountReadSome(w http.ResponseWriter, r *http.Request) {
 func AccountUpdateOne(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Start processing request url: %s", r.URL.Path)
 
+	// Version of the record read by the client if any
+	version, status, check, err := getExpectedVersion(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting record version - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Decode the incoming CustomerSegment json with values to be used in patch
 	var dataRequestResource resources.AccountRequestResource
 	log.Printf("Decoding payload")
@@ -254,24 +388,35 @@ func AccountUpdateOne(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Decoded payload: %#v", account)
 	
 	// Decode key values of the single record to updated
-	var err error
 	if account.Status, account.ReleaseId, account.BscsAccount, err = getAccountPathVars4KeyAccess(r); err != nil {
 		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	// The key is not changed, only the SAP account must be found in the dictionary
+	if !checkAccountReferences(w, r, "", account.OfiSapAccount) {
+		return
+	}
+
 	// Do selective update by the composite key
 	user := r.Header.Get("user")
-	repo, err := repository.NewAccountRepository(user, false)
+	repo, err := repository.NewAccountRepository(user, true)
 	if err != nil {
 		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
 		return
 	}
 	defer repo.Close()
+
+	// The record must not be changed by others since read
+	if check && !checkAccountVersion(w, r, repo, account, version, status) {
+		repo.Rollback()
+		return
+	}
 	
 	count, err := repo.UpdateByPrimaryKey(account)
 	if err != nil {
 		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository delete - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
 		return
 	} else if count == 0 {
 		common.DisplayAppError(w, common.ControllerError, "Error in repository update, no matching record found on: " + r.URL.Path, http.StatusNotFound)		
@@ -279,16 +424,31 @@ func AccountUpdateOne(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// New version of the record is returned to the client
+	if account.RecVersion, _, err = repo.ReadVersionByPrimaryKey(account); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read of record version - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
+		return
+	}
+
 	// Return final result set with headers and appropriate status
 	accounts := make([]models.Account, 1)
 	accounts[0] = *account
 	dataReplyResource := resources.AccountsReplyResource{Count: count, Data: accounts}
-	if j, err := json.Marshal(dataReplyResource); err != nil {
+	j, err := json.Marshal(dataReplyResource)
+	if err != nil {
 		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
 		return
-	} else {
-		WriteResponseJson(w, http.StatusOK, j)
 	}
+
+	// The response is written only after the changes are committed
+	if err := repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", common.ETag(account.RecVersion))
+	WriteResponseJson(w, http.StatusOK, j)
 	
 	log.Printf("Updated account, status: %d", http.StatusOK)
 }
@@ -299,12 +459,22 @@ func AccountUpdateOne(w http.ResponseWriter, r *http.Request) {
 //
 func AccountUpdateAttributes(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	// Version of the record read by the client if any
+	version, status, check, err := getExpectedVersion(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting record version - " + err.Error(), http.StatusBadRequest)
+		return
+	}
 	
 	evals, _, err := common.GetEvaluations(r)
 	if err != nil {
 		common.DisplayAppError(w, common.ControllerError, "Error in getting evaluations - " + err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	// Record version is a precondition, not an attribute to be set
+	delete(*evals, "recVersion")
 	
 	log.Printf("Evaluations: %#v", *evals)
 	
@@ -315,6 +485,13 @@ func AccountUpdateAttributes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Codes being set must be found in the dictionaries
+	bscsAccount, _ := (*evals)["bscsAccount"].(string)
+	ofiSapAccount, _ := (*evals)["ofiSapAccount"].(string)
+	if !checkAccountReferences(w, r, bscsAccount, ofiSapAccount) {
+		return
+	}
+
 	// Do selective update by the composite key
 	user := r.Header.Get("user")
 	repo, err := repository.NewAccountRepository(user, true)
@@ -323,6 +500,12 @@ func AccountUpdateAttributes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	defer repo.Close()
+
+	// The record must not be changed by others since read
+	if check && !checkAccountVersion(w, r, repo, key, version, status) {
+		repo.Rollback()
+		return
+	}
 	
 	var count int64
 	for attribute, value := range *evals {
@@ -367,19 +550,31 @@ func AccountUpdateAttributes(w http.ResponseWriter, r *http.Request) {
 	account.UpdateDateStr = key.UpdateDateStr
 	account.UpdateOwner = key.UpdateOwner
 
+	// New version of the record is returned to the client
+	if account.RecVersion, _, err = repo.ReadVersionByPrimaryKey(key); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read of record version - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
+		return
+	}
+
 	// Return final result set with headers and appropriate status
 	var accounts []models.Account = make([]models.Account, 1)
 	accounts[0] = *account
 	dataReplyResource := resources.AccountsReplyResource{Count: count, Data: accounts}
-	if j, err := json.Marshal(dataReplyResource); err != nil {
+	j, err := json.Marshal(dataReplyResource)
+	if err != nil {
 		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
 		repo.Rollback()
 		return
-	} else {
-		WriteResponseJson(w, http.StatusOK, j)
 	}
 
-	repo.Commit()
+	// The response is written only after the changes are committed
+	if err := repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", common.ETag(account.RecVersion))
+	WriteResponseJson(w, http.StatusOK, j)
 	
 	log.Printf("Updated account, status: %d", http.StatusOK)
 }
@@ -391,8 +586,14 @@ func AccountUpdateAttributes(w http.ResponseWriter, r *http.Request) {
 func AccountDeleteOne(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Start processing request url: %s", r.URL.Path)
 
+	// Version of the record read by the client if any
+	version, status, check, err := getExpectedVersion(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting record version - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Extract parameters
-	var err error
 	account := &models.Account{}
 	if account.Status, account.ReleaseId, account.BscsAccount, err = getAccountPathVars4KeyAccess(r); err != nil {
 		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
@@ -401,19 +602,27 @@ func AccountDeleteOne(w http.ResponseWriter, r *http.Request) {
 
 	// Do selective delete by the key
 	user := r.Header.Get("user")
-	repo, err := repository.NewAccountRepository(user, false)
+	repo, err := repository.NewAccountRepository(user, true)
 	if err != nil {
 		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
 		return
 	}
 	defer repo.Close()
+
+	// The record must not be changed by others since read
+	if check && !checkAccountVersion(w, r, repo, account, version, status) {
+		repo.Rollback()
+		return
+	}
 	
 	count, err := repo.DeleteByPrimaryKey(account)
 	if err != nil {
 		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository delete - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
 		return
 	} else if count == 0 {
 		common.DisplayAppError(w, common.ControllerError, "Error in repository delete, no matching record found on: " + r.URL.Path, http.StatusNotFound)
+		repo.Rollback()
 		return
 	}
 
@@ -421,12 +630,19 @@ func AccountDeleteOne(w http.ResponseWriter, r *http.Request) {
 	var accounts []models.Account = make([]models.Account, 1)
 	accounts[0] = *account	
 	dataReplyResource := resources.AccountsReplyResource{Count: count, Data: accounts}
-	if j, err := json.Marshal(dataReplyResource); err != nil {
+	j, err := json.Marshal(dataReplyResource)
+	if err != nil {
 		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
+		return
+	}
+
+	// The response is written only after the changes are committed
+	if err := repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
 		return
-	} else {
-		WriteResponseJson(w, http.StatusOK, j)
 	}
+	WriteResponseJson(w, http.StatusOK, j)
 	
 	log.Printf("Deleted account, status: %d", http.StatusOK)
 }
@@ -452,6 +668,8 @@ func AccountDeleteAll(w http.ResponseWriter, r *http.Request) {
 		common.DisplayAppError(w, err, "Error in repository delete", http.StatusInternalServerError)
 		return
 	} else {
+		// the mappings in production are deleted too
+		resolveCacheReset()
 		WriteResponseJson(w, http.StatusOK, nil)
 	}
 	
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+	"sam-api/valid"
+)
+
+// Attributes of Account which can be imported
+var accountImportAttributes = []string{
+	"status",
+	"releaseId",
+	"bscsAccount",
+	"ofiSapAccount",
+	"validFromDate",
+	"vatCodeInd",
+	"ofiSapWbsCode",
+	"citMarkerVatFlag",
+}
+
+//
+// Create accounts in W status from CSV or XLSX payload in one transaction,
+// with query parameter dryRun=true the rows are checked and inserted but
+// the transaction is rolled back
+//
+func AccountImport(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	rows, errs, err := parseImportPayload(r, accountImportAttributes, []string{"citMarkerVatFlag"}, "bscsAccount")
+	if err != nil {
+		common.DisplayAppError(w, common.DecoderExcelError, "Error in parsing import payload - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Parsed import payload records: %d errors: %d", len(rows), len(errs))
+
+	report := &resources.ImportReplyResource{
+		Count:  int64(len(rows) + len(errs)),
+		DryRun: dryRun,
+		Errors: append([]resources.ImportErrorResource{}, errs...),
+	}
+
+	// Check all rows like in POST
+	role := r.Header.Get("role")
+	references := newAccountReferenceCheck(r.Header.Get("user"), role)
+	accounts := make([]models.Account, len(rows))
+	for i, row := range rows {
+		if err := importRecord(row, role, valid.CheckAccountAttribute, &accounts[i]); err != nil {
+			report.Errors = append(report.Errors, importError(row, accounts[i].BscsAccount, err))
+			continue
+		}
+
+		// Codes must be found in the dictionaries
+		if info, err := references.check(accounts[i].BscsAccount, accounts[i].OfiSapAccount); err != nil {
+			common.DisplayAppError(w, common.RepositoryRunError, "Error in check of account references - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else if info != "" {
+			report.Errors = append(report.Errors, importError(row, accounts[i].BscsAccount, errors.New(info)))
+		}
+	}
+
+	// Insert all or nothing
+	if len(report.Errors) == 0 {
+		user := r.Header.Get("user")
+		uow, err := repository.NewUnitOfWork(user)
+		if err != nil {
+			common.DisplayAppError(w, common.RepositoryNewError, "Error while creating unit of work - " + err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer uow.Close()
+
+		// Orders of all segments are created with the accounts
+		segments := []models.DictionarySegment{}
+		if common.AppConfig.OrderStubs == "Y" {
+			if segments, err = readSegments(user); err != nil {
+				common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+
+		for i := range accounts {
+			accounts[i].Status, accounts[i].ReleaseId = "W", "0"
+			if err := uow.Accounts().Create(&accounts[i]); err != nil {
+				report.Errors = append(report.Errors, importError(rows[i], accounts[i].BscsAccount, err))
+				break
+			}
+			if _, err := createOrderStubs(uow, segments, &accounts[i]); err != nil {
+				report.Errors = append(report.Errors, importError(rows[i], accounts[i].BscsAccount, err))
+				break
+			}
+			report.Imported++
+		}
+
+		if dryRun || len(report.Errors) > 0 {
+			uow.Rollback()
+			if len(report.Errors) > 0 {
+				report.Imported = 0
+			}
+		} else if err := uow.Commit(); err != nil {
+			common.DisplayAppError(w, common.RepositoryRunError, "Error in commit of import - " + err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	writeImportReport(w, report)
+}
@@ -0,0 +1,131 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+)
+
+//
+// Check of the account references in GLACCOUNTS and SAP_OFI_ACCOUNTS,
+// the codes found are kept so that the import reads each code once
+//
+type accountReferenceCheck struct {
+	user, role, level string
+	bscs              map[string][]models.DictionaryAccountBscs
+	sap               map[string][]models.DictionaryAccountSap
+}
+
+func newAccountReferenceCheck(user, role string) *accountReferenceCheck {
+	return &accountReferenceCheck{
+		user:  user,
+		role:  role,
+		level: common.AppConfig.AccountReference,
+		bscs:  make(map[string][]models.DictionaryAccountBscs),
+		sap:   make(map[string][]models.DictionaryAccountSap),
+	}
+}
+
+// filter of the dictionary on the code
+func referenceQuery(attribute, code string) *common.ListQuery {
+	return &common.ListQuery{Filters: []common.Filter{{Attribute: attribute, Op: "=", Value: code}}}
+}
+
+func (c *accountReferenceCheck) readBscs(code string) (entries []models.DictionaryAccountBscs, err error) {
+	if entries, ok := c.bscs[code]; ok {
+		return entries, nil
+	}
+
+	repo, err := repository.NewDictionaryAccountBscsRepository(c.user)
+	if err != nil {
+		return
+	}
+	defer repo.Close()
+
+	if entries, _, err = repo.ReadAll(referenceQuery("account", code)); err == nil {
+		c.bscs[code] = entries
+	}
+
+	return
+}
+
+func (c *accountReferenceCheck) readSap(code string) (entries []models.DictionaryAccountSap, err error) {
+	if entries, ok := c.sap[code]; ok {
+		return entries, nil
+	}
+
+	repo, err := repository.NewDictionaryAccountSapRepository(c.user)
+	if err != nil {
+		return
+	}
+	defer repo.Close()
+
+	if entries, _, err = repo.ReadAll(referenceQuery("sapOfiAccount", code)); err == nil {
+		c.sap[code] = entries
+	}
+
+	return
+}
+
+//
+// The BSCS account must exist and be active, the SAP account must exist
+// unless the level is staged and the Booker maps to the one being created
+// and it must not be deactivated by the import of the SAP master, the info
+// names the code not accepted, empty codes are not checked
+//
+func (c *accountReferenceCheck) check(bscsAccount, ofiSapAccount string) (info string, err error) {
+	if c.level == common.AccountReferenceNone {
+		return
+	}
+
+	if bscsAccount != "" {
+		entries, err := c.readBscs(bscsAccount)
+		if err != nil {
+			return "", err
+		} else if len(entries) == 0 {
+			return fmt.Sprintf("Unknown BSCS account: %s", bscsAccount), nil
+		} else if entries[0].Active != "Y" {
+			return fmt.Sprintf("Inactive BSCS account: %s", bscsAccount), nil
+		}
+	}
+
+	if ofiSapAccount != "" {
+		entries, err := c.readSap(ofiSapAccount)
+		if err != nil {
+			return "", err
+		} else if len(entries) == 0 {
+			if c.level == common.AccountReferenceStaged && c.role == "Booker" {
+				log.Printf("Staged SAP account: %s of BSCS account: %s", ofiSapAccount, bscsAccount)
+				return "", nil
+			}
+			return fmt.Sprintf("Unknown SAP account: %s", ofiSapAccount), nil
+		} else if !entries[0].IsActive() {
+			return fmt.Sprintf("Inactive SAP account: %s", ofiSapAccount), nil
+		}
+	}
+
+	return
+}
+
+//
+// Check the references of the account being stored, the reply is sent
+// with 422 if a code is not accepted or with 500 if the check failed
+//
+func checkAccountReferences(w http.ResponseWriter, r *http.Request, bscsAccount, ofiSapAccount string) (ok bool) {
+	c := newAccountReferenceCheck(r.Header.Get("user"), r.Header.Get("role"))
+	info, err := c.check(bscsAccount, ofiSapAccount)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in check of account references - " + err.Error(), http.StatusInternalServerError)
+		return false
+	} else if info != "" {
+		log.Printf("Reference error: %s", info)
+		common.DisplayAppError(w, common.ValidationError, info, http.StatusUnprocessableEntity)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Move the account from C to W, it must not be in W already
+func rejectAccount(repo repository.AccountRepository, account *models.Account, reason string) (status int, err error) {
+	account.Status, account.ReleaseId = "C", "0"
+	count, err := repo.ReadByPrimaryKey(account)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	} else if count == 0 {
+		return http.StatusNotFound, fmt.Errorf("no matching record found in status C: %s", account.BscsAccount)
+	}
+
+	work := &models.Account{Status: "W", ReleaseId: "0", BscsAccount: account.BscsAccount}
+	if count, err = repo.ReadByPrimaryKey(work); err != nil {
+		return http.StatusInternalServerError, err
+	} else if count > 0 {
+		return http.StatusConflict, fmt.Errorf("record already in status W: %s", account.BscsAccount)
+	}
+
+	if count, err = repo.Reject(account, reason); err != nil {
+		return http.StatusInternalServerError, err
+	} else if count == 0 {
+		return http.StatusNotFound, fmt.Errorf("no matching record found in status C: %s", account.BscsAccount)
+	}
+
+	// current values with the new version
+	if _, err = repo.ReadByPrimaryKey(account); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+//
+// Reject the account accessed by primary key: {status}/{release}/{account}
+// back to W with the mandatory reason, the owner is notified
+//
+func AccountReject(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	// Version of the record read by the client if any
+	version, status, check, err := getExpectedVersion(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting record version - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reject, err := getRejectPayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ValidationError, "Invalid reject json request - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	account := &models.Account{}
+	if account.Status, account.ReleaseId, account.BscsAccount, err = getAccountPathVars4KeyAccess(r); err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = checkRejectKey(account.Status, account.ReleaseId); err != nil {
+		common.DisplayAppError(w, common.ValidationError, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewAccountRepository(user, true)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	// The record must not be changed by others since read
+	if check && !checkAccountVersion(w, r, repo, account, version, status) {
+		repo.Rollback()
+		return
+	}
+
+	if status, err := rejectAccount(repo, account, reject.Reason); err != nil {
+		repo.Rollback()
+		displayRejectError(w, status, err)
+		return
+	}
+
+	if err = repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the rejection is done, the mail failure must not make the client repeat it
+	if err = rejectNotify(user, "Account", reject.Reason, map[string][]string{account.EntryOwner: {account.BscsAccount}}); err != nil {
+		log.Printf("Rejection committed, error in sending mail: %s", err.Error())
+	}
+
+	w.Header().Set("ETag", common.ETag(account.RecVersion))
+	var dataReplyResource = resources.AccountReplyResource{Data: *account}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Rejected account, status: %d", http.StatusOK)
+}
+
+//
+// Reject the accounts listed in the payload back to W with the mandatory
+// reason in one transaction, the owners are notified
+//
+func AccountRejectBulk(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	reject, err := getRejectPayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ValidationError, "Invalid reject json request - " + err.Error(), http.StatusBadRequest)
+		return
+	} else if len(reject.Entries) == 0 {
+		common.DisplayAppError(w, common.ValidationError, "Invalid reject json request - no entries", http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewAccountRepository(user, true)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	accounts := []models.Account{}
+	owners := make(map[string][]string)
+	for _, e := range reject.Entries {
+		account := models.Account{BscsAccount: e.BscsAccount}
+		if status, err := rejectAccount(repo, &account, reject.Reason); err != nil {
+			repo.Rollback()
+			displayRejectError(w, status, err)
+			return
+		}
+		accounts = append(accounts, account)
+		owners[account.EntryOwner] = append(owners[account.EntryOwner], account.BscsAccount)
+	}
+
+	if err = repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the rejection is done, the mail failure must not make the client repeat it
+	if err = rejectNotify(user, "Account", reject.Reason, owners); err != nil {
+		log.Printf("Rejection committed, error in sending mail: %s", err.Error())
+	}
+
+	var dataReplyResource = resources.AccountsReplyResource{
+		Count: int64(len(accounts)),
+		Total: int64(len(accounts)),
+		Data:  accounts,
+	}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Rejected accounts: %d, status: %d", len(accounts), http.StatusOK)
+}
@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+	"sam-api/valid"
+)
+
+//
+// Create or overwrite the account in W from the log entry with the id,
+// the restored values are checked for the role and in the dictionaries
+// and the restore is written to the log
+//
+func restoreAccount(uow repository.UnitOfWork, r *http.Request, bscsAccount, id string) (account *models.Account, status int, err error) {
+	logs, err := uow.Accounts().ReadLog(bscsAccount)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	var entry *models.AccountLog
+	for i := range logs {
+		if logs[i].Id == id {
+			entry = &logs[i]
+		}
+	}
+	if entry == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("no log entry found of account: %s id: %s", bscsAccount, id)
+	}
+
+	restored := entry.CloneIntoWork()
+	restored.ValidFromDateStr = entry.ValidFromDateStr
+
+	current := &models.Account{Status: "W", ReleaseId: "0", BscsAccount: bscsAccount}
+	count, err := uow.Accounts().ReadByPrimaryKey(current)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	method, changes := "POST", restored.RestoreChanges(nil)
+	if count > 0 {
+		method, changes = "PATCH", restored.RestoreChanges(current)
+	}
+	role := r.Header.Get("role")
+	if info := checkRestoreChanges(valid.CheckAccountAttribute, method, role, changes); info != "" {
+		return nil, http.StatusForbidden, fmt.Errorf("%s", info)
+	}
+
+	// Codes must be found in the dictionaries like in POST or PATCH
+	bscs, sap := "", ""
+	if count == 0 {
+		bscs = bscsAccount
+	}
+	if _, ok := changes["ofiSapAccount"]; ok {
+		sap = restored.OfiSapAccount
+	}
+	if info, err := newAccountReferenceCheck(r.Header.Get("user"), role).check(bscs, sap); err != nil {
+		return nil, http.StatusInternalServerError, err
+	} else if info != "" {
+		return nil, http.StatusUnprocessableEntity, fmt.Errorf("%s", info)
+	}
+
+	if count == 0 {
+		err = uow.Accounts().Create(&restored)
+		status = http.StatusCreated
+	} else {
+		_, err = uow.Accounts().UpdateByPrimaryKey(&restored)
+		status = http.StatusOK
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	// current values with the new version
+	if _, err = uow.Accounts().ReadByPrimaryKey(&restored); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if err = uow.Accounts().LogRestore(&restored); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &restored, status, nil
+}
+
+//
+// Restore the account in W from the log entry with the id in the payload,
+// the account is created if it is not in W or overwritten otherwise
+//
+func AccountRestore(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	bscsAccount, err := getAccountPathVars4LogAccess(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	restore, err := getRestorePayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ValidationError, "Invalid restore json request - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	uow, err := repository.NewUnitOfWork(user)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating unit of work - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer uow.Close()
+
+	account, status, err := restoreAccount(uow, r, bscsAccount, restore.Id)
+	if err != nil {
+		uow.Rollback()
+		displayRestoreError(w, status, err)
+		return
+	}
+
+	if err = uow.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in commit of restore - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", common.ETag(account.RecVersion))
+	var dataReplyResource = resources.AccountReplyResource{Data: *account}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, status, j)
+	}
+
+	log.Printf("Restored account: %s from log entry: %s, status: %d", bscsAccount, restore.Id, status)
+}
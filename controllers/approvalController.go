@@ -0,0 +1,465 @@
+/*
+
+PACKAGE: Approval controller layer
+
+The release is requested by one user and approved or rejected
+by other user with the same role, so that the status transition
+W -> C or C -> P and the revoke P -> W are always seen by two
+persons. The approval does the release in the same transaction as
+the step of the request.
+
+The operations are:
+
+  Create
+  ReadAll
+  ReadPending
+  ReadOne
+  Approve
+  Reject
+
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Roles doing the status transition
+var approvalRoles = []string{"Booker", "Control"}
+
+// Payload of the request with the comment, it may be empty
+func getApprovalPayload(r *http.Request) (approval models.Approval, err error) {
+	if r.Body == nil {
+		return
+	}
+
+	var payload []byte
+	if payload, err = ioutil.ReadAll(r.Body); err != nil || len(bytes.TrimSpace(payload)) == 0 {
+		return
+	}
+
+	var dataRequestResource resources.ApprovalRequestResource
+	if err = json.Unmarshal(payload, &dataRequestResource); err != nil {
+		err = fmt.Errorf("Invalid Approval json request: %s", err.Error())
+		return
+	}
+	approval = dataRequestResource.Data
+
+	return
+}
+
+// Requested release of the transition C -> P: new, last or the id of existing one,
+// of the revoke P -> W: last or the id
+func getApprovalRelease(from, release string) (string, error) {
+	if from == "W" {
+		return "0", nil
+	}
+
+	if from == "P" && (release == "" || release == "new") {
+		return "", fmt.Errorf("Missing release to be revoked")
+	}
+
+	switch release {
+	case "", "new":
+		return "new", nil
+
+	case "last":
+		return release, nil
+
+	default:
+		if id, err := strconv.ParseInt(release, 10, 64); err != nil || id <= 0 {
+			return "", fmt.Errorf("Invalid release: %s", release)
+		}
+		return release, nil
+	}
+}
+
+// Release id and operation of the approved request
+func resolveApprovalRelease(a *models.Approval, ar repository.AccountRepository) (release int64, opcode string, err error) {
+	if a.FromStatus == "W" {
+		return 0, "N", nil
+	}
+
+	switch a.ReleaseId {
+	case "new":
+		if release, err = ar.GetMaxRelease(); err == nil {
+			release, opcode = release + 1, "N"
+		}
+
+	case "last":
+		release, err = ar.GetMaxRelease()
+		opcode = "A"
+
+	default:
+		release, err = strconv.ParseInt(a.ReleaseId, 10, 64)
+		opcode = "A"
+	}
+
+	if a.FromStatus == "P" {
+		opcode = "R"
+	}
+
+	return
+}
+
+// Reply with the request and its steps
+func writeApproval(w http.ResponseWriter, status int, approval *models.Approval) {
+	if j, err := json.Marshal(resources.ApprovalReplyResource{Data: *approval}); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, status, j)
+	}
+
+	log.Printf("Approval: %d, state: %s, status: %d", approval.ApprovalId, approval.State, status)
+}
+
+// Reply with the list of requests
+func writeApprovals(w http.ResponseWriter, approvals []models.Approval) {
+	var dataReplyResource = resources.ApprovalsReplyResource{
+		Count: int64(len(approvals)),
+		Data:  approvals,
+	}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Read approvals: %d, status: %d", len(approvals), http.StatusOK)
+}
+
+//
+// Raise the request of the release for the transition of the role,
+// the payload may give the release: new, last or its id and the comment
+//
+func ApprovalCreate(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	user := r.Header.Get("user")
+	role := r.Header.Get("role")
+	if !common.MemberOf(role, approvalRoles...) {
+		common.DisplayAppError(w, common.AuthorizationError, "Release may not be requested by role: " + role, http.StatusForbidden)
+		return
+	}
+	from, into := getTransitForRole(role)
+
+	payload, err := getApprovalPayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.DecoderJsonError, "Error in decoding approval payload - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// the revoke of the release in P is requested with intoStatus W
+	if payload.IntoStatus == "W" {
+		from, into = "P", "W"
+	}
+
+	release, err := getApprovalRelease(from, payload.ReleaseId)
+	if err != nil {
+		common.DisplayAppError(w, common.ValidationError, "Error in approval payload - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	repo, err := repository.NewApprovalRepository(user, true)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	approval := &models.Approval{
+		FromStatus:  from,
+		IntoStatus:  into,
+		ReleaseId:   release,
+		RequestRole: role,
+		Comment:     payload.Comment,
+	}
+	if err = repo.Create(approval); err != nil {
+		repo.Rollback()
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository create - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	repo.Commit()
+
+	writeApproval(w, http.StatusCreated, approval)
+}
+
+// List of requests in the state, all if empty
+func approvalRead(w http.ResponseWriter, r *http.Request, state string) {
+	user := r.Header.Get("user")
+	repo, err := repository.NewApprovalRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	approvals, err := repo.ReadAll(state)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeApprovals(w, approvals)
+}
+
+//
+// Read all requests of the release with their steps, optionally in state given by query parameter
+//
+func ApprovalReadAll(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	state := r.URL.Query().Get("state")
+	if state != "" && !common.MemberOf(state, models.ApprovalRequested, models.ApprovalApproved, models.ApprovalRejected) {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid state: " + state, http.StatusBadRequest)
+		return
+	}
+
+	approvalRead(w, r, state)
+}
+
+//
+// Read the requests of the release waiting for approval
+//
+func ApprovalReadPending(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	approvalRead(w, r, models.ApprovalRequested)
+}
+
+//
+// Read one request of the release with its steps
+//
+func ApprovalReadOne(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	id, err := common.PathVariableInt64(r, "id", true)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Error in url path - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewApprovalRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	approval := &models.Approval{ApprovalId: id}
+	if count, err := repo.ReadByPrimaryKey(approval); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else if count == 0 {
+		common.DisplayAppError(w, common.ControllerError, "Error in repository read, no matching record found on: " + r.URL.Path, http.StatusNotFound)
+		return
+	}
+
+	writeApproval(w, http.StatusOK, approval)
+}
+
+// Pending request which may be decided by the user with the role, else the status of error
+func approvalDecidable(r *http.Request, repo repository.ApprovalRepository) (approval *models.Approval, status int, err error) {
+	id, err := common.PathVariableInt64(r, "id", true)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+
+	approval = &models.Approval{ApprovalId: id}
+	if count, err := repo.ReadByPrimaryKey(approval); err != nil {
+		return nil, http.StatusInternalServerError, err
+	} else if count == 0 {
+		return nil, http.StatusNotFound, fmt.Errorf("No matching record found on: %s", r.URL.Path)
+	}
+
+	user := r.Header.Get("user")
+	role := r.Header.Get("role")
+	switch {
+	case approval.State != models.ApprovalRequested:
+		return nil, http.StatusConflict, fmt.Errorf("Request %d is not pending, state: %s", id, approval.State)
+
+	case approval.RequestOwner == user:
+		return nil, http.StatusForbidden, fmt.Errorf("Request %d may not be decided by its owner: %s", id, user)
+
+	case approval.RequestRole != role:
+		return nil, http.StatusForbidden, fmt.Errorf("Request %d may be decided only by role: %s", id, approval.RequestRole)
+	}
+
+	return approval, http.StatusOK, nil
+}
+
+//
+// Approve the pending request by other user with the role of the request,
+// the release is done in the same transaction as the approval
+//
+func ApprovalApprove(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	user := r.Header.Get("user")
+	role := r.Header.Get("role")
+
+	payload, err := getApprovalPayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.DecoderJsonError, "Error in decoding approval payload - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// release, its registry and the request in one transaction
+	uow, err := repository.NewUnitOfWork(user)
+	if err != nil {
+		common.DisplayAppError(w, err, "Error while creating repository", http.StatusInternalServerError)
+		return
+	}
+	defer uow.Close()
+
+	approval, status, err := approvalDecidable(r, uow.Approvals())
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, common.ControllerError, "Error in approval - " + err.Error(), status)
+		return
+	}
+
+	release, opcode, err := resolveApprovalRelease(approval, uow.Accounts())
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in loading release", http.StatusInternalServerError)
+		return
+	}
+
+	// valid dates of the released entries are checked per account and segment,
+	// the revoked release must not be valid yet and Work must be empty
+	var violations []models.ReleaseViolation
+	if approval.FromStatus == "P" {
+		violations, err = revokeCheck(uow, release, time.Now())
+	} else {
+		violations, err = releaseCheck(uow, approval.FromStatus, time.Now())
+	}
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in release check", http.StatusInternalServerError)
+		return
+	}
+	if len(violations) > 0 {
+		uow.Rollback()
+		writeReleaseViolations(w, violations)
+		return
+	}
+
+	var accounts, orders int64
+	if opcode == "R" {
+		accounts, orders, err = revokeApply(uow, release)
+	} else {
+		accounts, orders, err = releaseApply(uow, approval.FromStatus, approval.IntoStatus, release, opcode, approval.Comment)
+	}
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
+		return
+	}
+
+	approval.ReleaseId = strconv.FormatInt(release, 10)
+	if count, err := uow.Approvals().Decide(approval, models.ApprovalApproved, role, payload.Comment); err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository update - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else if count == 0 {
+		uow.Rollback()
+		common.DisplayAppError(w, common.ControllerError, "Error in approval, request is not pending: " + r.URL.Path, http.StatusConflict)
+		return
+	}
+
+	if _, err = uow.Approvals().ReadByPrimaryKey(approval); err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = uow.Commit(); err != nil {
+		common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Approved request: %d released accounts: %d, orders: %d", approval.ApprovalId, accounts, orders)
+
+	// the mappings in production are changed
+	resolveCacheReset()
+	releaseDeliver(user, approval.IntoStatus, release)
+
+	if err = releaseNotify(user, role, approval.IntoStatus, release); err != nil {
+		common.DisplayAppError(w, err, "Release committed, error in sending mail", http.StatusInternalServerError)
+		return
+	}
+
+	writeApproval(w, http.StatusOK, approval)
+}
+
+//
+// Reject the pending request by other user with the role of the request,
+// the comment is mandatory
+//
+func ApprovalReject(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	user := r.Header.Get("user")
+	role := r.Header.Get("role")
+
+	payload, err := getApprovalPayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.DecoderJsonError, "Error in decoding approval payload - " + err.Error(), http.StatusBadRequest)
+		return
+	} else if payload.Comment == "" {
+		common.DisplayAppError(w, common.ValidationError, "Missing mandatory comment of the rejection", http.StatusBadRequest)
+		return
+	}
+
+	repo, err := repository.NewApprovalRepository(user, true)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	approval, status, err := approvalDecidable(r, repo)
+	if err != nil {
+		repo.Rollback()
+		common.DisplayAppError(w, common.ControllerError, "Error in rejection - " + err.Error(), status)
+		return
+	}
+
+	if count, err := repo.Decide(approval, models.ApprovalRejected, role, payload.Comment); err != nil {
+		repo.Rollback()
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository update - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else if count == 0 {
+		repo.Rollback()
+		common.DisplayAppError(w, common.ControllerError, "Error in rejection, request is not pending: " + r.URL.Path, http.StatusConflict)
+		return
+	}
+
+	if _, err = repo.ReadByPrimaryKey(approval); err != nil {
+		repo.Rollback()
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	repo.Commit()
+
+	log.Printf("Rejected request: %d", approval.ApprovalId)
+
+	writeApproval(w, http.StatusOK, approval)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"fmt"
 	"log"
 	"net/http"
 
@@ -35,3 +36,38 @@ func WriteResponse(w http.ResponseWriter, status int, payload []byte, ct string)
 	}
 }
 
+
+//
+// Record version the client has read before the modification. The If-Match
+// header is checked first, then recVersion in the payload. The status to be
+// returned on mismatch is 412 for the header and 409 for the payload.
+//
+func getExpectedVersion(r *http.Request) (version int, status int, ok bool, err error) {
+	if version, ok, err = common.IfMatchVersion(r); err != nil || ok {
+		return version, http.StatusPreconditionFailed, ok, err
+	}
+
+	data, _, err := common.GetAttributesWithValues(r)
+	if err != nil {
+		return
+	}
+
+	if value, found := (*data)["recVersion"]; found {
+		if v, isNumber := value.(float64); !isNumber {
+			err = fmt.Errorf("Invalid value of recVersion: %v", value)
+		} else {
+			version, status, ok = int(v), http.StatusConflict, true
+		}
+	}
+
+	return
+}
+
+// Report failed read of a list, invalid query parameters are client errors
+func displayListReadError(w http.ResponseWriter, err error) {
+	if _, invalid := err.(common.InvalidQueryError); invalid {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+	} else {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,465 @@
+/*
+
+PACKAGE: Delivery controller layer
+
+The release in P is exported and delivered after the commit to the
+drop directory or SFTP server of configuration DeliveryTarget. The
+file is sent in background with retries, its status is recorded per
+release and updated by the acknowledgement files SAP puts in the ack
+subdirectory of the target.
+
+The operations are:
+
+  DeliveryReadAll
+  DeliveryRead
+  DeliverySend
+  DeliveryAck
+
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Deliveries in progress, a new delivery of the release stops the previous one
+var deliveries = struct {
+	m          sync.Mutex
+	generation map[string]int
+}{
+	generation: make(map[string]int),
+}
+
+// Generation of the delivery of the release being started
+func deliveryNext(release string) int {
+	deliveries.m.Lock()
+	defer deliveries.m.Unlock()
+
+	deliveries.generation[release]++
+
+	return deliveries.generation[release]
+}
+
+// The delivery is not replaced by the later one
+func deliveryCurrent(release string, generation int) bool {
+	deliveries.m.Lock()
+	defer deliveries.m.Unlock()
+
+	return deliveries.generation[release] == generation
+}
+
+// Message limited to the column size
+func deliveryMessage(err error) string {
+	if m := err.Error(); len(m) > 256 {
+		return m[:256]
+	} else {
+		return m
+	}
+}
+
+// Delivery replaced by the later one is not saved any more
+var errDeliveryReplaced = errors.New("Delivery replaced by the later one")
+
+//
+// Save the status of the delivery in own transaction if it is still the
+// current generation, the lock is held so the later one can't start in between
+//
+func deliverySave(user string, d *models.Delivery, generation int) (err error) {
+	deliveries.m.Lock()
+	defer deliveries.m.Unlock()
+
+	if deliveries.generation[d.ReleaseId] != generation {
+		return errDeliveryReplaced
+	}
+
+	repo, err := repository.NewDeliveryRepository(user, true)
+	if err != nil {
+		return
+	}
+	defer repo.Close()
+
+	if err = repo.Save(d); err != nil {
+		repo.Rollback()
+		return
+	}
+	repo.Commit()
+
+	return
+}
+
+//
+// Export the release in the configured format and record the delivery
+// pending, the file is sent in background, nil if the release is empty
+//
+func deliveryStart(user, release string) (d *models.Delivery, err error) {
+	format := common.AppConfig.DeliveryFormat
+	ct, found := releaseExportFormats[format]
+	if !found {
+		return nil, fmt.Errorf("Invalid delivery format: %s", format)
+	}
+
+	ar, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		return nil, err
+	}
+	defer ar.Close()
+
+	or, err := repository.NewOrderRepository(user, false)
+	if err != nil {
+		return nil, err
+	}
+	defer or.Close()
+
+	export, err := releaseExportLoad(ar, or, release)
+	if err != nil {
+		return nil, err
+	} else if len(export.Accounts) == 0 && len(export.Orders) == 0 {
+		return nil, nil
+	}
+
+	payload, err := releaseExportPayload(export, format)
+	if err != nil {
+		return nil, err
+	}
+
+	d = &models.Delivery{
+		ReleaseId: release,
+		Format:    format,
+		Target:    common.DeliveryTargetName(common.AppConfig.DeliveryTarget),
+		FileName:  models.DeliveryFileName(release, ct[1]),
+		Checksum:  export.Checksum(),
+		Status:    models.DeliveryPending,
+	}
+
+	// the previous delivery stops before its record is replaced
+	generation := deliveryNext(release)
+
+	// the record keeps the version of the previous delivery
+	repo, err := repository.NewDeliveryRepository(user, false)
+	if err != nil {
+		return nil, err
+	}
+	previous := models.Delivery{ReleaseId: release}
+	_, err = repo.ReadByPrimaryKey(&previous)
+	repo.Close()
+	if err != nil {
+		return nil, err
+	}
+	d.RecVersion = previous.RecVersion
+
+	if err = deliverySave(user, d, generation); err != nil {
+		return nil, err
+	}
+
+	go deliverySend(user, *d, payload, generation)
+
+	return
+}
+
+//
+// Send the file to the target retrying after the configured delay, the
+// status is saved after each attempt, Sent or finally Failed
+//
+func deliverySend(user string, d models.Delivery, payload []byte, generation int) {
+	retries, _ := strconv.Atoi(common.AppConfig.DeliveryRetries)
+	if retries < 1 {
+		retries = 1
+	}
+	delay, _ := strconv.Atoi(common.AppConfig.DeliveryRetryDelay)
+
+	for d.Attempts < retries && deliveryCurrent(d.ReleaseId, generation) {
+		if d.Attempts > 0 {
+			time.Sleep(time.Duration(delay) * time.Second)
+		}
+		d.Attempts++
+
+		err := deliveryPut(d.FileName, payload)
+		if err == nil {
+			d.Status, d.Message, d.SentDate = models.DeliverySent, "", time.Now()
+		} else {
+			log.Printf("Delivery of release: %s attempt: %d failed: %s", d.ReleaseId, d.Attempts, err.Error())
+			d.Message = deliveryMessage(err)
+			if d.Attempts >= retries {
+				d.Status = models.DeliveryFailed
+			}
+		}
+
+		if err := deliverySave(user, &d, generation); err == errDeliveryReplaced {
+			break
+		} else if err != nil {
+			log.Printf("Error in saving delivery of release: %s - %s", d.ReleaseId, err.Error())
+		}
+		if d.Status != models.DeliveryPending {
+			log.Printf("Delivery of release: %s file: %s status: %s", d.ReleaseId, d.FileName, d.Status)
+			return
+		}
+	}
+}
+
+// One attempt of the delivery of the file
+func deliveryPut(name string, payload []byte) (err error) {
+	transport, err := common.OpenDeliveryTransport(common.AppConfig.DeliveryTarget)
+	if err != nil {
+		return
+	}
+	defer transport.Close()
+
+	return transport.Put(name, payload)
+}
+
+// Deliver the release after the commit if it is in P and the target is configured
+func releaseDeliver(user, into string, release int64) {
+	if into != "P" || common.AppConfig.DeliveryTarget == "" {
+		return
+	}
+
+	if _, err := deliveryStart(user, strconv.FormatInt(release, 10)); err != nil {
+		log.Printf("Error in delivery of release: %d - %s", release, err.Error())
+	}
+}
+
+func writeDelivery(w http.ResponseWriter, status int, d *models.Delivery) {
+	var dataReplyResource = resources.DeliveryReplyResource{Data: *d}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+	} else {
+		WriteResponseJson(w, status, j)
+	}
+}
+
+//
+// Read the deliveries of all releases
+//
+func DeliveryReadAll(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewDeliveryRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating delivery repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	data, err := repo.ReadAll()
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var dataReplyResource = resources.DeliveriesReplyResource{
+		Count: int64(len(data)),
+		Data:  data,
+	}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Read deliveries: %d, status: %d", len(data), http.StatusOK)
+}
+
+//
+// Read the delivery of the release, the last one if the release is last
+//
+func DeliveryRead(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	user := r.Header.Get("user")
+	ar, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	release, err := getRelease(r, ar)
+	ar.Close()
+	if err != nil {
+		common.DisplayAppError(w, err, "Error in loading release", http.StatusInternalServerError)
+		return
+	}
+
+	repo, err := repository.NewDeliveryRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating delivery repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	d := &models.Delivery{ReleaseId: strconv.FormatInt(release, 10)}
+	if count, err := repo.ReadByPrimaryKey(d); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else if count == 0 {
+		common.DisplayAppError(w, common.ControllerError, "No delivery of release: " + d.ReleaseId, http.StatusNotFound)
+		return
+	}
+
+	writeDelivery(w, http.StatusOK, d)
+
+	log.Printf("Read delivery of release: %s status: %s", d.ReleaseId, d.Status)
+}
+
+//
+// Deliver the release again, the file is sent in background and the
+// delivery is accepted as pending
+//
+func DeliverySend(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	if common.AppConfig.DeliveryTarget == "" {
+		common.DisplayAppError(w, common.ControllerError, "Delivery target is not configured", http.StatusConflict)
+		return
+	}
+
+	user := r.Header.Get("user")
+	ar, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	release, err := getRelease(r, ar)
+	ar.Close()
+	if err != nil {
+		common.DisplayAppError(w, err, "Error in loading release", http.StatusInternalServerError)
+		return
+	}
+
+	d, err := deliveryStart(user, strconv.FormatInt(release, 10))
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error in delivery - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else if d == nil {
+		common.DisplayAppError(w, common.ControllerError, fmt.Sprintf("No accounts and orders found in release: %d", release), http.StatusNotFound)
+		return
+	}
+
+	writeDelivery(w, http.StatusAccepted, d)
+
+	log.Printf("Delivery of release: %s started, file: %s", d.ReleaseId, d.FileName)
+}
+
+// Apply the lines of the acknowledgement file to the deliveries, the number applied
+func deliveryAckApply(repo repository.DeliveryRepository, user string, acks []models.DeliveryAck) (applied int64, err error) {
+	for i := range acks {
+		ack := &acks[i]
+		if ack.Reason != "" {
+			continue
+		}
+
+		d := &models.Delivery{ReleaseId: ack.ReleaseId}
+		if count, err := repo.ReadByPrimaryKey(d); err != nil {
+			return 0, err
+		} else if count == 0 {
+			ack.Reason = "No delivery of release: " + ack.ReleaseId
+			continue
+		}
+
+		if d.Acknowledge(ack, user) {
+			if err = repo.Save(d); err != nil {
+				return 0, err
+			}
+			applied++
+		}
+	}
+
+	return
+}
+
+//
+// Ingest the acknowledgement files SAP puts in the ack subdirectory of the
+// target, the deliveries are marked Delivered or Rejected and the files
+// are moved to ack/done, the unreadable files are left in place
+//
+func DeliveryAck(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	if common.AppConfig.DeliveryTarget == "" {
+		common.DisplayAppError(w, common.ControllerError, "Delivery target is not configured", http.StatusConflict)
+		return
+	}
+
+	transport, err := common.OpenDeliveryTransport(common.AppConfig.DeliveryTarget)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error in opening delivery target - " + err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer transport.Close()
+
+	names, err := transport.List(common.DeliveryAckDir)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error in listing acknowledgement files - " + err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	user := r.Header.Get("user")
+	reply := resources.DeliveryAckReplyResource{Data: []models.DeliveryAck{}}
+	for _, name := range names {
+		if strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		file := path.Join(common.DeliveryAckDir, name)
+
+		data, err := transport.Get(file)
+		if err != nil {
+			log.Printf("Error in reading acknowledgement file: %s - %s", file, err.Error())
+			continue
+		}
+		acks, err := models.ParseDeliveryAcks(name, bytes.NewReader(data))
+		if err != nil {
+			log.Printf("Error in parsing acknowledgement file: %s - %s", file, err.Error())
+			continue
+		}
+
+		// all lines of the file or none
+		repo, err := repository.NewDeliveryRepository(user, true)
+		if err != nil {
+			common.DisplayAppError(w, common.RepositoryNewError, "Error while creating delivery repository - " + err.Error(), http.StatusInternalServerError)
+			return
+		}
+		applied, err := deliveryAckApply(repo, user, acks)
+		if err != nil {
+			repo.Rollback()
+			repo.Close()
+			common.DisplayAppError(w, common.RepositoryRunError, "Error in repository update - " + err.Error(), http.StatusInternalServerError)
+			return
+		}
+		repo.Commit()
+		repo.Close()
+
+		if err = transport.Move(file, common.DeliveryDoneDir); err != nil {
+			log.Printf("Error in moving acknowledgement file: %s - %s", file, err.Error())
+		}
+
+		reply.Files++
+		reply.Applied += applied
+		reply.Data = append(reply.Data, acks...)
+	}
+	reply.Count = int64(len(reply.Data))
+
+	if j, err := json.Marshal(reply); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Ingested acknowledgement files: %d, lines: %d, applied: %d", reply.Files, reply.Count, reply.Applied)
+}
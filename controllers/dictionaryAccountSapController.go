@@ -77,6 +77,13 @@ func DictionaryAccountSapCreate(w http.ResponseWriter, r *http.Request) {
 func DictionaryAccountSapReadAll(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Start processing request url: %s", r.URL.Path)
 
+	// Paging, sorting and filters of the list
+	query, err := common.GetListQuery(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Perform repository parameteric read using query parameters provided
 	user := r.Header.Get("user")
 	repo, err := repository.NewDictionaryAccountSapRepository(user)
@@ -86,15 +93,17 @@ func DictionaryAccountSapReadAll(w http.ResponseWriter, r *http.Request) {
 	}
 	defer repo.Close()
 	
-	entries, err := repo.ReadAll()
+	entries, total, err := repo.ReadAll(query)
 	if err != nil {
-		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		displayListReadError(w, err)
 		return
 	}
 
 	// Return selection result set with headers and appropriate status
 	var dataReplyResource = resources.DictionaryAccountSapsReplyResource{
 		Count: int64(len(entries)),
+		Total: total,
+		Next:  query.NextPage(r.URL.Path, total),
 		Data:  entries,
 	}
 	if j, err := json.Marshal(dataReplyResource); err != nil {
@@ -1,139 +1,240 @@
 package controllers
 
 import (
+	"encoding/json"
     "fmt"
-	"io/ioutil"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
     "github.com/tealeg/xlsx"
 
 	"sam-api/common"
-	"sam-api/models"
 	"sam-api/repository"
+	"sam-api/resources"
 )
 
-const (
-	CELL_ID_P_NAME int = 22
-	CELL_ID_DESC_EN_120 int = 45
-	CELL_ID_ACCOUNT_MIN int = 28
-	CELL_ID_ACCOUNT_MAX int = 39
-)
+// Size of SAP_OFI_ACCOUNT column
+const dictionaryAccountSapMaxLength = 32
 
-// Load account -> desciprion mappings from Excel sheet
-func dictionaryAccountSap(xfn string) (dictionary *map[string]string, err error) {
-	// open Excel file
-	log.Printf("Opening Excel file: %s\n", xfn)
-    xf, err := xlsx.OpenFile(xfn)
-    if err != nil {
-		return  nil, fmt.Errorf("Error opening Excel file: %s - %s", xfn, err.Error())
-    }
-	log.Printf("Opened Excel file: %s", xfn)
-
-	// load AC - AN, DESC EN(120) cells
-	var d = map[string]string {}
-    for _, sheet := range xf.Sheets {
-		log.Printf("Found Excel sheet: %s\n", sheet.Name)
-		if strings.HasPrefix(sheet.Name, "IKOS_AC_Master") {
-			log.Printf("Processing Excel sheet: %s\n", sheet.Name)
-			i := 0
-			for _, row := range sheet.Rows {
-				if i > 1 {
-					desc := row.Cells[CELL_ID_DESC_EN_120].String()
-					var account string
-					for k := CELL_ID_ACCOUNT_MIN; k < CELL_ID_ACCOUNT_MAX; k++ {
-						account += row.Cells[k].String()
-					}
-					if account != "" {
-						d[account] = desc
+// title of the column with case and spaces ignored
+func dictionaryAccountSapTitle(title string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(title), " ", "", -1))
+}
+
+// Index of the profile column titles in the header row, the first one of the repeated titles
+func dictionaryAccountSapHeader(cells []string, titles []string) map[string]int {
+	index := make(map[string]int)
+	for i := len(cells) - 1; i >= 0; i-- {
+		for _, title := range titles {
+			if dictionaryAccountSapTitle(cells[i]) == dictionaryAccountSapTitle(title) {
+				index[title] = i
+			}
+		}
+	}
+
+	return index
+}
+
+//
+// Load account -> description mappings from the sheets of the profile, the
+// header row is the first one with all columns of the profile or the rows
+// below the header rows of the profile taking the cells by the position,
+// the rows the profile can't handle are reported
+//
+func dictionaryAccountSap(payload []byte, p *common.SapImportProfile) (dictionary map[string]string, errs []resources.ImportErrorResource, err error) {
+	xf, err := xlsx.OpenBinary(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid Excel file: %s", err.Error())
+	}
+
+	titles := append(append([]string{}, p.AccountColumns...), p.DescriptionColumn)
+	description := p.DescriptionColumn
+	if p.Positional() {
+		description = fmt.Sprintf("cell %d", p.DescriptionCell)
+	}
+	dictionary = make(map[string]string)
+	found := make(map[string]int)
+	sheets := 0
+	for _, sheet := range xf.Sheets {
+		if !strings.HasPrefix(sheet.Name, p.SheetPrefix) {
+			log.Printf("Skip Excel sheet: %s", sheet.Name)
+			continue
+		}
+		log.Printf("Processing Excel sheet: %s with profile: %s", sheet.Name, p.Name)
+		sheets++
+
+		// position of the account and description cells, set by the header if not by the profile
+		var accountCells []int
+		descriptionCell := -1
+		if p.Positional() {
+			accountCells, descriptionCell = p.AccountCells, p.DescriptionCell
+		}
+		for i, row := range sheet.Rows {
+			cells := make([]string, len(row.Cells))
+			for k, c := range row.Cells {
+				cells[k] = strings.TrimSpace(c.String())
+			}
+
+			// rows above the data are skipped
+			if p.Positional() {
+				if i < p.HeaderRows {
+					continue
+				}
+			} else if descriptionCell < 0 {
+				if h := dictionaryAccountSapHeader(cells, titles); len(h) == len(titles) {
+					for _, column := range p.AccountColumns {
+						accountCells = append(accountCells, h[column])
 					}
+					descriptionCell = h[p.DescriptionColumn]
 				}
-				i++
+				continue
+			}
+
+			var parts []string
+			for _, k := range accountCells {
+				if k < len(cells) && cells[k] != "" {
+					parts = append(parts, cells[k])
+				}
+			}
+			account := strings.Join(parts, p.AccountSeparator)
+			if account == "" {
+				continue
+			}
+
+			rowError := func(msg string, args ...interface{}) {
+				errs = append(errs, resources.ImportErrorResource{Sheet: sheet.Name, Row: i + 1, Key: account, Error: fmt.Sprintf(msg, args...)})
 			}
-		} else {
-			log.Printf("Skip Excel sheet: %s\n", sheet.Name)
+			if k := descriptionCell; k >= len(cells) {
+				rowError("Row has %d cells, no column: %s", len(cells), description)
+			} else if len(account) > dictionaryAccountSapMaxLength {
+				rowError("Account longer than %d characters", dictionaryAccountSapMaxLength)
+			} else if desc, exists := dictionary[account]; exists && desc != cells[k] {
+				rowError("Account with other description in row: %d", found[account])
+			} else {
+				dictionary[account] = cells[k]
+				found[account] = i + 1
+			}
+		}
+
+		if descriptionCell < 0 {
+			errs = append(errs, resources.ImportErrorResource{Sheet: sheet.Name, Error: "No header with columns: " + strings.Join(titles, ", ")})
 		}
 	}
 
-	dictionary = &d
-	log.Printf("Loaded Excel file: %s record: %d", xfn, len(d))
+	if sheets == 0 {
+		return nil, nil, fmt.Errorf("No sheet %s of profile %s in Excel file", p.SheetPrefix, p.Name)
+	}
+
+	log.Printf("Loaded Excel file records: %d errors: %d", len(dictionary), len(errs))
 
 	return
 }
 
-// Parse Excel file loaded from request
-func parseExcelPayload(r *http.Request, encoding string) (dictionary *map[string]string, err error) {
-	log.Printf("Decoding Excel payload")
-
-    tmp, err := ioutil.TempFile(os.TempDir(), "sam-api-")
-    if err != nil {
-        return nil, fmt.Errorf("Cannot create temporary file: %s", err.Error())
-    }
-    defer os.Remove(tmp.Name())
-	log.Println("Created temporary file: " + tmp.Name())
-
-	data, err := common.GetPayload(r, encoding)
-    if _, err = tmp.Write(data); err != nil {
-        return nil, fmt.Errorf("Failed to write to temporary file: %s", err.Error())
-    }
-	dictionary, err = dictionaryAccountSap(tmp.Name())
+// Merge the accounts of the payload in XLSX format into the dictionary or preview the difference,
+// the merge is not applied if any row is rejected
+func dictionaryAccountSapMerge(w http.ResponseWriter, r *http.Request, apply bool) (reply *resources.DictionaryAccountSapMergeReplyResource, ok bool) {
+	profile, err := common.GetSapImportProfile(r.URL.Query().Get("profile"))
 	if err != nil {
-		return nil, fmt.Errorf("Failed to parse Excel file: %s", err.Error())
+		common.DisplayAppError(w, common.QueryParamError, "Error in import profile - " + err.Error(), http.StatusBadRequest)
+		return nil, false
 	}
 
-    if err = tmp.Close(); err != nil {
-        return
-    }
-
-	log.Printf("Decoded Excel payload")
-	return
-}
+	payload, err := common.GetPayload(r, r.Header.Get("Content-Encoding"))
+	if err != nil {
+		common.DisplayAppError(w, common.DecoderExcelError, "Error in reading Excel payload - " + err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
 
-//
-// Create dictionary using the payload in XLSX format
-//
-func DictionaryAccountSapCreateExcel(w http.ResponseWriter, r *http.Request) {
-	log.Printf("Start processing request url: %s", r.URL.Path)
-	
-	encoding := r.Header.Get("Content-Encoding")
-	d, err := parseExcelPayload(r, encoding)
+	d, errs, err := dictionaryAccountSap(payload, profile)
 	if err != nil {
-		common.DisplayAppError(w, common.DecoderExcelError, "Error in parsing Excel payload - " + err.Error(), http.StatusInternalServerError)
-		return		
+		common.DisplayAppError(w, common.DecoderExcelError, "Error in parsing Excel payload - " + err.Error(), http.StatusBadRequest)
+		return nil, false
 	}
 	log.Printf("Processed Excel payload")
 
+	// the empty master would deactivate the whole dictionary
+	if len(d) == 0 && len(errs) == 0 {
+		common.DisplayAppError(w, common.DecoderExcelError, "No accounts found in Excel payload", http.StatusBadRequest)
+		return nil, false
+	}
+
 	user := r.Header.Get("user")
 	repo, err := repository.NewDictionaryAccountSapRepository(user)
 	if err != nil {
 		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository - " + err.Error(), http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 	defer repo.Close()
-	
-	_, err = repo.DeleteAll()
+
+	apply = apply && len(errs) == 0
+	m, err := repo.Merge(d, apply)
 	if err != nil {
-		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository delete - " + err.Error(), http.StatusInternalServerError)
-		return
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository merge - " + err.Error(), http.StatusInternalServerError)
+		return nil, false
 	}
-	log.Printf("Dictionary cleaned up")
-	
-	for k, v := range *d { 	
-		e := &models.DictionaryAccountSap{
-			Account: k,
-			Name:    v,
-		}
-		if err := repo.Create(e); err != nil {
-			common.DisplayAppError(w, common.RepositoryRunError, "Error while creating dictionary sap account - " + err.Error(), http.StatusInternalServerError)
-			return
-		}
+
+	reply = &resources.DictionaryAccountSapMergeReplyResource{
+		Profile:   profile.Name,
+		Applied:   apply,
+		Inserted:  int64(len(m.Inserted)),
+		Updated:   int64(len(m.Updated)),
+		Removed:   int64(len(m.Removed)),
+		Unchanged: m.Unchanged,
+		Errors:    append([]resources.ImportErrorResource{}, errs...),
+		Data:      *m,
+	}
+
+	return reply, true
+}
+
+func writeDictionaryAccountSapMerge(w http.ResponseWriter, status int, reply *resources.DictionaryAccountSapMergeReplyResource) {
+	if j, err := json.Marshal(reply); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+	} else {
+		WriteResponseJson(w, status, j)
+	}
+}
+
+//
+// Merge the SAP master in XLSX format read with the import profile of query
+// parameter profile into the dictionary in one transaction, the new accounts
+// are inserted, the renamed ones updated and the ones no more in the master
+// deactivated, the reply is the difference applied, nothing is applied if
+// any row of the master is rejected
+//
+func DictionaryAccountSapCreateExcel(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	reply, ok := dictionaryAccountSapMerge(w, r, true)
+	if !ok {
+		return
 	}
 
 	// Return creation result with headers and appropriate status
-	WriteResponseJson(w, http.StatusCreated, nil)
+	status := http.StatusCreated
+	if !reply.Applied {
+		status = http.StatusUnprocessableEntity
+	}
+	writeDictionaryAccountSapMerge(w, status, reply)
 
-	log.Printf("Created dictionary accounts sap, status: %d", http.StatusCreated)
+	log.Printf("Merged dictionary accounts sap errors: %d, status: %d", len(reply.Errors), status)
 }
 
+//
+// Difference of the SAP master in XLSX format and the dictionary which
+// would be applied by the import with the rows rejected by the import
+// profile, nothing is changed
+//
+func DictionaryAccountSapPreviewExcel(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	reply, ok := dictionaryAccountSapMerge(w, r, false)
+	if !ok {
+		return
+	}
+
+	writeDictionaryAccountSapMerge(w, http.StatusOK, reply)
+
+	log.Printf("Previewed dictionary accounts sap, status: %d", http.StatusOK)
+}
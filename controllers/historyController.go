@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Filter of the history from query parameters, the dates are local days
+func getHistoryFilter(r *http.Request) (f models.HistoryFilter, err error) {
+	q := r.URL.Query()
+	for _, d := range []struct {
+		Name string
+		Date *time.Time
+	}{
+		{"from", &f.From},
+		{"to", &f.To},
+	} {
+		if v := q.Get(d.Name); v != "" {
+			if *d.Date, err = time.ParseInLocation(common.CutOffDateFormat, v, time.Local); err != nil {
+				err = fmt.Errorf("Invalid value of %s: %s", d.Name, v)
+				return
+			}
+		}
+	}
+
+	if !f.From.IsZero() && !f.To.IsZero() && f.To.Before(f.From) {
+		err = fmt.Errorf("Invalid date range from: %s to: %s", q.Get("from"), q.Get("to"))
+		return
+	}
+
+	f.OpCode = q.Get("op")
+	if f.OpCode != "" && f.OpCode != "I" && f.OpCode != "U" && f.OpCode != "D" && f.OpCode != models.OpRestore {
+		err = fmt.Errorf("Invalid value of op: %s", f.OpCode)
+		return
+	}
+
+	f.ReleaseId = q.Get("release")
+	if f.ReleaseId != "" {
+		if n, e := strconv.Atoi(f.ReleaseId); e != nil || n < 0 {
+			err = fmt.Errorf("Invalid value of release: %s", f.ReleaseId)
+			return
+		}
+	}
+
+	f.User = q.Get("user")
+	f.SegmentCode = q.Get("segment")
+
+	return
+}
+
+// Reply with the history in the format of the content type of the request
+func writeHistory(w http.ResponseWriter, r *http.Request, entries []models.HistoryEntry) {
+	h := models.History{Data: entries}
+	switch ct := r.Header.Get("Content-Type"); ct {
+	case "application/csv":
+		if payload, err := h.ToCsv(); err != nil {
+			common.DisplayAppError(w, common.EncoderCsvError, "Error in payload make - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			WriteResponse(w, http.StatusOK, payload, ct)
+		}
+
+	case "application/xlsx":
+		if payload, err := h.ToExcel(); err != nil {
+			common.DisplayAppError(w, common.EncoderExcelError, "Error in payload make - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			WriteResponse(w, http.StatusOK, payload, ct)
+		}
+
+	default:
+		var dataReplyResource = resources.HistoryReplyResource{
+			Count: int64(len(entries)),
+			Data:  entries,
+		}
+		if j, err := json.Marshal(dataReplyResource); err != nil {
+			common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			WriteResponseJson(w, http.StatusOK, j)
+		}
+	}
+}
+
+//
+// Read the changes of accounts from the log, all of them or the ones of
+// query parameter account, filtered by the query parameters from, to,
+// user, op, release and segment
+//
+func AccountReadHistory(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	filter, err := getHistoryFilter(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	logs, err := repo.ReadLog(r.URL.Query().Get("account"))
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read log - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the changes are found on the whole log before it is filtered
+	entries := filter.Apply(models.AccountHistory(logs))
+	writeHistory(w, r, entries)
+
+	log.Printf("Read history of accounts entries: %d, status: %d", len(entries), http.StatusOK)
+}
+
+//
+// Read the changes of orders from the log, all of them or the ones of
+// query parameter account, filtered by the query parameters from, to,
+// user, op, release and segment
+//
+func OrderReadHistory(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	filter, err := getHistoryFilter(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewOrderRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating order repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	logs, err := repo.ReadLog(r.URL.Query().Get("account"))
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read log - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the changes are found on the whole log before it is filtered
+	entries := filter.Apply(models.OrderHistory(logs))
+	writeHistory(w, r, entries)
+
+	log.Printf("Read history of orders entries: %d, status: %d", len(entries), http.StatusOK)
+}
@@ -0,0 +1,242 @@
+/*
+
+PACKAGE: Bulk import controller layer
+
+It provides the common part of the import of Account and Order
+records from CSV or XLSX files. The first row of the file or of
+each sheet is the header naming the attributes like in json,
+the case and spaces are ignored so the workbook produced
+by the Excel export can be loaded back. Each row is checked
+the same way as the payload of POST and all of them are
+inserted in one transaction.
+
+*/
+
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+
+	"sam-api/common"
+	"sam-api/resources"
+)
+
+// Row of the imported file with the attribute values
+type importRow struct {
+	Sheet string
+	Row   int
+	Data  map[string]interface{}
+}
+
+// Check of one attribute as done by the validation layer
+type importCheck func(method, role, k string, v interface{}) (bool, string)
+
+// header cell -> attribute, case and spaces ignored
+func importHeader(cells []string, attributes []string) (columns map[int]string) {
+	columns = make(map[int]string)
+	for i, c := range cells {
+		name := strings.ToLower(strings.Replace(strings.TrimSpace(c), " ", "", -1))
+		for _, a := range attributes {
+			if name == strings.ToLower(a) {
+				columns[i] = a
+			}
+		}
+	}
+
+	return
+}
+
+// attribute values of the row, empty cells are skipped, numbers are typed like in json
+func importValues(cells []string, columns map[int]string, numeric []string) (data map[string]interface{}, err error) {
+	data = make(map[string]interface{})
+	for i, a := range columns {
+		if i >= len(cells) || strings.TrimSpace(cells[i]) == "" {
+			continue
+		}
+		value := strings.TrimSpace(cells[i])
+		if common.MemberOf(a, numeric...) {
+			n, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return data, fmt.Errorf("Invalid number in %s: %s", a, value)
+			}
+			data[a] = n
+		} else {
+			data[a] = value
+		}
+	}
+
+	return
+}
+
+// Rows of CSV file, the separator is comma or semicolon as found in the header
+func parseImportCsv(payload []byte, attributes, numeric []string) (rows []importRow, errs []resources.ImportErrorResource, err error) {
+	reader := csv.NewReader(bytes.NewReader(payload))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if header := strings.SplitN(string(payload), "\n", 2)[0]; strings.Count(header, ";") > strings.Count(header, ",") {
+		reader.Comma = ';'
+	}
+
+	var columns map[int]string
+	for line := 1; ; line++ {
+		cells, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, nil, fmt.Errorf("Invalid CSV in line %d: %s", line, err.Error())
+		}
+
+		if columns == nil {
+			if columns = importHeader(cells, attributes); len(columns) == 0 {
+				return nil, nil, fmt.Errorf("No known attribute in CSV header")
+			}
+			continue
+		}
+
+		if data, err := importValues(cells, columns, numeric); err != nil {
+			errs = append(errs, resources.ImportErrorResource{Row: line, Error: err.Error()})
+		} else if len(data) > 0 {
+			rows = append(rows, importRow{Row: line, Data: data})
+		}
+	}
+
+	return
+}
+
+// Rows of all sheets of XLSX file having the key attribute in the header
+func parseImportExcel(payload []byte, attributes, numeric []string, key string) (rows []importRow, errs []resources.ImportErrorResource, err error) {
+	xf, err := xlsx.OpenBinary(payload)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Invalid Excel file: %s", err.Error())
+	}
+
+	for _, sheet := range xf.Sheets {
+		if len(sheet.Rows) == 0 {
+			continue
+		}
+
+		header := make([]string, len(sheet.Rows[0].Cells))
+		for i, c := range sheet.Rows[0].Cells {
+			header[i] = c.String()
+		}
+		columns := importHeader(header, attributes)
+		if !common.MemberOf(key, mapValues(columns)...) {
+			log.Printf("Skip Excel sheet: %s", sheet.Name)
+			continue
+		}
+		log.Printf("Processing Excel sheet: %s", sheet.Name)
+
+		for i, row := range sheet.Rows[1:] {
+			cells := make([]string, len(row.Cells))
+			for k, c := range row.Cells {
+				if c.IsTime() {
+					if t, err := c.GetTime(false); err == nil {
+						cells[k] = t.Format(common.CutOffDateFormat)
+						continue
+					}
+				}
+				cells[k] = c.String()
+			}
+
+			if data, err := importValues(cells, columns, numeric); err != nil {
+				errs = append(errs, resources.ImportErrorResource{Sheet: sheet.Name, Row: i + 2, Error: err.Error()})
+			} else if len(data) > 0 {
+				rows = append(rows, importRow{Sheet: sheet.Name, Row: i + 2, Data: data})
+			}
+		}
+	}
+
+	if len(rows) == 0 && len(errs) == 0 {
+		return nil, nil, fmt.Errorf("No sheet with attribute %s in Excel header", key)
+	}
+
+	return
+}
+
+func mapValues(m map[int]string) (values []string) {
+	for _, v := range m {
+		values = append(values, v)
+	}
+
+	return
+}
+
+//
+// Rows of the payload in the format given by Content-Type
+//
+func parseImportPayload(r *http.Request, attributes, numeric []string, key string) (rows []importRow, errs []resources.ImportErrorResource, err error) {
+	payload, err := common.GetPayload(r, r.Header.Get("Content-Encoding"))
+	if err != nil {
+		return
+	}
+
+	switch ct := r.Header.Get("Content-Type"); ct {
+	case "application/csv", "text/csv":
+		return parseImportCsv(payload, attributes, numeric)
+
+	case "application/xlsx":
+		return parseImportExcel(payload, attributes, numeric, key)
+
+	default:
+		return nil, nil, fmt.Errorf("Unsupported import format: %s", ct)
+	}
+}
+
+//
+// Check the row like the payload of POST and decode it into the record
+//
+func importRecord(row importRow, role string, check importCheck, record interface{}) error {
+	for k, v := range row.Data {
+		if ok, info := check("POST", role, k, v); !ok {
+			return fmt.Errorf("%s", info)
+		}
+	}
+
+	j, err := json.Marshal(row.Data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(j, record)
+}
+
+// Error of the row in the report
+func importError(row importRow, key string, err error) resources.ImportErrorResource {
+	return resources.ImportErrorResource{
+		Sheet: row.Sheet,
+		Row:   row.Row,
+		Key:   key,
+		Error: err.Error(),
+	}
+}
+
+//
+// Reply with the report, nothing was written if there are any errors
+//
+func writeImportReport(w http.ResponseWriter, report *resources.ImportReplyResource) {
+	status := http.StatusCreated
+	if len(report.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	} else if report.DryRun {
+		status = http.StatusOK
+	}
+
+	if j, err := json.Marshal(report); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, status, j)
+	}
+
+	log.Printf("Imported records: %d of %d errors: %d dry run: %v, status: %d", report.Imported, report.Count, len(report.Errors), report.DryRun, status)
+}
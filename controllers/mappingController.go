@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Date of the mapping from query parameter asOf, today by default
+func getMappingAsOf(r *http.Request) (asOf time.Time, err error) {
+	v := r.URL.Query().Get("asOf")
+	if v == "" {
+		v = time.Now().Format(common.CutOffDateFormat)
+	}
+
+	if asOf, err = time.Parse(common.CutOffDateFormat, v); err != nil {
+		err = fmt.Errorf("Invalid value of asOf: %s", v)
+	}
+
+	return
+}
+
+//
+// Read the mapping of BSCS accounts to SAP accounts and order numbers
+// effective on the date of query parameter asOf across all releases in
+// production, optionally of query parameters account and segment only
+//
+func MappingRead(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	asOf, err := getMappingAsOf(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+	account, segment := r.URL.Query().Get("account"), r.URL.Query().Get("segment")
+
+	user := r.Header.Get("user")
+	accountRepo, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer accountRepo.Close()
+
+	accounts, err := accountRepo.ReadProduction(asOf, account)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	orderRepo, err := repository.NewOrderRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating order repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer orderRepo.Close()
+
+	orders, err := orderRepo.ReadProduction(asOf, account, segment)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	mapping := models.Mapping{
+		AsOf: asOf.Format(common.CutOffDateFormat),
+		Data: models.EffectiveMapping(accounts, orders, segment),
+	}
+
+	switch ct := r.Header.Get("Content-Type"); ct {
+	case "application/csv":
+		if payload, err := mapping.ToCsv(); err != nil {
+			common.DisplayAppError(w, common.EncoderCsvError, "Error in payload make - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			WriteResponse(w, http.StatusOK, payload, ct)
+		}
+
+	default:
+		var dataReplyResource = resources.MappingReplyResource{
+			AsOf:  mapping.AsOf,
+			Count: int64(len(mapping.Data)),
+			Data:  mapping.Data,
+		}
+		if j, err := json.Marshal(dataReplyResource); err != nil {
+			common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			WriteResponseJson(w, http.StatusOK, j)
+		}
+	}
+
+	log.Printf("Read mapping as of: %s entries: %d, status: %d", mapping.AsOf, len(mapping.Data), http.StatusOK)
+}
@@ -12,6 +12,7 @@ The operations on Order are:
 
   CreateOne
   ReadSome
+  ReadOne
   UpdateOne
   DeleteOne
 
@@ -81,6 +82,33 @@ func getOrderPathVars4LogAccess(r *http.Request) (account string, err error) {
 	return
 }
 
+// Weak entity tag of the result set made of keys and record versions
+func ordersETag(orders []models.Order) string {
+	parts := make([]string, len(orders))
+	for i, o := range orders {
+		parts[i] = fmt.Sprintf("%s/%s/%s/%s/%d", o.Status, o.ReleaseId, o.BscsAccount, o.SegmentCode, o.RecVersion)
+	}
+
+	return common.WeakETag(parts)
+}
+
+// Compare the record version read by the client with the current one
+func checkOrderVersion(w http.ResponseWriter, r *http.Request, repo repository.OrderRepository, key *models.Order, version, status int) (ok bool) {
+	current, count, err := repo.ReadVersionByPrimaryKey(key)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read of record version - " + err.Error(), http.StatusInternalServerError)
+		return false
+	} else if count == 0 {
+		common.DisplayAppError(w, common.ControllerError, "Error in repository read of record version, no matching record found on: " + r.URL.Path, http.StatusNotFound)
+		return false
+	} else if current != version {
+		common.DisplayAppError(w, common.VersionError, fmt.Sprintf("Record was changed since read, version expected: %d, current: %d", version, current), status)
+		return false
+	}
+
+	return true
+}
+
 //
 // Create entity in the backend, no parameters,
 // body only used containing json image with values
@@ -135,6 +163,13 @@ func OrderReadActiveAll(w http.ResponseWriter, r *http.Request) {
 	// Perform repository parameteric read using query parameters provided
 	var err error
 
+	// Paging, sorting and filters of the list
+	query, err := common.GetListQuery(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// do bulk read
 	user := r.Header.Get("user")
 	repo, err := repository.NewOrderRepository(user, false)
@@ -146,11 +181,12 @@ func OrderReadActiveAll(w http.ResponseWriter, r *http.Request) {
 	
 	// Do select on account with key pattern
 	order := &models.Order{}	
-	orders, err := repo.ReadBulkByPartialKey(order)
+	orders, total, err := repo.ReadBulkByPartialKey(order, query)
 	if err != nil {
-		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		displayListReadError(w, err)
 		return
 	}
+	w.Header().Set("ETag", ordersETag(orders))
 
 	switch ct := r.Header.Get("Content-Type"); ct {
 	case "application/csv":
@@ -168,13 +204,15 @@ func OrderReadActiveAll(w http.ResponseWriter, r *http.Request) {
 			common.DisplayAppError(w, common.EncoderExcelError, "Error in payload make - " + err.Error(), http.StatusInternalServerError)
 			return	
 		} else {			
-			WriteResponse(w, http.StatusNotImplemented, payload, ct)
+			WriteResponse(w, http.StatusOK, payload, ct)
 		}
 		
 	default:
 		// Return selection result set with headers and appropriate status
 		var dataReplyResource = resources.OrdersReplyResource{
 			Count: int64(len(orders)),
+			Total: total,
+			Next:  query.NextPage(r.URL.Path, total),
 			Data:  orders,
 		}
 		if j, err := json.Marshal(dataReplyResource); err != nil {
@@ -203,6 +241,13 @@ func OrderReadSome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Paging, sorting and filters of the list
+	query, err := common.GetListQuery(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// do bulk read
 	user := r.Header.Get("user")
 	repo, err := repository.NewOrderRepository(user, false)
@@ -223,15 +268,18 @@ func OrderReadSome(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Do select on account with key pattern
-	orders, err := repo.ReadBulkByPartialKey(order)
+	orders, total, err := repo.ReadBulkByPartialKey(order, query)
 	if err != nil {
-		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - "+ err.Error(), http.StatusInternalServerError)
+		displayListReadError(w, err)
 		return
 	}
+	w.Header().Set("ETag", ordersETag(orders))
 
 	// Return selection result set with headers and appropriate status
 	var dataReplyResource = resources.OrdersReplyResource{
 		Count: int64(len(orders)),
+		Total: total,
+		Next:  query.NextPage(r.URL.Path, total),
 		Data:  orders,
 	}
 	if j, err := json.Marshal(dataReplyResource); err != nil {
@@ -244,6 +292,60 @@ func OrderReadSome(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Read from account, status: %d", http.StatusOK)
 }
 
+//
+// Read single entity from backend by primary key: {status}/{release}/{account}/{segment},
+// the record version is returned as ETag to be used in If-Match of the modifications
+//
+func OrderReadOne(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	var err error
+	order := &models.Order{}
+	if order.Status, order.ReleaseId, order.BscsAccount, order.SegmentCode, err = getOrderPathVars4KeyAccess(r); err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewOrderRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	// Obtain last release with helper
+	if order.ReleaseId == "last" {
+		if id, err := repo.GetMaxRelease(); err != nil {
+			common.DisplayAppError(w, common.RepositoryRunError, "Error in obtaining max release - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			order.ReleaseId = fmt.Sprintf("%d", id)
+		}
+	}
+
+	count, err := repo.ReadByPrimaryKey(order)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else if count == 0 {
+		common.DisplayAppError(w, common.ControllerError, "Error in repository read, no matching record found on: " + r.URL.Path, http.StatusNotFound)
+		return
+	}
+
+	// Return the record with its version
+	w.Header().Set("ETag", common.ETag(order.RecVersion))
+	var dataReplyResource = resources.OrderReplyResource{Data: *order}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An unexpected error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Read from order, status: %d", http.StatusOK)
+}
+
 //
 // Makes modifications to a single record accessed
 // by primary key: {status}/{release}/{account}/{segment}
@@ -251,6 +353,13 @@ func OrderReadSome(w http.ResponseWriter, r *http.Request) {
 func OrderUpdateOne(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Start processing request url: %s", r.URL.Path)
 
+	// Version of the record read by the client if any
+	version, status, check, err := getExpectedVersion(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting record version - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Decode the incoming Order json
 	var dataRequestResource resources.OrderRequestResource
 	log.Printf("Decoding payload")
@@ -262,7 +371,6 @@ func OrderUpdateOne(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Decoded payload: %v", order)
 
 	// Decode key values of the single record to updated
-	var err error
 	if order.Status, order.ReleaseId, order.BscsAccount, order.SegmentCode, err = getOrderPathVars4KeyAccess(r); err != nil {
 		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
 		return
@@ -270,32 +378,55 @@ func OrderUpdateOne(w http.ResponseWriter, r *http.Request) {
 
 	// do selective update by the composite key
 	user := r.Header.Get("user")
-	repo, err := repository.NewOrderRepository(user, false)
+	repo, err := repository.NewOrderRepository(user, true)
 	if err != nil {
 		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository - " + err.Error(), http.StatusInternalServerError)
 		return
 	}
 	defer repo.Close()
+
+	// The record must not be changed by others since read
+	if check && !checkOrderVersion(w, r, repo, order, version, status) {
+		repo.Rollback()
+		return
+	}
 	
 	count, err := repo.UpdateByPrimaryKey(order)
 	if err != nil {
 		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository delete - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
 		return
 	} else if count == 0 {
 		common.DisplayAppError(w, common.ControllerError, "Error in repository update, no matching record found on: " + r.URL.Path, http.StatusNotFound)
 		repo.Rollback()
 		return
 	}
+
+	// New version of the record is returned to the client
+	if order.RecVersion, _, err = repo.ReadVersionByPrimaryKey(order); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read of record version - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
+		return
+	}
 	
 	// Return final result set with headers and appropriate status
 	orders := make([]models.Order, 1)
 	orders[0] = *order
 	dataReplyResource := resources.OrdersReplyResource{Count: count, Data: orders}
-	if j, err := json.Marshal(dataReplyResource); err != nil {
+	j, err := json.Marshal(dataReplyResource)
+	if err != nil {
 		common.DisplayAppError(w, common.EncoderJsonError, "An unexpected error has occurred - " + err.Error(), http.StatusInternalServerError)
-	} else {
-		WriteResponseJson(w, http.StatusOK, j)
+		repo.Rollback()
+		return
+	}
+
+	// The response is written only after the changes are committed
+	if err := repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
+		return
 	}
+	w.Header().Set("ETag", common.ETag(order.RecVersion))
+	WriteResponseJson(w, http.StatusOK, j)
 
 	log.Printf("Updated order, status: %d", http.StatusOK)
 }
@@ -307,11 +438,21 @@ func OrderUpdateOne(w http.ResponseWriter, r *http.Request) {
 func OrderUpdateAttributes(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Start processing request url: %s", r.URL.Path)
 
+	// Version of the record read by the client if any
+	version, status, check, err := getExpectedVersion(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting record version - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	evals, _, err := common.GetEvaluations(r)
 	if err != nil {
 		common.DisplayAppError(w, common.ControllerError, "Error in getting evaluations - " + err.Error(), http.StatusInternalServerError)
 		return
 	}
+
+	// Record version is a precondition, not an attribute to be set
+	delete(*evals, "recVersion")
 	
 	log.Printf("Evaluations: %#v", *evals)
 
@@ -329,6 +470,13 @@ func OrderUpdateAttributes(w http.ResponseWriter, r *http.Request) {
 		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository- " + err.Error(), http.StatusInternalServerError)
 		return
 	}
+	defer repo.Close()
+
+	// The record must not be changed by others since read
+	if check && !checkOrderVersion(w, r, repo, key, version, status) {
+		repo.Rollback()
+		return
+	}
 
 	var count int64
 	for attribute, value := range *evals {
@@ -375,20 +523,32 @@ func OrderUpdateAttributes(w http.ResponseWriter, r *http.Request) {
 	order.UpdateDate = key.UpdateDate
 	order.UpdateDateStr = key.UpdateDateStr
 	order.UpdateOwner = key.UpdateOwner
+
+	// New version of the record is returned to the client
+	if order.RecVersion, _, err = repo.ReadVersionByPrimaryKey(key); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read of record version - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
+		return
+	}
 		
 	// Return final result set with headers and appropriate status
 	var orders []models.Order = make([]models.Order, 1)
 	orders[0] = *order
 	dataReplyResource := resources.OrdersReplyResource{Count: count, Data: orders}
-	if j, err := json.Marshal(dataReplyResource); err != nil {
+	j, err := json.Marshal(dataReplyResource)
+	if err != nil {
 		common.DisplayAppError(w, common.EncoderJsonError, "An unexpected error has occurred - " + err.Error(), http.StatusInternalServerError)
 		repo.Rollback()
 		return
-	} else {
-		WriteResponseJson(w, http.StatusOK, j)
 	}
 
-	repo.Commit()
+	// The response is written only after the changes are committed
+	if err := repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("ETag", common.ETag(order.RecVersion))
+	WriteResponseJson(w, http.StatusOK, j)
 	
 	log.Printf("Updated order, status: %d", http.StatusOK)
 }
@@ -400,8 +560,14 @@ func OrderUpdateAttributes(w http.ResponseWriter, r *http.Request) {
 func OrderDeleteOne(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Start processing request url: %s", r.URL.Path)
 
+	// Version of the record read by the client if any
+	version, status, check, err := getExpectedVersion(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting record version - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	// Decode key values of the single record to updated
-	var err error
 	order := &models.Order{}
 	if order.Status, order.ReleaseId, order.BscsAccount, order.SegmentCode, err = getOrderPathVars4KeyAccess(r); err != nil {
 		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
@@ -410,19 +576,27 @@ func OrderDeleteOne(w http.ResponseWriter, r *http.Request) {
 
 	// Do selective delete by the key
 	user := r.Header.Get("user")
-	repo, err := repository.NewOrderRepository(user, false)
+	repo, err := repository.NewOrderRepository(user, true)
 	if err != nil {
 		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository - " + err.Error(), http.StatusInternalServerError)
 		return
 	}
 	defer repo.Close()
+
+	// The record must not be changed by others since read
+	if check && !checkOrderVersion(w, r, repo, order, version, status) {
+		repo.Rollback()
+		return
+	}
 	
 	count, err := repo.DeleteByPrimaryKey(order)
 	if err != nil {
 		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository delete - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
 		return
 	} else if count == 0 {
 		common.DisplayAppError(w, common.ControllerError, "Error in repository delete, no matching record found on: " + r.URL.Path, http.StatusNotFound)		
+		repo.Rollback()
 		return
 	}
 	
@@ -430,12 +604,19 @@ func OrderDeleteOne(w http.ResponseWriter, r *http.Request) {
 	var orders []models.Order = make([]models.Order, 1)
 	orders[0] = *order	
 	dataReplyResource := resources.OrdersReplyResource{Count: count, Data: orders}
-	if j, err := json.Marshal(dataReplyResource); err != nil {
+	j, err := json.Marshal(dataReplyResource)
+	if err != nil {
 		common.DisplayAppError(w, common.EncoderJsonError, "An unexpected error has occurred - " + err.Error(), http.StatusInternalServerError)
+		repo.Rollback()
+		return
+	}
+
+	// The response is written only after the changes are committed
+	if err := repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
 		return
-	} else {
-		WriteResponseJson(w, http.StatusOK, j)
 	}
+	WriteResponseJson(w, http.StatusOK, j)
 
 	log.Printf("Deleted order, status: %d", http.StatusOK)
 }
@@ -459,6 +640,8 @@ func OrderDeleteAll(w http.ResponseWriter, r *http.Request) {
 		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository delete - " + err.Error(), http.StatusInternalServerError)
 		return
 	} else {
+		// the mappings in production are deleted too
+		resolveCacheReset()
 		WriteResponseJson(w, http.StatusOK, nil)
 	}
 	
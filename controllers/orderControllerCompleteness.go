@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Segments of CUSTOMER_SEGMENT each account needs the order for
+func readSegments(user string) (segments []models.DictionarySegment, err error) {
+	repo, err := repository.NewDictionarySegmentRepository(user, false)
+	if err != nil {
+		return
+	}
+	defer repo.Close()
+
+	return repo.ReadAll()
+}
+
+//
+// Create the orders without number in W for the segments the account
+// has no order yet, they are made in the transaction of the account
+//
+func createOrderStubs(uow repository.UnitOfWork, segments []models.DictionarySegment, account *models.Account) (count int64, err error) {
+	for _, s := range segments {
+		order := &models.Order{
+			Status:           "W",
+			ReleaseId:        "0",
+			BscsAccount:      account.BscsAccount,
+			SegmentCode:      s.CsTradeRef,
+			ValidFromDateStr: account.ValidFromDateStr,
+		}
+
+		var exists int64
+		if exists, err = uow.Orders().ReadByPrimaryKey(&models.Order{Status: "W", ReleaseId: "0", BscsAccount: account.BscsAccount, SegmentCode: s.CsTradeRef}); err != nil {
+			return
+		} else if exists > 0 {
+			continue
+		}
+
+		if err = uow.Orders().Create(order); err != nil {
+			return
+		}
+		count++
+	}
+	log.Printf("Created order stubs of account: %s orders: %d", account.BscsAccount, count)
+
+	return
+}
+
+// Status and release of the completeness check from query parameters
+func getCompletenessParams(r *http.Request) (status, release string, err error) {
+	status, release = "W", "0"
+	if v := r.URL.Query().Get("status"); v != "" {
+		status = v
+	}
+	if v := r.URL.Query().Get("release"); v != "" {
+		release = v
+	}
+
+	if status != "W" && status != "C" && status != "P" {
+		err = fmt.Errorf("Invalid value of status: %s", status)
+		return
+	}
+
+	if release != "last" {
+		if n, e := strconv.Atoi(release); e != nil || n < 0 {
+			err = fmt.Errorf("Invalid value of release: %s", release)
+		}
+	}
+
+	return
+}
+
+//
+// List the accounts and segments of the status and release missing the order
+// or its number, the query parameters status and release are W and 0 by default
+//
+func OrderCompleteness(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	status, release, err := getCompletenessParams(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	accountRepo, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer accountRepo.Close()
+
+	// Obtain last release with helper
+	if release == "last" {
+		if id, err := accountRepo.GetMaxRelease(); err != nil {
+			common.DisplayAppError(w, common.RepositoryRunError, "Error in obtaining max release - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			release = fmt.Sprintf("%d", id)
+		}
+	}
+
+	accounts, _, err := accountRepo.ReadBulkByPartialKey(&models.Account{Status: status, ReleaseId: release}, nil)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	orderRepo, err := repository.NewOrderRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating order repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer orderRepo.Close()
+
+	orders, _, err := orderRepo.ReadBulkByPartialKey(&models.Order{Status: status, ReleaseId: release}, nil)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	segments, err := readSegments(user)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	gaps := models.CheckOrderCompleteness(accounts, orders, segments)
+	var dataReplyResource = resources.OrderCompletenessReplyResource{
+		Count: int64(len(gaps)),
+		Data:  gaps,
+	}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Checked completeness of orders in status: %s release: %s gaps: %d, status: %d", status, release, len(gaps), http.StatusOK)
+}
@@ -0,0 +1,85 @@
+package controllers
+
+import (
+	"log"
+	"net/http"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+	"sam-api/valid"
+)
+
+// Attributes of Order which can be imported
+var orderImportAttributes = []string{
+	"status",
+	"releaseId",
+	"bscsAccount",
+	"segmentCode",
+	"orderNumber",
+	"validFromDate",
+}
+
+//
+// Create orders in W status from CSV or XLSX payload in one transaction,
+// with query parameter dryRun=true the rows are checked and inserted but
+// the transaction is rolled back
+//
+func OrderImport(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	dryRun := r.URL.Query().Get("dryRun") == "true"
+	rows, errs, err := parseImportPayload(r, orderImportAttributes, nil, "bscsAccount")
+	if err != nil {
+		common.DisplayAppError(w, common.DecoderExcelError, "Error in parsing import payload - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Parsed import payload records: %d errors: %d", len(rows), len(errs))
+
+	report := &resources.ImportReplyResource{
+		Count:  int64(len(rows) + len(errs)),
+		DryRun: dryRun,
+		Errors: append([]resources.ImportErrorResource{}, errs...),
+	}
+
+	// Check all rows like in POST
+	role := r.Header.Get("role")
+	orders := make([]models.Order, len(rows))
+	for i, row := range rows {
+		if err := importRecord(row, role, valid.CheckOrderAttribute, &orders[i]); err != nil {
+			report.Errors = append(report.Errors, importError(row, orders[i].BscsAccount + "/" + orders[i].SegmentCode, err))
+		}
+	}
+
+	// Insert all or nothing
+	if len(report.Errors) == 0 {
+		user := r.Header.Get("user")
+		repo, err := repository.NewOrderRepository(user, true)
+		if err != nil {
+			common.DisplayAppError(w, common.RepositoryNewError, "Error while creating order repository - " + err.Error(), http.StatusInternalServerError)
+			return
+		}
+		defer repo.Close()
+
+		for i := range orders {
+			orders[i].Status, orders[i].ReleaseId = "W", "0"
+			if err := repo.Create(&orders[i]); err != nil {
+				report.Errors = append(report.Errors, importError(rows[i], orders[i].BscsAccount + "/" + orders[i].SegmentCode, err))
+				break
+			}
+			report.Imported++
+		}
+
+		if dryRun || len(report.Errors) > 0 {
+			repo.Rollback()
+			if len(report.Errors) > 0 {
+				report.Imported = 0
+			}
+		} else {
+			repo.Commit()
+		}
+	}
+
+	writeImportReport(w, report)
+}
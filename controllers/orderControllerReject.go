@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Move the order from C to W, it must not be in W already
+func rejectOrder(repo repository.OrderRepository, order *models.Order, reason string) (status int, err error) {
+	order.Status, order.ReleaseId = "C", "0"
+	count, err := repo.ReadByPrimaryKey(order)
+	if err != nil {
+		return http.StatusInternalServerError, err
+	} else if count == 0 {
+		return http.StatusNotFound, fmt.Errorf("no matching record found in status C: %s/%s", order.BscsAccount, order.SegmentCode)
+	}
+
+	work := &models.Order{Status: "W", ReleaseId: "0", BscsAccount: order.BscsAccount, SegmentCode: order.SegmentCode}
+	if count, err = repo.ReadByPrimaryKey(work); err != nil {
+		return http.StatusInternalServerError, err
+	} else if count > 0 {
+		return http.StatusConflict, fmt.Errorf("record already in status W: %s/%s", order.BscsAccount, order.SegmentCode)
+	}
+
+	if count, err = repo.Reject(order, reason); err != nil {
+		return http.StatusInternalServerError, err
+	} else if count == 0 {
+		return http.StatusNotFound, fmt.Errorf("no matching record found in status C: %s/%s", order.BscsAccount, order.SegmentCode)
+	}
+
+	// current values with the new version
+	if _, err = repo.ReadByPrimaryKey(order); err != nil {
+		return http.StatusInternalServerError, err
+	}
+
+	return http.StatusOK, nil
+}
+
+//
+// Reject the order accessed by primary key: {status}/{release}/{account}/{segment}
+// back to W with the mandatory reason, the owner is notified
+//
+func OrderReject(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	// Version of the record read by the client if any
+	version, status, check, err := getExpectedVersion(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting record version - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reject, err := getRejectPayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ValidationError, "Invalid reject json request - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	order := &models.Order{}
+	if order.Status, order.ReleaseId, order.BscsAccount, order.SegmentCode, err = getOrderPathVars4KeyAccess(r); err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err = checkRejectKey(order.Status, order.ReleaseId); err != nil {
+		common.DisplayAppError(w, common.ValidationError, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewOrderRepository(user, true)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating order repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	// The record must not be changed by others since read
+	if check && !checkOrderVersion(w, r, repo, order, version, status) {
+		repo.Rollback()
+		return
+	}
+
+	if status, err := rejectOrder(repo, order, reject.Reason); err != nil {
+		repo.Rollback()
+		displayRejectError(w, status, err)
+		return
+	}
+
+	if err = repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the rejection is done, the mail failure must not make the client repeat it
+	if err = rejectNotify(user, "Order", reject.Reason, map[string][]string{order.EntryOwner: {order.BscsAccount + "/" + order.SegmentCode}}); err != nil {
+		log.Printf("Rejection committed, error in sending mail: %s", err.Error())
+	}
+
+	w.Header().Set("ETag", common.ETag(order.RecVersion))
+	var dataReplyResource = resources.OrderReplyResource{Data: *order}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Rejected order, status: %d", http.StatusOK)
+}
+
+//
+// Reject the orders listed in the payload back to W with the mandatory
+// reason in one transaction, the owners are notified
+//
+func OrderRejectBulk(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	reject, err := getRejectPayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ValidationError, "Invalid reject json request - " + err.Error(), http.StatusBadRequest)
+		return
+	} else if len(reject.Entries) == 0 {
+		common.DisplayAppError(w, common.ValidationError, "Invalid reject json request - no entries", http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewOrderRepository(user, true)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating order repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	orders := []models.Order{}
+	owners := make(map[string][]string)
+	for _, e := range reject.Entries {
+		order := models.Order{BscsAccount: e.BscsAccount, SegmentCode: e.SegmentCode}
+		if status, err := rejectOrder(repo, &order, reject.Reason); err != nil {
+			repo.Rollback()
+			displayRejectError(w, status, err)
+			return
+		}
+		orders = append(orders, order)
+		owners[order.EntryOwner] = append(owners[order.EntryOwner], order.BscsAccount + "/" + order.SegmentCode)
+	}
+
+	if err = repo.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository commit - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// the rejection is done, the mail failure must not make the client repeat it
+	if err = rejectNotify(user, "Order", reject.Reason, owners); err != nil {
+		log.Printf("Rejection committed, error in sending mail: %s", err.Error())
+	}
+
+	var dataReplyResource = resources.OrdersReplyResource{
+		Count: int64(len(orders)),
+		Total: int64(len(orders)),
+		Data:  orders,
+	}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Rejected orders: %d, status: %d", len(orders), http.StatusOK)
+}
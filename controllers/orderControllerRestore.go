@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+	"sam-api/valid"
+)
+
+//
+// Create or overwrite the order in W from the log entry with the id,
+// the restored values are checked for the role and the restore is
+// written to the log
+//
+func restoreOrder(uow repository.UnitOfWork, r *http.Request, bscsAccount, id string) (order *models.Order, status int, err error) {
+	logs, err := uow.Orders().ReadLog(bscsAccount)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	var entry *models.OrderLog
+	for i := range logs {
+		if logs[i].Id == id {
+			entry = &logs[i]
+		}
+	}
+	if entry == nil {
+		return nil, http.StatusNotFound, fmt.Errorf("no log entry found of account: %s id: %s", bscsAccount, id)
+	}
+
+	restored := entry.CloneIntoWork()
+	restored.ValidFromDateStr = entry.ValidFromDateStr
+
+	current := &models.Order{Status: "W", ReleaseId: "0", BscsAccount: bscsAccount, SegmentCode: restored.SegmentCode}
+	count, err := uow.Orders().ReadByPrimaryKey(current)
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	method, changes := "POST", restored.RestoreChanges(nil)
+	if count > 0 {
+		method, changes = "PATCH", restored.RestoreChanges(current)
+	}
+	if info := checkRestoreChanges(valid.CheckOrderAttribute, method, r.Header.Get("role"), changes); info != "" {
+		return nil, http.StatusForbidden, fmt.Errorf("%s", info)
+	}
+
+	if count == 0 {
+		err = uow.Orders().Create(&restored)
+		status = http.StatusCreated
+	} else {
+		_, err = uow.Orders().UpdateByPrimaryKey(&restored)
+		status = http.StatusOK
+	}
+	if err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	// current values with the new version
+	if _, err = uow.Orders().ReadByPrimaryKey(&restored); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+	if err = uow.Orders().LogRestore(&restored); err != nil {
+		return nil, http.StatusInternalServerError, err
+	}
+
+	return &restored, status, nil
+}
+
+//
+// Restore the order in W from the log entry with the id in the payload,
+// the order is created if it is not in W or overwritten otherwise
+//
+func OrderRestore(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	bscsAccount, err := getOrderPathVars4LogAccess(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error getting url variables - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	restore, err := getRestorePayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ValidationError, "Invalid restore json request - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	uow, err := repository.NewUnitOfWork(user)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating unit of work - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer uow.Close()
+
+	order, status, err := restoreOrder(uow, r, bscsAccount, restore.Id)
+	if err != nil {
+		uow.Rollback()
+		displayRestoreError(w, status, err)
+		return
+	}
+
+	if err = uow.Commit(); err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in commit of restore - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", common.ETag(order.RecVersion))
+	var dataReplyResource = resources.OrderReplyResource{Data: *order}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, status, j)
+	}
+
+	log.Printf("Restored order of account: %s from log entry: %s, status: %d", bscsAccount, restore.Id, status)
+}
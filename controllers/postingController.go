@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/resources"
+)
+
+//
+// Translate the BSCS GL posting extract with the mappings in production,
+// used by the upload and by the command line
+//
+func TranslatePostingFile(user string, r io.Reader, currency string) (*models.PostingTranslation, error) {
+	postings, rejects, err := models.ParsePostings(r, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := resolveIndex(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return models.TranslatePostings(index, postings, rejects), nil
+}
+
+//
+// Translate the uploaded BSCS GL posting extract into the SAP OFI upload
+// file, query parameter currency is the one of the lines without it and
+// query parameter file=ofi or file=reject gives only the file as CSV
+//
+func PostingTranslate(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	file := r.URL.Query().Get("file")
+	if file != "" && file != "ofi" && file != "reject" {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - Invalid value of file: " + file, http.StatusBadRequest)
+		return
+	}
+
+	t, err := TranslatePostingFile(r.Header.Get("user"), r.Body, r.URL.Query().Get("currency"))
+	if err != nil {
+		common.DisplayAppError(w, common.ValidationError, "Error in translation of postings - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ofi, err := t.ToOfiCsv()
+	if err != nil {
+		common.DisplayAppError(w, common.EncoderCsvError, "Error in payload make - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	rejects, err := t.ToRejectCsv()
+	if err != nil {
+		common.DisplayAppError(w, common.EncoderCsvError, "Error in payload make - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch file {
+	case "ofi":
+		WriteResponse(w, http.StatusOK, ofi, "application/csv")
+
+	case "reject":
+		WriteResponse(w, http.StatusOK, rejects, "application/csv")
+
+	default:
+		var dataReplyResource = resources.PostingTranslateReplyResource{
+			Lines:      t.Lines,
+			Posted:     int64(len(t.Postings)),
+			Rejected:   int64(len(t.Rejects)),
+			Totals:     t.Totals,
+			OfiFile:    string(ofi),
+			RejectFile: string(rejects),
+		}
+		if j, err := json.Marshal(dataReplyResource); err != nil {
+			common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			WriteResponseJson(w, http.StatusOK, j)
+		}
+	}
+
+	log.Printf("Translated posting lines: %d, posted: %d, rejected: %d, status: %d", t.Lines, len(t.Postings), len(t.Rejects), http.StatusOK)
+}
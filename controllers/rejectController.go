@@ -0,0 +1,107 @@
+/*
+
+PACKAGE: Reject controller layer
+
+It provides the common part of the rejection of Account and Order
+entries. Control sends the entries in status C back to W with
+the mandatory reason, the entry keeps the reason and the user
+who rejected it until it is released again. The change is written
+to the log tables like any other update so the rejection is seen
+in the log of the account. The owner of each entry is notified
+by mail after the rejection is committed.
+
+*/
+
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/resources"
+)
+
+// Payload with the mandatory reason and the keys of the bulk rejection
+func getRejectPayload(r *http.Request) (reject models.Reject, err error) {
+	var dataRequestResource resources.RejectRequestResource
+	if err = json.NewDecoder(r.Body).Decode(&dataRequestResource); err != nil {
+		return
+	}
+
+	reject = dataRequestResource.Data
+	reject.Reason = strings.TrimSpace(reject.Reason)
+	if reject.Reason == "" {
+		err = fmt.Errorf("Missing mandatory reason of the rejection")
+	}
+
+	return
+}
+
+// Only the entries in status C of release 0 may be rejected
+func checkRejectKey(status, release string) error {
+	if status != "C" || release != "0" {
+		return fmt.Errorf("Only entries in status C of release 0 may be rejected, got: %s/%s", status, release)
+	}
+
+	return nil
+}
+
+// Report failed rejection, the key errors are client errors
+func displayRejectError(w http.ResponseWriter, status int, err error) {
+	if status == http.StatusInternalServerError {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository reject - " + err.Error(), status)
+	} else {
+		common.DisplayAppError(w, common.ControllerError, "Error in reject - " + err.Error(), status)
+	}
+}
+
+// Mail address of the user, the alert address if the mail domain is not configured
+func userMailAddress(user string) string {
+	if common.AppConfig.UserMailDomain == "" {
+		return common.AppConfig.AlertMailAddress
+	}
+
+	return user + "@" + common.AppConfig.UserMailDomain
+}
+
+//
+// Notify the owners about their entries rejected back to Work,
+// the keys are grouped by the owner
+//
+func rejectNotify(user, entity, reason string, owners map[string][]string) (err error) {
+	if common.AppConfig.AlertMailServerAddress == "" {
+		return
+	}
+
+	names := []string{}
+	for owner := range owners {
+		names = append(names, owner)
+	}
+	sort.Strings(names)
+
+	for _, owner := range names {
+		log.Printf("Sending e-mail to server: %s", common.AppConfig.AlertMailServerAddress)
+		err = common.MailTo(userMailAddress(owner),
+			fmt.Sprintf("BSCS to SAP %s rejected back to Work by" +
+				" user: %s" +
+				" owner: %s" +
+				" entries: %s" +
+				" reason: %s",
+				entity,
+				user,
+				owner,
+				strings.Join(owners[owner], ", "),
+				reason))
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -5,19 +5,26 @@ PACKAGE: Release controller layer
 The operations are:
 
   Release
+  ReadAll
 
 */
 
 package controllers
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"strconv"
+	"time"
 	
 	"sam-api/common"
+	"sam-api/models"
 	"sam-api/repository"
+	"sam-api/resources"
 )
 
 // Exact row level acces by full primary key: {id}
@@ -31,7 +38,7 @@ func getReleasePathVars4KeyAccess(r *http.Request) (release string, err error) {
 	return
 }
 
-func getRelease(r *http.Request, ar *repository.AccountRepository) (release int64, err error) {
+func getRelease(r *http.Request, ar repository.AccountRepository) (release int64, err error) {
 	var value string
 	value, err = getReleasePathVars4KeyAccess(r)
 	if err != nil {
@@ -54,6 +61,27 @@ func getRelease(r *http.Request, ar *repository.AccountRepository) (release int6
 	return
 }	
 
+// Optional payload of the release with description
+func getReleasePayload(r *http.Request) (release models.Release, err error) {
+	if r.Body == nil {
+		return
+	}
+
+	var payload []byte
+	if payload, err = ioutil.ReadAll(r.Body); err != nil || len(bytes.TrimSpace(payload)) == 0 {
+		return
+	}
+
+	var dataRequestResource resources.ReleaseRequestResource
+	if err = json.Unmarshal(payload, &dataRequestResource); err != nil {
+		err = fmt.Errorf("Invalid Release json request: %s", err.Error())
+		return
+	}
+	release = dataRequestResource.Data
+
+	return
+}
+
 // determine role dependent transition of status
 func getTransitForRole(role string) (from, into string) {
 	switch role {
@@ -72,6 +100,84 @@ func getTransitForRole(role string) (from, into string) {
 	return
 }
 
+// Move accounts and orders into the status and release, the release in P is registered
+func releaseApply(uow repository.UnitOfWork, from, into string, releaseNew int64, opcode, description string) (accounts, orders int64, err error) {
+	// fix account: status -> C or P, release = 0 -> release
+	if accounts, err = uow.Accounts().SetStatusRelease(from, into, 0, releaseNew); err != nil {
+		return
+	}
+
+	// fix order accordingly using release of account
+	if orders, err = uow.Orders().SetStatusRelease(from, into, 0, releaseNew); err != nil {
+		return
+	}
+
+	// register release in the catalogue
+	if into == "P" {
+		release := &models.Release{
+			ReleaseId:   strconv.FormatInt(releaseNew, 10),
+			FromStatus:  from,
+			IntoStatus:  into,
+			Description: description,
+		}
+		err = uow.Releases().Register(release, opcode, accounts, orders)
+	}
+
+	return
+}
+
+// Send mail if only the snmt server is known, done after the commit
+func releaseNotify(user, role, into string, release int64) (err error) {
+	if common.AppConfig.AlertMailServerAddress == "" {
+		return
+	}
+
+	log.Printf("Sending e-mail to server: %s", common.AppConfig.AlertMailServerAddress)
+	err = common.MailTo(common.AppConfig.AlertMailAddress,
+		fmt.Sprintf("BSCS to SAP Account/Order release done by" +
+			" user: %s" +
+			" role:  %s" +
+			" to status: %s" +
+			" of release: %d",
+			user,
+			role,
+			into,
+			release))
+
+	return
+}
+
+// Move accounts and orders of the release from P back to W, the revocation is registered
+func revokeApply(uow repository.UnitOfWork, release int64) (accounts, orders int64, err error) {
+	if accounts, err = uow.Accounts().SetStatusRelease("P", "W", release, 0); err != nil {
+		return
+	}
+
+	if orders, err = uow.Orders().SetStatusRelease("P", "W", release, 0); err != nil {
+		return
+	}
+
+	// keep the revocation in the release history
+	err = uow.Releases().Revoke(&models.Release{ReleaseId: strconv.FormatInt(release, 10)}, accounts, orders)
+
+	return
+}
+
+// Query parameter dryRun=true asks only for the report of the release
+func getReleaseDryRun(r *http.Request) bool {
+	return r.URL.Query().Get("dryRun") == "true"
+}
+
+// Release may be configured to be done only by approval of other user
+func releaseByApprovalOnly(w http.ResponseWriter, dryRun bool) bool {
+	if dryRun || common.AppConfig.ReleaseApproval != "Y" {
+		return false
+	}
+
+	common.DisplayAppError(w, common.AuthorizationError, "Release and revoke are done only by approval of other user, use /api/approval", http.StatusForbidden)
+	return true
+}
+
 //
 // Change Account, Order entries status W->C or C->P depending on the role
 // The value of attribute release is to be qual max(relese) of Account, Order
@@ -79,85 +185,98 @@ func getTransitForRole(role string) (from, into string) {
 func ReleaseNew(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Start processing request url: %s", r.URL.Path)
 
+	doReleaseNew(w, r, getReleaseDryRun(r))
+}
+
+//
+// Report what the new release would do without changing anything
+//
+func ReleaseCheck(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	doReleaseNew(w, r, true)
+}
+
+// New release in the transaction being rolled back if dry run only
+func doReleaseNew(w http.ResponseWriter, r *http.Request, dryRun bool) {
 	// determine transition depending on role, Booker: W -> C, Control: C -> P
 	user := r.Header.Get("user")
 	role := r.Header.Get("role")
 	from, into := getTransitForRole(role) // may panic
 
-	var err error
-	var accountRepository *repository.AccountRepository
-	if accountRepository, err = repository.NewAccountRepository(user, true); err != nil {
+	if releaseByApprovalOnly(w, dryRun) {
+		return
+	}
+
+	payload, err := getReleasePayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.DecoderJsonError, "Error in decoding release payload - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// accounts, orders and release registry in one transaction
+	uow, err := repository.NewUnitOfWork(user)
+	if err != nil {
 		common.DisplayAppError(w, err, "Error while creating repository", http.StatusInternalServerError)
 		return
 	}
+	defer uow.Close()
 	
 	// for transit W -> C no change of release
 	var release, releaseNew int64
-	if from == "W" {
-		release = 0
-		releaseNew = 0
-	} else if from == "C" {
-		release, err = accountRepository.GetMaxRelease()
+	if from == "C" {
+		release, err = uow.Accounts().GetMaxRelease()
 		if err != nil {
-			accountRepository.Rollback()
+			uow.Rollback()
 			common.DisplayAppError(w, err, "Error in get max release of account", http.StatusInternalServerError)
 			return
 		}
 		releaseNew = release + 1
 	}
 	
-	// fix account: status -> C or P, release = 0 -> max(release) + 1
-	var accounts int64
-	if accounts, err = accountRepository.SetStatusRelease(from, into, 0, releaseNew); err != nil {
-		accountRepository.Rollback()
+	if dryRun {
+		releaseDryRun(w, uow, user, from, into, releaseNew, "N", payload.Description)
+		return
+	}
+
+	// valid dates of the released entries are checked per account and segment
+	violations, err := releaseCheck(uow, from, time.Now())
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in release check", http.StatusInternalServerError)
+		return
+	}
+	if len(violations) > 0 {
+		uow.Rollback()
+		writeReleaseViolations(w, violations)
+		return
+	}
+
+	accounts, orders, err := releaseApply(uow, from, into, releaseNew, "N", payload.Description)
+	if err != nil {
+		uow.Rollback()
 		common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
 		return
 	}
 
-	// fix order accordingly using release of account
-	var orders int64
-	var orderRepository *repository.OrderRepository
-	if orderRepository, err = repository.NewOrderRepository(user, true); err != nil {
-		accountRepository.Rollback()
-		common.DisplayAppError(w, err, "Error while creating repository", http.StatusInternalServerError)
+	if err = uow.Commit(); err != nil {
+		common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
 		return
-	} else {
-		if orders, err = orderRepository.SetStatusRelease(from, into, 0, releaseNew); err != nil {
-			orderRepository.Rollback()
-			accountRepository.Rollback()
-			common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
-			return
-		}
 	}
 	
 	log.Printf("Released accounts: %d, orders: %d", accounts, orders)
 
-	// send mail if only the snmt server is known
-	if common.AppConfig.AlertMailServerAddress != "" {
-		log.Printf("Sending e-mail to server: %s", common.AppConfig.AlertMailServerAddress)
-		err = common.MailTo(common.AppConfig.AlertMailAddress,
-			fmt.Sprintf("BSCS to SAP Account/Order release done by" +
-				" user: %s" +
-				" role:  %s" +
-				" to status: %s" +
-				" of release: %d",
-				user,
-				role,
-				into,
-				releaseNew))
-		if err != nil {
-			orderRepository.Rollback()
-			accountRepository.Rollback()
-			common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
-			return			
-		}
+	// the mappings in production are changed
+	resolveCacheReset()
+	releaseDeliver(user, into, releaseNew)
+
+	if err = releaseNotify(user, role, into, releaseNew); err != nil {
+		common.DisplayAppError(w, err, "Release committed, error in sending mail", http.StatusInternalServerError)
+		return
 	}
 
 	WriteResponseJson(w, http.StatusOK, nil)
 
-	accountRepository.Commit()
-	orderRepository.Commit()
-	
 	log.Printf("Release, status: %d, response: %#v", http.StatusOK, w)
 }
 
@@ -174,77 +293,78 @@ func ReleaseAppend(w http.ResponseWriter, r *http.Request) {
 	role := r.Header.Get("role")
 	from, into := getTransitForRole(role) // may panic
 
-	var err error
-	var ar *repository.AccountRepository
-	if ar, err = repository.NewAccountRepository(user, true); err != nil {
+	if releaseByApprovalOnly(w, getReleaseDryRun(r)) {
+		return
+	}
+
+	payload, err := getReleasePayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.DecoderJsonError, "Error in decoding release payload - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// accounts, orders and release registry in one transaction
+	uow, err := repository.NewUnitOfWork(user)
+	if err != nil {
 		common.DisplayAppError(w, err, "Error while creating repository", http.StatusInternalServerError)
 		return
 	}
+	defer uow.Close()
 	
 	// for transit W -> C no change of release
 	var releaseNew int64
-	if from == "W" {
-		releaseNew = 0
-	} else if from == "C" {
-		releaseNew, err = getRelease(r, ar)
+	if from == "C" {
+		releaseNew, err = getRelease(r, uow.Accounts())
 		if err != nil {
+			uow.Rollback()
 			common.DisplayAppError(w, err, "Error in loading release", http.StatusInternalServerError)
 			return		
 		}
 	}
 	
-	// fix account: status -> C or P, release = 0 -> release
-	var accounts int64
-	if accounts, err = ar.SetStatusRelease(from, into, 0, releaseNew); err != nil {
-		ar.Rollback()
+	if getReleaseDryRun(r) {
+		releaseDryRun(w, uow, user, from, into, releaseNew, "A", payload.Description)
+		return
+	}
+
+	// valid dates of the released entries are checked per account and segment
+	violations, err := releaseCheck(uow, from, time.Now())
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in release check", http.StatusInternalServerError)
+		return
+	}
+	if len(violations) > 0 {
+		uow.Rollback()
+		writeReleaseViolations(w, violations)
+		return
+	}
+
+	accounts, orders, err := releaseApply(uow, from, into, releaseNew, "A", payload.Description)
+	if err != nil {
+		uow.Rollback()
 		common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
 		return
 	}
 
-	// fix order accordingly using release of account
-	var orders int64
-	var or *repository.OrderRepository
-	if or, err = repository.NewOrderRepository(user, true); err != nil {
-		ar.Rollback()
-		common.DisplayAppError(w, err, "Error while creating repository", http.StatusInternalServerError)
+	if err = uow.Commit(); err != nil {
+		common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
 		return
-	} else {
-		if orders, err = or.SetStatusRelease(from, into, 0, releaseNew); err != nil {
-			or.Rollback()
-			ar.Rollback()
-			common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
-			return
-		}
 	}
 	
 	log.Printf("Released accounts: %d, orders: %d", accounts, orders)
 
-	// send mail if only the snmt server is known
-	if common.AppConfig.AlertMailServerAddress != "" {
-		log.Printf("Sending e-mail to server: %s", common.AppConfig.AlertMailServerAddress)
-		err = common.MailTo(common.AppConfig.AlertMailAddress,
-			fmt.Sprintf("BSCS to SAP Account/Order release done by" +
-				" user: %s" +
-				" role:  %s" +
-				" to status: %s" +
-				" of release: %d",
-				user,
-				role,
-				into,
-				releaseNew))
-		if err != nil {
-			or.Rollback()
-			ar.Rollback()
-			common.DisplayAppError(w, err, "Error in repository release", http.StatusInternalServerError)
-			return			
-		}
+	// the mappings in production are changed
+	resolveCacheReset()
+	releaseDeliver(user, into, releaseNew)
+
+	if err = releaseNotify(user, role, into, releaseNew); err != nil {
+		common.DisplayAppError(w, err, "Release committed, error in sending mail", http.StatusInternalServerError)
+		return
 	}
 
 	WriteResponseJson(w, http.StatusOK, nil)
 
-	ar.Commit()
-	or.Commit()
-	
 	log.Printf("Release, status: %d, response: %#v", http.StatusOK, w)
 }
 
@@ -257,43 +377,95 @@ func ReleaseAppend(w http.ResponseWriter, r *http.Request) {
 func ReleaseRevoke(w http.ResponseWriter, r *http.Request) {
 	log.Printf("Start processing request url: %s", r.URL.Path)
 
-	var err error
-
-	user := r.Header.Get("user")
-	
-	var ar *repository.AccountRepository
-	if ar, err = repository.NewAccountRepository(user, true); err != nil {
-		common.DisplayAppError(w, err, "Error while creating repository", http.StatusInternalServerError)
+	// the revoke is requested like the release
+	if releaseByApprovalOnly(w, false) {
 		return
 	}
 
-	var or *repository.OrderRepository
-	if or, err = repository.NewOrderRepository(user, true); err != nil {
+	user := r.Header.Get("user")
+
+	// accounts, orders and release registry in one transaction
+	uow, err := repository.NewUnitOfWork(user)
+	if err != nil {
 		common.DisplayAppError(w, err, "Error while creating repository", http.StatusInternalServerError)
 		return
 	}
+	defer uow.Close()
 	
-	release, err := getRelease(r, ar)
+	release, err := getRelease(r, uow.Accounts())
 	if err != nil {
+		uow.Rollback()
 		common.DisplayAppError(w, err, "Error in loading release", http.StatusInternalServerError)
 		return		
 	}
 
-	if _, err := ar.SetStatusRelease("P", "W", release, 0); err != nil {
+	// only release not yet valid may be revoked and only into empty Work
+	violations, err := revokeCheck(uow, release, time.Now())
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in revoke check", http.StatusInternalServerError)
+		return
+	}
+	if len(violations) > 0 {
+		uow.Rollback()
+		writeReleaseViolations(w, violations)
+		return
+	}
+
+	accounts, orders, err := revokeApply(uow, release)
+	if err != nil {
+		uow.Rollback()
 		common.DisplayAppError(w, err, "Error in repository update", http.StatusInternalServerError)
 		return
 	}
 
-	if _, err := or.SetStatusRelease("P", "W", release, 0); err != nil {
-		ar.Rollback()
+	if err = uow.Commit(); err != nil {
 		common.DisplayAppError(w, err, "Error in repository update", http.StatusInternalServerError)
 		return
 	}
+
+	log.Printf("Revoked release: %d accounts: %d, orders: %d", release, accounts, orders)
+
+	// the mappings in production are changed
+	resolveCacheReset()
 	
 	WriteResponseJson(w, http.StatusOK, nil)
-
-	ar.Commit()
-	or.Commit()
 	
 	log.Printf("Release, status: %d, response: %#v", http.StatusOK, w)
 }
+
+//
+// Read all releases with the transition, owner and date of the release,
+// numbers of accounts and orders, earliest valid date and history
+//
+func ReleaseReadAll(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	user := r.Header.Get("user")
+	repo, err := repository.NewReleaseRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer repo.Close()
+
+	releases, err := repo.ReadAll()
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Return selection result set with headers and appropriate status
+	var dataReplyResource = resources.ReleasesReplyResource{
+		Count: int64(len(releases)),
+		Data:  releases,
+	}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Read releases: %d, status: %d", len(releases), http.StatusOK)
+}
@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Active entries in production, the last released version per business key
+func productionQuery() *common.ListQuery {
+	return &common.ListQuery{Filters: []common.Filter{{Attribute: "status", Op: "=", Value: "P"}}}
+}
+
+// Entries being released and the ones in production
+type releaseEntries struct {
+	accounts, accountsProduction []models.Account
+	orders, ordersProduction     []models.Order
+}
+
+func readReleaseEntries(uow repository.UnitOfWork, from string) (e releaseEntries, err error) {
+	if e.accounts, _, err = uow.Accounts().ReadBulkByPartialKey(&models.Account{Status: from, ReleaseId: "0"}, nil); err != nil {
+		return
+	}
+	if e.accountsProduction, _, err = uow.Accounts().ReadBulkByPartialKey(&models.Account{}, productionQuery()); err != nil {
+		return
+	}
+	if e.orders, _, err = uow.Orders().ReadBulkByPartialKey(&models.Order{Status: from, ReleaseId: "0"}, nil); err != nil {
+		return
+	}
+	e.ordersProduction, _, err = uow.Orders().ReadBulkByPartialKey(&models.Order{}, productionQuery())
+
+	return
+}
+
+// Valid dates must be in the future and after the ones in production
+func (e *releaseEntries) check(now time.Time) []models.ReleaseViolation {
+	return append(models.CheckReleaseAccounts(e.accounts, e.accountsProduction, now),
+		models.CheckReleaseOrders(e.orders, e.ordersProduction, now)...)
+}
+
+//
+// Check valid dates of the accounts and orders being moved from the status,
+// they must be in the future and after the ones in production
+//
+func releaseCheck(uow repository.UnitOfWork, from string, now time.Time) (violations []models.ReleaseViolation, err error) {
+	e, err := readReleaseEntries(uow, from)
+	if err != nil {
+		return
+	}
+
+	violations = e.check(now)
+	log.Printf("Checked release from status: %s accounts: %d orders: %d violations: %d",
+		from, len(e.accounts), len(e.orders), len(violations))
+
+	return
+}
+
+// Dictionaries referenced by the accounts and orders
+func readReleaseDictionaries(user string) (segments []models.DictionarySegment, accountsBscs []models.DictionaryAccountBscs, accountsSap []models.DictionaryAccountSap, err error) {
+	sr, err := repository.NewDictionarySegmentRepository(user, false)
+	if err != nil {
+		return
+	}
+	defer sr.Close()
+	if segments, err = sr.ReadAll(); err != nil {
+		return
+	}
+
+	br, err := repository.NewDictionaryAccountBscsRepository(user)
+	if err != nil {
+		return
+	}
+	defer br.Close()
+	if accountsBscs, _, err = br.ReadAll(&common.ListQuery{}); err != nil {
+		return
+	}
+
+	ar, err := repository.NewDictionaryAccountSapRepository(user)
+	if err != nil {
+		return
+	}
+	defer ar.Close()
+	accountsSap, _, err = ar.ReadAll(&common.ListQuery{})
+
+	return
+}
+
+//
+// Do the release in the transaction which is always rolled back and reply
+// with the report of counts, missing values, references and date violations
+//
+func releaseDryRun(w http.ResponseWriter, uow repository.UnitOfWork, user, from, into string, releaseNew int64, opcode, description string) {
+	report := models.ReleaseCheck{
+		FromStatus: from,
+		IntoStatus: into,
+		ReleaseId:  strconv.FormatInt(releaseNew, 10),
+	}
+
+	e, err := readReleaseEntries(uow, from)
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in release check", http.StatusInternalServerError)
+		return
+	}
+	report.Violations = e.check(time.Now())
+
+	segments, accountsBscs, accountsSap, err := readReleaseDictionaries(user)
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in release check", http.StatusInternalServerError)
+		return
+	}
+	report.CheckEntries(e.accounts, e.orders, e.ordersProduction, segments, accountsBscs, accountsSap)
+
+	// the transition is done to count the entries and find the errors of backend
+	if report.Accounts, report.Orders, err = releaseApply(uow, from, into, releaseNew, opcode, description); err != nil {
+		report.Error = err.Error()
+	}
+	uow.Rollback()
+	report.Done()
+
+	if j, err := json.Marshal(resources.ReleaseCheckReplyResource{Data: report}); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Release dry run accounts: %d, orders: %d, ok: %v, status: %d", report.Accounts, report.Orders, report.Ok, http.StatusOK)
+}
+
+//
+// Check the release may be revoked, all its valid dates are in the future
+// and nothing is pending in Work
+//
+func revokeCheck(uow repository.UnitOfWork, release int64, now time.Time) (violations []models.ReleaseViolation, err error) {
+	violations = []models.ReleaseViolation{}
+
+	accountsValid, err := uow.Accounts().GetMinValidDate("P", release)
+	if err != nil {
+		return
+	}
+	if accountsValid.Before(now) {
+		violations = append(violations, models.ReleaseViolation{
+			Entity:        "account",
+			ValidFromDate: accountsValid.Format(common.CutOffDateFormat),
+			Rule:          models.ReleaseRuleInPast,
+			Message:       fmt.Sprintf("Accounts of release %d are valid already", release),
+		})
+	}
+
+	ordersValid, err := uow.Orders().GetMinValidDate("P", release)
+	if err != nil {
+		return
+	}
+	if ordersValid.Before(now) {
+		violations = append(violations, models.ReleaseViolation{
+			Entity:        "order",
+			ValidFromDate: ordersValid.Format(common.CutOffDateFormat),
+			Rule:          models.ReleaseRuleInPast,
+			Message:       fmt.Sprintf("Orders of release %d are valid already", release),
+		})
+	}
+
+	_, accounts, err := uow.Accounts().ReadBulkByPartialKey(&models.Account{Status: "W", ReleaseId: "0"}, nil)
+	if err != nil {
+		return
+	}
+	if accounts > 0 {
+		violations = append(violations, models.ReleaseViolation{
+			Entity:  "account",
+			Rule:    models.ReleaseRuleWorkNotEmpty,
+			Message: fmt.Sprintf("Accounts in Work: %d", accounts),
+		})
+	}
+
+	_, orders, err := uow.Orders().ReadBulkByPartialKey(&models.Order{Status: "W", ReleaseId: "0"}, nil)
+	if err != nil {
+		return
+	}
+	if orders > 0 {
+		violations = append(violations, models.ReleaseViolation{
+			Entity:  "order",
+			Rule:    models.ReleaseRuleWorkNotEmpty,
+			Message: fmt.Sprintf("Orders in Work: %d", orders),
+		})
+	}
+	log.Printf("Checked revoke of release: %d violations: %d", release, len(violations))
+
+	return
+}
+
+//
+// Reply with the violations, nothing was changed
+//
+func writeReleaseViolations(w http.ResponseWriter, violations []models.ReleaseViolation) {
+	var dataReplyResource = resources.ReleaseViolationsReplyResource{
+		Count: int64(len(violations)),
+		Data:  violations,
+	}
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusUnprocessableEntity, j)
+	}
+
+	log.Printf("Release violations: %d, status: %d", len(violations), http.StatusUnprocessableEntity)
+}
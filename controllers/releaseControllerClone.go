@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Handling of the entries already in Work from query parameter, fail by default
+func getCloneMode(r *http.Request) (mode string, err error) {
+	mode = r.URL.Query().Get("mode")
+	switch mode {
+	case "":
+		mode = models.CloneFail
+	case models.CloneSkip, models.CloneOverwrite, models.CloneFail:
+	default:
+		err = fmt.Errorf("Invalid value of mode: %s", mode)
+	}
+
+	return
+}
+
+// Copy the accounts into Work, the ones already there are handled by the mode
+func cloneAccounts(uow repository.UnitOfWork, accounts []models.Account, report *models.ReleaseClone) (err error) {
+	for i := range accounts {
+		clone := accounts[i].CloneIntoWork()
+
+		var exists int64
+		if exists, err = uow.Accounts().ReadByPrimaryKey(&models.Account{Status: "W", ReleaseId: "0", BscsAccount: clone.BscsAccount}); err != nil {
+			return
+		} else if exists == 0 {
+			if err = uow.Accounts().Create(&clone); err != nil {
+				return
+			}
+			report.Accounts++
+			continue
+		}
+
+		report.Conflicts = append(report.Conflicts, models.ReleaseCheckEntry{Entity: "account", BscsAccount: clone.BscsAccount})
+		switch report.Mode {
+		case models.CloneSkip:
+			report.Skipped++
+		case models.CloneOverwrite:
+			if _, err = uow.Accounts().UpdateByPrimaryKey(&clone); err != nil {
+				return
+			}
+			report.Overwritten++
+		}
+	}
+
+	return
+}
+
+// Copy the orders into Work, the ones already there are handled by the mode
+func cloneOrders(uow repository.UnitOfWork, orders []models.Order, report *models.ReleaseClone) (err error) {
+	for i := range orders {
+		clone := orders[i].CloneIntoWork()
+
+		var exists int64
+		if exists, err = uow.Orders().ReadByPrimaryKey(&models.Order{Status: "W", ReleaseId: "0", BscsAccount: clone.BscsAccount, SegmentCode: clone.SegmentCode}); err != nil {
+			return
+		} else if exists == 0 {
+			if err = uow.Orders().Create(&clone); err != nil {
+				return
+			}
+			report.Orders++
+			continue
+		}
+
+		report.Conflicts = append(report.Conflicts, models.ReleaseCheckEntry{Entity: "order", BscsAccount: clone.BscsAccount, SegmentCode: clone.SegmentCode})
+		switch report.Mode {
+		case models.CloneSkip:
+			report.Skipped++
+		case models.CloneOverwrite:
+			if _, err = uow.Orders().UpdateByPrimaryKey(&clone); err != nil {
+				return
+			}
+			report.Overwritten++
+		}
+	}
+
+	return
+}
+
+//
+// Copy all accounts and orders of the release in P into W with release 0
+// in one transaction so that they can be changed in the next cycle, the
+// query parameter mode tells what to do with the entries already in W:
+// skip them, overwrite them or fail with 409 without any change
+//
+func ReleaseClone(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	mode, err := getCloneMode(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+
+	// accounts and orders in one transaction
+	uow, err := repository.NewUnitOfWork(user)
+	if err != nil {
+		common.DisplayAppError(w, err, "Error while creating repository", http.StatusInternalServerError)
+		return
+	}
+	defer uow.Close()
+
+	release, err := getRelease(r, uow.Accounts())
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in loading release", http.StatusInternalServerError)
+		return
+	}
+
+	report := models.ReleaseClone{
+		ReleaseId: strconv.FormatInt(release, 10),
+		Mode:      mode,
+		Conflicts: []models.ReleaseCheckEntry{},
+	}
+
+	accounts, _, err := uow.Accounts().ReadBulkByPartialKey(&models.Account{Status: "P", ReleaseId: report.ReleaseId}, nil)
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in repository read", http.StatusInternalServerError)
+		return
+	}
+
+	orders, _, err := uow.Orders().ReadBulkByPartialKey(&models.Order{Status: "P", ReleaseId: report.ReleaseId}, nil)
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in repository read", http.StatusInternalServerError)
+		return
+	}
+
+	if len(accounts) == 0 && len(orders) == 0 {
+		uow.Rollback()
+		common.DisplayAppError(w, common.ControllerError, "No accounts and orders found in release: " + report.ReleaseId, http.StatusNotFound)
+		return
+	}
+
+	if err = cloneAccounts(uow, accounts, &report); err == nil {
+		err = cloneOrders(uow, orders, &report)
+	}
+	if err != nil {
+		uow.Rollback()
+		common.DisplayAppError(w, err, "Error in repository update", http.StatusInternalServerError)
+		return
+	}
+
+	status := http.StatusOK
+	if mode == models.CloneFail && len(report.Conflicts) > 0 {
+		uow.Rollback()
+		report.Accounts, report.Orders = 0, 0
+		status = http.StatusConflict
+	} else if err = uow.Commit(); err != nil {
+		common.DisplayAppError(w, err, "Error in repository update", http.StatusInternalServerError)
+		return
+	}
+
+	if j, err := json.Marshal(resources.ReleaseCloneReplyResource{Data: report}); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, status, j)
+	}
+
+	log.Printf("Cloned release: %s accounts: %d, orders: %d, conflicts: %d, status: %d",
+		report.ReleaseId, report.Accounts, report.Orders, len(report.Conflicts), status)
+}
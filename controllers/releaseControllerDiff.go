@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+)
+
+// Side of the diff: W or C with release 0, last or id of the release in P
+func getReleaseDiffSide(r *http.Request, name string, ar repository.AccountRepository) (status string, release int64, err error) {
+	switch value := r.URL.Query().Get(name); value {
+	case "":
+		err = common.InvalidQueryError{Reason: fmt.Sprintf("Missing mandatory query parameter %s", name)}
+
+	case "W", "C":
+		status = value
+
+	case "last":
+		status = "P"
+		release, err = ar.GetMaxRelease()
+
+	default:
+		status = "P"
+		if release, err = strconv.ParseInt(value, 10, 64); err != nil || release <= 0 {
+			err = common.InvalidQueryError{Reason: fmt.Sprintf("Invalid value of query parameter %s: %s", name, value)}
+		}
+	}
+
+	return
+}
+
+// Accounts and orders of the side of the diff
+func readReleaseDiffSide(ar repository.AccountRepository, or repository.OrderRepository, status string, release int64) (accounts []models.Account, orders []models.Order, err error) {
+	id := strconv.FormatInt(release, 10)
+	if accounts, _, err = ar.ReadBulkByPartialKey(&models.Account{Status: status, ReleaseId: id}, nil); err != nil {
+		return
+	}
+	orders, _, err = or.ReadBulkByPartialKey(&models.Order{Status: status, ReleaseId: id}, nil)
+
+	return
+}
+
+// Name of the side in the report
+func releaseDiffLabel(status string, release int64) string {
+	if status == "P" {
+		return strconv.FormatInt(release, 10)
+	}
+
+	return status
+}
+
+//
+// Compare accounts and orders of two releases or of Work or Control with a release,
+// by business key, added, removed and changed attributes are reported
+//
+func ReleaseDiff(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	user := r.Header.Get("user")
+	ar, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer ar.Close()
+
+	or, err := repository.NewOrderRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating order repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer or.Close()
+
+	fromStatus, fromRelease, err := getReleaseDiffSide(r, "from", ar)
+	if err != nil {
+		displayListReadError(w, err)
+		return
+	}
+
+	toStatus, toRelease, err := getReleaseDiffSide(r, "to", ar)
+	if err != nil {
+		displayListReadError(w, err)
+		return
+	}
+
+	fromAccounts, fromOrders, err := readReleaseDiffSide(ar, or, fromStatus, fromRelease)
+	if err != nil {
+		displayListReadError(w, err)
+		return
+	}
+
+	toAccounts, toOrders, err := readReleaseDiffSide(ar, or, toStatus, toRelease)
+	if err != nil {
+		displayListReadError(w, err)
+		return
+	}
+
+	diff := models.ReleaseDiff{
+		From:     releaseDiffLabel(fromStatus, fromRelease),
+		To:       releaseDiffLabel(toStatus, toRelease),
+		Accounts: models.DiffAccounts(fromAccounts, toAccounts),
+		Orders:   models.DiffOrders(fromOrders, toOrders),
+	}
+
+	switch ct := r.Header.Get("Content-Type"); ct {
+	case "application/xlsx":
+		if payload, err := diff.ToExcel(); err != nil {
+			common.DisplayAppError(w, common.EncoderExcelError, "Error in payload make - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			WriteResponse(w, http.StatusOK, payload, ct)
+		}
+
+	default:
+		if j, err := json.Marshal(diff); err != nil {
+			common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+			return
+		} else {
+			WriteResponseJson(w, http.StatusOK, j)
+		}
+	}
+
+	log.Printf("Release diff from: %s to: %s, status: %d", diff.From, diff.To, http.StatusOK)
+}
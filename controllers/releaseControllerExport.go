@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+)
+
+// Content type and file name extension of the formats
+var releaseExportFormats = map[string][2]string{
+	models.ReleaseExportFixed: {"text/plain", "txt"},
+	models.ReleaseExportCsv:   {"application/csv", "csv"},
+	models.ReleaseExportXml:   {"application/xml", "xml"},
+}
+
+// Format of the export from query parameter format, mandatory
+func getReleaseExportFormat(r *http.Request) (format string, err error) {
+	format = r.URL.Query().Get("format")
+	if format == "" {
+		err = fmt.Errorf("Missing mandatory query parameter format")
+	} else if _, found := releaseExportFormats[format]; !found {
+		err = fmt.Errorf("Invalid value of format: %s", format)
+	}
+
+	return
+}
+
+// Accounts and orders of the release in P to be exported
+func releaseExportLoad(ar repository.AccountRepository, or repository.OrderRepository, release string) (export *models.ReleaseExport, err error) {
+	export = &models.ReleaseExport{
+		ReleaseId: release,
+		CreatedAt: time.Now(),
+	}
+	if export.Accounts, _, err = ar.ReadBulkByPartialKey(&models.Account{Status: "P", ReleaseId: release}, nil); err != nil {
+		return nil, err
+	}
+	if export.Orders, _, err = or.ReadBulkByPartialKey(&models.Order{Status: "P", ReleaseId: release}, nil); err != nil {
+		return nil, err
+	}
+
+	return
+}
+
+// Interface file of the export in the format
+func releaseExportPayload(export *models.ReleaseExport, format string) (payload []byte, err error) {
+	switch format {
+	case models.ReleaseExportFixed:
+		payload, err = export.ToFixed()
+	case models.ReleaseExportCsv:
+		payload, err = export.ToCsv()
+	case models.ReleaseExportXml:
+		payload, err = export.ToXml()
+	default:
+		err = fmt.Errorf("Invalid export format: %s", format)
+	}
+
+	return
+}
+
+//
+// Export the accounts and orders of the release in P as the SAP OFI interface
+// file in the format of query parameter format: fixed width flat file,
+// semicolon CSV or IDoc like XML, each with the header and the trailer
+// carrying the record counts and the checksum
+//
+func ReleaseExport(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	format, err := getReleaseExportFormat(r)
+	if err != nil {
+		common.DisplayAppError(w, common.QueryParamError, "Invalid query parameter - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user := r.Header.Get("user")
+	ar, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating account repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer ar.Close()
+
+	or, err := repository.NewOrderRepository(user, false)
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryNewError, "Error while creating order repository - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer or.Close()
+
+	release, err := getRelease(r, ar)
+	if err != nil {
+		common.DisplayAppError(w, err, "Error in loading release", http.StatusInternalServerError)
+		return
+	}
+
+	export, err := releaseExportLoad(ar, or, strconv.FormatInt(release, 10))
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if len(export.Accounts) == 0 && len(export.Orders) == 0 {
+		common.DisplayAppError(w, common.ControllerError, "No accounts and orders found in release: " + export.ReleaseId, http.StatusNotFound)
+		return
+	}
+
+	payload, err := releaseExportPayload(export, format)
+	if err != nil {
+		common.DisplayAppError(w, common.ControllerError, "Error in payload make - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ct := releaseExportFormats[format]
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", models.DeliveryFileName(export.ReleaseId, ct[1])))
+	WriteResponse(w, http.StatusOK, payload, ct[0])
+
+	log.Printf("Exported release: %s format: %s accounts: %d, orders: %d, status: %d",
+		export.ReleaseId, format, len(export.Accounts), len(export.Orders), http.StatusOK)
+}
@@ -0,0 +1,150 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
+)
+
+// Max number of postings resolved in one request
+const resolveMaxLookups = 10000
+
+// Index of the mappings in production built at the first lookup after the release
+var resolveCache struct {
+	m     sync.Mutex
+	index *models.MappingIndex
+}
+
+// Drop the index after the change of the entries in production
+func resolveCacheReset() {
+	resolveCache.m.Lock()
+	defer resolveCache.m.Unlock()
+
+	resolveCache.index = nil
+}
+
+// The index of the mappings in production, built if it is not there
+func resolveIndex(user string) (*models.MappingIndex, error) {
+	resolveCache.m.Lock()
+	defer resolveCache.m.Unlock()
+
+	if resolveCache.index != nil {
+		return resolveCache.index, nil
+	}
+
+	accountRepo, err := repository.NewAccountRepository(user, false)
+	if err != nil {
+		return nil, err
+	}
+	defer accountRepo.Close()
+
+	accounts, err := accountRepo.ReadProduction(time.Time{}, "")
+	if err != nil {
+		return nil, err
+	}
+
+	orderRepo, err := repository.NewOrderRepository(user, false)
+	if err != nil {
+		return nil, err
+	}
+	defer orderRepo.Close()
+
+	orders, err := orderRepo.ReadProduction(time.Time{}, "", "")
+	if err != nil {
+		return nil, err
+	}
+
+	resolveCache.index = models.NewMappingIndex(accounts, orders)
+
+	log.Printf("Built mapping index of accounts: %d, orders: %d", len(accounts), len(orders))
+
+	return resolveCache.index, nil
+}
+
+// Payload with the postings, not empty and not over the limit
+func getResolvePayload(r *http.Request) (lookups []models.ResolveLookup, err error) {
+	var dataRequestResource resources.ResolveRequestResource
+	if err = json.NewDecoder(r.Body).Decode(&dataRequestResource); err != nil {
+		return
+	}
+
+	lookups = dataRequestResource.Data
+	if len(lookups) == 0 {
+		err = fmt.Errorf("No postings to be resolved")
+	} else if len(lookups) > resolveMaxLookups {
+		err = fmt.Errorf("Too many postings: %d, max: %d", len(lookups), resolveMaxLookups)
+	}
+
+	return
+}
+
+// The posting without the account or with the invalid date is not resolved
+func checkResolveLookup(l models.ResolveLookup) (info string) {
+	if l.BscsAccount == "" {
+		return "Missing mandatory bscsAccount"
+	}
+	if _, err := time.Parse(common.CutOffDateFormat, l.PostingDate); err != nil {
+		return "Invalid value of postingDate: " + l.PostingDate
+	}
+
+	return
+}
+
+//
+// Resolve the postings of BSCS accounts and segments on the dates into SAP
+// accounts and order numbers in production, the result of each posting is
+// mapped, unmapped or invalid
+//
+func Resolve(w http.ResponseWriter, r *http.Request) {
+	log.Printf("Start processing request url: %s", r.URL.Path)
+
+	lookups, err := getResolvePayload(r)
+	if err != nil {
+		common.DisplayAppError(w, common.ValidationError, "Invalid resolve json request - " + err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	index, err := resolveIndex(r.Header.Get("user"))
+	if err != nil {
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository read - " + err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var dataReplyResource = resources.ResolveReplyResource{
+		Count: int64(len(lookups)),
+		Data:  make([]models.ResolveResult, len(lookups)),
+	}
+	for i, l := range lookups {
+		if info := checkResolveLookup(l); info != "" {
+			dataReplyResource.Data[i] = models.ResolveResult{
+				BscsAccount: l.BscsAccount,
+				SegmentCode: l.SegmentCode,
+				PostingDate: l.PostingDate,
+				Status:      models.ResolveInvalid,
+				Reason:      info,
+			}
+		} else {
+			dataReplyResource.Data[i] = index.Resolve(l)
+		}
+		if dataReplyResource.Data[i].Status != models.ResolveMapped {
+			dataReplyResource.Unmapped++
+		}
+	}
+
+	if j, err := json.Marshal(dataReplyResource); err != nil {
+		common.DisplayAppError(w, common.EncoderJsonError, "An error has occurred - " + err.Error(), http.StatusInternalServerError)
+		return
+	} else {
+		WriteResponseJson(w, http.StatusOK, j)
+	}
+
+	log.Printf("Resolved postings: %d, unmapped: %d, status: %d", dataReplyResource.Count, dataReplyResource.Unmapped, http.StatusOK)
+}
@@ -0,0 +1,65 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/resources"
+)
+
+// Payload of the restore with the mandatory id of the log entry
+func getRestorePayload(r *http.Request) (restore models.Restore, err error) {
+	var dataRequestResource resources.RestoreRequestResource
+	if err = json.NewDecoder(r.Body).Decode(&dataRequestResource); err != nil {
+		return
+	}
+
+	restore = dataRequestResource.Data
+	restore.Id = strings.TrimSpace(restore.Id)
+	if restore.Id == "" {
+		err = fmt.Errorf("Missing mandatory id of the log entry")
+	}
+
+	return
+}
+
+//
+// The restored attributes are checked like the ones of POST if the entry
+// is created or of PATCH if it is overwritten, the info names the first one
+// not allowed for the role in the order of the attribute names
+//
+func checkRestoreChanges(check importCheck, method, role string, changes map[string]interface{}) (info string) {
+	keys := make([]string, 0, len(changes))
+	for k := range changes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if ok, info := check(method, role, k, changes[k]); !ok {
+			log.Printf("Access check: %s %s %s %#v: %v %s", role, method, k, changes[k], ok, info)
+			return info
+		}
+	}
+
+	return
+}
+
+// Error of the restore by the status found
+func displayRestoreError(w http.ResponseWriter, status int, err error) {
+	switch status {
+	case http.StatusInternalServerError:
+		common.DisplayAppError(w, common.RepositoryRunError, "Error in repository restore - " + err.Error(), status)
+	case http.StatusForbidden:
+		log.Printf("Validation error: %s", err.Error())
+		common.DisplayAppError(w, common.ValidationError, err.Error(), status)
+	default:
+		common.DisplayAppError(w, common.ControllerError, "Error in restore - " + err.Error(), status)
+	}
+}
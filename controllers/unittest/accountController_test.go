@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"github.com/unrolled/render"
 	"net/http"
+	"os"
 	"testing"
 	"time"
 	
 	"sam-api/models"
 	"sam-api/common"
+	"sam-api/repository"
+	"sam-api/resources"
 )
 
 var (
@@ -576,3 +579,593 @@ func AccountCreate5Patch5(t *testing.T) {
 	}
 }
 
+
+//
+// scenario: modifications checked against the record version read before
+//
+func TestAccountUpdateWithVersion(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	a := accountCreate(t, s, c, token, &models.Account{Status: "W", ReleaseId: "0", BscsAccount: newAccountId()})
+	if a == nil {
+		return
+	}
+	route := s.URL + "/api/account/W/0/" + a.BscsAccount
+
+	// version of the new record is returned as ETag
+	res := doRequest(t, c, "GET", route, token, nil, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return
+	}
+	etag := res.Header.Get("ETag")
+	if etag != "\"0\"" {
+		t.Errorf("Expected ETag \"0\", received %s", etag)
+		return
+	}
+
+	// update with current version is accepted and gives new version
+	body := []byte("{\"data\":{\"ofiSapAccount\":\"XXX\"}}")
+	res = doRequest(t, c, "PATCH", route, token, body, map[string]string{"If-Match": etag})
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return
+	} else if res.Header.Get("ETag") == etag {
+		t.Errorf("Expected new ETag, received %s", res.Header.Get("ETag"))
+		return
+	}
+
+	// stale version in If-Match
+	res = doRequest(t, c, "PATCH", route, token, body, map[string]string{"If-Match": etag})
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("Expected response status %d, received %d", http.StatusPreconditionFailed, res.StatusCode)
+		return
+	}
+
+	// stale version in payload
+	body = []byte("{\"data\":{\"bscsAccount\":\"" + a.BscsAccount + "\", \"recVersion\": 0}}")
+	res = doRequest(t, c, "PUT", route, token, body, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusConflict {
+		t.Errorf("Expected response status %d, received %d", http.StatusConflict, res.StatusCode)
+		return
+	}
+
+	// delete with stale version is refused
+	res = doRequest(t, c, "DELETE", route, token, nil, map[string]string{"If-Match": etag})
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusPreconditionFailed {
+		t.Errorf("Expected response status %d, received %d", http.StatusPreconditionFailed, res.StatusCode)
+		return
+	}
+}
+
+//
+// scenario: read filtered list sorted page by page
+//
+func TestAccountReadSomePaged(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	// records distinguished by OFI SAP account
+	marker := "P" + newAccountId()
+	for i := 0; i < 3; i++ {
+		if accountCreate(t, s, c, token, &models.Account{Status: "W", ReleaseId: "0", BscsAccount: newAccountId(), OfiSapAccount: marker}) == nil {
+			return
+		}
+	}
+
+	// first page with link to the next one
+	page := accountsReadPage(t, s, c, token, "/api/account/W/0?ofiSapAccount=" + marker + "&sort=-bscsAccount&limit=2")
+	if page == nil {
+		return
+	} else if page.Count != 2 || page.Total != 3 || page.Next == "" {
+		t.Errorf("Expected page of 2 records of 3 with next link, received: %d of %d next: %s", page.Count, page.Total, page.Next)
+		return
+	} else if page.Data[0].BscsAccount < page.Data[1].BscsAccount {
+		t.Errorf("Expected descending order, received: %s, %s", page.Data[0].BscsAccount, page.Data[1].BscsAccount)
+		return
+	}
+
+	// last page
+	page = accountsReadPage(t, s, c, token, page.Next)
+	if page == nil {
+		return
+	} else if page.Count != 1 || page.Total != 3 || page.Next != "" {
+		t.Errorf("Expected last page of 1 record of 3, received: %d of %d next: %s", page.Count, page.Total, page.Next)
+		return
+	}
+
+	// filter on unknown attribute
+	res := doRequest(t, c, "GET", s.URL + "/api/account/W/0?password=secret", token, nil, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, res.StatusCode)
+		return
+	}
+}
+
+//
+// scenario: read all as Excel workbook
+//
+func TestAccountReadAllAsExcel(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	a := accountCreate(t, s, c, token, &models.Account{Status: "W", ReleaseId: "0", BscsAccount: newAccountId(), ValidFromDateStr: "2030-01-01"})
+	if a == nil {
+		return
+	}
+
+	xf := excelRead(t, c, s.URL + "/api/account", "Bearer " + token)
+	if xf == nil || !excelSheetsCheck(t, xf) {
+		return
+	}
+
+	// new record is on the Work sheet with typed date
+	found := false
+	for _, row := range xf.Sheet["Work"].Rows[1:] {
+		if row.Cells[2].String() == a.BscsAccount {
+			found = true
+			if !row.Cells[4].IsTime() {
+				t.Errorf("Expected date cell of valid from date")
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected account %s on Work sheet", a.BscsAccount)
+	}
+}
+
+//
+// scenario: import accounts from CSV, dry run first then for real
+//
+func TestAccountImport(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	a1, a2 := newAccountId(), newAccountId()
+	body := []byte("bscsAccount;ofiSapAccount;validFromDate;citMarkerVatFlag\n" +
+		a1 + ";4711;2030-01-01;1\n" +
+		a2 + ";4712;;0\n")
+
+	// nothing written in dry run
+	status, report := importPost(t, c, s.URL + "/api/account/import?dryRun=true", token, "application/csv", body)
+	if report == nil {
+		return
+	} else if status != http.StatusOK || report.Imported != 2 || len(report.Errors) != 0 {
+		t.Errorf("Expected dry run with 2 records, received status: %d report: %#v", status, *report)
+		return
+	}
+	res := doRequest(t, c, "GET", s.URL + "/api/account/W/0/" + a1, token, nil, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected response status %d, received %d", http.StatusNotFound, res.StatusCode)
+		return
+	}
+
+	// all records created
+	status, report = importPost(t, c, s.URL + "/api/account/import", token, "application/csv", body)
+	if report == nil {
+		return
+	} else if status != http.StatusCreated || report.Imported != 2 {
+		t.Errorf("Expected 2 records created, received status: %d report: %#v", status, *report)
+		return
+	}
+	res = doRequest(t, c, "GET", s.URL + "/api/account/W/0/" + a2, token, nil, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return
+	}
+
+	// row refused by the validation, nothing is written
+	a3 := newAccountId()
+	body = []byte("bscsAccount,ofiSapWbsCode\n" + a3 + ",\n" + newAccountId() + ",WBS\n")
+	status, report = importPost(t, c, s.URL + "/api/account/import", token, "application/csv", body)
+	if report == nil {
+		return
+	} else if status != http.StatusUnprocessableEntity || len(report.Errors) != 1 || report.Errors[0].Row != 3 {
+		t.Errorf("Expected error in row 3, received status: %d report: %#v", status, *report)
+		return
+	}
+	res = doRequest(t, c, "GET", s.URL + "/api/account/W/0/" + a3, token, nil, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected response status %d, received %d", http.StatusNotFound, res.StatusCode)
+		return
+	}
+}
+
+//
+// scenario: Control rejects the account in C back to W with the reason
+//
+func TestAccountReject(t *testing.T) {
+	TestAccountDeleteAll(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account}) == nil {
+		return
+	}
+	if res := doRequest(t, c, "POST", s.URL + "/api/release/new", booker, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected release response status %d", http.StatusOK)
+		return
+	}
+
+	route := s.URL + "/api/account/C/0/" + account + "/reject"
+	body := []byte("{\"data\":{\"reason\": \"wrong SAP account\"}}")
+	reply := resources.AccountReplyResource{}
+
+	// only Control with the reason on the entry in C
+	if status := doRequestDecode(t, c, "POST", route, booker, body, &reply); status != http.StatusForbidden {
+		t.Errorf("Expected response status %d, received %d", http.StatusForbidden, status)
+	}
+	if status := doRequestDecode(t, c, "POST", route, control, []byte("{\"data\":{\"reason\": \" \"}}"), &reply); status != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, status)
+	}
+	if status := doRequestDecode(t, c, "POST", s.URL + "/api/account/W/0/" + account + "/reject", control, body, &reply); status != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, status)
+	}
+
+	if status := doRequestDecode(t, c, "POST", route, control, body, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if a := reply.Data; a.Status != "W" || a.RejectReason != "wrong SAP account" || a.RejectOwner != "OTHER" || a.EntryOwner != "USER" {
+		t.Errorf("Expected account rejected to W by OTHER, got: %#v", a)
+	}
+
+	// nothing left in C
+	if status := doRequestDecode(t, c, "POST", route, control, body, &reply); status != http.StatusNotFound {
+		t.Errorf("Expected response status %d, received %d", http.StatusNotFound, status)
+	}
+
+	// the rejection is in the log
+	logs := resources.AccountLogsReplyResource{}
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/account/log/" + account, control, nil, &logs); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if n := len(logs.Data); n == 0 || logs.Data[n - 1].Status != "W" || logs.Data[n - 1].RejectReason != "wrong SAP account" {
+		t.Errorf("Expected rejection in the log, got: %#v", logs.Data)
+	}
+
+	TestAccountDeleteAll(t)
+}
+
+//
+// scenario: bulk rejection is done for all the accounts or for none of them
+//
+func TestAccountRejectBulk(t *testing.T) {
+	TestAccountDeleteAll(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	accounts := []string{newAccountId(), newAccountId()}
+	for _, account := range accounts {
+		if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account}) == nil {
+			return
+		}
+	}
+	if res := doRequest(t, c, "POST", s.URL + "/api/release/new", booker, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected release response status %d", http.StatusOK)
+		return
+	}
+
+	route := s.URL + "/api/account/reject"
+	reply := resources.AccountsReplyResource{}
+	body := []byte("{\"data\":{\"reason\": \"missing WBS code\", \"entries\": [{\"bscsAccount\": \"" + accounts[0] + "\"}, {\"bscsAccount\": \"NONE\"}]}}")
+	if status := doRequestDecode(t, c, "POST", route, control, body, &reply); status != http.StatusNotFound {
+		t.Errorf("Expected response status %d, received %d", http.StatusNotFound, status)
+	}
+	if as := accountsRead(t, s, c, control, "C", 0); as == nil || len(*as) != 2 {
+		t.Errorf("Expected 2 accounts still in C")
+	}
+
+	body = []byte("{\"data\":{\"reason\": \"missing WBS code\", \"entries\": [{\"bscsAccount\": \"" + accounts[0] + "\"}, {\"bscsAccount\": \"" + accounts[1] + "\"}]}}")
+	if status := doRequestDecode(t, c, "POST", route, control, body, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if reply.Count != 2 {
+		t.Errorf("Expected 2 accounts rejected, got: %d", reply.Count)
+	}
+	if as := accountsRead(t, s, c, control, "W", 0); as == nil || len(*as) != 2 {
+		t.Errorf("Expected 2 accounts back in W")
+	} else if (*as)[0].RejectReason != "missing WBS code" {
+		t.Errorf("Expected reason of the rejection, got: %#v", (*as)[0])
+	}
+
+	TestAccountDeleteAll(t)
+}
+
+//
+// scenario: accounts refer to BSCS and SAP accounts of the dictionaries
+//
+func TestAccountReference(t *testing.T) {
+	TestAccountDeleteAll(t)
+
+	os.Setenv("ACCOUNTREFERENCE", common.AccountReferenceStrict)
+	defer os.Unsetenv("ACCOUNTREFERENCE")
+
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	active, inactive, unknown, sap := newAccountId(), newAccountId(), newAccountId(), newAccountId()
+	repository.LoadMemDictionaryAccountBscs([]models.DictionaryAccountBscs{
+		{Account: active, Name: "active", Active: "Y"},
+		{Account: inactive, Name: "inactive", Active: "N"},
+	})
+	repo, err := repository.NewDictionaryAccountSapRepository("USER")
+	if err != nil {
+		t.Errorf("Error in creating SAP account repository: %v", err)
+		return
+	}
+	err = repo.Create(&models.DictionaryAccountSap{Account: sap, Name: "sap"})
+	repo.Close()
+	if err != nil {
+		t.Errorf("Error in creating SAP account: %v", err)
+		return
+	}
+
+	body := func(bscs, sap string) []byte {
+		return []byte(fmt.Sprintf("{\"data\":{\"bscsAccount\":\"%s\",\"ofiSapAccount\":\"%s\"}}", bscs, sap))
+	}
+
+	// the code not accepted is named in the error
+	for _, tc := range []struct{ bscs, sap, message string }{
+		{unknown, sap, "Unknown BSCS account: " + unknown},
+		{inactive, sap, "Inactive BSCS account: " + inactive},
+		{active, unknown, "Unknown SAP account: " + unknown},
+	} {
+		status, e := doRequestError(t, c, "POST", s.URL + "/api/account", token, body(tc.bscs, tc.sap))
+		if status != http.StatusUnprocessableEntity || e == nil || e.Message != tc.message {
+			t.Errorf("Expected response status %d with %q, received %d %#v", http.StatusUnprocessableEntity, tc.message, status, e)
+		}
+	}
+
+	if accountCreate(t, s, c, token, &models.Account{BscsAccount: active, OfiSapAccount: sap}) == nil {
+		return
+	}
+
+	// update and patch are checked the same way
+	route := s.URL + "/api/account/W/0/" + active
+	if status, _ := doRequestError(t, c, "PUT", route, token, body("", unknown)); status != http.StatusUnprocessableEntity {
+		t.Errorf("Expected update response status %d, received %d", http.StatusUnprocessableEntity, status)
+	}
+	if status, _ := doRequestError(t, c, "PATCH", route, token, []byte("{\"data\":{\"ofiSapAccount\":\"" + unknown + "\"}}")); status != http.StatusUnprocessableEntity {
+		t.Errorf("Expected patch response status %d, received %d", http.StatusUnprocessableEntity, status)
+	}
+
+	// import reports the row
+	status, report := importPost(t, c, s.URL + "/api/account/import", token, "application/csv",
+		[]byte("bscsAccount;ofiSapAccount\n" + inactive + ";" + sap + "\n"))
+	if report == nil {
+		return
+	} else if status != http.StatusUnprocessableEntity || len(report.Errors) != 1 || report.Errors[0].Error != "Inactive BSCS account: " + inactive {
+		t.Errorf("Expected error of inactive account, received status: %d report: %#v", status, *report)
+	}
+
+	// the Booker may stage the SAP account being created
+	os.Setenv("ACCOUNTREFERENCE", common.AccountReferenceStaged)
+	_, _, token = initTestEnv(t, "USER", "Booker", true)
+	if !accountUpdateAttributeOne(t, s, c, token, "W", 0, active, "ofiSapAccount", unknown) {
+		t.Errorf("Expected staged SAP account %s", unknown)
+	}
+	if status, _ := doRequestError(t, c, "POST", s.URL + "/api/account", token, body(inactive, sap)); status != http.StatusUnprocessableEntity {
+		t.Errorf("Expected staged response status %d, received %d", http.StatusUnprocessableEntity, status)
+	}
+}
+
+//
+// scenario: history lists the changed attributes of the account with the filters
+//
+func TestAccountHistory(t *testing.T) {
+	TestAccountDeleteAll(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account, OfiSapAccount: "SAP1"}) == nil {
+		return
+	}
+	if !accountUpdateAttributeOne(t, s, c, booker, "W", 0, account, "ofiSapAccount", "SAP2") {
+		return
+	}
+	if res := doRequest(t, c, "POST", s.URL + "/api/release/new", booker, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected release response status %d", http.StatusOK)
+		return
+	}
+
+	route := s.URL + "/api/account/history?account=" + account
+	reply := resources.HistoryReplyResource{}
+	if status := doRequestDecode(t, c, "GET", route, control, nil, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if reply.Count != 3 || reply.Data[0].OpCode != "I" || reply.Data[0].User != "USER" {
+		t.Errorf("Expected insert and 2 updates, got: %#v", reply.Data)
+		return
+	}
+	changed := func(e models.HistoryEntry, attribute, from, to string) bool {
+		for _, ch := range e.Changes {
+			if ch.Attribute == attribute {
+				return ch.From == from && ch.To == to
+			}
+		}
+		return false
+	}
+	if e := reply.Data[1]; e.OpCode != "U" || len(e.Changes) != 1 || !changed(e, "ofiSapAccount", "SAP1", "SAP2") {
+		t.Errorf("Expected change of SAP account, got: %#v", e)
+	}
+	if e := reply.Data[2]; e.Status != "C" || !changed(e, "status", "W", "C") || changed(e, "ofiSapAccount", "SAP1", "SAP2") {
+		t.Errorf("Expected change of status by release, got: %#v", e)
+	}
+
+	// filters
+	tomorrow := time.Now().AddDate(0, 0, 1).Format(common.CutOffDateFormat)
+	for _, tc := range []struct {
+		query string
+		count int64
+	}{
+		{"&op=I", 1},
+		{"&op=D", 0},
+		{"&user=OTHER", 0},
+		{"&release=0", 3},
+		{"&release=1", 0},
+		{"&from=" + tomorrow, 0},
+		{"&to=" + tomorrow, 3},
+	} {
+		if status := doRequestDecode(t, c, "GET", route + tc.query, control, nil, &reply); status != http.StatusOK || reply.Count != tc.count {
+			t.Errorf("Expected %d entries of %s, received %d %d", tc.count, tc.query, status, reply.Count)
+		}
+	}
+	for _, query := range []string{"&op=X", "&from=yesterday", "&release=-1"} {
+		if status, _ := doRequestError(t, c, "GET", route + query, control, nil); status != http.StatusBadRequest {
+			t.Errorf("Expected response status %d of %s, received %d", http.StatusBadRequest, query, status)
+		}
+	}
+
+	// export one row per change
+	if xf := excelRead(t, c, route, control); xf != nil {
+		if sheet, ok := xf.Sheet["History"]; !ok || len(sheet.Rows) < 4 {
+			t.Errorf("Expected History sheet with the changes")
+		}
+	}
+
+	TestAccountDeleteAll(t)
+}
+
+//
+// scenario: account in W is restored from the log after the change and the deletion
+//
+func TestAccountRestore(t *testing.T) {
+	TestAccountDeleteAll(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account, OfiSapAccount: "SAP1"}) == nil {
+		return
+	}
+	if !accountUpdateAttributeOne(t, s, c, booker, "W", 0, account, "ofiSapAccount", "SAP2") {
+		return
+	}
+
+	logs := resources.AccountLogsReplyResource{}
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/account/log/" + account, booker, nil, &logs); status != http.StatusOK || len(logs.Data) != 2 {
+		t.Errorf("Expected 2 log entries, received %d %#v", status, logs.Data)
+		return
+	}
+	inserted := logs.Data[0]
+	if inserted.OpCode != "I" || inserted.Id == "" {
+		t.Errorf("Expected insert with id, got: %#v", inserted)
+		return
+	}
+
+	route := s.URL + "/api/account/log/" + account + "/restore"
+	body := []byte("{\"data\":{\"id\": \"" + inserted.Id + "\"}}")
+	reply := resources.AccountReplyResource{}
+
+	// the id must be given and found
+	if status, _ := doRequestError(t, c, "POST", route, booker, []byte("{\"data\":{}}")); status != http.StatusForbidden {
+		t.Errorf("Expected response status %d, received %d", http.StatusForbidden, status)
+	}
+	if status, _ := doRequestError(t, c, "POST", route, booker, []byte("{\"data\":{\"id\": \"NONE\"}}")); status != http.StatusNotFound {
+		t.Errorf("Expected response status %d, received %d", http.StatusNotFound, status)
+	}
+
+	// Control may not change the SAP account
+	if status, e := doRequestError(t, c, "POST", route, control, body); status != http.StatusForbidden || e == nil || e.Message != "Invalid role Control accessing field ofiSapAccount" {
+		t.Errorf("Expected response status %d, received %d %#v", http.StatusForbidden, status, e)
+	}
+
+	// overwrite of the change
+	if status := doRequestDecode(t, c, "POST", route, booker, body, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if a := reply.Data; a.Status != "W" || a.OfiSapAccount != "SAP1" || a.RecVersion != 2 {
+		t.Errorf("Expected SAP account restored, got: %#v", a)
+	}
+
+	// creation after the deletion
+	if res := doRequest(t, c, "DELETE", s.URL + "/api/account/W/0/" + account, booker, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected delete response status %d", http.StatusOK)
+		return
+	}
+	if status := doRequestDecode(t, c, "POST", route, booker, body, &reply); status != http.StatusCreated {
+		t.Errorf("Expected response status %d, received %d", http.StatusCreated, status)
+		return
+	}
+	if a := reply.Data; a.OfiSapAccount != "SAP1" || a.EntryOwner != "USER" {
+		t.Errorf("Expected account created by restore, got: %#v", a)
+	}
+
+	// both restores are audited
+	history := resources.HistoryReplyResource{}
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/account/history?op=R&account=" + account, control, nil, &history); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+	} else if history.Count != 2 || history.Data[0].User != "USER" {
+		t.Errorf("Expected 2 restores by USER, got: %#v", history.Data)
+	}
+
+	TestAccountDeleteAll(t)
+}
+
+//
+// scenario: the rejection is committed and reported done though the mail server is unavailable
+//
+func TestAccountRejectWithNoEmail(t *testing.T) {
+	TestAccountDeleteAll(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account}) == nil {
+		return
+	}
+	if res := doRequest(t, c, "POST", s.URL + "/api/release/new", booker, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected release response status %d", http.StatusOK)
+		return
+	}
+
+	common.AppConfig.AlertMailServerAddress = "xxx:23123"
+	defer func() { common.AppConfig.AlertMailServerAddress = "" }()
+
+	reply := resources.AccountReplyResource{}
+	body := []byte("{\"data\":{\"reason\": \"wrong SAP account\"}}")
+	if status := doRequestDecode(t, c, "POST", s.URL + "/api/account/C/0/" + account + "/reject", control, body, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if reply.Data.Status != "W" {
+		t.Errorf("Expected account rejected to W, got: %#v", reply.Data)
+	}
+
+	TestAccountDeleteAll(t)
+}
@@ -0,0 +1,245 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+//
+// scenario: release W -> C requested by one Booker and approved by other one
+//
+func TestApprovalRelease(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	os.Setenv("RELEASEAPPROVAL", "Y")
+	defer os.Unsetenv("RELEASEAPPROVAL")
+
+	client, server, token := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+	_, _, other := initTestEnv(t, "OTHER", "Booker", true)
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: account}) == nil {
+		return
+	}
+
+	// direct release is not allowed
+	if res := doRequest(t, client, "POST", server.URL + "/api/release/new", token, nil, nil); res == nil || res.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected release response status %d", http.StatusForbidden)
+		return
+	}
+
+	status, approval := approvalPost(t, client, server.URL + "/api/approval", token, []byte("{\"data\":{\"comment\": \"to be checked\"}}"))
+	if status != http.StatusCreated || approval == nil {
+		t.Errorf("Expected approval response status %d, received %d", http.StatusCreated, status)
+		return
+	}
+	a := approval.Data
+	if a.State != models.ApprovalRequested || a.FromStatus != "W" || a.IntoStatus != "C" || a.RequestOwner != "USER" || a.RequestRole != "Booker" {
+		t.Errorf("Expected request W -> C by USER as Booker, got: %#v", a)
+	}
+	url := fmt.Sprintf("%s/api/approval/%d", server.URL, a.ApprovalId)
+
+	pending := approvalsRead(t, client, server.URL + "/api/approval/pending", token)
+	if pending == nil || pending.Count == 0 || pending.Data[len(pending.Data) - 1].ApprovalId != a.ApprovalId {
+		t.Errorf("Expected request %d pending", a.ApprovalId)
+	}
+
+	// no self approval, no other role, no rejection without comment
+	if status, _ := approvalPost(t, client, url + "/approve", token, nil); status != http.StatusForbidden {
+		t.Errorf("Expected self approval response status %d, received %d", http.StatusForbidden, status)
+	}
+	if status, _ := approvalPost(t, client, url + "/approve", control, nil); status != http.StatusForbidden {
+		t.Errorf("Expected approval by other role response status %d, received %d", http.StatusForbidden, status)
+	}
+	if status, _ := approvalPost(t, client, url + "/reject", other, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected rejection without comment response status %d, received %d", http.StatusBadRequest, status)
+	}
+
+	status, approval = approvalPost(t, client, url + "/approve", other, []byte("{\"data\":{\"comment\": \"checked\"}}"))
+	if status != http.StatusOK || approval == nil {
+		t.Errorf("Expected approval response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	a = approval.Data
+	if a.State != models.ApprovalApproved || len(a.Steps) != 2 {
+		t.Errorf("Expected approved request with 2 steps, got: %#v", a)
+	} else if s := a.Steps[1]; s.Step != models.ApprovalApproved || s.StepOwner != "OTHER" || s.StepRole != "Booker" || s.Comment != "checked" || s.StepDateStr == "" {
+		t.Errorf("Expected approval step by OTHER as Booker, got: %#v", s)
+	}
+	if accounts := accountsRead(t, server, client, token, "C", 0); accounts == nil || len(*accounts) != 1 {
+		t.Errorf("Expected account %s released to C", account)
+	}
+
+	// decided only once
+	if status, _ := approvalPost(t, client, url + "/approve", other, nil); status != http.StatusConflict {
+		t.Errorf("Expected approval response status %d, received %d", http.StatusConflict, status)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: release C -> P requested by Control and rejected by other one
+//
+func TestApprovalReject(t *testing.T) {
+	client, server, token := initTestEnv(t, "USER", "Control", true)
+	defer server.Close()
+	_, _, other := initTestEnv(t, "OTHER", "Control", true)
+
+	if status, _ := approvalPost(t, client, server.URL + "/api/approval", token, []byte("{\"data\":{\"releaseId\": \"X\"}}")); status != http.StatusBadRequest {
+		t.Errorf("Expected approval response status %d, received %d", http.StatusBadRequest, status)
+	}
+
+	status, approval := approvalPost(t, client, server.URL + "/api/approval", token, nil)
+	if status != http.StatusCreated || approval == nil {
+		t.Errorf("Expected approval response status %d, received %d", http.StatusCreated, status)
+		return
+	}
+	if a := approval.Data; a.FromStatus != "C" || a.IntoStatus != "P" || a.ReleaseId != "new" {
+		t.Errorf("Expected request C -> P of new release, got: %#v", a)
+	}
+	url := fmt.Sprintf("%s/api/approval/%d", server.URL, approval.Data.ApprovalId)
+
+	status, approval = approvalPost(t, client, url + "/reject", other, []byte("{\"data\":{\"comment\": \"not yet\"}}"))
+	if status != http.StatusOK || approval == nil {
+		t.Errorf("Expected rejection response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if a := approval.Data; a.State != models.ApprovalRejected || len(a.Steps) != 2 || a.Steps[1].Comment != "not yet" {
+		t.Errorf("Expected rejected request with comment, got: %#v", a)
+	}
+
+	rejected := approvalsRead(t, client, server.URL + "/api/approval?state=J", token)
+	if rejected == nil || rejected.Count == 0 {
+		t.Errorf("Expected rejected request listed")
+	}
+	pending := approvalsRead(t, client, server.URL + "/api/approval/pending", token)
+	for _, a := range pending.Data {
+		if a.ApprovalId == approval.Data.ApprovalId {
+			t.Errorf("Expected request %d not pending", a.ApprovalId)
+		}
+	}
+}
+
+//
+// scenario: revoke of the release in P is refused directly and done by approval of other Control
+//
+func TestApprovalRevoke(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	client, server, booker := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+	_, _, control := initTestEnv(t, "USER", "Control", true)
+	_, _, other := initTestEnv(t, "OTHER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, server, client, booker, &models.Account{BscsAccount: account}) == nil {
+		return
+	}
+
+	// W -> C -> P before the approval is required
+	for _, tk := range []string{booker, control} {
+		if res := doRequest(t, client, "POST", server.URL + "/api/release/new", tk, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return
+		}
+	}
+	releases := releasesRead(t, server, client, control)
+	if releases == nil || len(releases.Data) == 0 {
+		return
+	}
+	release := releaseLatest(releases)
+
+	common.AppConfig.ReleaseApproval = "Y"
+	defer func() { common.AppConfig.ReleaseApproval = "N" }()
+
+	// direct revoke is not allowed
+	if res := doRequest(t, client, "DELETE", server.URL + "/api/release/" + release.ReleaseId, control, nil, nil); res == nil || res.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected revoke response status %d", http.StatusForbidden)
+		return
+	}
+
+	if status, _ := approvalPost(t, client, server.URL + "/api/approval", control, []byte("{\"data\":{\"intoStatus\": \"W\"}}")); status != http.StatusBadRequest {
+		t.Errorf("Expected revoke request without release response status %d, received %d", http.StatusBadRequest, status)
+	}
+
+	status, approval := approvalPost(t, client, server.URL + "/api/approval", control, []byte("{\"data\":{\"intoStatus\": \"W\", \"releaseId\": \"" + release.ReleaseId + "\"}}"))
+	if status != http.StatusCreated || approval == nil {
+		t.Errorf("Expected approval response status %d, received %d", http.StatusCreated, status)
+		return
+	}
+	if a := approval.Data; a.FromStatus != "P" || a.IntoStatus != "W" || a.ReleaseId != release.ReleaseId {
+		t.Errorf("Expected request P -> W of release %s, got: %#v", release.ReleaseId, a)
+	}
+	url := fmt.Sprintf("%s/api/approval/%d", server.URL, approval.Data.ApprovalId)
+
+	status, approval = approvalPost(t, client, url + "/approve", other, nil)
+	if status != http.StatusOK || approval == nil || approval.Data.State != models.ApprovalApproved {
+		t.Errorf("Expected approval response status %d, received %d", http.StatusOK, status)
+		return
+	}
+
+	if accounts := accountsRead(t, server, client, booker, "W", 0); accounts == nil || len(*accounts) != 1 || (*accounts)[0].BscsAccount != account {
+		t.Errorf("Expected account revoked to Work, got: %#v", accounts)
+	}
+	if releases = releasesRead(t, server, client, control); releases != nil {
+		for _, r := range releases.Data {
+			if r.ReleaseId == release.ReleaseId && r.RevokeOwner != "OTHER" {
+				t.Errorf("Expected release revoked by OTHER, got: %#v", r)
+			}
+		}
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: requests made at the same time get own ids, all rejected after
+//
+func TestApprovalConcurrent(t *testing.T) {
+	client, server, token := initTestEnv(t, "USER", "Control", true)
+	defer server.Close()
+	_, _, other := initTestEnv(t, "OTHER", "Control", true)
+
+	const requests = 5
+	ids := make(chan int64, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if status, approval := approvalPost(t, client, server.URL + "/api/approval", token, nil); status == http.StatusCreated && approval != nil {
+				ids <- approval.Data.ApprovalId
+			} else {
+				t.Errorf("Expected approval response status %d, received %d", http.StatusCreated, status)
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[int64]bool)
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("Expected own id of each request, got %d twice", id)
+		}
+		seen[id] = true
+
+		url := fmt.Sprintf("%s/api/approval/%d", server.URL, id)
+		if status, _ := approvalPost(t, client, url + "/reject", other, []byte("{\"data\":{\"comment\": \"duplicate\"}}")); status != http.StatusOK {
+			t.Errorf("Expected rejection response status %d, received %d", http.StatusOK, status)
+		}
+	}
+}
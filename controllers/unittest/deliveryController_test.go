@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/resources"
+)
+
+// Release in P with one account and order, the id of the release
+func deliveryRelease(t *testing.T, c *http.Client, s *httptest.Server, booker, control string) string {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	account := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account, OfiSapAccount: "SAP1", VatCodeInd: "V1"}) == nil {
+		return ""
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\", \"orderNumber\": \"N1\"}}")
+	if res := doRequest(t, c, "POST", s.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return ""
+	}
+	for _, tk := range []string{booker, control} {
+		if res := doRequest(t, c, "POST", s.URL + "/api/release/new", tk, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return ""
+		}
+	}
+	releases := releasesRead(t, s, c, booker)
+	if releases == nil || len(releases.Data) == 0 {
+		return ""
+	}
+
+	return releaseLatest(releases).ReleaseId
+}
+
+// Delivery of the release once it is not pending any more
+func deliveryWait(t *testing.T, c *http.Client, s *httptest.Server, token, release string) *models.Delivery {
+	for i := 0; i < 100; i++ {
+		reply := resources.DeliveryReplyResource{}
+		if status := doRequestDecode(t, c, "GET", s.URL + "/api/delivery/" + release, token, nil, &reply); status != http.StatusOK {
+			t.Errorf("Expected delivery of release %s, received %d", release, status)
+			return nil
+		}
+		if reply.Data.Status != models.DeliveryPending {
+			return &reply.Data
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	t.Errorf("Expected delivery of release %s done", release)
+	return nil
+}
+
+// Acknowledgement file put by SAP and ingested
+func deliveryAck(t *testing.T, c *http.Client, s *httptest.Server, token, dir, name, text string) *resources.DeliveryAckReplyResource {
+	os.MkdirAll(filepath.Join(dir, "ack"), 0755)
+	if err := ioutil.WriteFile(filepath.Join(dir, "ack", name), []byte(text), 0644); err != nil {
+		t.Errorf("Error in writing acknowledgement file: %v", err)
+		return nil
+	}
+
+	reply := resources.DeliveryAckReplyResource{}
+	if status := doRequestDecode(t, c, "POST", s.URL + "/api/delivery/ack", token, nil, &reply); status != http.StatusOK {
+		t.Errorf("Expected acknowledgement ingested, received %d", status)
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(dir, "ack", "done", name)); err != nil {
+		t.Errorf("Expected acknowledgement file moved to done: %v", err)
+	}
+
+	return &reply
+}
+
+//
+// scenario: release in P delivered to the drop directory, acknowledged by SAP
+//
+func TestDeliveryDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delivery")
+	if err != nil {
+		t.Errorf("Error in creating drop directory: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+	os.Setenv("DELIVERYTARGET", dir)
+	defer os.Unsetenv("DELIVERYTARGET")
+	os.Setenv("DELIVERYFORMAT", "csv")
+	defer os.Unsetenv("DELIVERYFORMAT")
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	release := deliveryRelease(t, c, s, booker, control)
+	if release == "" {
+		return
+	}
+
+	d := deliveryWait(t, c, s, booker, release)
+	if d == nil {
+		return
+	}
+	if d.Status != models.DeliverySent || d.Attempts != 1 || d.FileName != "SAMAPI_" + release + ".csv" || d.SentDateStr == "" {
+		t.Errorf("Expected delivery sent, got: %#v", *d)
+		return
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, d.FileName))
+	if err != nil || !strings.HasSuffix(string(data), ";" + d.Checksum + "\n") {
+		t.Errorf("Expected file delivered with checksum %s: %v %s", d.Checksum, err, data)
+		return
+	}
+
+	// the checksum of other file is not applied, the valid line is
+	reply := deliveryAck(t, c, s, booker, dir, "first.ack",
+		"# SAP acknowledgement\n" +
+		d.FileName + ";A;FFFFFFFF;Posted\n" +
+		"OTHER.csv;A;;Posted\n" +
+		d.FileName + ";A;" + d.Checksum + ";Posted\n")
+	if reply == nil {
+		return
+	}
+	if reply.Files != 1 || reply.Count != 3 || reply.Applied != 1 || reply.Data[0].Reason == "" || reply.Data[1].Reason == "" || reply.Data[2].Status != models.DeliveryDelivered {
+		t.Errorf("Expected one line applied, got: %#v", *reply)
+	}
+	if d = deliveryWait(t, c, s, booker, release); d == nil || d.Status != models.DeliveryDelivered || d.Message != "Posted" || d.AckDateStr == "" {
+		t.Errorf("Expected delivery delivered, got: %#v", d)
+	}
+
+	if reply = deliveryAck(t, c, s, booker, dir, "second.ack", d.FileName + ";R;;Unknown account\n"); reply == nil || reply.Applied != 1 {
+		t.Errorf("Expected rejection applied, got: %#v", reply)
+	}
+	if d = deliveryWait(t, c, s, booker, release); d == nil || d.Status != models.DeliveryRejected || d.Message != "Unknown account" {
+		t.Errorf("Expected delivery rejected, got: %#v", d)
+	}
+
+	// delivered again on request
+	if res := doRequest(t, c, "POST", s.URL + "/api/delivery/" + release, control, nil, nil); res == nil || res.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected delivery accepted")
+	}
+	if d = deliveryWait(t, c, s, booker, release); d == nil || d.Status != models.DeliverySent {
+		t.Errorf("Expected delivery sent again, got: %#v", d)
+	}
+
+	deliveries := resources.DeliveriesReplyResource{}
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/delivery", booker, nil, &deliveries); status != http.StatusOK || deliveries.Count == 0 {
+		t.Errorf("Expected deliveries, received %d: %#v", status, deliveries)
+	}
+	if status, _ := doRequestError(t, c, "GET", s.URL + "/api/delivery/9999", booker, nil); status != http.StatusNotFound {
+		t.Errorf("Expected response status %d, received %d", http.StatusNotFound, status)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: delivery to the target not writable fails after the retries
+//
+func TestDeliveryRetry(t *testing.T) {
+	file, err := ioutil.TempFile("", "delivery")
+	if err != nil {
+		t.Errorf("Error in creating file: %v", err)
+		return
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+	os.Setenv("DELIVERYTARGET", file.Name())
+	defer os.Unsetenv("DELIVERYTARGET")
+	os.Setenv("DELIVERYRETRIES", "3")
+	defer os.Unsetenv("DELIVERYRETRIES")
+	os.Setenv("DELIVERYRETRYDELAY", "0")
+	defer os.Unsetenv("DELIVERYRETRYDELAY")
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	release := deliveryRelease(t, c, s, booker, control)
+	if release == "" {
+		return
+	}
+
+	if d := deliveryWait(t, c, s, booker, release); d != nil && (d.Status != models.DeliveryFailed || d.Attempts != 3 || d.Message == "") {
+		t.Errorf("Expected delivery failed after 3 attempts, got: %#v", *d)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+// SFTP server of the local files accepting the user with the password, its address
+func sftpServe(t *testing.T, user, password string) (listener net.Listener) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Errorf("Error in generating host key: %v", err)
+		return nil
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		t.Errorf("Error in creating host key: %v", err)
+		return nil
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(c ssh.ConnMetadata, pass []byte) (*ssh.Permissions, error) {
+			if c.User() == user && string(pass) == password {
+				return nil, nil
+			}
+			return nil, ssh.ErrNoAuth
+		},
+	}
+	config.AddHostKey(signer)
+
+	if listener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+		t.Errorf("Error in listening: %v", err)
+		return nil
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, chans, reqs, err := ssh.NewServerConn(conn, config)
+				if err != nil {
+					return
+				}
+				go ssh.DiscardRequests(reqs)
+				for ch := range chans {
+					if ch.ChannelType() != "session" {
+						ch.Reject(ssh.UnknownChannelType, "unknown channel type")
+						continue
+					}
+					channel, requests, err := ch.Accept()
+					if err != nil {
+						continue
+					}
+					go func() {
+						for req := range requests {
+							ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+							req.Reply(ok, nil)
+							if ok {
+								if server, err := sftp.NewServer(channel); err == nil {
+									server.Serve()
+									server.Close()
+								}
+							}
+						}
+					}()
+				}
+			}()
+		}
+	}()
+
+	return listener
+}
+
+//
+// scenario: release in P delivered to the SFTP server, acknowledged by SAP
+//
+func TestDeliverySftp(t *testing.T) {
+	dir, err := ioutil.TempDir("", "delivery")
+	if err != nil {
+		t.Errorf("Error in creating drop directory: %v", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	listener := sftpServe(t, "sam", "secret")
+	if listener == nil {
+		return
+	}
+	defer listener.Close()
+
+	os.Setenv("DELIVERYTARGET", "sftp://sam:secret@" + listener.Addr().String() + filepath.ToSlash(dir) + "/outbound")
+	defer os.Unsetenv("DELIVERYTARGET")
+	os.Setenv("DELIVERYFORMAT", "xml")
+	defer os.Unsetenv("DELIVERYFORMAT")
+	os.Setenv("DELIVERYINSECUREHOSTKEY", "Y")
+	defer os.Unsetenv("DELIVERYINSECUREHOSTKEY")
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	release := deliveryRelease(t, c, s, booker, control)
+	if release == "" {
+		return
+	}
+
+	d := deliveryWait(t, c, s, booker, release)
+	if d == nil {
+		return
+	}
+	if d.Status != models.DeliverySent || d.FileName != "SAMAPI_" + release + ".xml" || strings.Contains(d.Target, "secret") {
+		t.Errorf("Expected delivery sent without the password, got: %#v", *d)
+		return
+	}
+	if data, err := ioutil.ReadFile(filepath.Join(dir, "outbound", d.FileName)); err != nil || !strings.Contains(string(data), d.Checksum) {
+		t.Errorf("Expected file delivered with checksum %s: %v", d.Checksum, err)
+	}
+
+	if reply := deliveryAck(t, c, s, booker, filepath.Join(dir, "outbound"), "sap.ack", d.FileName + ";A;" + d.Checksum + ";Posted\n"); reply == nil || reply.Applied != 1 {
+		t.Errorf("Expected acknowledgement applied, got: %#v", reply)
+	}
+	if d = deliveryWait(t, c, s, booker, release); d == nil || d.Status != models.DeliveryDelivered {
+		t.Errorf("Expected delivery delivered, got: %#v", d)
+	}
+
+	// the host key of the test server is not known, so it is refused without the flag
+	common.AppConfig.DeliveryInsecureHostKey = "N"
+	if transport, err := common.OpenDeliveryTransport(common.AppConfig.DeliveryTarget); err == nil {
+		transport.Close()
+		t.Errorf("Expected delivery refused without the host key check")
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
@@ -2,11 +2,16 @@ package controllers
 
 import (
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"testing"
 
+	"github.com/tealeg/xlsx"
+
 	"sam-api/common"
+	"sam-api/models"
+	"sam-api/resources"
 )
 
 //
@@ -139,3 +144,303 @@ func TestDictionaryAccountSapCreateExcel(t *testing.T) {
 		return
 	}
 }
+
+// SAP master sheet with the account in the first account cell and the description
+func sapMasterExcel(t *testing.T, accounts [][2]string) []byte {
+	xf := xlsx.NewFile()
+	sheet, err := xf.AddSheet("IKOS_AC_Master")
+	if err != nil {
+		t.Errorf("Error in creating Excel sheet: %v", err)
+		return nil
+	}
+	for i := 0; i < 2; i++ {
+		row := sheet.AddRow()
+		row.AddCell().SetString("Header")
+	}
+	for _, a := range accounts {
+		row := sheet.AddRow()
+		for k := 0; k <= 45; k++ {
+			cell := row.AddCell()
+			switch k {
+			case 28:
+				cell.SetString(a[0])
+			case 45:
+				cell.SetString(a[1])
+			}
+		}
+	}
+	var body bytes.Buffer
+	if err := xf.Write(&body); err != nil {
+		t.Errorf("Error in writing Excel file: %v", err)
+		return nil
+	}
+
+	return body.Bytes()
+}
+
+// SAP master sheet with the header titles of the account columns and the description, the
+// account in the first account column and the description cell missing if it is empty
+func sapMasterExcelHeader(t *testing.T, accounts [][2]string) []byte {
+	xf := xlsx.NewFile()
+	sheet, err := xf.AddSheet("IKOS_AC_Master")
+	if err != nil {
+		t.Errorf("Error in creating Excel sheet: %v", err)
+		return nil
+	}
+	sheet.AddRow().AddCell().SetString("Relevance")
+	header := sheet.AddRow()
+	for k := 2; k <= 12; k++ {
+		header.AddCell().SetInt(k)
+	}
+	header.AddCell().SetString("DESC EN (120)")
+	for _, a := range accounts {
+		row := sheet.AddRow()
+		row.AddCell().SetString(a[0])
+		for k := 3; k <= 12; k++ {
+			row.AddCell()
+		}
+		if a[1] != "" {
+			row.AddCell().SetString(a[1])
+		}
+	}
+	var body bytes.Buffer
+	if err := xf.Write(&body); err != nil {
+		t.Errorf("Error in writing Excel file: %v", err)
+		return nil
+	}
+
+	return body.Bytes()
+}
+
+func sapMasterPost(t *testing.T, c *http.Client, url, token string, body []byte) (int, *resources.DictionaryAccountSapMergeReplyResource) {
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		t.Errorf("Error in creating POST request for %s: %v", url, err)
+		return 0, nil
+	}
+	req.Header.Add("Content-Type", "application/xlsx")
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in POST to %s: %v", url, err)
+		return 0, nil
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest && res.StatusCode != http.StatusUnprocessableEntity {
+		return res.StatusCode, nil
+	}
+
+	reply := resources.DictionaryAccountSapMergeReplyResource{}
+	if err = json.NewDecoder(res.Body).Decode(&reply); err != nil {
+		t.Errorf("Expected DictionaryAccountSapMergeReplyResource json: %s", err.Error())
+		return res.StatusCode, nil
+	}
+
+	return res.StatusCode, &reply
+}
+
+func sapAccountsRead(t *testing.T, c *http.Client, url, token string) map[string]models.DictionaryAccountSap {
+	reply := resources.DictionaryAccountSapsReplyResource{}
+	if status := doRequestDecode(t, c, "GET", url, token, nil, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return nil
+	}
+
+	accounts := make(map[string]models.DictionaryAccountSap)
+	for _, d := range reply.Data {
+		accounts[d.Account] = d
+	}
+
+	return accounts
+}
+
+func sapMergeEq(m *resources.DictionaryAccountSapMergeReplyResource, inserted, updated, removed string, unchanged int64) bool {
+	return m.Inserted == 1 && m.Data.Inserted[0].Account == inserted &&
+		m.Updated == 1 && m.Data.Updated[0].Account == updated &&
+		m.Removed == 1 && m.Data.Removed[0].Account == removed &&
+		m.Unchanged == unchanged
+}
+
+//
+// scenario: preview and merge the SAP master, the new account is inserted,
+// the renamed one updated and the missing one deactivated
+//
+func TestDictionaryAccountSapMerge(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	url := s.URL + "/api/dictionary/account/sap"
+	if res := doRequest(t, c, "DELETE", url, token, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected dictionary deleted")
+		return
+	}
+	for _, a := range [][2]string{{"KEEP", "Keep"}, {"REN", "Old name"}, {"GONE", "Gone"}} {
+		body := []byte("{\"data\":{\"sapOfiAccount\":\"" + a[0] + "\",\"name\":\"" + a[1] + "\",\"status\":\"" + models.DictionaryAccountSapActive + "\"}}")
+		if res := doRequest(t, c, "POST", url, token, body, nil); res == nil || res.StatusCode != http.StatusCreated {
+			t.Errorf("Expected SAP account created: %s", a[0])
+			return
+		}
+	}
+
+	master := sapMasterExcel(t, [][2]string{{"KEEP", "Keep"}, {"REN", "New name"}, {"NEW", "New"}})
+
+	// preview changes nothing
+	status, m := sapMasterPost(t, c, url + "/preview", token, master)
+	if status != http.StatusOK || m == nil {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if m.Applied || !sapMergeEq(m, "NEW", "REN", "GONE", 1) || m.Data.Updated[0].OldName != "Old name" {
+		t.Errorf("Unexpected preview: %#v", *m)
+	}
+	if accounts := sapAccountsRead(t, c, url, token); len(accounts) != 3 || accounts["REN"].Name != "Old name" {
+		t.Errorf("Expected dictionary not changed by preview: %#v", accounts)
+	}
+
+	// merge applies the same difference
+	status, m = sapMasterPost(t, c, url, token, master)
+	if status != http.StatusCreated || m == nil {
+		t.Errorf("Expected response status %d, received %d", http.StatusCreated, status)
+		return
+	}
+	if !m.Applied || !sapMergeEq(m, "NEW", "REN", "GONE", 1) {
+		t.Errorf("Unexpected merge: %#v", *m)
+	}
+	accounts := sapAccountsRead(t, c, url, token)
+	if len(accounts) != 4 {
+		t.Errorf("Expected 4 SAP accounts, found: %d", len(accounts))
+	}
+	if d := accounts["GONE"]; d.Status != models.DictionaryAccountSapInactive {
+		t.Errorf("Expected removed account inactive: %#v", d)
+	}
+	if d := accounts["REN"]; d.Name != "New name" || d.Status != models.DictionaryAccountSapActive {
+		t.Errorf("Expected account renamed: %#v", d)
+	}
+	if d := accounts["NEW"]; d.Name != "New" || d.Status != models.DictionaryAccountSapActive {
+		t.Errorf("Expected account inserted: %#v", d)
+	}
+
+	// merge once again has nothing to do
+	status, m = sapMasterPost(t, c, url, token, master)
+	if status != http.StatusCreated || m == nil {
+		t.Errorf("Expected response status %d, received %d", http.StatusCreated, status)
+		return
+	}
+	if m.Inserted != 0 || m.Updated != 0 || m.Removed != 0 || m.Unchanged != 4 {
+		t.Errorf("Expected no changes: %#v", *m)
+	}
+
+	// empty master would deactivate all
+	if status, _ = sapMasterPost(t, c, url, token, sapMasterExcel(t, nil)); status != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, status)
+	}
+}
+
+//
+// scenario: the master is read with the header profile of the upload, the
+// short row is rejected and nothing is applied, the unknown profile is refused
+//
+func TestDictionaryAccountSapProfile(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	url := s.URL + "/api/dictionary/account/sap"
+	if res := doRequest(t, c, "DELETE", url, token, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected dictionary deleted")
+		return
+	}
+
+	// the short row has no description column
+	master := sapMasterExcelHeader(t, [][2]string{{"FULL", "Full"}, {"SHORT", ""}})
+	status, m := sapMasterPost(t, c, url + "?profile=ikos_en", token, master)
+	if status != http.StatusUnprocessableEntity || m == nil {
+		t.Errorf("Expected response status %d, received %d", http.StatusUnprocessableEntity, status)
+		return
+	}
+	if m.Applied || m.Profile != "ikos_en" || len(m.Errors) != 1 || m.Errors[0].Key != "SHORT" || m.Errors[0].Row != 4 {
+		t.Errorf("Expected short row rejected: %#v", *m)
+	}
+	if accounts := sapAccountsRead(t, c, url, token); len(accounts) != 0 {
+		t.Errorf("Expected nothing applied: %#v", accounts)
+	}
+
+	// preview shows the difference of the rows accepted
+	status, m = sapMasterPost(t, c, url + "/preview?profile=ikos_en", token, master)
+	if status != http.StatusOK || m == nil || m.Inserted != 1 || len(m.Errors) != 1 {
+		t.Errorf("Expected preview with error, status: %d reply: %#v", status, m)
+	}
+
+	// the German profile finds no header in the English master
+	status, m = sapMasterPost(t, c, url + "/preview?profile=ikos_de", token, master)
+	if status != http.StatusOK || m == nil || m.Profile != "ikos_de" || len(m.Errors) != 1 || m.Errors[0].Row != 0 {
+		t.Errorf("Expected missing header reported, status: %d reply: %#v", status, m)
+	}
+
+	// the header profile finds no header in the master of the default layout
+	status, m = sapMasterPost(t, c, url + "/preview?profile=ikos_en", token, sapMasterExcel(t, [][2]string{{"FULL", "Full"}}))
+	if status != http.StatusOK || m == nil || len(m.Errors) != 1 || m.Errors[0].Row != 0 {
+		t.Errorf("Expected missing header reported, status: %d reply: %#v", status, m)
+	}
+
+	if status, _ = sapMasterPost(t, c, url + "?profile=unknown", token, master); status != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, status)
+	}
+}
+
+//
+// scenario: the master of the IKOS export is read the same by the default
+// layout of the cells and by the titles of the header
+//
+func TestDictionaryAccountSapProfileLayout(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	ffn := common.AppConfig.RunPath + "/examples/" + "SAP_SEGMENT_ACCOUNTS_SHORT.xlsx"
+	master, err := ioutil.ReadFile(ffn)
+	if err != nil {
+		t.Errorf("Error reading file: %s - %s", ffn, err)
+		return
+	}
+
+	url := s.URL + "/api/dictionary/account/sap"
+	if res := doRequest(t, c, "DELETE", url, token, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected dictionary deleted")
+		return
+	}
+
+	status, m := sapMasterPost(t, c, url + "/preview", token, master)
+	if status != http.StatusOK || m == nil || m.Profile != "ikos" || m.Inserted == 0 {
+		t.Errorf("Expected accounts of the default layout, status: %d reply: %#v", status, m)
+		return
+	}
+	status, h := sapMasterPost(t, c, url + "/preview?profile=ikos_en", token, master)
+	if status != http.StatusOK || h == nil || h.Inserted != m.Inserted || len(h.Errors) != len(m.Errors) {
+		t.Errorf("Expected the same accounts by the header, status: %d reply: %#v", status, h)
+	}
+}
+
+//
+// scenario: without the profiles configured the master is read in the
+// layout of the cells of the IKOS export
+//
+func TestDictionaryAccountSapProfileDefault(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	profiles := common.AppConfig.SapImportProfiles
+	common.AppConfig.SapImportProfiles = ""
+	defer func() { common.AppConfig.SapImportProfiles = profiles }()
+
+	url := s.URL + "/api/dictionary/account/sap"
+	status, m := sapMasterPost(t, c, url + "/preview", token, sapMasterExcel(t, [][2]string{{"FULL", "Full"}, {"EMPTY", ""}}))
+	if status != http.StatusOK || m == nil || m.Profile != "ikos" || len(m.Errors) != 0 {
+		t.Errorf("Expected master read by the default profile, status: %d reply: %#v", status, m)
+		return
+	}
+	if m.Inserted + m.Updated + m.Unchanged != 2 {
+		t.Errorf("Expected 2 accounts of the master: %#v", *m)
+	}
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/resources"
+)
+
+//
+// scenario: the mapping as of the date is taken from the release valid on it
+//
+func TestMappingAsOf(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	first, err := common.NextCutOffDate()
+	if err != nil {
+		t.Errorf("Error in creating cut off date: %v", err)
+		return
+	}
+	second := first.AddDate(0, 1, 0)
+
+	release := func() bool {
+		for _, token := range []string{booker, control} {
+			if res := doRequest(t, c, "POST", s.URL + "/api/release/new", token, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+				t.Errorf("Expected release response status %d", http.StatusOK)
+				return false
+			}
+		}
+		return true
+	}
+
+	// the account changes in the second release, the order stays
+	account := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account, OfiSapAccount: "SAP1", ValidFromDateStr: first.Format(common.CutOffDateFormat)}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\", \"orderNumber\": \"N1\", \"validFromDate\": \"" + first.Format(common.CutOffDateFormat) + "\"}}")
+	if res := doRequest(t, c, "POST", s.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+	if !release() {
+		return
+	}
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account, OfiSapAccount: "SAP2", ValidFromDateStr: second.Format(common.CutOffDateFormat)}) == nil {
+		return
+	}
+	if !release() {
+		return
+	}
+
+	route := s.URL + "/api/mapping?account=" + account
+	reply := resources.MappingReplyResource{}
+	for _, tc := range []struct {
+		asOf, sap string
+		count     int64
+	}{
+		{"", "", 0},
+		{first.Format(common.CutOffDateFormat), "SAP1", 1},
+		{second.AddDate(0, 0, 10).Format(common.CutOffDateFormat), "SAP2", 1},
+	} {
+		if status := doRequestDecode(t, c, "GET", route + "&asOf=" + tc.asOf, booker, nil, &reply); status != http.StatusOK {
+			t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+			continue
+		}
+		if reply.Count != tc.count {
+			t.Errorf("Expected %d entries as of %s, got: %#v", tc.count, tc.asOf, reply.Data)
+		} else if tc.count > 0 && (reply.Data[0].OfiSapAccount != tc.sap || reply.Data[0].SegmentCode != "XXX" || reply.Data[0].OrderNumber != "N1") {
+			t.Errorf("Expected %s and order N1 as of %s, got: %#v", tc.sap, tc.asOf, reply.Data[0])
+		}
+	}
+
+	// the account without the order of the segment
+	asOf := "&asOf=" + second.Format(common.CutOffDateFormat)
+	reply = resources.MappingReplyResource{}
+	if status := doRequestDecode(t, c, "GET", route + asOf + "&segment=YYY", booker, nil, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+	} else if reply.Count != 1 || reply.Data[0].SegmentCode != "YYY" || reply.Data[0].OrderNumber != "" || reply.Data[0].OfiSapAccount != "SAP2" {
+		t.Errorf("Expected account without order of YYY, got: %#v", reply.Data)
+	}
+
+	if status, _ := doRequestError(t, c, "GET", route + "&asOf=tomorrow", booker, nil); status != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, status)
+	}
+
+	// CSV with the header
+	req, _ := http.NewRequest("GET", route + asOf, nil)
+	req.Header.Add("Content-Type", "application/csv")
+	req.Header.Add("Authorization", booker)
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in GET for %s: %v", route, err)
+		return
+	}
+	defer res.Body.Close()
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(res.Body); err != nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected CSV mapping, received %d %v", res.StatusCode, err)
+	} else if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.HasPrefix(lines[1], account + ",XXX,SAP2,") {
+		t.Errorf("Expected CSV mapping of %s, got: %s", account, buf.String())
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
@@ -2,9 +2,16 @@ package controllers
 
 import (
 	"bytes"
+	"github.com/tealeg/xlsx"
 	"github.com/unrolled/render"
 	"net/http"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"sam-api/models"
+	"sam-api/resources"
 )
 
 var (
@@ -262,6 +269,19 @@ func TestOrderReadAllAsCsv(t *testing.T) {
 	}
 }
 
+//
+// scenario: read all as Excel workbook
+//
+func TestOrderReadAllAsExcel(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	xf := excelRead(t, c, s.URL + "/api/order", "Bearer " + token)
+	if xf == nil || !excelSheetsCheck(t, xf) {
+		return
+	}
+}
+
 //
 // scenario: update previously created order
 //
@@ -356,3 +376,379 @@ func TestOrderDeleteOne(t *testing.T) {
 		return
 	}
 }
+
+//
+// scenario: order modifications checked against the record version read before
+//
+func TestOrderUpdateWithVersion(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Control", true)
+	defer s.Close()
+
+	account := newAccountId()
+	body := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\"}}")
+	res := doRequest(t, c, "POST", s.URL + "/api/order", token, body, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected response status %d, received %d", http.StatusCreated, res.StatusCode)
+		return
+	}
+	route := s.URL + "/api/order/W/0/" + account + "/XXX"
+
+	// version of the new record is returned as ETag
+	res = doRequest(t, c, "GET", route, token, nil, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return
+	}
+	etag := res.Header.Get("ETag")
+
+	// update with current version in payload is accepted
+	body = []byte("{\"data\":{\"orderNumber\":\"123\", \"recVersion\": " + strings.Trim(etag, "\"") + "}}")
+	res = doRequest(t, c, "PATCH", route, token, body, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return
+	}
+
+	// the same version is stale now
+	res = doRequest(t, c, "PATCH", route, token, body, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusConflict {
+		t.Errorf("Expected response status %d, received %d", http.StatusConflict, res.StatusCode)
+		return
+	}
+}
+
+//
+// scenario: import orders from Excel workbook with the export header
+//
+func TestOrderImportExcel(t *testing.T) {
+	c, s, token := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+
+	account := newAccountId()
+	xf := xlsx.NewFile()
+	sheet, err := xf.AddSheet("Work")
+	if err != nil {
+		t.Errorf("Error in creating Excel sheet: %v", err)
+		return
+	}
+	row := sheet.AddRow()
+	row.AddCell().SetString("BSCS Account")
+	row.AddCell().SetString("Segment Code")
+	row.AddCell().SetString("Valid From Date")
+	for _, segment := range []string{"XXX", "YYY"} {
+		row = sheet.AddRow()
+		row.AddCell().SetString(account)
+		row.AddCell().SetString(segment)
+		row.AddCell().SetDate(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+	}
+	var body bytes.Buffer
+	if err := xf.Write(&body); err != nil {
+		t.Errorf("Error in writing Excel file: %v", err)
+		return
+	}
+
+	status, report := importPost(t, c, s.URL + "/api/order/import", token, "application/xlsx", body.Bytes())
+	if report == nil {
+		return
+	} else if status != http.StatusCreated || report.Imported != 2 {
+		t.Errorf("Expected 2 records created, received status: %d report: %#v", status, *report)
+		return
+	}
+
+	res := doRequest(t, c, "GET", s.URL + "/api/order/W/0/" + account + "/YYY", token, nil, nil)
+	if res == nil {
+		return
+	} else if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return
+	}
+}
+
+//
+// scenario: Control rejects the orders in C back to W one by one and in bulk
+//
+func TestOrderReject(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	c, s, control := initTestEnv(t, "OTHER", "Control", true)
+	defer s.Close()
+	_, _, booker := initTestEnv(t, "USER", "Booker", true)
+
+	account := newAccountId()
+	for _, segment := range []string{"XXX", "YYY"} {
+		body := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"" + segment + "\"}}")
+		if res := doRequest(t, c, "POST", s.URL + "/api/order", control, body, nil); res == nil || res.StatusCode != http.StatusCreated {
+			t.Errorf("Expected response status %d", http.StatusCreated)
+			return
+		}
+	}
+	if res := doRequest(t, c, "POST", s.URL + "/api/release/new", booker, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected release response status %d", http.StatusOK)
+		return
+	}
+
+	route := s.URL + "/api/order/C/0/" + account + "/XXX/reject"
+	body := []byte("{\"data\":{\"reason\": \"wrong order number\"}}")
+	reply := resources.OrderReplyResource{}
+	if status := doRequestDecode(t, c, "POST", route, booker, body, &reply); status != http.StatusForbidden {
+		t.Errorf("Expected response status %d, received %d", http.StatusForbidden, status)
+	}
+	if status := doRequestDecode(t, c, "POST", route, control, []byte("{\"data\":{}}"), &reply); status != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, status)
+	}
+	if status := doRequestDecode(t, c, "POST", route, control, body, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if o := reply.Data; o.Status != "W" || o.SegmentCode != "XXX" || o.RejectReason != "wrong order number" || o.RejectOwner != "OTHER" {
+		t.Errorf("Expected order rejected to W by OTHER, got: %#v", o)
+	}
+
+	// bulk with the order already in W fails as a whole
+	orders := resources.OrdersReplyResource{}
+	body = []byte("{\"data\":{\"reason\": \"wrong order number\", \"entries\": [" +
+		"{\"bscsAccount\": \"" + account + "\", \"segmentCode\": \"YYY\"}, " +
+		"{\"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\"}]}}")
+	if status := doRequestDecode(t, c, "POST", s.URL + "/api/order/reject", control, body, &orders); status != http.StatusNotFound {
+		t.Errorf("Expected response status %d, received %d", http.StatusNotFound, status)
+	}
+
+	body = []byte("{\"data\":{\"reason\": \"wrong order number\", \"entries\": [" +
+		"{\"bscsAccount\": \"" + account + "\", \"segmentCode\": \"YYY\"}]}}")
+	if status := doRequestDecode(t, c, "POST", s.URL + "/api/order/reject", control, body, &orders); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if orders.Count != 1 || orders.Data[0].Status != "W" || orders.Data[0].SegmentCode != "YYY" {
+		t.Errorf("Expected order YYY rejected, got: %#v", orders)
+	}
+
+	// the rejection is in the log
+	logs := resources.OrderLogsReplyResource{}
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/order/log/" + account, control, nil, &logs); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	rejected := 0
+	for _, l := range logs.Data {
+		if l.RejectReason == "wrong order number" {
+			rejected++
+		}
+	}
+	if rejected != 2 {
+		t.Errorf("Expected 2 rejections in the log, got: %d", rejected)
+	}
+
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: accounts are missing the orders of segments or their numbers
+//
+func TestOrderCompleteness(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+	TestDictionarySegmentDeleteAll(t)
+	TestSegmentCreate(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "USER", "Control", true)
+
+	// account without orders, order of other segment without number
+	a1 := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: a1}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + a1 + "\", \"segmentCode\": \"Y\"}}")
+	if res := doRequest(t, c, "POST", s.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+
+	var reply resources.OrderCompletenessReplyResource
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/order/completeness", booker, nil, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	expected := []models.OrderGap{
+		{BscsAccount: a1, SegmentCode: "X", Missing: models.OrderMissing},
+		{BscsAccount: a1, SegmentCode: "Y", Missing: models.OrderNumberMissing},
+	}
+	if reply.Count != 2 || len(reply.Data) != 2 || reply.Data[0] != expected[0] || reply.Data[1] != expected[1] {
+		t.Errorf("Expected gaps %#v, got: %#v", expected, reply)
+	}
+
+	// the account is created with the orders of all segments
+	os.Setenv("ORDERSTUBS", "Y")
+	defer os.Unsetenv("ORDERSTUBS")
+	_, _, booker = initTestEnv(t, "USER", "Booker", true)
+
+	a2 := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: a2}) == nil {
+		return
+	}
+	if res := doRequest(t, c, "GET", s.URL + "/api/order/W/0/" + a2 + "/X", booker, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected order stub of %s in segment X", a2)
+	}
+
+	reply = resources.OrderCompletenessReplyResource{}
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/order/completeness?status=W&release=0", booker, nil, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	found := false
+	for _, g := range reply.Data {
+		if g.BscsAccount == a2 {
+			found = g == models.OrderGap{BscsAccount: a2, SegmentCode: "X", Missing: models.OrderNumberMissing}
+		}
+	}
+	if reply.Count != 3 || !found {
+		t.Errorf("Expected missing number of the order stub of %s, got: %#v", a2, reply)
+	}
+
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/order/completeness?status=X", booker, nil, &reply); status != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, status)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+	TestDictionarySegmentDeleteAll(t)
+}
+
+//
+// scenario: history of the orders follows the rejection back to W
+//
+func TestOrderHistory(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	c, s, control := initTestEnv(t, "OTHER", "Control", true)
+	defer s.Close()
+	_, _, booker := initTestEnv(t, "USER", "Booker", true)
+
+	account := newAccountId()
+	for _, segment := range []string{"XXX", "YYY"} {
+		body := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"" + segment + "\"}}")
+		if res := doRequest(t, c, "POST", s.URL + "/api/order", control, body, nil); res == nil || res.StatusCode != http.StatusCreated {
+			t.Errorf("Expected response status %d", http.StatusCreated)
+			return
+		}
+	}
+	if res := doRequest(t, c, "POST", s.URL + "/api/release/new", booker, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected release response status %d", http.StatusOK)
+		return
+	}
+	body := []byte("{\"data\":{\"reason\": \"wrong order number\"}}")
+	if res := doRequest(t, c, "POST", s.URL + "/api/order/C/0/" + account + "/XXX/reject", control, body, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected reject response status %d", http.StatusOK)
+		return
+	}
+
+	route := s.URL + "/api/order/history?account=" + account
+	reply := resources.HistoryReplyResource{}
+	if status := doRequestDecode(t, c, "GET", route + "&segment=XXX", control, nil, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if reply.Count != 3 {
+		t.Errorf("Expected insert, release and rejection, got: %#v", reply.Data)
+		return
+	}
+	rejected := false
+	for _, ch := range reply.Data[2].Changes {
+		rejected = rejected || (ch.Attribute == "rejectReason" && ch.To == "wrong order number")
+	}
+	if e := reply.Data[2]; e.Status != "W" || e.User != "OTHER" || !rejected {
+		t.Errorf("Expected rejection by OTHER, got: %#v", e)
+	}
+	if status := doRequestDecode(t, c, "GET", route, control, nil, &reply); status != http.StatusOK || reply.Count != 5 {
+		t.Errorf("Expected 5 entries of both segments, received %d %d", status, reply.Count)
+	}
+
+	// CSV export with the header
+	req, _ := http.NewRequest("GET", route, nil)
+	req.Header.Add("Content-Type", "application/csv")
+	req.Header.Add("Authorization", control)
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in GET for %s: %v", route, err)
+		return
+	}
+	defer res.Body.Close()
+	buf := new(bytes.Buffer)
+	buf.ReadFrom(res.Body)
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(buf.String(), "opdate,opcode,user,entity") {
+		t.Errorf("Expected CSV history, received %d %s", res.StatusCode, buf.String())
+	}
+
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: order number is restored from the log by Control
+//
+func TestOrderRestore(t *testing.T) {
+	TestOrderDeleteAll(t)
+
+	c, s, control := initTestEnv(t, "OTHER", "Control", true)
+	defer s.Close()
+	_, _, booker := initTestEnv(t, "USER", "Booker", true)
+
+	account := newAccountId()
+	body := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\"}}")
+	if res := doRequest(t, c, "POST", s.URL + "/api/order", control, body, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected response status %d", http.StatusCreated)
+		return
+	}
+	for _, number := range []string{"N1", "N2"} {
+		body := []byte("{\"data\":{\"orderNumber\": \"" + number + "\"}}")
+		if res := doRequest(t, c, "PATCH", s.URL + "/api/order/W/0/" + account + "/XXX", control, body, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected patch response status %d", http.StatusOK)
+			return
+		}
+	}
+
+	logs := resources.OrderLogsReplyResource{}
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/order/log/" + account, control, nil, &logs); status != http.StatusOK || len(logs.Data) != 3 {
+		t.Errorf("Expected 3 log entries, received %d %#v", status, logs.Data)
+		return
+	}
+	if logs.Data[1].OrderNumber != "N1" {
+		t.Errorf("Expected order number N1 in the log, got: %#v", logs.Data[1])
+		return
+	}
+
+	route := s.URL + "/api/order/log/" + account + "/restore"
+	body = []byte("{\"data\":{\"id\": \"" + logs.Data[1].Id + "\"}}")
+	reply := resources.OrderReplyResource{}
+
+	// Booker may not set the order number
+	if status := doRequestDecode(t, c, "POST", route, booker, body, &reply); status != http.StatusForbidden {
+		t.Errorf("Expected response status %d, received %d", http.StatusForbidden, status)
+	}
+	if status := doRequestDecode(t, c, "POST", route, control, body, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if o := reply.Data; o.SegmentCode != "XXX" || o.OrderNumber != "N1" {
+		t.Errorf("Expected order number N1 restored, got: %#v", o)
+	}
+
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/order/log/" + account, control, nil, &logs); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+	} else if n := len(logs.Data); n != 5 || logs.Data[n - 1].OpCode != "R" || logs.Data[n - 1].OrderNumber != "N1" {
+		t.Errorf("Expected restore in the log, got: %#v", logs.Data)
+	}
+
+	TestOrderDeleteAll(t)
+}
@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/resources"
+)
+
+// account mapped to SAP1 with the order N1 of XXX released into P, empty on failure
+func postingRelease(t *testing.T, s *httptest.Server, c *http.Client, booker, control string) (account string, first time.Time) {
+	first, err := common.NextCutOffDate()
+	if err != nil {
+		t.Errorf("Error in creating cut off date: %v", err)
+		return "", first
+	}
+	day := first.Format(common.CutOffDateFormat)
+
+	account = newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account, OfiSapAccount: "SAP1", ValidFromDateStr: day}) == nil {
+		return "", first
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\", \"orderNumber\": \"N1\", \"validFromDate\": \"" + day + "\"}}")
+	if res := doRequest(t, c, "POST", s.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return "", first
+	}
+	for _, token := range []string{booker, control} {
+		if res := doRequest(t, c, "POST", s.URL + "/api/release/new", token, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return "", first
+		}
+	}
+
+	return
+}
+
+//
+// scenario: BSCS postings translated with the release, the lines not mapped rejected
+//
+func TestPostingTranslate(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	account, first := postingRelease(t, s, c, booker, control)
+	if account == "" {
+		return
+	}
+	day := first.Format(common.CutOffDateFormat)
+
+	extract := []byte("glAccount;segment;amount;postingDate;currency\n" +
+		account + ";XXX;10.50;" + day + ";\n" +
+		account + ";XXX;-0.25;" + day + ";PLN\n" +
+		account + ";XXX;7;" + day + ";EUR\n" +
+		account + ";YYY;1.00;" + day + ";PLN\n" +
+		account + ";XXX;1.00;" + first.AddDate(0, 0, -1).Format(common.CutOffDateFormat) + ";PLN\n" +
+		account + ";XXX;1.001;" + day + ";PLN\n")
+
+	reply := resources.PostingTranslateReplyResource{}
+	if status := doRequestDecode(t, c, "POST", s.URL + "/api/posting/translate?currency=PLN", booker, extract, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if reply.Lines != 6 || reply.Posted != 3 || reply.Rejected != 3 {
+		t.Errorf("Expected 6 lines, 3 posted and 3 rejected, got: %#v", reply)
+	}
+	if len(reply.Totals) != 2 ||
+		reply.Totals[0] != (models.PostingTotal{OfiSapAccount: "SAP1", Currency: "EUR", Count: 1, Amount: "7.00"}) ||
+		reply.Totals[1] != (models.PostingTotal{OfiSapAccount: "SAP1", Currency: "PLN", Count: 2, Amount: "10.25"}) {
+		t.Errorf("Expected totals of SAP1 in EUR and PLN, got: %#v", reply.Totals)
+	}
+	date := strings.Replace(day, "-", "", -1)
+	if !strings.HasPrefix(reply.OfiFile, "D;" + date + ";SAP1;;N1;;0;10.50;PLN;" + account + ";XXX\n") || !strings.Contains(reply.OfiFile, "T;SAP1;PLN;2;10.25\n") {
+		t.Errorf("Expected SAP OFI file with postings and totals, got: %s", reply.OfiFile)
+	}
+	if rejects := strings.Split(strings.TrimSpace(reply.RejectFile), "\n"); len(rejects) != 4 || !strings.HasPrefix(rejects[1], "5,") || !strings.HasPrefix(rejects[3], "7,Invalid amount") {
+		t.Errorf("Expected reject file with lines 5, 6 and 7, got: %s", reply.RejectFile)
+	}
+
+	// the reject file only
+	req, _ := http.NewRequest("POST", s.URL + "/api/posting/translate?file=reject&currency=PLN", bytes.NewBuffer(extract))
+	req.Header.Add("Authorization", booker)
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in POST of postings: %v", err)
+		return
+	}
+	defer res.Body.Close()
+	buf := new(bytes.Buffer)
+	if _, err := buf.ReadFrom(res.Body); err != nil || res.StatusCode != http.StatusOK || buf.String() != reply.RejectFile {
+		t.Errorf("Expected reject file, received %d: %s", res.StatusCode, buf.String())
+	}
+
+	if status, _ := doRequestError(t, c, "POST", s.URL + "/api/posting/translate?file=all", booker, extract); status != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, status)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: BSCS postings in currencies with 0 and 3 decimal places
+//
+func TestPostingTranslateCurrencyExponent(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	account, first := postingRelease(t, s, c, booker, control)
+	if account == "" {
+		return
+	}
+	day := first.Format(common.CutOffDateFormat)
+
+	extract := []byte("glAccount;segment;amount;postingDate;currency\n" +
+		account + ";XXX;1500;" + day + ";JPY\n" +
+		account + ";XXX;-20;" + day + ";JPY\n" +
+		account + ";XXX;1.5;" + day + ";JPY\n" +
+		account + ";XXX;1.234;" + day + ";KWD\n" +
+		account + ";XXX;0.5;" + day + ";KWD\n" +
+		account + ";XXX;1.2345;" + day + ";KWD\n")
+
+	reply := resources.PostingTranslateReplyResource{}
+	if status := doRequestDecode(t, c, "POST", s.URL + "/api/posting/translate", booker, extract, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if reply.Lines != 6 || reply.Posted != 4 || reply.Rejected != 2 {
+		t.Errorf("Expected 6 lines, 4 posted and 2 rejected, got: %#v", reply)
+	}
+	if len(reply.Totals) != 2 ||
+		reply.Totals[0] != (models.PostingTotal{OfiSapAccount: "SAP1", Currency: "JPY", Count: 2, Amount: "1480"}) ||
+		reply.Totals[1] != (models.PostingTotal{OfiSapAccount: "SAP1", Currency: "KWD", Count: 2, Amount: "1.734"}) {
+		t.Errorf("Expected totals of SAP1 in JPY and KWD, got: %#v", reply.Totals)
+	}
+	if !strings.Contains(reply.OfiFile, ";1500;JPY;") || !strings.Contains(reply.OfiFile, ";0.500;KWD;") {
+		t.Errorf("Expected SAP OFI file with amounts in the decimal places of the currency, got: %s", reply.OfiFile)
+	}
+	if rejects := strings.Split(strings.TrimSpace(reply.RejectFile), "\n"); len(rejects) != 3 || !strings.HasPrefix(rejects[1], "4,Invalid amount") || !strings.HasPrefix(rejects[2], "7,Invalid amount") {
+		t.Errorf("Expected reject file with lines 4 and 7, got: %s", reply.RejectFile)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
@@ -1,12 +1,21 @@
 package controllers
 
 import (
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"sam-api/common"
+	"sam-api/models"
+	"sam-api/repository"
+	"sam-api/resources"
 )
 
 //
@@ -129,3 +138,771 @@ func TestReleaseAsBookerWithNoEmail(t *testing.T) {
 
 	log.Printf("Got error: %#v", dataResource)
 }
+
+//
+// scenario: release with description is listed in the catalogue, revoke is in its history
+//
+func TestReleaseReadAll(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+	TestAccountCreate(t)
+	TestOrderCreate(t)
+	TestReleaseAsBooker(t)
+
+	client, server, token := initTestEnv(t, "USER", "Control", true)
+	defer server.Close()
+
+	description := fmt.Sprintf("Test release %d", time.Now().UnixNano())
+	body := []byte("{\"data\":{\"description\": \"" + description + "\"}}")
+	res := doRequest(t, client, "POST", server.URL + "/api/release/new", token, body, nil)
+	if res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected release response status %d", http.StatusOK)
+		return
+	}
+
+	// find the release just done
+	find := func() *models.Release {
+		releases := releasesRead(t, server, client, token)
+		if releases == nil {
+			return nil
+		}
+		for i := range releases.Data {
+			if releases.Data[i].Description == description {
+				return &releases.Data[i]
+			}
+		}
+		t.Errorf("Expected release with description: %s", description)
+		return nil
+	}
+
+	release := find()
+	if release == nil {
+		return
+	}
+	if release.FromStatus != "C" || release.IntoStatus != "P" {
+		t.Errorf("Expected transition C -> P, got: %s -> %s", release.FromStatus, release.IntoStatus)
+	}
+	if release.ReleaseOwner != "USER" || release.ReleaseDateStr == "" {
+		t.Errorf("Expected release owner and date, got: %#v", release)
+	}
+	if release.Accounts != 1 || release.Orders != 1 {
+		t.Errorf("Expected 1 account and 1 order, got: %d %d", release.Accounts, release.Orders)
+	}
+	n := len(release.History)
+	if n == 0 || release.History[n - 1].OpCode != "N" {
+		t.Errorf("Expected history with new release, got: %#v", release.History)
+		return
+	}
+
+	// revoke it
+	res = doRequest(t, client, "DELETE", server.URL + "/api/release/" + release.ReleaseId, token, nil, nil)
+	if res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected revoke response status %d", http.StatusOK)
+		return
+	}
+
+	release = find()
+	if release == nil {
+		return
+	}
+	if release.RevokeDateStr == "" || release.Accounts != 0 || release.Orders != 0 {
+		t.Errorf("Expected revoked release without entries, got: %#v", release)
+	}
+	if len(release.History) != n + 1 || release.History[n].OpCode != "R" || release.History[n].Accounts != 1 {
+		t.Errorf("Expected history with revoke, got: %#v", release.History)
+	}
+
+	// released again under the same id the revoke is cleared
+	res = doRequest(t, client, "POST", server.URL + "/api/release/new", token, body, nil)
+	if res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected release response status %d", http.StatusOK)
+		return
+	}
+	if release = find(); release == nil {
+		return
+	}
+	if release.RevokeDateStr != "" || release.RevokeOwner != "" || release.ReleaseDateStr == "" {
+		t.Errorf("Expected release again without revoke, got: %#v", release)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: diff of Work with the last release as json and Excel report
+//
+func TestReleaseDiff(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	client, server, token := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+
+	changed := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: changed, OfiSapAccount: "OFI1"}) == nil {
+		return
+	}
+	TestOrderCreate(t)
+
+	// W -> C -> P
+	_, _, control := initTestEnv(t, "USER", "Control", true)
+	for _, tk := range []string{token, control} {
+		res := doRequest(t, client, "POST", server.URL + "/api/release/new", tk, nil, nil)
+		if res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return
+		}
+	}
+
+	// new version of the released account and a new one in Work
+	added := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: changed, OfiSapAccount: "OFI2"}) == nil ||
+		accountCreate(t, server, client, token, &models.Account{BscsAccount: added}) == nil {
+		return
+	}
+
+	req, err := http.NewRequest("GET", server.URL + "/api/release/diff?from=last&to=W", nil)
+	if err != nil {
+		t.Errorf("Error in creating GET request for ReleaseDiff: %v", err)
+		return
+	}
+	req.Header.Add("Authorization", token)
+
+	res, err := client.Do(req)
+	if err != nil {
+		t.Errorf("Error in GET on ReleaseDiff: %v", err)
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return
+	}
+
+	diff := models.ReleaseDiff{}
+	if err = json.NewDecoder(res.Body).Decode(&diff); err != nil {
+		t.Errorf("Expected ReleaseDiff json: %s", err.Error())
+		return
+	}
+
+	if diff.To != "W" || len(diff.Accounts.Added) != 1 || diff.Accounts.Added[0].BscsAccount != added {
+		t.Errorf("Expected added account %s, got: %#v", added, diff.Accounts)
+	}
+	if len(diff.Accounts.Changed) != 1 || len(diff.Accounts.Changed[0].Changes) != 1 ||
+		diff.Accounts.Changed[0].Changes[0] != (models.ReleaseDiffChange{Attribute: "ofiSapAccount", From: "OFI1", To: "OFI2"}) {
+		t.Errorf("Expected changed ofiSapAccount of %s, got: %#v", changed, diff.Accounts.Changed)
+	}
+	if len(diff.Orders.Removed) != 1 || len(diff.Orders.Added) != 0 {
+		t.Errorf("Expected removed order, got: %#v", diff.Orders)
+	}
+
+	// the same as Excel
+	xf := excelRead(t, client, server.URL + "/api/release/diff?from=last&to=W", token)
+	if xf == nil {
+		return
+	}
+	if sheet, ok := xf.Sheet["Accounts"]; !ok || len(sheet.Rows) != 3 {
+		t.Errorf("Expected sheet Accounts with header and 2 rows")
+	}
+	if _, ok := xf.Sheet["Orders"]; !ok {
+		t.Errorf("Expected sheet Orders")
+	}
+
+	// invalid side
+	res = doRequest(t, client, "GET", server.URL + "/api/release/diff?from=X&to=W", token, nil, nil)
+	if res == nil || res.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected response status %d for invalid release", http.StatusBadRequest)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: revoke failing on orders leaves the accounts released
+//
+func TestReleaseRevokeAtomic(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	client, server, token := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+	_, _, control := initTestEnv(t, "USER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: account}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\"}}")
+	if res := doRequest(t, client, "POST", server.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+
+	// W -> C -> P
+	for _, tk := range []string{token, control} {
+		if res := doRequest(t, client, "POST", server.URL + "/api/release/new", tk, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return
+		}
+	}
+	releases := releasesRead(t, server, client, token)
+	if releases == nil || len(releases.Data) == 0 {
+		return
+	}
+	release := releaseLatest(releases)
+
+	// the same order in Work clashes with the revoked one, the revoke is refused
+	if res := doRequest(t, client, "POST", server.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+	status, violations := releaseViolationsRead(t, client, "DELETE", server.URL + "/api/release/" + release.ReleaseId, control)
+	if status != http.StatusUnprocessableEntity || violations == nil {
+		t.Errorf("Expected revoke response status %d, received %d", http.StatusUnprocessableEntity, status)
+		return
+	}
+	if violations.Count != 1 || violations.Data[0].Entity != "order" || violations.Data[0].Rule != models.ReleaseRuleWorkNotEmpty {
+		t.Errorf("Expected order in Work violation, got: %#v", violations.Data)
+	}
+
+	id, _ := strconv.Atoi(release.ReleaseId)
+	if accounts := accountsRead(t, server, client, token, "P", id); accounts == nil || len(*accounts) != 1 {
+		t.Errorf("Expected account still released in %s", release.ReleaseId)
+	}
+	if accounts := accountsRead(t, server, client, token, "W", 0); accounts != nil && len(*accounts) != 0 {
+		t.Errorf("Expected no account revoked to Work, got: %#v", *accounts)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: revoke passing the check fails on the orders or on the registry
+// after the accounts are moved, nothing is moved back to Work
+//
+func TestReleaseRevokeFault(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	client, server, token := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+	_, _, control := initTestEnv(t, "USER", "Control", true)
+
+	// the failure is injected into the in-memory tables only
+	if common.AppConfig.Backend != common.BackendMemory {
+		t.Skip("Failure injection needs the memory backend")
+	}
+	defer func() { repository.MemFault = nil }()
+
+	account := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: account}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\"}}")
+	if res := doRequest(t, client, "POST", server.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+
+	// W -> C -> P
+	for _, tk := range []string{token, control} {
+		if res := doRequest(t, client, "POST", server.URL + "/api/release/new", tk, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return
+		}
+	}
+	releases := releasesRead(t, server, client, token)
+	if releases == nil || len(releases.Data) == 0 {
+		return
+	}
+	release := releaseLatest(releases)
+	id, _ := strconv.Atoi(release.ReleaseId)
+
+	for _, step := range [][2]string{{"SAP_ACC_SEGM_ORDER_NUMBERS", "SetStatusRelease"}, {"SAP_RELEASES", "Revoke"}} {
+		step := step
+		repository.MemFault = func(table, operation string) error {
+			if table == step[0] && operation == step[1] {
+				return fmt.Errorf("injected failure")
+			}
+			return nil
+		}
+
+		if res := doRequest(t, client, "DELETE", server.URL + "/api/release/" + release.ReleaseId, control, nil, nil); res == nil || res.StatusCode != http.StatusInternalServerError {
+			t.Errorf("Expected revoke failure in %s", step[0])
+			return
+		}
+
+		if accounts := accountsRead(t, server, client, token, "P", id); accounts == nil || len(*accounts) != 1 {
+			t.Errorf("Expected account still released in %s after failure in %s", release.ReleaseId, step[0])
+		}
+		if accounts := accountsRead(t, server, client, token, "W", 0); accounts != nil && len(*accounts) != 0 {
+			t.Errorf("Expected no account revoked to Work after failure in %s, got: %#v", step[0], *accounts)
+		}
+		orders := resources.OrdersReplyResource{}
+		if status := doRequestDecode(t, client, "GET", fmt.Sprintf("%s/api/order/P/%d", server.URL, id), token, nil, &orders); status != http.StatusOK || len(orders.Data) != 1 {
+			t.Errorf("Expected order still released in %s after failure in %s", release.ReleaseId, step[0])
+		}
+		if releases = releasesRead(t, server, client, token); releases != nil {
+			for _, r := range releases.Data {
+				if r.ReleaseId == release.ReleaseId && r.RevokeOwner != "" {
+					t.Errorf("Expected release not revoked after failure in %s, got: %#v", step[0], r)
+				}
+			}
+		}
+	}
+
+	// without the failure the revoke is done
+	repository.MemFault = nil
+	if res := doRequest(t, client, "DELETE", server.URL + "/api/release/" + release.ReleaseId, control, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected revoke response status %d", http.StatusOK)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: append to the release fails on the orders, the accounts stay in C
+//
+func TestReleaseAppendAtomic(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	client, server, token := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+	_, _, control := initTestEnv(t, "USER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: account}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\"}}")
+	if res := doRequest(t, client, "POST", server.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+
+	// W -> C -> P
+	for _, tk := range []string{token, control} {
+		if res := doRequest(t, client, "POST", server.URL + "/api/release/new", tk, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return
+		}
+	}
+	releases := releasesRead(t, server, client, token)
+	if releases == nil || len(releases.Data) == 0 {
+		return
+	}
+	release := releaseLatest(releases)
+
+	// new account and the same order once again W -> C
+	other := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: other}) == nil {
+		return
+	}
+	if res := doRequest(t, client, "POST", server.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+	if res := doRequest(t, client, "POST", server.URL + "/api/release/new", token, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected release response status %d", http.StatusOK)
+		return
+	}
+
+	// the order clashes with the one in the release
+	if res := doRequest(t, client, "POST", server.URL + "/api/release/" + release.ReleaseId, control, nil, nil); res == nil || res.StatusCode == http.StatusOK {
+		t.Errorf("Expected append failure")
+		return
+	}
+
+	if accounts := accountsRead(t, server, client, token, "C", 0); accounts == nil || len(*accounts) != 1 || (*accounts)[0].BscsAccount != other {
+		t.Errorf("Expected account %s still in C", other)
+	}
+	id, _ := strconv.Atoi(release.ReleaseId)
+	if accounts := accountsRead(t, server, client, token, "P", id); accounts == nil || len(*accounts) != 1 {
+		t.Errorf("Expected only account %s in release %s", account, release.ReleaseId)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: valid date of the account not after the one in production is refused
+//
+func TestReleaseCheckOverlap(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	client, server, token := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+	_, _, control := initTestEnv(t, "USER", "Control", true)
+
+	cutoff, err := common.NextCutOffDate()
+	if err != nil {
+		t.Errorf("Error in creating cut off date: %v", err)
+		return
+	}
+	later := cutoff.AddDate(0, 1, 0).Format(common.CutOffDateFormat)
+
+	account := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: account, ValidFromDateStr: later}) == nil {
+		return
+	}
+	for _, tk := range []string{token, control} {
+		if res := doRequest(t, client, "POST", server.URL + "/api/release/new", tk, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return
+		}
+	}
+
+	// the new version starts before the released one
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: account, ValidFromDateStr: cutoff.Format(common.CutOffDateFormat)}) == nil {
+		return
+	}
+	status, violations := releaseViolationsRead(t, client, "POST", server.URL + "/api/release/new", token)
+	if status != http.StatusUnprocessableEntity || violations == nil {
+		t.Errorf("Expected release response status %d, received %d", http.StatusUnprocessableEntity, status)
+		return
+	}
+	if violations.Count != 1 || violations.Data[0].BscsAccount != account || violations.Data[0].Rule != models.ReleaseRuleOverlap {
+		t.Errorf("Expected overlap violation of account %s, got: %#v", account, violations.Data)
+	}
+	if accounts := accountsRead(t, server, client, token, "W", 0); accounts == nil || len(*accounts) != 1 {
+		t.Errorf("Expected account %s still in W", account)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: dry run of the release reports the entries and changes nothing
+//
+func TestReleaseCheck(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+	TestDictionarySegmentDeleteAll(t)
+	TestSegmentCreate(t)
+
+	client, server, token := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+	_, _, control := initTestEnv(t, "USER", "Control", true)
+
+	// account without WBS code and order of unknown segment without number
+	account := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: account}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"Y\"}}")
+	if res := doRequest(t, client, "POST", server.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+
+	for _, url := range []string{"/api/release/check", "/api/release/new?dryRun=true"} {
+		method := "GET"
+		if url != "/api/release/check" {
+			method = "POST"
+		}
+		report := releaseCheckRead(t, client, method, server.URL + url, token)
+		if report == nil {
+			return
+		}
+		c := report.Data
+		if c.FromStatus != "W" || c.IntoStatus != "C" || c.Accounts != 1 || c.Orders != 1 || c.Ok {
+			t.Errorf("Expected W -> C of 1 account and 1 order with errors, got: %#v", c)
+		}
+		if len(c.MissingWbsCodes) != 1 || c.MissingWbsCodes[0].BscsAccount != account {
+			t.Errorf("Expected missing WBS code of %s, got: %#v", account, c.MissingWbsCodes)
+		}
+		if len(c.MissingOrderNumbers) != 1 || c.MissingOrderNumbers[0].SegmentCode != "Y" {
+			t.Errorf("Expected missing order number in segment Y, got: %#v", c.MissingOrderNumbers)
+		}
+		if len(c.MissingOrders) != 1 || c.MissingOrders[0].SegmentCode != "X" {
+			t.Errorf("Expected missing order in segment X, got: %#v", c.MissingOrders)
+		}
+		unresolved := false
+		for _, e := range c.UnresolvedReferences {
+			if e.Attribute == "segmentCode" && e.Value == "Y" {
+				unresolved = true
+			}
+		}
+		if !unresolved {
+			t.Errorf("Expected unresolved segment Y, got: %#v", c.UnresolvedReferences)
+		}
+	}
+
+	// nothing was released
+	if accounts := accountsRead(t, server, client, token, "W", 0); accounts == nil || len(*accounts) != 1 {
+		t.Errorf("Expected account %s still in W", account)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+	TestDictionarySegmentDeleteAll(t)
+}
+
+//
+// scenario: release in production copied back into Work with the modes of conflicts
+//
+func TestReleaseClone(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	client, server, booker := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+	_, _, control := initTestEnv(t, "USER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, server, client, booker, &models.Account{BscsAccount: account, OfiSapAccount: "4711"}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\", \"orderNumber\": \"1\"}}")
+	if res := doRequest(t, client, "POST", server.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+	for _, token := range []string{booker, control} {
+		if res := doRequest(t, client, "POST", server.URL + "/api/release/new", token, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return
+		}
+	}
+
+	// only Booker fills Work
+	if res := doRequest(t, client, "POST", server.URL + "/api/release/last/clone", control, nil, nil); res == nil || res.StatusCode != http.StatusForbidden {
+		t.Errorf("Expected clone response status %d", http.StatusForbidden)
+	}
+
+	var reply resources.ReleaseCloneReplyResource
+	if status := doRequestDecode(t, client, "POST", server.URL + "/api/release/last/clone", booker, nil, &reply); status != http.StatusOK {
+		t.Errorf("Expected clone response status %d, received %d", http.StatusOK, status)
+		return
+	}
+	if c := reply.Data; c.Mode != models.CloneFail || c.Accounts != 1 || c.Orders != 1 || len(c.Conflicts) != 0 {
+		t.Errorf("Expected clone of 1 account and 1 order, got: %#v", c)
+	}
+	if accounts := accountsRead(t, server, client, booker, "W", 0); accounts == nil || len(*accounts) != 1 || (*accounts)[0].OfiSapAccount != "4711" {
+		t.Errorf("Expected account %s cloned into W", account)
+	}
+
+	// entries already in Work
+	if res := doRequest(t, client, "POST", server.URL + "/api/release/last/clone", booker, nil, nil); res == nil || res.StatusCode != http.StatusConflict {
+		t.Errorf("Expected clone response status %d", http.StatusConflict)
+	}
+	for mode, expected := range map[string]models.ReleaseClone{
+		models.CloneSkip:      {Skipped: 2},
+		models.CloneOverwrite: {Overwritten: 2},
+	} {
+		reply = resources.ReleaseCloneReplyResource{}
+		if status := doRequestDecode(t, client, "POST", server.URL + "/api/release/last/clone?mode=" + mode, booker, nil, &reply); status != http.StatusOK {
+			t.Errorf("Expected clone response status %d, received %d", http.StatusOK, status)
+			continue
+		}
+		if c := reply.Data; c.Accounts != 0 || c.Orders != 0 || c.Skipped != expected.Skipped || c.Overwritten != expected.Overwritten || len(c.Conflicts) != 2 {
+			t.Errorf("Expected clone with mode %s, got: %#v", mode, c)
+		}
+	}
+
+	if res := doRequest(t, client, "POST", server.URL + "/api/release/last/clone?mode=merge", booker, nil, nil); res == nil || res.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected clone response status %d", http.StatusBadRequest)
+	}
+	if res := doRequest(t, client, "POST", server.URL + "/api/release/99999/clone", booker, nil, nil); res == nil || res.StatusCode != http.StatusNotFound {
+		t.Errorf("Expected clone response status %d", http.StatusNotFound)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: the release exported in all formats with the same counts and checksum
+//
+func TestReleaseExport(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	client, server, token := initTestEnv(t, "USER", "Booker", true)
+	defer server.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, server, client, token, &models.Account{BscsAccount: account, OfiSapAccount: "SAP1", VatCodeInd: "V1"}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\", \"orderNumber\": \"N1\"}}")
+	if res := doRequest(t, client, "POST", server.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+	for _, tk := range []string{token, control} {
+		if res := doRequest(t, client, "POST", server.URL + "/api/release/new", tk, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return
+		}
+	}
+	releases := releasesRead(t, server, client, token)
+	if releases == nil || len(releases.Data) == 0 {
+		return
+	}
+	release := releaseLatest(releases).ReleaseId
+
+	export := func(format string) (int, string) {
+		req, _ := http.NewRequest("GET", server.URL + "/api/release/" + release + "/export?format=" + format, nil)
+		req.Header.Add("Authorization", token)
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Error in GET of export: %v", err)
+			return 0, ""
+		}
+		defer res.Body.Close()
+		buf := new(bytes.Buffer)
+		buf.ReadFrom(res.Body)
+		return res.StatusCode, buf.String()
+	}
+
+	// fixed width records H, A, O and T
+	status, fixed := export("fixed")
+	lines := strings.Split(strings.TrimSuffix(fixed, "\n"), "\n")
+	if status != http.StatusOK || len(lines) != 4 {
+		t.Errorf("Expected fixed width export, received %d: %s", status, fixed)
+		return
+	}
+	for i, expected := range []struct {
+		prefix string
+		width  int
+	}{
+		{"HSAMAPI    " + fmt.Sprintf("%010s", release), 35},
+		{"A" + fmt.Sprintf("%-32s%-32s%-32s%-32s0", account, "SAP1", "", "V1"), 138},
+		{"O" + fmt.Sprintf("%-32s%-32s%-32s", account, "XXX", "N1"), 105},
+		{"T000000001000000001000000004", 36},
+	} {
+		if !strings.HasPrefix(lines[i], expected.prefix) || len(lines[i]) != expected.width {
+			t.Errorf("Expected record %d of width %d: %s, got: %s", i, expected.width, expected.prefix, lines[i])
+		}
+	}
+	checksum := lines[3][len(lines[3]) - 8:]
+
+	// semicolon CSV with the same trailer
+	status, csv := export("csv")
+	if status != http.StatusOK || !strings.Contains(csv, "A;" + account + ";SAP1;;V1;0;\n") || !strings.HasSuffix(csv, "T;1;1;4;" + checksum + "\n") {
+		t.Errorf("Expected CSV export with checksum %s, received %d: %s", checksum, status, csv)
+	}
+
+	// IDoc like XML with the same trailer
+	status, idoc := export("xml")
+	doc := struct {
+		Accounts []string `xml:"IDOC>E1ACCOUNT>BSCS_ACCOUNT"`
+		Records  int      `xml:"IDOC>E1TRAILER>RECORDS"`
+		Checksum string   `xml:"IDOC>E1TRAILER>CHECKSUM"`
+	}{}
+	if err := xml.Unmarshal([]byte(idoc), &doc); status != http.StatusOK || err != nil {
+		t.Errorf("Expected XML export, received %d: %v", status, err)
+	} else if len(doc.Accounts) != 1 || doc.Accounts[0] != account || doc.Records != 4 || doc.Checksum != checksum {
+		t.Errorf("Expected XML export with checksum %s, got: %s", checksum, idoc)
+	}
+
+	for format, expected := range map[string]int{"": http.StatusBadRequest, "pdf": http.StatusBadRequest} {
+		if status, _ := export(format); status != expected {
+			t.Errorf("Expected response status %d for format %s, received %d", expected, format, status)
+		}
+	}
+	release = "9999"
+	if status, _ := export("csv"); status != http.StatusNotFound {
+		t.Errorf("Expected response status %d, received %d", http.StatusNotFound, status)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
+
+//
+// scenario: entries rejected and released again are read with every endpoint,
+// the columns cleared by release and revoke must stay readable on PostgreSQL
+//
+func TestReleaseClearedColumns(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+
+	account := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\"}}")
+	if res := doRequest(t, c, "POST", s.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+
+	// W -> C, rejected back to W, W -> C -> P
+	release := func(token string) bool {
+		if res := doRequest(t, c, "POST", s.URL + "/api/release/new", token, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected release response status %d", http.StatusOK)
+			return false
+		}
+		return true
+	}
+	if !release(booker) {
+		return
+	}
+	body := []byte("{\"data\":{\"reason\": \"wrong mapping\"}}")
+	for _, route := range []string{"/api/account/C/0/" + account + "/reject", "/api/order/C/0/" + account + "/XXX/reject"} {
+		if res := doRequest(t, c, "POST", s.URL + route, control, body, nil); res == nil || res.StatusCode != http.StatusOK {
+			t.Errorf("Expected rejection of %s", route)
+			return
+		}
+	}
+	if !release(booker) || !release(control) {
+		return
+	}
+	releases := releasesRead(t, s, c, booker)
+	if releases == nil || len(releases.Data) == 0 {
+		return
+	}
+	id := releaseLatest(releases).ReleaseId
+
+	read := func() {
+		for _, route := range []string{
+			"/api/release",
+			"/api/account",
+			"/api/account/P/" + id,
+			"/api/account/log/" + account,
+			"/api/account/history?account=" + account,
+			"/api/order",
+			"/api/order/P/" + id,
+			"/api/order/log/" + account,
+			"/api/order/history?account=" + account,
+		} {
+			var reply interface{}
+			if status := doRequestDecode(t, c, "GET", s.URL + route, control, nil, &reply); status != http.StatusOK {
+				t.Errorf("Expected response status %d of %s, received %d", http.StatusOK, route, status)
+			}
+		}
+	}
+	read()
+
+	reply := resources.AccountReplyResource{}
+	if status := doRequestDecode(t, c, "GET", s.URL + "/api/account/P/" + id + "/" + account, control, nil, &reply); status != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+	} else if reply.Data.RejectReason != "" || reply.Data.RejectOwner != "" {
+		t.Errorf("Expected rejection cleared by release, got: %#v", reply.Data)
+	}
+
+	if res := doRequest(t, c, "DELETE", s.URL + "/api/release/" + id, control, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+		t.Errorf("Expected revoke response status %d", http.StatusOK)
+		return
+	}
+	read()
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+	"sam-api/resources"
+)
+
+//
+// scenario: postings resolved by the service on the mappings of the latest release
+//
+func TestResolve(t *testing.T) {
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+
+	os.Setenv("SERVICECREDENTIALS", "BILLING:secret")
+	defer os.Unsetenv("SERVICECREDENTIALS")
+
+	c, s, booker := initTestEnv(t, "USER", "Booker", true)
+	defer s.Close()
+	_, _, control := initTestEnv(t, "OTHER", "Control", true)
+	service := "Basic " + base64.StdEncoding.EncodeToString([]byte("BILLING:secret"))
+
+	first, err := common.NextCutOffDate()
+	if err != nil {
+		t.Errorf("Error in creating cut off date: %v", err)
+		return
+	}
+	second := first.AddDate(0, 1, 0)
+
+	release := func() bool {
+		for _, token := range []string{booker, control} {
+			if res := doRequest(t, c, "POST", s.URL + "/api/release/new", token, nil, nil); res == nil || res.StatusCode != http.StatusOK {
+				t.Errorf("Expected release response status %d", http.StatusOK)
+				return false
+			}
+		}
+		return true
+	}
+
+	account := newAccountId()
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account, OfiSapAccount: "SAP1", ValidFromDateStr: first.Format(common.CutOffDateFormat)}) == nil {
+		return
+	}
+	order := []byte("{\"data\":{\"status\": \"W\", \"releaseId\": \"0\", \"bscsAccount\": \"" + account + "\", \"segmentCode\": \"XXX\", \"orderNumber\": \"N1\", \"validFromDate\": \"" + first.Format(common.CutOffDateFormat) + "\"}}")
+	if res := doRequest(t, c, "POST", s.URL + "/api/order", control, order, nil); res == nil || res.StatusCode != http.StatusCreated {
+		t.Errorf("Expected order created")
+		return
+	}
+	if !release() {
+		return
+	}
+
+	resolve := func(lookups []models.ResolveLookup) *resources.ResolveReplyResource {
+		body, _ := json.Marshal(resources.ResolveRequestResource{Data: lookups})
+		reply := resources.ResolveReplyResource{}
+		if status := doRequestDecode(t, c, "POST", s.URL + "/api/resolve", service, body, &reply); status != http.StatusOK {
+			t.Errorf("Expected response status %d, received %d", http.StatusOK, status)
+			return nil
+		}
+		if int(reply.Count) != len(lookups) || len(reply.Data) != len(lookups) {
+			t.Errorf("Expected %d results, got: %#v", len(lookups), reply)
+			return nil
+		}
+		return &reply
+	}
+
+	lookup := func(account, segment, date string) models.ResolveLookup {
+		return models.ResolveLookup{BscsAccount: account, SegmentCode: segment, PostingDate: date}
+	}
+	day := first.Format(common.CutOffDateFormat)
+	reply := resolve([]models.ResolveLookup{
+		lookup(account, "XXX", day),
+		lookup(account, "", day),
+		lookup(account, "YYY", day),
+		lookup(account, "XXX", time.Now().Format(common.CutOffDateFormat)),
+		lookup("UNKNOWN", "XXX", day),
+		lookup(account, "XXX", "tomorrow"),
+	})
+	if reply == nil {
+		return
+	}
+	for i, expected := range []string{models.ResolveMapped, models.ResolveMapped, models.ResolveUnmapped, models.ResolveUnmapped, models.ResolveUnmapped, models.ResolveInvalid} {
+		if reply.Data[i].Status != expected {
+			t.Errorf("Expected %s result %d, got: %#v", expected, i, reply.Data[i])
+		}
+	}
+	if reply.Unmapped != 4 {
+		t.Errorf("Expected 4 unmapped, got: %d", reply.Unmapped)
+	}
+	if r := reply.Data[0]; r.OfiSapAccount != "SAP1" || r.OrderNumber != "N1" || r.BscsAccount != account {
+		t.Errorf("Expected SAP1 and N1, got: %#v", r)
+	}
+	if r := reply.Data[2]; r.Reason == "" || r.OfiSapAccount != "SAP1" {
+		t.Errorf("Expected SAP1 without order of YYY, got: %#v", r)
+	}
+
+	// the new release is resolved after it is done
+	if accountCreate(t, s, c, booker, &models.Account{BscsAccount: account, OfiSapAccount: "SAP2", ValidFromDateStr: second.Format(common.CutOffDateFormat)}) == nil {
+		return
+	}
+	if !release() {
+		return
+	}
+	if reply = resolve([]models.ResolveLookup{lookup(account, "XXX", day), lookup(account, "XXX", second.Format(common.CutOffDateFormat))}); reply != nil {
+		if reply.Data[0].OfiSapAccount != "SAP1" || reply.Data[1].OfiSapAccount != "SAP2" || reply.Data[1].OrderNumber != "N1" {
+			t.Errorf("Expected SAP1 then SAP2 with N1, got: %#v", reply.Data)
+		}
+	}
+
+	// the users and unknown services are not allowed, nor the empty request
+	for _, token := range []string{booker, "Basic " + base64.StdEncoding.EncodeToString([]byte("BILLING:other"))} {
+		if status, _ := doRequestError(t, c, "POST", s.URL + "/api/resolve", token, []byte("{\"data\":[]}")); status != http.StatusUnauthorized {
+			t.Errorf("Expected response status %d, received %d", http.StatusUnauthorized, status)
+		}
+	}
+	if status, _ := doRequestError(t, c, "POST", s.URL + "/api/resolve", service, []byte("{\"data\":[]}")); status != http.StatusBadRequest {
+		t.Errorf("Expected response status %d, received %d", http.StatusBadRequest, status)
+	}
+
+	TestAccountDeleteAll(t)
+	TestOrderDeleteAll(t)
+}
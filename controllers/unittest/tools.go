@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"net/http/httptest"
 	"net/http"
@@ -11,6 +12,8 @@ import (
 	"testing"
 	"time"
 	
+	"github.com/tealeg/xlsx"
+
 	"sam-api/common"
 	"sam-api/models"
 	"sam-api/resources"
@@ -213,3 +216,367 @@ func accountUpdateAttributeOne(t *testing.T, s *httptest.Server, c *http.Client,
 	return true
 }
 
+func doRequest(t *testing.T, c *http.Client, method, url, token string, body []byte, headers map[string]string) *http.Response {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	if err != nil {
+		t.Errorf("Error in creating %s request for %s: %v", method, url, err)
+		return nil
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+	for k, v := range headers {
+		req.Header.Add(k, v)
+	}
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in %s for %s: %v", method, url, err)
+		return nil
+	}
+	res.Body.Close()
+
+	return res
+}
+
+func accountsReadPage(t *testing.T, s *httptest.Server, c *http.Client, token string, route string) *resources.AccountsReplyResource {
+	req, err := http.NewRequest("GET", s.URL + route, nil)
+	if err != nil {
+		t.Errorf("Error in GET request for %s: %v", route, err)
+		return nil
+	}
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in GET for %s: %v", route, err)
+		return nil
+	}
+	defer res.Body.Close()
+
+	// check result(s)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return nil
+	}
+
+	dataResource := resources.AccountsReplyResource{}
+	err = json.NewDecoder(res.Body).Decode(&dataResource)
+	if err != nil {
+		t.Errorf("Expected AccountResource json: %s", err.Error())
+		return nil
+	}
+
+	return &dataResource
+}
+
+func excelRead(t *testing.T, c *http.Client, url, token string) *xlsx.File {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Errorf("Error in GET request for %s: %v", url, err)
+		return nil
+	}
+	req.Header.Add("Content-Type", "application/xlsx")
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in GET for %s: %v", url, err)
+		return nil
+	}
+	defer res.Body.Close()
+
+	// check result(s)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Errorf("Error reading Excel payload: %v", err)
+		return nil
+	}
+	xf, err := xlsx.OpenBinary(body)
+	if err != nil {
+		t.Errorf("Expected Excel payload: %v", err)
+		return nil
+	}
+
+	return xf
+}
+
+func excelSheetsCheck(t *testing.T, xf *xlsx.File) bool {
+	for _, name := range []string{"Work", "Control", "Production", "Metadata"} {
+		sheet, ok := xf.Sheet[name]
+		if !ok {
+			t.Errorf("Expected Excel sheet: %s", name)
+			return false
+		}
+		if len(sheet.Rows) == 0 || len(sheet.Rows[0].Cells) == 0 {
+			t.Errorf("Expected header row in Excel sheet: %s", name)
+			return false
+		}
+	}
+
+	return true
+}
+
+func importPost(t *testing.T, c *http.Client, url, token, ct string, body []byte) (int, *resources.ImportReplyResource) {
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		t.Errorf("Error in creating POST request for %s: %v", url, err)
+		return 0, nil
+	}
+	req.Header.Add("Content-Type", ct)
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in POST to %s: %v", url, err)
+		return 0, nil
+	}
+	defer res.Body.Close()
+
+	report := resources.ImportReplyResource{}
+	err = json.NewDecoder(res.Body).Decode(&report)
+	if err != nil {
+		t.Errorf("Expected ImportReplyResource json: %s", err.Error())
+		return res.StatusCode, nil
+	}
+
+	return res.StatusCode, &report
+}
+
+func releasesRead(t *testing.T, s *httptest.Server, c *http.Client, token string) *resources.ReleasesReplyResource {
+	req, err := http.NewRequest("GET", s.URL + "/api/release", nil)
+	if err != nil {
+		t.Errorf("Error in GET request for Release: %v", err)
+		return nil
+	}
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in GET on Release: %v", err)
+		return nil
+	}
+	defer res.Body.Close()
+
+	// check result(s)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return nil
+	}
+
+	dataResource := resources.ReleasesReplyResource{}
+	err = json.NewDecoder(res.Body).Decode(&dataResource)
+	if err != nil {
+		t.Errorf("Expected ReleasesReplyResource json: %s", err.Error())
+		return nil
+	}
+
+	return &dataResource
+}
+
+// the registry keeps the releases of the deleted accounts, the last one with accounts is the latest
+func releaseLatest(releases *resources.ReleasesReplyResource) models.Release {
+	for i := len(releases.Data) - 1; i > 0; i-- {
+		if releases.Data[i].Accounts > 0 {
+			return releases.Data[i]
+		}
+	}
+
+	return releases.Data[0]
+}
+
+func releaseViolationsRead(t *testing.T, c *http.Client, method, url, token string) (int, *resources.ReleaseViolationsReplyResource) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Errorf("Error in creating %s request for %s: %v", method, url, err)
+		return 0, nil
+	}
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in %s for %s: %v", method, url, err)
+		return 0, nil
+	}
+	defer res.Body.Close()
+
+	// only rejected request has the violations
+	if res.StatusCode != http.StatusUnprocessableEntity {
+		return res.StatusCode, nil
+	}
+
+	violations := resources.ReleaseViolationsReplyResource{}
+	err = json.NewDecoder(res.Body).Decode(&violations)
+	if err != nil {
+		t.Errorf("Expected ReleaseViolationsReplyResource json: %s", err.Error())
+		return res.StatusCode, nil
+	}
+
+	return res.StatusCode, &violations
+}
+
+func releaseCheckRead(t *testing.T, c *http.Client, method, url, token string) *resources.ReleaseCheckReplyResource {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		t.Errorf("Error in creating %s request for %s: %v", method, url, err)
+		return nil
+	}
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in %s for %s: %v", method, url, err)
+		return nil
+	}
+	defer res.Body.Close()
+
+	// check result(s)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return nil
+	}
+
+	report := resources.ReleaseCheckReplyResource{}
+	err = json.NewDecoder(res.Body).Decode(&report)
+	if err != nil {
+		t.Errorf("Expected ReleaseCheckReplyResource json: %s", err.Error())
+		return nil
+	}
+
+	return &report
+}
+
+func approvalPost(t *testing.T, c *http.Client, url, token string, body []byte) (int, *resources.ApprovalReplyResource) {
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		t.Errorf("Error in creating POST request for %s: %v", url, err)
+		return 0, nil
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in POST to %s: %v", url, err)
+		return 0, nil
+	}
+	defer res.Body.Close()
+
+	// only successful request has the approval
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		return res.StatusCode, nil
+	}
+
+	approval := resources.ApprovalReplyResource{}
+	err = json.NewDecoder(res.Body).Decode(&approval)
+	if err != nil {
+		t.Errorf("Expected ApprovalReplyResource json: %s", err.Error())
+		return res.StatusCode, nil
+	}
+
+	return res.StatusCode, &approval
+}
+
+func approvalsRead(t *testing.T, c *http.Client, url, token string) *resources.ApprovalsReplyResource {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Errorf("Error in GET request for %s: %v", url, err)
+		return nil
+	}
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in GET on %s: %v", url, err)
+		return nil
+	}
+	defer res.Body.Close()
+
+	// check result(s)
+	if res.StatusCode != http.StatusOK {
+		t.Errorf("Expected response status %d, received %d", http.StatusOK, res.StatusCode)
+		return nil
+	}
+
+	dataResource := resources.ApprovalsReplyResource{}
+	err = json.NewDecoder(res.Body).Decode(&dataResource)
+	if err != nil {
+		t.Errorf("Expected ApprovalsReplyResource json: %s", err.Error())
+		return nil
+	}
+
+	return &dataResource
+}
+
+func doRequestDecode(t *testing.T, c *http.Client, method, url, token string, body []byte, reply interface{}) int {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	if err != nil {
+		t.Errorf("Error in creating %s request for %s: %v", method, url, err)
+		return 0
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in %s for %s: %v", method, url, err)
+		return 0
+	}
+	defer res.Body.Close()
+
+	// only successful request has the reply
+	if res.StatusCode != http.StatusOK {
+		return res.StatusCode
+	}
+
+	if err = json.NewDecoder(res.Body).Decode(reply); err != nil {
+		t.Errorf("Expected %T json: %s", reply, err.Error())
+	}
+
+	return res.StatusCode
+}
+
+func doRequestError(t *testing.T, c *http.Client, method, url, token string, body []byte) (int, *common.AppError) {
+	req, err := http.NewRequest(method, url, bytes.NewBuffer(body))
+	if err != nil {
+		t.Errorf("Error in creating %s request for %s: %v", method, url, err)
+		return 0, nil
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Authorization", token)
+
+	// send test case to server
+	res, err := c.Do(req)
+	if err != nil {
+		t.Errorf("Error in %s for %s: %v", method, url, err)
+		return 0, nil
+	}
+	defer res.Body.Close()
+
+	// only failed request has the error
+	if res.StatusCode < http.StatusBadRequest {
+		return res.StatusCode, nil
+	}
+
+	dataResource := common.ErrorResource{}
+	if err = json.NewDecoder(res.Body).Decode(&dataResource); err != nil {
+		t.Errorf("Expected ErrorResource json: %s", err.Error())
+		return res.StatusCode, nil
+	}
+
+	return res.StatusCode, &dataResource.Data
+}
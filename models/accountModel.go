@@ -25,6 +25,8 @@ type (
 		ReleaseDateStr   string    `json:"releaseDate,omitempty" db:"-"`
 		ReleaseOwner     string    `json:"-" db:"RELEASE_OWNER,size:16"`
 		RecVersion       int       `json:"recVersion" db:"REC_VERSION"`
+		RejectReason     string    `json:"rejectReason,omitempty" db:"REJECT_REASON,size:256"`
+		RejectOwner      string    `json:"rejectOwner,omitempty" db:"REJECT_OWNER,size:16"`
 	}
 
 	Accounts struct {
@@ -32,6 +34,7 @@ type (
 	}
 
 	AccountLog struct {
+		Id     string    `json:"id" db:"-"`
 		OpCode string    `json:"opcode" db:"OPCODE, size:1"`
 		OpDate time.Time `json:"opdate" db:"OPDATE"`
 		Account
@@ -1,9 +1,82 @@
 package models
 
+import (
+	"log"
+
+	"github.com/tealeg/xlsx"
+)
+
+var accountExcelColumns = []excelColumn{
+	{"Status", 8},
+	{"Release Id", 10},
+	{"BSCS Account", 16},
+	{"OFI SAP Account", 18},
+	{"Valid From Date", 16},
+	{"VAT Code Ind", 12},
+	{"OFI SAP WBS Code", 20},
+	{"CIT Marker VAT Flag", 18},
+	{"Entry Date", 20},
+	{"Entry Owner", 14},
+	{"Update Date", 20},
+	{"Update Owner", 14},
+	{"Release Date", 20},
+	{"Release Owner", 14},
+	{"Rec Version", 12},
+}
+
+func (a *Account) ToExcelRow(row *xlsx.Row) {
+	row.AddCell().SetString(a.Status)
+	row.AddCell().SetString(a.ReleaseId)
+	row.AddCell().SetString(a.BscsAccount)
+	row.AddCell().SetString(a.OfiSapAccount)
+	excelDateCell(row, a.ValidFromDate)
+	row.AddCell().SetString(a.VatCodeInd)
+	row.AddCell().SetString(a.OfiSapWbsCode)
+	row.AddCell().SetInt(a.CitMarkerVatFlag)
+	excelDateTimeCell(row, a.EntryDate)
+	row.AddCell().SetString(a.EntryOwner)
+	excelDateTimeCell(row, a.UpdateDate)
+	row.AddCell().SetString(a.UpdateOwner)
+	excelDateTimeCell(row, a.ReleaseDate)
+	row.AddCell().SetString(a.ReleaseOwner)
+	row.AddCell().SetInt(a.RecVersion)
+}
+
 func (a *Account) ToExcel() (rv []byte, err error) {
-	return
+	as := Accounts{Data: []Account{*a}}
+	return as.ToExcel()
 }
 
-func (as *Accounts) ToExcel() (rv []byte, err error) {
+//
+// Workbook with one sheet per status and the metadata sheet
+//
+func (accounts *Accounts) ToExcel() (rv []byte, err error) {
+	xf := xlsx.NewFile()
+	releases := map[string]bool{}
+	counts := map[string]int{}
+
+	for _, s := range excelStatusSheets {
+		sheet, err := excelSheet(xf, s.Name, accountExcelColumns)
+		if err != nil {
+			return nil, err
+		}
+		for i := range accounts.Data {
+			if a := &accounts.Data[i]; a.Status == s.Status {
+				a.ToExcelRow(sheet.AddRow())
+				releases[a.ReleaseId] = true
+				counts[s.Status]++
+			}
+		}
+	}
+
+	if err = excelMetaSheet(xf, releases, counts); err != nil {
+		return
+	}
+
+	if rv, err = excelBytes(xf); err != nil {
+		return
+	}
+	log.Printf("Produced Excel records: %d len: %d", len(accounts.Data), len(rv))
+
 	return
 }
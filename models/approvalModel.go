@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// States of the release request and the steps done on it
+const (
+	ApprovalRequested = "R"
+	ApprovalApproved  = "A"
+	ApprovalRejected  = "J"
+)
+
+type (
+	Approval struct {
+		ApprovalId     int64          `json:"approvalId" db:"APPROVAL_ID,primarykey"`
+		FromStatus     string         `json:"fromStatus" db:"FROM_STATUS,size:1"`
+		IntoStatus     string         `json:"intoStatus" db:"INTO_STATUS,size:1"`
+		ReleaseId      string         `json:"releaseId" db:"RELEASE_ID,size:8"`
+		State          string         `json:"state" db:"STATE,size:1"`
+		RequestDate    time.Time      `json:"-" db:"REQUEST_DATE"`
+		RequestDateStr string         `json:"requestDate,omitempty" db:"-"`
+		RequestOwner   string         `json:"requestOwner" db:"REQUEST_OWNER,size:16"`
+		RequestRole    string         `json:"requestRole" db:"REQUEST_ROLE,size:16"`
+		Comment        string         `json:"comment,omitempty" db:"REQUEST_COMMENT,size:256"`
+		RecVersion     int            `json:"recVersion" db:"REC_VERSION"`
+		Steps          []ApprovalStep `json:"steps,omitempty" db:"-"`
+	}
+
+	ApprovalStep struct {
+		ApprovalId  int64     `json:"-" db:"APPROVAL_ID"`
+		Step        string    `json:"step" db:"STEP,size:1"`
+		StepDate    time.Time `json:"-" db:"STEP_DATE"`
+		StepDateStr string    `json:"date" db:"-"`
+		StepOwner   string    `json:"owner" db:"STEP_OWNER,size:16"`
+		StepRole    string    `json:"role" db:"STEP_ROLE,size:16"`
+		Comment     string    `json:"comment,omitempty" db:"STEP_COMMENT,size:256"`
+	}
+)
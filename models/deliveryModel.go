@@ -0,0 +1,146 @@
+package models
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// States of the delivery of the release file to SAP
+const (
+	DeliveryPending   = "Pending"
+	DeliverySent      = "Sent"
+	DeliveryFailed    = "Failed"
+	DeliveryDelivered = "Delivered"
+	DeliveryRejected  = "Rejected"
+)
+
+// Codes of the acknowledgement from SAP
+const (
+	deliveryAckAccepted = "A"
+	deliveryAckRejected = "R"
+)
+
+type (
+	// Last delivery of the interface file of the release with the acknowledgement
+	Delivery struct {
+		ReleaseId   string    `json:"releaseId" db:"RELEASE_ID,size:8,primarykey"`
+		Format      string    `json:"format" db:"FORMAT,size:8"`
+		Target      string    `json:"target" db:"TARGET,size:256"`
+		FileName    string    `json:"fileName" db:"FILE_NAME,size:64"`
+		Checksum    string    `json:"checksum" db:"CHECKSUM,size:8"`
+		Status      string    `json:"status" db:"STATUS,size:16"`
+		Attempts    int       `json:"attempts" db:"ATTEMPTS"`
+		Message     string    `json:"message,omitempty" db:"MESSAGE,size:256"`
+		SentDate    time.Time `json:"-" db:"SENT_DATE"`
+		SentDateStr string    `json:"sentDate,omitempty" db:"-"`
+		AckDate     time.Time `json:"-" db:"ACK_DATE"`
+		AckDateStr  string    `json:"ackDate,omitempty" db:"-"`
+		AckOwner    string    `json:"ackOwner,omitempty" db:"ACK_OWNER,size:16"`
+		RecVersion  int       `json:"recVersion" db:"REC_VERSION"`
+	}
+
+	// Line of the acknowledgement file and the result of its ingestion
+	DeliveryAck struct {
+		AckFile   string `json:"ackFile"`
+		Line      int    `json:"line"`
+		FileName  string `json:"fileName"`
+		ReleaseId string `json:"releaseId,omitempty"`
+		Code      string `json:"code"`
+		Checksum  string `json:"checksum,omitempty"`
+		Message   string `json:"message,omitempty"`
+		Status    string `json:"status,omitempty"`
+		Reason    string `json:"reason,omitempty"`
+	}
+)
+
+// Name of the interface file of the release with the extension of the format
+func DeliveryFileName(release, ext string) string {
+	return fmt.Sprintf("SAMAPI_%s.%s", release, ext)
+}
+
+// Release of the interface file name, empty if it is not one
+func deliveryFileRelease(name string) string {
+	name = strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if !strings.HasPrefix(name, "SAMAPI_") {
+		return ""
+	}
+
+	return strings.TrimPrefix(name, "SAMAPI_")
+}
+
+//
+// Parse the acknowledgement file from SAP, each line being the name of the
+// interface file, code A accepted or R rejected, the checksum of its trailer
+// and the message separated by semicolon, the empty lines and the ones
+// starting with # are skipped, the invalid lines have the reason set
+//
+func ParseDeliveryAcks(ackFile string, r io.Reader) (acks []DeliveryAck, err error) {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, ";", 4)
+		for len(fields) < 4 {
+			fields = append(fields, "")
+		}
+		ack := DeliveryAck{
+			AckFile:  ackFile,
+			Line:     n,
+			FileName: strings.TrimSpace(fields[0]),
+			Code:     strings.ToUpper(strings.TrimSpace(fields[1])),
+			Checksum: strings.ToUpper(strings.TrimSpace(fields[2])),
+			Message:  strings.TrimSpace(fields[3]),
+		}
+		ack.ReleaseId = deliveryFileRelease(ack.FileName)
+
+		if ack.ReleaseId == "" {
+			ack.Reason = "Invalid interface file name: " + ack.FileName
+		} else if ack.Code != deliveryAckAccepted && ack.Code != deliveryAckRejected {
+			ack.Reason = "Invalid acknowledgement code: " + ack.Code
+		}
+		acks = append(acks, ack)
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("Can't read acknowledgement file %s: %s", ackFile, err.Error())
+	}
+
+	return
+}
+
+//
+// Apply the acknowledgement to the delivery of its file, it must be sent
+// already and the checksum if given must match the one delivered
+//
+func (d *Delivery) Acknowledge(ack *DeliveryAck, owner string) bool {
+	switch {
+	case ack.Reason != "":
+		return false
+	case d.FileName != ack.FileName:
+		ack.Reason = "The file is not the last delivery of release: " + d.ReleaseId
+	case d.Status != DeliverySent && d.Status != DeliveryDelivered && d.Status != DeliveryRejected:
+		ack.Reason = "The file is not sent, delivery status: " + d.Status
+	case ack.Checksum != "" && ack.Checksum != d.Checksum:
+		ack.Reason = fmt.Sprintf("Checksum %s does not match the delivered one: %s", ack.Checksum, d.Checksum)
+	}
+	if ack.Reason != "" {
+		return false
+	}
+
+	d.Status = DeliveryDelivered
+	if ack.Code == deliveryAckRejected {
+		d.Status = DeliveryRejected
+	}
+	d.Message = ack.Message
+	d.AckDate, d.AckOwner = time.Now(), owner
+	ack.Status = d.Status
+
+	return true
+}
@@ -1,9 +1,16 @@
 package models
 
 import (
+	"sort"
 	"time"
 )
 
+// Status of the SAP account, the accounts no more in the SAP master are inactive
+const (
+	DictionaryAccountSapActive   = "ACTIVE"
+	DictionaryAccountSapInactive = "INACTIVE"
+)
+
 type (
 	DictionaryAccountSap struct {
 		Account       string    `json:"sapOfiAccount" db:"SAP_OFI_ACCOUNT,size:32,primarykey"`
@@ -17,4 +24,71 @@ type (
 		UpdateOwner   string    `json:"-" db:"UPDATE_OWNER,size:16"`
 		RecVersion    int       `json:"recVersion" db:"REC_VERSION"`		
 	}
+
+	// Account inserted, renamed, reactivated or deactivated by the import
+	DictionaryAccountSapChange struct {
+		Account   string `json:"sapOfiAccount"`
+		Name      string `json:"name"`
+		OldName   string `json:"oldName,omitempty"`
+		OldStatus string `json:"oldStatus,omitempty"`
+	}
+
+	// Difference of the imported SAP master and the dictionary
+	DictionaryAccountSapMerge struct {
+		Inserted  []DictionaryAccountSapChange `json:"inserted"`
+		Updated   []DictionaryAccountSapChange `json:"updated"`
+		Removed   []DictionaryAccountSapChange `json:"removed"`
+		Unchanged int64                        `json:"unchanged"`
+	}
 )
+
+// Accounts not deactivated, also the ones without status loaded before it was kept
+func (d *DictionaryAccountSap) IsActive() bool {
+	return d.Status != DictionaryAccountSapInactive
+}
+
+//
+// Difference of the imported accounts with their names and the dictionary:
+// the new accounts are inserted, the renamed or inactive ones are updated
+// and the ones missing in the import are removed, that is deactivated
+//
+func MergeDictionaryAccountSap(current []DictionaryAccountSap, imported map[string]string) *DictionaryAccountSapMerge {
+	m := &DictionaryAccountSapMerge{
+		Inserted: []DictionaryAccountSapChange{},
+		Updated:  []DictionaryAccountSapChange{},
+		Removed:  []DictionaryAccountSapChange{},
+	}
+
+	existing := make(map[string]DictionaryAccountSap)
+	for _, d := range current {
+		existing[d.Account] = d
+		if _, found := imported[d.Account]; found {
+			continue
+		}
+		if d.IsActive() {
+			m.Removed = append(m.Removed, DictionaryAccountSapChange{Account: d.Account, Name: d.Name, OldStatus: d.Status})
+		} else {
+			m.Unchanged++
+		}
+	}
+
+	for account, name := range imported {
+		d, found := existing[account]
+		switch {
+		case !found:
+			m.Inserted = append(m.Inserted, DictionaryAccountSapChange{Account: account, Name: name})
+		case d.Name != name || d.Status != DictionaryAccountSapActive:
+			m.Updated = append(m.Updated, DictionaryAccountSapChange{Account: account, Name: name, OldName: d.Name, OldStatus: d.Status})
+		default:
+			m.Unchanged++
+		}
+	}
+
+	for _, changes := range [][]DictionaryAccountSapChange{m.Inserted, m.Updated, m.Removed} {
+		sort.Slice(changes, func(i, j int) bool {
+			return changes[i].Account < changes[j].Account
+		})
+	}
+
+	return m
+}
@@ -0,0 +1,217 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+type (
+	// Operation on the entry with the attributes changed by it
+	HistoryEntry struct {
+		Entity      string              `json:"entity"`
+		OpCode      string              `json:"opcode"`
+		OpDate      time.Time           `json:"-"`
+		OpDateStr   string              `json:"opdate"`
+		User        string              `json:"user,omitempty"`
+		Status      string              `json:"status"`
+		ReleaseId   string              `json:"releaseId"`
+		BscsAccount string              `json:"bscsAccount"`
+		SegmentCode string              `json:"segmentCode,omitempty"`
+		Changes     []ReleaseDiffChange `json:"changes"`
+	}
+
+	History struct {
+		Data []HistoryEntry
+	}
+
+	// Conditions of the entries listed, empty ones are not checked
+	HistoryFilter struct {
+		From        time.Time
+		To          time.Time
+		User        string
+		OpCode      string
+		ReleaseId   string
+		SegmentCode string
+	}
+)
+
+// Attributes of the account followed in the history
+var accountHistoryAttributes = []string{"status", "releaseId", "ofiSapAccount", "ofiSapWbsCode", "vatCodeInd", "validFromDate", "citMarkerVatFlag", "rejectReason"}
+
+func (a *Account) historyValues() []string {
+	return []string{a.Status, a.ReleaseId, a.OfiSapAccount, a.OfiSapWbsCode, a.VatCodeInd, a.ValidFromDateStr, strconv.Itoa(a.CitMarkerVatFlag), a.RejectReason}
+}
+
+// Attributes of the order followed in the history
+var orderHistoryAttributes = []string{"status", "releaseId", "orderNumber", "validFromDate", "rejectReason"}
+
+func (o *Order) historyValues() []string {
+	return []string{o.Status, o.ReleaseId, o.OrderNumber, o.ValidFromDateStr, o.RejectReason}
+}
+
+// Snapshot of the row written by the trigger
+type historySnapshot struct {
+	entry  HistoryEntry
+	values []string
+}
+
+// Row of the table the snapshot was taken from
+func (s *historySnapshot) key() string {
+	return s.entity() + strconv.Quote(s.entry.Status) + strconv.Quote(s.entry.ReleaseId)
+}
+
+// Business entry the rows belong to
+func (s *historySnapshot) entity() string {
+	return strconv.Quote(s.entry.BscsAccount) + strconv.Quote(s.entry.SegmentCode)
+}
+
+// The user of the latest change of the row, the deletion is not signed
+func historyUser(opcode, entryOwner, updateOwner, releaseOwner string, updateDate, releaseDate time.Time) string {
+	switch {
+	case opcode == "D":
+		return ""
+	case opcode == OpRestore:
+		return updateOwner
+	case opcode == "I" || (updateOwner == "" && releaseOwner == ""):
+		return entryOwner
+	case releaseOwner != "" && !releaseDate.Before(updateDate):
+		return releaseOwner
+	}
+
+	return updateOwner
+}
+
+// Statuses the row may be moved from into the status by release, reject or revoke
+var historyPredecessors = map[string][]string{
+	"C": {"W"},
+	"P": {"C"},
+	"W": {"C", "P"},
+}
+
+// Row the update was done on, the key of the row is changed by the status transitions,
+// of the rows in production the one of the latest release is revoked
+func predecessor(live map[string]historySnapshot, s *historySnapshot) (key string, found bool) {
+	if _, found = live[s.key()]; found || s.entry.OpCode != "U" {
+		return s.key(), found
+	}
+
+	for _, status := range historyPredecessors[s.entry.Status] {
+		release := -1
+		for k, l := range live {
+			if l.entity() != s.entity() || l.entry.Status != status {
+				continue
+			}
+			if n, _ := strconv.Atoi(l.entry.ReleaseId); n > release {
+				key, found, release = k, true, n
+			}
+		}
+		if found {
+			return
+		}
+	}
+
+	return
+}
+
+// Changes of each snapshot against the previous state of its row in the order of operations
+func buildHistory(attributes []string, snapshots []historySnapshot) (entries []HistoryEntry) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].entry.OpDate.Before(snapshots[j].entry.OpDate)
+	})
+
+	entries = []HistoryEntry{}
+	live := make(map[string]historySnapshot)
+	for _, s := range snapshots {
+		var previous []string
+		k, found := predecessor(live, &s)
+		if found {
+			previous = live[k].values
+			delete(live, k)
+		}
+
+		e := s.entry
+		switch e.OpCode {
+		case "D":
+			e.Changes = diffChanges(attributes, s.values, nil)
+		default:
+			e.Changes = diffChanges(attributes, previous, s.values)
+			live[s.key()] = s
+		}
+		entries = append(entries, e)
+	}
+
+	return
+}
+
+//
+// History of accounts from the snapshots of SAP_ACCOUNTS_LOG
+//
+func AccountHistory(logs []AccountLog) []HistoryEntry {
+	snapshots := make([]historySnapshot, len(logs))
+	for i := range logs {
+		l := &logs[i]
+		snapshots[i] = historySnapshot{
+			entry: HistoryEntry{
+				Entity:      "account",
+				OpCode:      l.OpCode,
+				OpDate:      l.OpDate,
+				OpDateStr:   l.OpDate.Format(time.RFC3339),
+				User:        historyUser(l.OpCode, l.EntryOwner, l.UpdateOwner, l.ReleaseOwner, l.UpdateDate, l.ReleaseDate),
+				Status:      l.Status,
+				ReleaseId:   l.ReleaseId,
+				BscsAccount: l.BscsAccount,
+			},
+			values: l.historyValues(),
+		}
+	}
+
+	return buildHistory(accountHistoryAttributes, snapshots)
+}
+
+//
+// History of orders from the snapshots of SAP_ACC_SEGM_ORDER_NUMBERS_LOG
+//
+func OrderHistory(logs []OrderLog) []HistoryEntry {
+	snapshots := make([]historySnapshot, len(logs))
+	for i := range logs {
+		l := &logs[i]
+		snapshots[i] = historySnapshot{
+			entry: HistoryEntry{
+				Entity:      "order",
+				OpCode:      l.OpCode,
+				OpDate:      l.OpDate,
+				OpDateStr:   l.OpDate.Format(time.RFC3339),
+				User:        historyUser(l.OpCode, l.EntryOwner, l.UpdateOwner, l.ReleaseOwner, l.UpdateDate, l.ReleaseDate),
+				Status:      l.Status,
+				ReleaseId:   l.ReleaseId,
+				BscsAccount: l.BscsAccount,
+				SegmentCode: l.SegmentCode,
+			},
+			values: l.historyValues(),
+		}
+	}
+
+	return buildHistory(orderHistoryAttributes, snapshots)
+}
+
+//
+// Entries of the history matching all conditions of the filter,
+// the dates are from the beginning of the day till its end
+//
+func (f *HistoryFilter) Apply(entries []HistoryEntry) []HistoryEntry {
+	filtered := []HistoryEntry{}
+	for _, e := range entries {
+		if (!f.From.IsZero() && e.OpDate.Before(f.From)) ||
+			(!f.To.IsZero() && !e.OpDate.Before(f.To.AddDate(0, 0, 1))) ||
+			(f.User != "" && e.User != f.User) ||
+			(f.OpCode != "" && e.OpCode != f.OpCode) ||
+			(f.ReleaseId != "" && e.ReleaseId != f.ReleaseId) ||
+			(f.SegmentCode != "" && e.SegmentCode != f.SegmentCode) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+
+	return filtered
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+)
+
+var historyCsvHeader = []string{"opdate", "opcode", "user", "entity", "bscsAccount", "segmentCode", "status", "releaseId", "attribute", "from", "to"}
+
+// One record per changed attribute, the entry without changes has one record
+func (e *HistoryEntry) toCsvRecords() (records [][]string) {
+	changes := e.Changes
+	if len(changes) == 0 {
+		changes = []ReleaseDiffChange{{}}
+	}
+	for _, c := range changes {
+		records = append(records, []string{e.OpDateStr, e.OpCode, e.User, e.Entity, e.BscsAccount, e.SegmentCode, e.Status, e.ReleaseId, c.Attribute, c.From, c.To})
+	}
+
+	return
+}
+
+func (h *History) ToCsv() (rv []byte, err error) {
+	records := [][]string{historyCsvHeader}
+	for i := range h.Data {
+		records = append(records, h.Data[i].toCsvRecords()...)
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err = w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("Can't write CSV history: %s", err.Error())
+	}
+	rv = buf.Bytes()
+	log.Printf("Produced CSV history entries: %d len: %d", len(h.Data), len(rv))
+
+	return
+}
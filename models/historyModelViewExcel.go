@@ -0,0 +1,50 @@
+package models
+
+import (
+	"log"
+
+	"github.com/tealeg/xlsx"
+)
+
+var historyExcelColumns = []excelColumn{
+	{"Date", 20},
+	{"Operation", 10},
+	{"User", 16},
+	{"Entity", 10},
+	{"BSCS Account", 16},
+	{"Segment Code", 14},
+	{"Status", 8},
+	{"Release", 8},
+	{"Attribute", 16},
+	{"From", 20},
+	{"To", 20},
+}
+
+//
+// Workbook with one row per changed attribute of the entries
+//
+func (h *History) ToExcel() (rv []byte, err error) {
+	xf := xlsx.NewFile()
+	sheet, err := excelSheet(xf, "History", historyExcelColumns)
+	if err != nil {
+		return
+	}
+
+	for i := range h.Data {
+		e := &h.Data[i]
+		for _, r := range e.toCsvRecords() {
+			row := sheet.AddRow()
+			excelDateTimeCell(row, e.OpDate)
+			for _, v := range r[1:] {
+				row.AddCell().SetString(v)
+			}
+		}
+	}
+
+	if rv, err = excelBytes(xf); err != nil {
+		return
+	}
+	log.Printf("Produced Excel history entries: %d len: %d", len(h.Data), len(rv))
+
+	return
+}
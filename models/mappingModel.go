@@ -0,0 +1,111 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+)
+
+type (
+	// Account and order in production effective for the BSCS account and segment on the date
+	MappingEntry struct {
+		BscsAccount          string `json:"bscsAccount"`
+		SegmentCode          string `json:"segmentCode,omitempty"`
+		OfiSapAccount        string `json:"ofiSapAccount,omitempty"`
+		OfiSapWbsCode        string `json:"ofiSapWbsCode,omitempty"`
+		VatCodeInd           string `json:"vatCodeInd,omitempty"`
+		CitMarkerVatFlag     int    `json:"citMarkerVatFlag"`
+		OrderNumber          string `json:"orderNumber,omitempty"`
+		AccountReleaseId     string `json:"accountReleaseId,omitempty"`
+		AccountValidFromDate string `json:"accountValidFromDate,omitempty"`
+		OrderReleaseId       string `json:"orderReleaseId,omitempty"`
+		OrderValidFromDate   string `json:"orderValidFromDate,omitempty"`
+	}
+
+	Mapping struct {
+		AsOf string
+		Data []MappingEntry
+	}
+)
+
+// The entry of the later valid date or of the later release on the same date takes effect
+func effectiveLater(validFrom, release, currentValidFrom, currentRelease string) bool {
+	if validFrom != currentValidFrom {
+		return validFrom > currentValidFrom
+	}
+	n, _ := strconv.Atoi(release)
+	m, _ := strconv.Atoi(currentRelease)
+
+	return n > m
+}
+
+//
+// Mapping effective on the date from the accounts and orders in production
+// of all releases valid on it, the account without the orders has one entry
+// without segment unless the segment is selected, then its order is empty
+//
+func EffectiveMapping(accounts []Account, orders []Order, segment string) []MappingEntry {
+	effectiveAccounts := make(map[string]*Account)
+	for i := range accounts {
+		a := &accounts[i]
+		if e, found := effectiveAccounts[a.BscsAccount]; !found || effectiveLater(a.ValidFromDateStr, a.ReleaseId, e.ValidFromDateStr, e.ReleaseId) {
+			effectiveAccounts[a.BscsAccount] = a
+		}
+	}
+
+	effectiveOrders := make(map[string]map[string]*Order)
+	for i := range orders {
+		o := &orders[i]
+		if effectiveOrders[o.BscsAccount] == nil {
+			effectiveOrders[o.BscsAccount] = make(map[string]*Order)
+		}
+		if e, found := effectiveOrders[o.BscsAccount][o.SegmentCode]; !found || effectiveLater(o.ValidFromDateStr, o.ReleaseId, e.ValidFromDateStr, e.ReleaseId) {
+			effectiveOrders[o.BscsAccount][o.SegmentCode] = o
+		}
+	}
+
+	keys := []string{}
+	for k := range effectiveAccounts {
+		keys = append(keys, k)
+	}
+	for k := range effectiveOrders {
+		if _, found := effectiveAccounts[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	entries := []MappingEntry{}
+	for _, k := range keys {
+		entry := MappingEntry{BscsAccount: k}
+		if a := effectiveAccounts[k]; a != nil {
+			entry.OfiSapAccount = a.OfiSapAccount
+			entry.OfiSapWbsCode = a.OfiSapWbsCode
+			entry.VatCodeInd = a.VatCodeInd
+			entry.CitMarkerVatFlag = a.CitMarkerVatFlag
+			entry.AccountReleaseId = a.ReleaseId
+			entry.AccountValidFromDate = a.ValidFromDateStr
+		}
+
+		segments := []string{}
+		for s := range effectiveOrders[k] {
+			segments = append(segments, s)
+		}
+		sort.Strings(segments)
+		if len(segments) == 0 {
+			entry.SegmentCode = segment
+			entries = append(entries, entry)
+			continue
+		}
+
+		for _, s := range segments {
+			o := effectiveOrders[k][s]
+			entry.SegmentCode = s
+			entry.OrderNumber = o.OrderNumber
+			entry.OrderReleaseId = o.ReleaseId
+			entry.OrderValidFromDate = o.ValidFromDateStr
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,44 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+var mappingCsvHeader = []string{"bscsAccount", "segmentCode", "ofiSapAccount", "ofiSapWbsCode", "vatCodeInd", "citMarkerVatFlag", "orderNumber", "accountReleaseId", "accountValidFromDate", "orderReleaseId", "orderValidFromDate"}
+
+func (e *MappingEntry) ToCsvRecord() []string {
+	return []string{
+		e.BscsAccount,
+		e.SegmentCode,
+		e.OfiSapAccount,
+		e.OfiSapWbsCode,
+		e.VatCodeInd,
+		strconv.Itoa(e.CitMarkerVatFlag),
+		e.OrderNumber,
+		e.AccountReleaseId,
+		e.AccountValidFromDate,
+		e.OrderReleaseId,
+		e.OrderValidFromDate,
+	}
+}
+
+func (m *Mapping) ToCsv() (rv []byte, err error) {
+	records := [][]string{mappingCsvHeader}
+	for i := range m.Data {
+		records = append(records, m.Data[i].ToCsvRecord())
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err = w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("Can't write CSV mapping: %s", err.Error())
+	}
+	rv = buf.Bytes()
+	log.Printf("Produced CSV mapping as of: %s entries: %d len: %d", m.AsOf, len(m.Data), len(rv))
+
+	return
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+// Column of the exported sheet
+type excelColumn struct {
+	Header string
+	Width  float64
+}
+
+// Sheet names of the statuses, in the order of the workbook
+var excelStatusSheets = []struct {
+	Status string
+	Name   string
+}{
+	{"W", "Work"},
+	{"C", "Control"},
+	{"P", "Production"},
+}
+
+// Sheet with bold header row and the column widths set
+func excelSheet(xf *xlsx.File, name string, columns []excelColumn) (sheet *xlsx.Sheet, err error) {
+	sheet, err = xf.AddSheet(name)
+	if err != nil {
+		return nil, fmt.Errorf("Can't add Excel sheet %s: %s", name, err.Error())
+	}
+
+	style := xlsx.NewStyle()
+	style.Font.Bold = true
+	style.ApplyFont = true
+
+	row := sheet.AddRow()
+	for i, c := range columns {
+		cell := row.AddCell()
+		cell.SetString(c.Header)
+		cell.SetStyle(style)
+		if err = sheet.SetColWidth(i, i, c.Width); err != nil {
+			return nil, err
+		}
+	}
+
+	return
+}
+
+// Date cell, empty if the date is not set
+func excelDateCell(row *xlsx.Row, t time.Time) {
+	cell := row.AddCell()
+	if !t.IsZero() {
+		cell.SetDate(t)
+	}
+}
+
+// Date and time cell, empty if not set
+func excelDateTimeCell(row *xlsx.Row, t time.Time) {
+	cell := row.AddCell()
+	if !t.IsZero() {
+		cell.SetDateTime(t)
+	}
+}
+
+//
+// Metadata sheet with the release ids of the exported records,
+// the record counts per status and the export time
+//
+func excelMetaSheet(xf *xlsx.File, releases map[string]bool, counts map[string]int) (err error) {
+	sheet, err := excelSheet(xf, "Metadata", []excelColumn{{"Attribute", 20}, {"Value", 30}})
+	if err != nil {
+		return
+	}
+
+	ids := []string{}
+	for id := range releases {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	row := sheet.AddRow()
+	row.AddCell().SetString("Release")
+	row.AddCell().SetString(strings.Join(ids, ", "))
+
+	row = sheet.AddRow()
+	row.AddCell().SetString("Export time")
+	excelDateTimeCell(row, time.Now())
+
+	for _, s := range excelStatusSheets {
+		row = sheet.AddRow()
+		row.AddCell().SetString("Records " + s.Name)
+		row.AddCell().SetInt(counts[s.Status])
+	}
+
+	return
+}
+
+// Contents of the workbook
+func excelBytes(xf *xlsx.File) (rv []byte, err error) {
+	var buf bytes.Buffer
+	if err = xf.Write(&buf); err != nil {
+		return nil, fmt.Errorf("Can't write Excel file: %s", err.Error())
+	}
+
+	return buf.Bytes(), nil
+}
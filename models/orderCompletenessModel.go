@@ -0,0 +1,53 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// What is missing for the account and segment
+const (
+	OrderMissing       = "order"
+	OrderNumberMissing = "orderNumber"
+)
+
+type (
+	// Account and segment without the order or without its number
+	OrderGap struct {
+		BscsAccount string `json:"bscsAccount"`
+		SegmentCode string `json:"segmentCode"`
+		Missing     string `json:"missing"`
+	}
+)
+
+//
+// Each account needs the order with the number for every customer segment,
+// the orders without the number are reported even if the account is not given
+//
+func CheckOrderCompleteness(accounts []Account, orders []Order, segments []DictionarySegment) (gaps []OrderGap) {
+	gaps = []OrderGap{}
+
+	ordered := make(map[string]bool)
+	for _, o := range orders {
+		ordered[fmt.Sprintf("%q %q", o.BscsAccount, o.SegmentCode)] = true
+		if o.OrderNumber == "" {
+			gaps = append(gaps, OrderGap{BscsAccount: o.BscsAccount, SegmentCode: o.SegmentCode, Missing: OrderNumberMissing})
+		}
+	}
+
+	for _, a := range accounts {
+		for _, s := range segments {
+			if !ordered[fmt.Sprintf("%q %q", a.BscsAccount, s.CsTradeRef)] {
+				gaps = append(gaps, OrderGap{BscsAccount: a.BscsAccount, SegmentCode: s.CsTradeRef, Missing: OrderMissing})
+			}
+		}
+	}
+
+	sort.Slice(gaps, func(i, j int) bool {
+		a, b := gaps[i], gaps[j]
+		return a.BscsAccount < b.BscsAccount ||
+			(a.BscsAccount == b.BscsAccount && a.SegmentCode < b.SegmentCode)
+	})
+
+	return
+}
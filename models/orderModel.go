@@ -22,7 +22,9 @@ type (
 		ReleaseDate      time.Time `json:"-" db:"RELEASE_DATE"`
 		ReleaseDateStr   string    `json:"releaseDate,omitempty" db:"-"`
 		ReleaseOwner     string    `json:"-" db:"RELEASE_OWNER,size:16"`
-		RecVersion       int       `json:"recVersion" db:"REC_VERSION"`		
+		RecVersion       int       `json:"recVersion" db:"REC_VERSION"`
+		RejectReason     string    `json:"rejectReason,omitempty" db:"REJECT_REASON,size:256"`
+		RejectOwner      string    `json:"rejectOwner,omitempty" db:"REJECT_OWNER,size:16"`
 	}
 
 	Orders struct {
@@ -30,6 +32,7 @@ type (
 	}
 
 	OrderLog struct {
+		Id     string    `json:"id" db:"-"`
 		OpCode string    `json:"opcode" db:"OPCODE, size:1"`
 		OpDate time.Time `json:"opdate" db:"OPDATE"`
 		Order
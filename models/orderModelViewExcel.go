@@ -1,9 +1,78 @@
 package models
 
+import (
+	"log"
+
+	"github.com/tealeg/xlsx"
+)
+
+var orderExcelColumns = []excelColumn{
+	{"Status", 8},
+	{"Release Id", 10},
+	{"BSCS Account", 16},
+	{"Segment Code", 14},
+	{"Order Number", 18},
+	{"Valid From Date", 16},
+	{"Entry Date", 20},
+	{"Entry Owner", 14},
+	{"Update Date", 20},
+	{"Update Owner", 14},
+	{"Release Date", 20},
+	{"Release Owner", 14},
+	{"Rec Version", 12},
+}
+
+func (o *Order) ToExcelRow(row *xlsx.Row) {
+	row.AddCell().SetString(o.Status)
+	row.AddCell().SetString(o.ReleaseId)
+	row.AddCell().SetString(o.BscsAccount)
+	row.AddCell().SetString(o.SegmentCode)
+	row.AddCell().SetString(o.OrderNumber)
+	excelDateCell(row, o.ValidFromDate)
+	excelDateTimeCell(row, o.EntryDate)
+	row.AddCell().SetString(o.EntryOwner)
+	excelDateTimeCell(row, o.UpdateDate)
+	row.AddCell().SetString(o.UpdateOwner)
+	excelDateTimeCell(row, o.ReleaseDate)
+	row.AddCell().SetString(o.ReleaseOwner)
+	row.AddCell().SetInt(o.RecVersion)
+}
+
 func (o *Order) ToExcel() (rv []byte, err error) {
-	return
+	os := Orders{Data: []Order{*o}}
+	return os.ToExcel()
 }
 
-func (os *Orders) ToExcel() (rv []byte, err error) {
+//
+// Workbook with one sheet per status and the metadata sheet
+//
+func (orders *Orders) ToExcel() (rv []byte, err error) {
+	xf := xlsx.NewFile()
+	releases := map[string]bool{}
+	counts := map[string]int{}
+
+	for _, s := range excelStatusSheets {
+		sheet, err := excelSheet(xf, s.Name, orderExcelColumns)
+		if err != nil {
+			return nil, err
+		}
+		for i := range orders.Data {
+			if o := &orders.Data[i]; o.Status == s.Status {
+				o.ToExcelRow(sheet.AddRow())
+				releases[o.ReleaseId] = true
+				counts[s.Status]++
+			}
+		}
+	}
+
+	if err = excelMetaSheet(xf, releases, counts); err != nil {
+		return
+	}
+
+	if rv, err = excelBytes(xf); err != nil {
+		return
+	}
+	log.Printf("Produced Excel records: %d len: %d", len(orders.Data), len(rv))
+
 	return
 }
@@ -0,0 +1,277 @@
+package models
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Date of the posting in the BSCS extract
+const postingDateFormat = "2006-01-02"
+
+// Amount with the decimal places limited by the currency
+var postingAmount = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Decimal places of the currencies by ISO 4217 not having 2 of them
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+	"CLF": 4, "UYW": 4,
+}
+
+// Decimal places of the amounts in the currency, 2 if not listed
+func currencyExponent(currency string) int {
+	if exponent, found := currencyExponents[strings.ToUpper(currency)]; found {
+		return exponent
+	}
+
+	return 2
+}
+
+type (
+	// Line of the BSCS GL posting extract, the amount in the minor units of the currency
+	Posting struct {
+		Line        int
+		BscsAccount string
+		SegmentCode string
+		Amount      int64
+		Currency    string
+		PostingDate string
+		Fields      []string
+	}
+
+	// Posting translated into the SAP OFI values
+	SapPosting struct {
+		Posting Posting
+		Result  ResolveResult
+	}
+
+	// Line of the extract not translated with the reason
+	PostingReject struct {
+		Line   int      `json:"line"`
+		Fields []string `json:"fields"`
+		Reason string   `json:"reason"`
+	}
+
+	// Control total of the SAP account in the currency
+	PostingTotal struct {
+		OfiSapAccount string `json:"ofiSapAccount"`
+		Currency      string `json:"currency"`
+		Count         int64  `json:"count"`
+		Amount        string `json:"amount"`
+		amount        int64
+	}
+
+	PostingTranslation struct {
+		Lines    int64
+		Postings []SapPosting
+		Rejects  []PostingReject
+		Totals   []PostingTotal
+	}
+)
+
+// Columns of the extract by the names in the header, the default order otherwise
+var postingColumns = map[string][]string{
+	"account":     {"bscsaccount", "glaccount", "account"},
+	"segment":     {"segmentcode", "segment"},
+	"amount":      {"amount"},
+	"postingDate": {"postingdate", "date"},
+	"currency":    {"currency"},
+}
+
+// Position of the columns found in the header, nil if the line is not the header
+func postingHeader(record []string) map[string]int {
+	index := make(map[string]int)
+	for i, v := range record {
+		v = strings.ToLower(strings.TrimSpace(v))
+		for column, names := range postingColumns {
+			for _, name := range names {
+				if v == name {
+					index[column] = i
+				}
+			}
+		}
+	}
+	for _, column := range []string{"account", "amount", "postingDate"} {
+		if _, found := index[column]; !found {
+			return nil
+		}
+	}
+
+	return index
+}
+
+// Amount in the minor units of the currency from the decimal text
+func parsePostingAmount(v string, currency string) (amount int64, err error) {
+	exponent := currencyExponent(currency)
+	if !postingAmount.MatchString(v) {
+		return 0, fmt.Errorf("Invalid amount: %s", v)
+	}
+	negative := strings.HasPrefix(v, "-")
+	parts := strings.SplitN(strings.TrimPrefix(v, "-"), ".", 2)
+	if len(parts) == 1 {
+		parts = append(parts, "")
+	}
+	if len(parts[1]) > exponent {
+		return 0, fmt.Errorf("Invalid amount: %s with %d decimal places of %s", v, exponent, currency)
+	}
+	parts[1] += strings.Repeat("0", exponent - len(parts[1]))
+	if amount, err = strconv.ParseInt(parts[0] + parts[1], 10, 64); err != nil {
+		return 0, fmt.Errorf("Invalid amount: %s", v)
+	}
+	if negative {
+		amount = -amount
+	}
+
+	return
+}
+
+// Decimal text of the amount in the minor units of the currency
+func formatPostingAmount(amount int64, currency string) string {
+	exponent := currencyExponent(currency)
+	sign := ""
+	if amount < 0 {
+		sign, amount = "-", -amount
+	}
+	if exponent == 0 {
+		return fmt.Sprintf("%s%d", sign, amount)
+	}
+	unit := int64(1)
+	for i := 0; i < exponent; i++ {
+		unit *= 10
+	}
+
+	return fmt.Sprintf("%s%d.%0*d", sign, amount / unit, exponent, amount % unit)
+}
+
+//
+// Parse the BSCS GL posting extract in CSV with comma or semicolon, the
+// columns are found by the header or taken in the order account, segment,
+// amount, posting date and currency, the lines without the currency get the
+// default one, the invalid lines are rejected
+//
+func ParsePostings(r io.Reader, currency string) (postings []Posting, rejects []PostingReject, err error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("Can't read postings: %s", err.Error())
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff")
+	reader := csv.NewReader(strings.NewReader(text))
+	if first := strings.SplitN(text, "\n", 2)[0]; strings.Count(first, ";") > strings.Count(first, ",") {
+		reader.Comma = ';'
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("Can't parse postings: %s", err.Error())
+	}
+
+	index := map[string]int{"account": 0, "segment": 1, "amount": 2, "postingDate": 3, "currency": 4}
+	start := 0
+	if len(records) > 0 {
+		if header := postingHeader(records[0]); header != nil {
+			index, start = header, 1
+		}
+	}
+
+	field := func(record []string, column string) string {
+		if i, found := index[column]; found && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	for n := start; n < len(records); n++ {
+		record := records[n]
+		p := Posting{
+			Line:        n + 1,
+			BscsAccount: field(record, "account"),
+			SegmentCode: field(record, "segment"),
+			Currency:    field(record, "currency"),
+			PostingDate: field(record, "postingDate"),
+			Fields:      record,
+		}
+		if p.Currency == "" {
+			p.Currency = currency
+		}
+
+		var reason string
+		if p.BscsAccount == "" {
+			reason = "Missing GL account"
+		} else if p.Currency == "" {
+			reason = "Missing currency"
+		} else if amount, e := parsePostingAmount(field(record, "amount"), p.Currency); e != nil {
+			reason = e.Error()
+		} else if _, e := time.Parse(postingDateFormat, p.PostingDate); e != nil {
+			reason = "Invalid posting date: " + p.PostingDate
+		} else {
+			p.Amount = amount
+		}
+
+		if reason != "" {
+			rejects = append(rejects, PostingReject{Line: n + 1, Fields: record, Reason: reason})
+		} else {
+			postings = append(postings, p)
+		}
+	}
+
+	return
+}
+
+//
+// Translate the postings with the mappings in production valid on their
+// dates, the postings not mapped are rejected, the control totals are
+// summed per SAP account and currency
+//
+func TranslatePostings(x *MappingIndex, postings []Posting, rejects []PostingReject) *PostingTranslation {
+	t := &PostingTranslation{
+		Lines:   int64(len(postings) + len(rejects)),
+		Rejects: rejects,
+	}
+
+	totals := make(map[string]*PostingTotal)
+	for _, p := range postings {
+		result := x.Resolve(ResolveLookup{BscsAccount: p.BscsAccount, SegmentCode: p.SegmentCode, PostingDate: p.PostingDate})
+		if result.Status != ResolveMapped {
+			t.Rejects = append(t.Rejects, PostingReject{
+				Line:   p.Line,
+				Fields: p.Fields,
+				Reason: result.Reason,
+			})
+			continue
+		}
+		t.Postings = append(t.Postings, SapPosting{Posting: p, Result: result})
+
+		k := result.OfiSapAccount + "/" + p.Currency
+		if totals[k] == nil {
+			totals[k] = &PostingTotal{OfiSapAccount: result.OfiSapAccount, Currency: p.Currency}
+		}
+		totals[k].Count++
+		totals[k].amount += p.Amount
+	}
+
+	for _, total := range totals {
+		total.Amount = formatPostingAmount(total.amount, total.Currency)
+		t.Totals = append(t.Totals, *total)
+	}
+	sort.Slice(t.Totals, func(i, j int) bool {
+		if t.Totals[i].OfiSapAccount != t.Totals[j].OfiSapAccount {
+			return t.Totals[i].OfiSapAccount < t.Totals[j].OfiSapAccount
+		}
+		return t.Totals[i].Currency < t.Totals[j].Currency
+	})
+	sort.SliceStable(t.Rejects, func(i, j int) bool {
+		return t.Rejects[i].Line < t.Rejects[j].Line
+	})
+
+	return t
+}
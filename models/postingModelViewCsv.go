@@ -0,0 +1,82 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Records of the SAP OFI upload file
+const (
+	postingRecordData  = "D"
+	postingRecordTotal = "T"
+)
+
+var postingRejectCsvHeader = []string{"line", "reason", "fields"}
+
+// Posting record with the date in the SAP format YYYYMMDD
+func (p *SapPosting) toCsvRecord() []string {
+	return []string{
+		postingRecordData,
+		strings.Replace(p.Posting.PostingDate, "-", "", -1),
+		p.Result.OfiSapAccount,
+		p.Result.OfiSapWbsCode,
+		p.Result.OrderNumber,
+		p.Result.VatCodeInd,
+		strconv.Itoa(p.Result.CitMarkerVatFlag),
+		formatPostingAmount(p.Posting.Amount, p.Posting.Currency),
+		p.Posting.Currency,
+		p.Posting.BscsAccount,
+		p.Posting.SegmentCode,
+	}
+}
+
+func (t *PostingTotal) toCsvRecord() []string {
+	return []string{postingRecordTotal, t.OfiSapAccount, t.Currency, strconv.FormatInt(t.Count, 10), t.Amount}
+}
+
+//
+// SAP OFI upload file with semicolon, the posting records followed by
+// the control totals per SAP account and currency
+//
+func (t *PostingTranslation) ToOfiCsv() (rv []byte, err error) {
+	records := [][]string{}
+	for i := range t.Postings {
+		records = append(records, t.Postings[i].toCsvRecord())
+	}
+	for i := range t.Totals {
+		records = append(records, t.Totals[i].toCsvRecord())
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	if err = w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("Can't write CSV postings: %s", err.Error())
+	}
+	rv = buf.Bytes()
+	log.Printf("Produced CSV postings: %d totals: %d len: %d", len(t.Postings), len(t.Totals), len(rv))
+
+	return
+}
+
+// Reject file with the line of the extract, the reason and the fields of the line
+func (t *PostingTranslation) ToRejectCsv() (rv []byte, err error) {
+	records := [][]string{postingRejectCsvHeader}
+	for _, r := range t.Rejects {
+		records = append(records, append([]string{strconv.Itoa(r.Line), r.Reason}, r.Fields...))
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err = w.WriteAll(records); err != nil {
+		return nil, fmt.Errorf("Can't write CSV rejects: %s", err.Error())
+	}
+	rv = buf.Bytes()
+	log.Printf("Produced CSV rejects: %d len: %d", len(t.Rejects), len(rv))
+
+	return
+}
@@ -0,0 +1,15 @@
+package models
+
+type (
+	// Rejection of the entries in status C back to W with the reason
+	Reject struct {
+		Reason  string        `json:"reason"`
+		Entries []RejectEntry `json:"entries,omitempty"`
+	}
+
+	// Key of the rejected entry, the segment code is given for the order only
+	RejectEntry struct {
+		BscsAccount string `json:"bscsAccount"`
+		SegmentCode string `json:"segmentCode,omitempty"`
+	}
+)
@@ -0,0 +1,226 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Rules checked before the release and the revocation
+const (
+	ReleaseRuleInPast       = "validFromDateInPast"
+	ReleaseRuleOverlap      = "validFromDateOverlap"
+	ReleaseRuleWorkNotEmpty = "workNotEmpty"
+)
+
+type (
+	// Violation of the rule by the entry or by the whole release
+	ReleaseViolation struct {
+		Entity        string `json:"entity"`
+		BscsAccount   string `json:"bscsAccount,omitempty"`
+		SegmentCode   string `json:"segmentCode,omitempty"`
+		ValidFromDate string `json:"validFromDate,omitempty"`
+		Rule          string `json:"rule"`
+		Message       string `json:"message"`
+	}
+
+	// Entry of the release missing a value or with the value not found in dictionary
+	ReleaseCheckEntry struct {
+		Entity      string `json:"entity"`
+		BscsAccount string `json:"bscsAccount"`
+		SegmentCode string `json:"segmentCode,omitempty"`
+		Attribute   string `json:"attribute,omitempty"`
+		Value       string `json:"value,omitempty"`
+	}
+
+	// Report of the release done in the transaction being rolled back
+	ReleaseCheck struct {
+		FromStatus           string              `json:"fromStatus"`
+		IntoStatus           string              `json:"intoStatus"`
+		ReleaseId            string              `json:"releaseId"`
+		Accounts             int64               `json:"accounts"`
+		Orders               int64               `json:"orders"`
+		MissingWbsCodes      []ReleaseCheckEntry `json:"missingWbsCodes"`
+		MissingOrderNumbers  []ReleaseCheckEntry `json:"missingOrderNumbers"`
+		MissingOrders        []ReleaseCheckEntry `json:"missingOrders"`
+		UnresolvedReferences []ReleaseCheckEntry `json:"unresolvedReferences"`
+		Violations           []ReleaseViolation  `json:"violations"`
+		Error                string              `json:"error,omitempty"`
+		Ok                   bool                `json:"ok"`
+	}
+)
+
+// Entry being released compared with the one in production by the business key
+type checkRecord struct {
+	violation ReleaseViolation
+	validFrom time.Time
+	release   string
+}
+
+// Released valid dates must be in the future and after the one in production
+func checkSet(entity string, released, production map[string]checkRecord, now time.Time) (violations []ReleaseViolation) {
+	violations = []ReleaseViolation{}
+	for k, r := range released {
+		if r.validFrom.IsZero() {
+			continue
+		}
+
+		if !r.validFrom.After(now) {
+			v := r.violation
+			v.Rule = ReleaseRuleInPast
+			v.Message = fmt.Sprintf("Valid from date %s of %s is not in the future", v.ValidFromDate, entity)
+			violations = append(violations, v)
+		}
+
+		if p, ok := production[k]; ok && !p.validFrom.IsZero() && !r.validFrom.After(p.validFrom) {
+			v := r.violation
+			v.Rule = ReleaseRuleOverlap
+			v.Message = fmt.Sprintf("Valid from date %s of %s overlaps with %s released in release %s",
+				v.ValidFromDate, entity, p.violation.ValidFromDate, p.release)
+			violations = append(violations, v)
+		}
+	}
+
+	sort.Slice(violations, func(i, j int) bool {
+		a, b := violations[i], violations[j]
+		return a.BscsAccount < b.BscsAccount ||
+			(a.BscsAccount == b.BscsAccount && (a.SegmentCode < b.SegmentCode ||
+				(a.SegmentCode == b.SegmentCode && a.Rule < b.Rule)))
+	})
+
+	return
+}
+
+func accountCheckRecords(entity string, accounts []Account) map[string]checkRecord {
+	records := make(map[string]checkRecord)
+	for i := range accounts {
+		a := &accounts[i]
+		records[a.BscsAccount] = checkRecord{
+			violation: ReleaseViolation{
+				Entity:        entity,
+				BscsAccount:   a.BscsAccount,
+				ValidFromDate: a.ValidFromDateStr,
+			},
+			validFrom: a.ValidFromDate,
+			release:   a.ReleaseId,
+		}
+	}
+
+	return records
+}
+
+func orderCheckRecords(entity string, orders []Order) map[string]checkRecord {
+	records := make(map[string]checkRecord)
+	for i := range orders {
+		o := &orders[i]
+		records[fmt.Sprintf("%q %q", o.BscsAccount, o.SegmentCode)] = checkRecord{
+			violation: ReleaseViolation{
+				Entity:        entity,
+				BscsAccount:   o.BscsAccount,
+				SegmentCode:   o.SegmentCode,
+				ValidFromDate: o.ValidFromDateStr,
+			},
+			validFrom: o.ValidFromDate,
+			release:   o.ReleaseId,
+		}
+	}
+
+	return records
+}
+
+//
+// Check the accounts being released against the ones in production per BSCS account
+//
+func CheckReleaseAccounts(released, production []Account, now time.Time) []ReleaseViolation {
+	return checkSet("account",
+		accountCheckRecords("account", released),
+		accountCheckRecords("account", production),
+		now)
+}
+
+//
+// Check the orders being released against the ones in production per BSCS account and segment
+//
+func CheckReleaseOrders(released, production []Order, now time.Time) []ReleaseViolation {
+	return checkSet("order",
+		orderCheckRecords("order", released),
+		orderCheckRecords("order", production),
+		now)
+}
+
+//
+// Check values and dictionary references of the accounts and orders being released,
+// each account needs the order for every customer segment, released or in production
+//
+func (c *ReleaseCheck) CheckEntries(accounts []Account, orders, ordersProduction []Order,
+	segments []DictionarySegment, accountsBscs []DictionaryAccountBscs, accountsSap []DictionaryAccountSap) {
+	c.MissingWbsCodes = []ReleaseCheckEntry{}
+	c.MissingOrderNumbers = []ReleaseCheckEntry{}
+	c.MissingOrders = []ReleaseCheckEntry{}
+	c.UnresolvedReferences = []ReleaseCheckEntry{}
+
+	bscs := make(map[string]bool)
+	for _, d := range accountsBscs {
+		bscs[d.Account] = true
+	}
+	sap := make(map[string]bool)
+	for _, d := range accountsSap {
+		sap[d.Account] = d.IsActive()
+	}
+	codes := []string{}
+	segment := make(map[string]bool)
+	for _, d := range segments {
+		codes = append(codes, d.CsTradeRef)
+		segment[d.CsTradeRef] = true
+	}
+	sort.Strings(codes)
+
+	ordered := make(map[string]bool)
+	for _, os := range [][]Order{orders, ordersProduction} {
+		for _, o := range os {
+			ordered[fmt.Sprintf("%q %q", o.BscsAccount, o.SegmentCode)] = true
+		}
+	}
+
+	for _, a := range accounts {
+		if a.OfiSapWbsCode == "" {
+			c.MissingWbsCodes = append(c.MissingWbsCodes, ReleaseCheckEntry{Entity: "account", BscsAccount: a.BscsAccount})
+		}
+		if !bscs[a.BscsAccount] {
+			c.UnresolvedReferences = append(c.UnresolvedReferences,
+				ReleaseCheckEntry{Entity: "account", BscsAccount: a.BscsAccount, Attribute: "bscsAccount", Value: a.BscsAccount})
+		}
+		if a.OfiSapAccount != "" && !sap[a.OfiSapAccount] {
+			c.UnresolvedReferences = append(c.UnresolvedReferences,
+				ReleaseCheckEntry{Entity: "account", BscsAccount: a.BscsAccount, Attribute: "ofiSapAccount", Value: a.OfiSapAccount})
+		}
+		for _, code := range codes {
+			if !ordered[fmt.Sprintf("%q %q", a.BscsAccount, code)] {
+				c.MissingOrders = append(c.MissingOrders, ReleaseCheckEntry{Entity: "order", BscsAccount: a.BscsAccount, SegmentCode: code})
+			}
+		}
+	}
+
+	for _, o := range orders {
+		if o.OrderNumber == "" {
+			c.MissingOrderNumbers = append(c.MissingOrderNumbers,
+				ReleaseCheckEntry{Entity: "order", BscsAccount: o.BscsAccount, SegmentCode: o.SegmentCode})
+		}
+		if !segment[o.SegmentCode] {
+			c.UnresolvedReferences = append(c.UnresolvedReferences,
+				ReleaseCheckEntry{Entity: "order", BscsAccount: o.BscsAccount, SegmentCode: o.SegmentCode, Attribute: "segmentCode", Value: o.SegmentCode})
+		}
+	}
+}
+
+//
+// The release may be done if nothing was found
+//
+func (c *ReleaseCheck) Done() {
+	c.Ok = c.Error == "" &&
+		len(c.MissingWbsCodes) == 0 &&
+		len(c.MissingOrderNumbers) == 0 &&
+		len(c.MissingOrders) == 0 &&
+		len(c.UnresolvedReferences) == 0 &&
+		len(c.Violations) == 0
+}
@@ -0,0 +1,51 @@
+package models
+
+// Handling of the entries of the clone already in Work
+const (
+	CloneSkip      = "skip"
+	CloneOverwrite = "overwrite"
+	CloneFail      = "fail"
+)
+
+type (
+	// Report of the release copied into Work
+	ReleaseClone struct {
+		ReleaseId   string              `json:"releaseId"`
+		Mode        string              `json:"mode"`
+		Accounts    int64               `json:"accounts"`
+		Orders      int64               `json:"orders"`
+		Overwritten int64               `json:"overwritten"`
+		Skipped     int64               `json:"skipped"`
+		Conflicts   []ReleaseCheckEntry `json:"conflicts"`
+	}
+)
+
+//
+// Copy of the account in Work without the dates and owners of the release
+//
+func (a *Account) CloneIntoWork() Account {
+	return Account{
+		Status:           "W",
+		ReleaseId:        "0",
+		BscsAccount:      a.BscsAccount,
+		OfiSapAccount:    a.OfiSapAccount,
+		ValidFromDate:    a.ValidFromDate,
+		VatCodeInd:       a.VatCodeInd,
+		OfiSapWbsCode:    a.OfiSapWbsCode,
+		CitMarkerVatFlag: a.CitMarkerVatFlag,
+	}
+}
+
+//
+// Copy of the order in Work without the dates and owners of the release
+//
+func (o *Order) CloneIntoWork() Order {
+	return Order{
+		Status:        "W",
+		ReleaseId:     "0",
+		BscsAccount:   o.BscsAccount,
+		SegmentCode:   o.SegmentCode,
+		OrderNumber:   o.OrderNumber,
+		ValidFromDate: o.ValidFromDate,
+	}
+}
@@ -0,0 +1,149 @@
+package models
+
+import (
+	"sort"
+	"strconv"
+)
+
+type (
+	// Value of the attribute in both compared sets
+	ReleaseDiffChange struct {
+		Attribute string `json:"attribute"`
+		From      string `json:"from"`
+		To        string `json:"to"`
+	}
+
+	// Entry identified by the business key
+	ReleaseDiffEntry struct {
+		BscsAccount string              `json:"bscsAccount"`
+		SegmentCode string              `json:"segmentCode,omitempty"`
+		Changes     []ReleaseDiffChange `json:"changes"`
+	}
+
+	ReleaseDiffSet struct {
+		Added   []ReleaseDiffEntry `json:"added"`
+		Removed []ReleaseDiffEntry `json:"removed"`
+		Changed []ReleaseDiffEntry `json:"changed"`
+	}
+
+	ReleaseDiff struct {
+		From     string         `json:"from"`
+		To       string         `json:"to"`
+		Accounts ReleaseDiffSet `json:"accounts"`
+		Orders   ReleaseDiffSet `json:"orders"`
+	}
+)
+
+// Compared attributes of the account
+var accountDiffAttributes = []string{"ofiSapAccount", "ofiSapWbsCode", "vatCodeInd", "validFromDate"}
+
+func (a *Account) diffValues() []string {
+	return []string{a.OfiSapAccount, a.OfiSapWbsCode, a.VatCodeInd, a.ValidFromDateStr}
+}
+
+// Compared attributes of the order
+var orderDiffAttributes = []string{"orderNumber", "validFromDate"}
+
+func (o *Order) diffValues() []string {
+	return []string{o.OrderNumber, o.ValidFromDateStr}
+}
+
+// Entry with the values of the compared attributes
+type diffRecord struct {
+	entry  ReleaseDiffEntry
+	values []string
+}
+
+// Attributes with different values, the values of missing record are empty
+func diffChanges(attributes, from, to []string) (changes []ReleaseDiffChange) {
+	changes = []ReleaseDiffChange{}
+	for i, a := range attributes {
+		c := ReleaseDiffChange{Attribute: a}
+		if from != nil {
+			c.From = from[i]
+		}
+		if to != nil {
+			c.To = to[i]
+		}
+		if c.From != c.To {
+			changes = append(changes, c)
+		}
+	}
+
+	return
+}
+
+// Entries added, removed or changed between the sets keyed by the business key
+func diffSet(attributes []string, from, to map[string]diffRecord) (set ReleaseDiffSet) {
+	set = ReleaseDiffSet{
+		Added:   []ReleaseDiffEntry{},
+		Removed: []ReleaseDiffEntry{},
+		Changed: []ReleaseDiffEntry{},
+	}
+
+	for k, f := range from {
+		e := f.entry
+		if t, exists := to[k]; !exists {
+			e.Changes = diffChanges(attributes, f.values, nil)
+			set.Removed = append(set.Removed, e)
+		} else if e.Changes = diffChanges(attributes, f.values, t.values); len(e.Changes) > 0 {
+			set.Changed = append(set.Changed, e)
+		}
+	}
+
+	for k, t := range to {
+		if _, exists := from[k]; !exists {
+			e := t.entry
+			e.Changes = diffChanges(attributes, nil, t.values)
+			set.Added = append(set.Added, e)
+		}
+	}
+
+	for _, entries := range [][]ReleaseDiffEntry{set.Added, set.Removed, set.Changed} {
+		sort.Slice(entries, func(i, j int) bool {
+			if entries[i].BscsAccount != entries[j].BscsAccount {
+				return entries[i].BscsAccount < entries[j].BscsAccount
+			}
+			return entries[i].SegmentCode < entries[j].SegmentCode
+		})
+	}
+
+	return
+}
+
+//
+// Compare accounts by BSCS_ACCOUNT
+//
+func DiffAccounts(from, to []Account) ReleaseDiffSet {
+	records := func(accounts []Account) map[string]diffRecord {
+		m := make(map[string]diffRecord)
+		for i := range accounts {
+			m[accounts[i].BscsAccount] = diffRecord{
+				entry:  ReleaseDiffEntry{BscsAccount: accounts[i].BscsAccount},
+				values: accounts[i].diffValues(),
+			}
+		}
+		return m
+	}
+
+	return diffSet(accountDiffAttributes, records(from), records(to))
+}
+
+//
+// Compare orders by BSCS_ACCOUNT and SEGMENT_CODE
+//
+func DiffOrders(from, to []Order) ReleaseDiffSet {
+	records := func(orders []Order) map[string]diffRecord {
+		m := make(map[string]diffRecord)
+		for i := range orders {
+			k := strconv.Quote(orders[i].BscsAccount) + strconv.Quote(orders[i].SegmentCode)
+			m[k] = diffRecord{
+				entry:  ReleaseDiffEntry{BscsAccount: orders[i].BscsAccount, SegmentCode: orders[i].SegmentCode},
+				values: orders[i].diffValues(),
+			}
+		}
+		return m
+	}
+
+	return diffSet(orderDiffAttributes, records(from), records(to))
+}
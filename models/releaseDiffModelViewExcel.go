@@ -0,0 +1,101 @@
+package models
+
+import (
+	"log"
+
+	"github.com/tealeg/xlsx"
+)
+
+var releaseDiffExcelColumns = []excelColumn{
+	{"Change", 10},
+	{"BSCS Account", 16},
+	{"Segment Code", 14},
+	{"Attribute", 16},
+	{"From", 20},
+	{"To", 20},
+}
+
+// One row per changed attribute of the entries
+func (set *ReleaseDiffSet) toExcelSheet(sheet *xlsx.Sheet) {
+	for _, group := range []struct {
+		Change  string
+		Entries []ReleaseDiffEntry
+	}{
+		{"Added", set.Added},
+		{"Removed", set.Removed},
+		{"Changed", set.Changed},
+	} {
+		for _, e := range group.Entries {
+			changes := e.Changes
+			if len(changes) == 0 {
+				// entry without any value set
+				changes = []ReleaseDiffChange{{}}
+			}
+			for _, c := range changes {
+				row := sheet.AddRow()
+				row.AddCell().SetString(group.Change)
+				row.AddCell().SetString(e.BscsAccount)
+				row.AddCell().SetString(e.SegmentCode)
+				row.AddCell().SetString(c.Attribute)
+				row.AddCell().SetString(c.From)
+				row.AddCell().SetString(c.To)
+			}
+		}
+	}
+}
+
+//
+// Workbook with the sheets of accounts and orders and the compared releases
+//
+func (d *ReleaseDiff) ToExcel() (rv []byte, err error) {
+	xf := xlsx.NewFile()
+	sets := []struct {
+		Name string
+		Set  *ReleaseDiffSet
+	}{
+		{"Accounts", &d.Accounts},
+		{"Orders", &d.Orders},
+	}
+
+	for _, s := range sets {
+		sheet, err := excelSheet(xf, s.Name, releaseDiffExcelColumns)
+		if err != nil {
+			return nil, err
+		}
+		s.Set.toExcelSheet(sheet)
+	}
+
+	sheet, err := excelSheet(xf, "Metadata", []excelColumn{{"Attribute", 20}, {"Value", 30}})
+	if err != nil {
+		return
+	}
+
+	row := sheet.AddRow()
+	row.AddCell().SetString("From")
+	row.AddCell().SetString(d.From)
+	row = sheet.AddRow()
+	row.AddCell().SetString("To")
+	row.AddCell().SetString(d.To)
+
+	for _, s := range sets {
+		for _, c := range []struct {
+			Name  string
+			Count int
+		}{
+			{"added", len(s.Set.Added)},
+			{"removed", len(s.Set.Removed)},
+			{"changed", len(s.Set.Changed)},
+		} {
+			row = sheet.AddRow()
+			row.AddCell().SetString(s.Name + " " + c.Name)
+			row.AddCell().SetInt(c.Count)
+		}
+	}
+
+	if rv, err = excelBytes(xf); err != nil {
+		return
+	}
+	log.Printf("Produced Excel diff from: %s to: %s len: %d", d.From, d.To, len(rv))
+
+	return
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats of the SAP OFI interface file
+const (
+	ReleaseExportFixed = "fixed"
+	ReleaseExportCsv   = "csv"
+	ReleaseExportXml   = "xml"
+)
+
+// Records of the interface file
+const (
+	releaseExportHeader  = "H"
+	releaseExportAccount = "A"
+	releaseExportOrder   = "O"
+	releaseExportTrailer = "T"
+)
+
+// Sender of the interface file in the header
+const releaseExportSender = "SAMAPI"
+
+type (
+	// Accounts and orders of the release in P exported for SAP
+	ReleaseExport struct {
+		ReleaseId string
+		CreatedAt time.Time
+		Accounts  []Account
+		Orders    []Order
+	}
+
+	// Counts and checksum of the trailer, the records include the header and the trailer
+	releaseExportTotals struct {
+		Accounts int
+		Orders   int
+		Records  int
+		Checksum string
+	}
+)
+
+// Valid date in the SAP format YYYYMMDD
+func releaseExportDate(validFrom string) string {
+	return strings.Replace(validFrom, "-", "", -1)
+}
+
+func (a *Account) toExportRecord() []string {
+	return []string{
+		a.BscsAccount,
+		a.OfiSapAccount,
+		a.OfiSapWbsCode,
+		a.VatCodeInd,
+		strconv.Itoa(a.CitMarkerVatFlag),
+		releaseExportDate(a.ValidFromDateStr),
+	}
+}
+
+func (o *Order) toExportRecord() []string {
+	return []string{
+		o.BscsAccount,
+		o.SegmentCode,
+		o.OrderNumber,
+		releaseExportDate(o.ValidFromDateStr),
+	}
+}
+
+// Header record of the release created at the time
+func (e *ReleaseExport) header() []string {
+	return []string{releaseExportSender, e.ReleaseId, e.CreatedAt.Format("20060102150405")}
+}
+
+//
+// Counts of the records and CRC-32 of the data records, each being the
+// type and the values separated by | ended with new line, the checksum is
+// the same in all the formats
+//
+func (e *ReleaseExport) totals() releaseExportTotals {
+	h := crc32.NewIEEE()
+	for i := range e.Accounts {
+		h.Write([]byte(releaseExportAccount + "|" + strings.Join(e.Accounts[i].toExportRecord(), "|") + "\n"))
+	}
+	for i := range e.Orders {
+		h.Write([]byte(releaseExportOrder + "|" + strings.Join(e.Orders[i].toExportRecord(), "|") + "\n"))
+	}
+
+	return releaseExportTotals{
+		Accounts: len(e.Accounts),
+		Orders:   len(e.Orders),
+		Records:  len(e.Accounts) + len(e.Orders) + 2,
+		Checksum: fmt.Sprintf("%08X", h.Sum32()),
+	}
+}
+
+// Checksum of the trailer the acknowledgement of the file refers to
+func (e *ReleaseExport) Checksum() string {
+	return e.totals().Checksum
+}
+
+// Trailer record with the counts and the checksum
+func (t releaseExportTotals) record() []string {
+	return []string{strconv.Itoa(t.Accounts), strconv.Itoa(t.Orders), strconv.Itoa(t.Records), t.Checksum}
+}
+
+//
+// All the records of the interface file with the type first, the header,
+// the accounts, the orders and the trailer
+//
+func (e *ReleaseExport) records() (records [][]string) {
+	records = append(records, append([]string{releaseExportHeader}, e.header()...))
+	for i := range e.Accounts {
+		records = append(records, append([]string{releaseExportAccount}, e.Accounts[i].toExportRecord()...))
+	}
+	for i := range e.Orders {
+		records = append(records, append([]string{releaseExportOrder}, e.Orders[i].toExportRecord()...))
+	}
+	records = append(records, append([]string{releaseExportTrailer}, e.totals().record()...))
+
+	return
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"log"
+)
+
+// Records with semicolon, the type is the first value
+func (e *ReleaseExport) ToCsv() (rv []byte, err error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = ';'
+	if err = w.WriteAll(e.records()); err != nil {
+		return nil, fmt.Errorf("Can't write CSV release: %s", err.Error())
+	}
+	rv = buf.Bytes()
+	log.Printf("Produced CSV release: %s accounts: %d orders: %d len: %d", e.ReleaseId, len(e.Accounts), len(e.Orders), len(rv))
+
+	return
+}
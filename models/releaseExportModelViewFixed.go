@@ -0,0 +1,57 @@
+package models
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Field of the fixed width record, the numeric ones are zero padded on the left
+type releaseExportField struct {
+	width   int
+	numeric bool
+}
+
+// Layout of the fixed width records by the type, the type itself takes 1 character
+var releaseExportLayout = map[string][]releaseExportField{
+	releaseExportHeader:  {{10, false}, {10, true}, {14, false}},
+	releaseExportAccount: {{32, false}, {32, false}, {32, false}, {32, false}, {1, true}, {8, false}},
+	releaseExportOrder:   {{32, false}, {32, false}, {32, false}, {8, false}},
+	releaseExportTrailer: {{9, true}, {9, true}, {9, true}, {8, false}},
+}
+
+// Values padded to the widths of the layout, too long value is an error
+func fixedWidthRecord(record []string) (line string, err error) {
+	layout := releaseExportLayout[record[0]]
+	var b strings.Builder
+	b.WriteString(record[0])
+	for i, f := range layout {
+		v := record[i + 1]
+		if len(v) > f.width {
+			return "", fmt.Errorf("Value too long for fixed width %d: %s", f.width, v)
+		}
+		if f.numeric {
+			b.WriteString(strings.Repeat("0", f.width - len(v)) + v)
+		} else {
+			b.WriteString(v + strings.Repeat(" ", f.width - len(v)))
+		}
+	}
+
+	return b.String(), nil
+}
+
+func (e *ReleaseExport) ToFixed() (rv []byte, err error) {
+	var buf bytes.Buffer
+	for _, record := range e.records() {
+		line, err := fixedWidthRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("Can't write fixed width release: %s", err.Error())
+		}
+		buf.WriteString(line + "\n")
+	}
+	rv = buf.Bytes()
+	log.Printf("Produced fixed width release: %s accounts: %d orders: %d len: %d", e.ReleaseId, len(e.Accounts), len(e.Orders), len(rv))
+
+	return
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log"
+	"strconv"
+)
+
+type (
+	releaseExportIdoc struct {
+		XMLName xml.Name              `xml:"SAMAPI_MAPPING01"`
+		Idoc    releaseExportIdocBody `xml:"IDOC"`
+	}
+
+	releaseExportIdocBody struct {
+		Begin    string                     `xml:"BEGIN,attr"`
+		Control  releaseExportIdocControl   `xml:"EDI_DC40"`
+		Accounts []releaseExportIdocAccount `xml:"E1ACCOUNT"`
+		Orders   []releaseExportIdocOrder   `xml:"E1ORDER"`
+		Trailer  releaseExportIdocTrailer   `xml:"E1TRAILER"`
+	}
+
+	// Control record being the header
+	releaseExportIdocControl struct {
+		Segment string `xml:"SEGMENT,attr"`
+		Sender  string `xml:"SNDPRN"`
+		DocNum  string `xml:"DOCNUM"`
+		MesTyp  string `xml:"MESTYP"`
+		CreDat  string `xml:"CREDAT"`
+		CreTim  string `xml:"CRETIM"`
+	}
+
+	releaseExportIdocAccount struct {
+		Segment          string `xml:"SEGMENT,attr"`
+		BscsAccount      string `xml:"BSCS_ACCOUNT"`
+		OfiSapAccount    string `xml:"OFI_SAP_ACCOUNT"`
+		OfiSapWbsCode    string `xml:"OFI_SAP_WBS_CODE"`
+		VatCodeInd       string `xml:"VAT_CODE_IND"`
+		CitMarkerVatFlag string `xml:"CIT_MARKER_VAT_FLAG"`
+		ValidFromDate    string `xml:"VALID_FROM_DATE"`
+	}
+
+	releaseExportIdocOrder struct {
+		Segment       string `xml:"SEGMENT,attr"`
+		BscsAccount   string `xml:"BSCS_ACCOUNT"`
+		SegmentCode   string `xml:"SEGMENT_CODE"`
+		OrderNumber   string `xml:"ORDER_NUMBER"`
+		ValidFromDate string `xml:"VALID_FROM_DATE"`
+	}
+
+	releaseExportIdocTrailer struct {
+		Segment  string `xml:"SEGMENT,attr"`
+		Accounts int    `xml:"ACCOUNTS"`
+		Orders   int    `xml:"ORDERS"`
+		Records  int    `xml:"RECORDS"`
+		Checksum string `xml:"CHECKSUM"`
+	}
+)
+
+// IDoc like document with the control record, the segments of the accounts and orders and the trailer
+func (e *ReleaseExport) ToXml() (rv []byte, err error) {
+	totals := e.totals()
+	doc := releaseExportIdocBody{
+		Begin: "1",
+		Control: releaseExportIdocControl{
+			Segment: "1",
+			Sender:  releaseExportSender,
+			DocNum:  e.ReleaseId,
+			MesTyp:  "SAMAPI_MAPPING",
+			CreDat:  e.CreatedAt.Format("20060102"),
+			CreTim:  e.CreatedAt.Format("150405"),
+		},
+		Accounts: []releaseExportIdocAccount{},
+		Orders:   []releaseExportIdocOrder{},
+		Trailer: releaseExportIdocTrailer{
+			Segment:  "1",
+			Accounts: totals.Accounts,
+			Orders:   totals.Orders,
+			Records:  totals.Records,
+			Checksum: totals.Checksum,
+		},
+	}
+	for _, a := range e.Accounts {
+		doc.Accounts = append(doc.Accounts, releaseExportIdocAccount{
+			Segment:          "1",
+			BscsAccount:      a.BscsAccount,
+			OfiSapAccount:    a.OfiSapAccount,
+			OfiSapWbsCode:    a.OfiSapWbsCode,
+			VatCodeInd:       a.VatCodeInd,
+			CitMarkerVatFlag: strconv.Itoa(a.CitMarkerVatFlag),
+			ValidFromDate:    releaseExportDate(a.ValidFromDateStr),
+		})
+	}
+	for _, o := range e.Orders {
+		doc.Orders = append(doc.Orders, releaseExportIdocOrder{
+			Segment:       "1",
+			BscsAccount:   o.BscsAccount,
+			SegmentCode:   o.SegmentCode,
+			OrderNumber:   o.OrderNumber,
+			ValidFromDate: releaseExportDate(o.ValidFromDateStr),
+		})
+	}
+
+	body, err := xml.MarshalIndent(releaseExportIdoc{Idoc: doc}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("Can't write XML release: %s", err.Error())
+	}
+	rv = append([]byte(xml.Header), body...)
+	log.Printf("Produced XML release: %s accounts: %d orders: %d len: %d", e.ReleaseId, len(e.Accounts), len(e.Orders), len(rv))
+
+	return
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+type (
+	Release struct {
+		ReleaseId        string       `json:"releaseId" db:"RELEASE_ID,size:8,primarykey"`
+		FromStatus       string       `json:"fromStatus,omitempty" db:"FROM_STATUS,size:1"`
+		IntoStatus       string       `json:"intoStatus,omitempty" db:"INTO_STATUS,size:1"`
+		Description      string       `json:"description,omitempty" db:"DESCRIPTION,size:256"`
+		ReleaseDate      time.Time    `json:"-" db:"RELEASE_DATE"`
+		ReleaseDateStr   string       `json:"releaseDate,omitempty" db:"-"`
+		ReleaseOwner     string       `json:"releaseOwner,omitempty" db:"RELEASE_OWNER,size:16"`
+		RevokeDate       time.Time    `json:"-" db:"REVOKE_DATE"`
+		RevokeDateStr    string       `json:"revokeDate,omitempty" db:"-"`
+		RevokeOwner      string       `json:"revokeOwner,omitempty" db:"REVOKE_OWNER,size:16"`
+		RecVersion       int          `json:"recVersion" db:"REC_VERSION"`
+		Accounts         int64        `json:"accounts" db:"-"`
+		Orders           int64        `json:"orders" db:"-"`
+		ValidFromDateStr string       `json:"validFromDate,omitempty" db:"-"`
+		History          []ReleaseLog `json:"history,omitempty" db:"-"`
+	}
+
+	ReleaseLog struct {
+		ReleaseId string    `json:"-" db:"RELEASE_ID,size:8"`
+		OpCode    string    `json:"opcode" db:"OPCODE,size:1"`
+		OpDate    time.Time `json:"-" db:"OPDATE"`
+		OpDateStr string    `json:"opdate" db:"-"`
+		OpOwner   string    `json:"owner" db:"OPOWNER,size:16"`
+		Accounts  int64     `json:"accounts" db:"ACCOUNTS"`
+		Orders    int64     `json:"orders" db:"ORDERS"`
+	}
+)
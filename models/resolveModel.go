@@ -0,0 +1,143 @@
+package models
+
+import (
+	"sort"
+)
+
+// Results of the resolution
+const (
+	ResolveMapped   = "mapped"
+	ResolveUnmapped = "unmapped"
+	ResolveInvalid  = "invalid"
+)
+
+type (
+	// Posting of the BSCS account and segment on the date to be resolved
+	ResolveLookup struct {
+		BscsAccount string `json:"bscsAccount"`
+		SegmentCode string `json:"segmentCode"`
+		PostingDate string `json:"postingDate"`
+	}
+
+	// SAP values of the posting, only the status and the reason if not mapped
+	ResolveResult struct {
+		BscsAccount      string `json:"bscsAccount"`
+		SegmentCode      string `json:"segmentCode"`
+		PostingDate      string `json:"postingDate"`
+		Status           string `json:"status"`
+		Reason           string `json:"reason,omitempty"`
+		OfiSapAccount    string `json:"ofiSapAccount,omitempty"`
+		OfiSapWbsCode    string `json:"ofiSapWbsCode,omitempty"`
+		VatCodeInd       string `json:"vatCodeInd,omitempty"`
+		CitMarkerVatFlag int    `json:"citMarkerVatFlag"`
+		OrderNumber      string `json:"orderNumber,omitempty"`
+		AccountReleaseId string `json:"accountReleaseId,omitempty"`
+		OrderReleaseId   string `json:"orderReleaseId,omitempty"`
+	}
+
+	// All the versions of accounts and orders in production by their keys
+	MappingIndex struct {
+		accounts map[string][]Account
+		orders   map[string][]Order
+		Accounts int
+		Orders   int
+	}
+)
+
+// Key of the order of the account in the segment
+func orderIndexKey(account, segment string) string {
+	return account + "/" + segment
+}
+
+//
+// Index of all the versions of accounts and orders in production
+// for the resolution of the postings on any date
+//
+func NewMappingIndex(accounts []Account, orders []Order) *MappingIndex {
+	x := &MappingIndex{
+		accounts: make(map[string][]Account),
+		orders:   make(map[string][]Order),
+		Accounts: len(accounts),
+		Orders:   len(orders),
+	}
+
+	for _, a := range accounts {
+		x.accounts[a.BscsAccount] = append(x.accounts[a.BscsAccount], a)
+	}
+	for _, o := range orders {
+		k := orderIndexKey(o.BscsAccount, o.SegmentCode)
+		x.orders[k] = append(x.orders[k], o)
+	}
+
+	// the versions valid from the earliest date, on the same date the later release is after
+	for _, v := range x.accounts {
+		sort.Slice(v, func(i, j int) bool {
+			return effectiveLater(v[j].ValidFromDateStr, v[j].ReleaseId, v[i].ValidFromDateStr, v[i].ReleaseId)
+		})
+	}
+	for _, v := range x.orders {
+		sort.Slice(v, func(i, j int) bool {
+			return effectiveLater(v[j].ValidFromDateStr, v[j].ReleaseId, v[i].ValidFromDateStr, v[i].ReleaseId)
+		})
+	}
+
+	return x
+}
+
+// The last version valid on the date, the date not set is valid since the release
+func effectiveOn(date string, n int, validFrom func(int) string) int {
+	found := -1
+	for i := 0; i < n; i++ {
+		if v := validFrom(i); v == "" || v <= date {
+			found = i
+		}
+	}
+
+	return found
+}
+
+//
+// Resolve the posting into the SAP account and order effective on its date,
+// the posting of a segment without the order is not mapped, the date is
+// YYYY-MM-DD
+//
+func (x *MappingIndex) Resolve(l ResolveLookup) ResolveResult {
+	result := ResolveResult{
+		BscsAccount: l.BscsAccount,
+		SegmentCode: l.SegmentCode,
+		PostingDate: l.PostingDate,
+		Status:      ResolveUnmapped,
+	}
+
+	accounts := x.accounts[l.BscsAccount]
+	i := effectiveOn(l.PostingDate, len(accounts), func(i int) string { return accounts[i].ValidFromDateStr })
+	if i < 0 {
+		result.Reason = "No account mapping valid on the posting date"
+		return result
+	}
+	a := accounts[i]
+	result.OfiSapAccount = a.OfiSapAccount
+	result.OfiSapWbsCode = a.OfiSapWbsCode
+	result.VatCodeInd = a.VatCodeInd
+	result.CitMarkerVatFlag = a.CitMarkerVatFlag
+	result.AccountReleaseId = a.ReleaseId
+
+	if l.SegmentCode != "" {
+		orders := x.orders[orderIndexKey(l.BscsAccount, l.SegmentCode)]
+		j := effectiveOn(l.PostingDate, len(orders), func(j int) string { return orders[j].ValidFromDateStr })
+		if j < 0 {
+			result.Reason = "No order of the segment valid on the posting date"
+			return result
+		}
+		if orders[j].OrderNumber == "" {
+			result.Reason = "No order number of the segment valid on the posting date"
+			return result
+		}
+		result.OrderNumber = orders[j].OrderNumber
+		result.OrderReleaseId = orders[j].ReleaseId
+	}
+
+	result.Status = ResolveMapped
+
+	return result
+}
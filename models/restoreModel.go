@@ -0,0 +1,73 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Operation of the restore written to the log by the API next to the one of the trigger
+const OpRestore = "R"
+
+type (
+	// Log entry the entry in Work is restored from
+	Restore struct {
+		Id string `json:"id"`
+	}
+)
+
+// Identifier of the log entry made of the time and code of the operation, the version and the key of the row
+func logEntryId(opdate time.Time, opcode string, version int, keys ...string) string {
+	return strings.Join(append([]string{opdate.Format("20060102150405"), opcode, strconv.Itoa(version)}, keys...), "-")
+}
+
+func (l *AccountLog) LogId() string {
+	return logEntryId(l.OpDate, l.OpCode, l.RecVersion, l.Status, l.ReleaseId)
+}
+
+func (l *OrderLog) LogId() string {
+	return logEntryId(l.OpDate, l.OpCode, l.RecVersion, l.SegmentCode, l.Status, l.ReleaseId)
+}
+
+// Attributes set by the restore which differ from the entry in Work, the ones set if there is no entry
+func restoreChanges(attributes, values, current []string) map[string]interface{} {
+	changes := make(map[string]interface{})
+	for i, k := range attributes {
+		switch k {
+		case "status", "releaseId", "rejectReason":
+			// the key and the rejection are not restored
+		default:
+			if (current == nil && values[i] != "" && values[i] != "0") || (current != nil && values[i] != current[i]) {
+				changes[k] = values[i]
+			}
+		}
+	}
+
+	return changes
+}
+
+//
+// Attributes of the account restored into Work to be checked for the role,
+// current is the account already in Work or nil if it is created
+//
+func (a *Account) RestoreChanges(current *Account) map[string]interface{} {
+	var values []string
+	if current != nil {
+		values = current.historyValues()
+	}
+
+	return restoreChanges(accountHistoryAttributes, a.historyValues(), values)
+}
+
+//
+// Attributes of the order restored into Work to be checked for the role,
+// current is the order already in Work or nil if it is created
+//
+func (o *Order) RestoreChanges(current *Order) map[string]interface{} {
+	var values []string
+	if current != nil {
+		values = current.historyValues()
+	}
+
+	return restoreChanges(orderHistoryAttributes, o.historyValues(), values)
+}
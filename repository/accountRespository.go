@@ -8,35 +8,71 @@ import (
 
 	"database/sql"
 	_ "gopkg.in/goracle.v2"
+	"gopkg.in/gorp.v2"
 
 	"sam-api/common"
 	"sam-api/models"
 )
 
+//
+// Operations on SAP_ACCOUNTS the controllers depend on
+//
+type AccountRepository interface {
+	Close()
+	Commit() error
+	Rollback()
+	Create(a *models.Account) error
+	ReadBulkByPartialKey(a *models.Account, q *common.ListQuery) ([]models.Account, int64, error)
+	ReadByPrimaryKey(a *models.Account) (int64, error)
+	ReadVersionByPrimaryKey(a *models.Account) (int, int64, error)
+	UpdateByPrimaryKey(a *models.Account) (int64, error)
+	UpdateAttributeByPrimaryKey(a *models.Account, attribute string, value interface{}) (int64, error)
+	Reject(a *models.Account, reason string) (int64, error)
+	DeleteByPrimaryKey(a *models.Account) (int64, error)
+	GetMaxRelease() (int64, error)
+	SetStatusRelease(from, into string, release, releaseNew int64) (int64, error)
+	DeleteAll() (int64, error)
+	GetMinValidDate(status string, release int64) (time.Time, error)
+	ReadLog(account string) ([]models.AccountLog, error)
+	ReadProduction(asOf time.Time, account string) ([]models.Account, error)
+	LogRestore(a *models.Account) error
+}
+
 //
 // Pepository being handled by request
 //
-type AccountRepository struct {
+type DbAccountRepository struct {
 	Repository
 }
 
+//
+// Creates new repository on the configured backend
+//
+func NewAccountRepository(user string, trans bool) (AccountRepository, error) {
+	if common.AppConfig.Backend == common.BackendMemory {
+		return newMemAccountRepository(user, trans), nil
+	}
+
+	r, err := newDbAccountRepository(user, trans)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
 //
 // Creates new repository using existing db connection
 //
-func NewAccountRepository(user string, trans bool) (r *AccountRepository, err error) {
+func newDbAccountRepository(user string, trans bool) (r *DbAccountRepository, err error) {
 	log.Printf("Creating new repository for user: %s", user)
 
 	if db, err := common.GetDbSession(); err != nil {
 		return nil, err
 	} else {
 		dbmap := initRepository(db)
-		dbmap.AddTableWithName(models.Account{}, "SAP_ACCOUNTS").
-			SetKeys(false, "STATUS").
-			SetKeys(false, "RELEASE_ID").
-			SetKeys(false, "BSCS_ACCOUNT")
-		dbmap.AddTableWithName(models.AccountLog{}, "SAP_ACCOUNTS_LOG").
-			SetKeys(false, "BSCS_ACCOUNT")
-		r = &AccountRepository{
+		addAccountTables(dbmap)
+		r = &DbAccountRepository{
 			Repository{
 				Owner: user,
 				Db:    db,
@@ -55,20 +91,31 @@ func NewAccountRepository(user string, trans bool) (r *AccountRepository, err er
 	return
 }
 
-func (r *AccountRepository) Close() {
+// associate the structures with the tables
+func addAccountTables(dbmap *gorp.DbMap) {
+	dbmap.AddTableWithName(models.Account{}, "SAP_ACCOUNTS").
+		SetKeys(false, "STATUS").
+		SetKeys(false, "RELEASE_ID").
+		SetKeys(false, "BSCS_ACCOUNT")
+	dbmap.AddTableWithName(models.AccountLog{}, "SAP_ACCOUNTS_LOG").
+		SetKeys(false, "BSCS_ACCOUNT")
+}
+
+func (r *DbAccountRepository) Close() {
 	r.m.Unlock()
 }
 
-func (r *AccountRepository) Commit() {
+func (r *DbAccountRepository) Commit() error {
 	if r.t != nil {
-		err := r.t.Commit()
-		if err != nil {
-			log.Printf("Commit error: %s", err.Error())
+		if err := r.t.Commit(); err != nil {
+			return fmt.Errorf("Commit error: %s", err.Error())
 		}
 	}
+
+	return nil
 }
 
-func (r *AccountRepository) Rollback() {
+func (r *DbAccountRepository) Rollback() {
 	if r.t != nil {
 		err := r.t.Rollback()
 		if err != nil {
@@ -80,7 +127,7 @@ func (r *AccountRepository) Rollback() {
 //
 // Insert new record to the resource SAP_ACCOUNTS
 //
-func (r *AccountRepository) Create(a *models.Account) (err error) {
+func (r *DbAccountRepository) Create(a *models.Account) (err error) {
 	log.Printf("Inserting to SAP_ACCOUNTS: %s %#v", r.Owner, *a)
 
 	// default value
@@ -124,13 +171,13 @@ func (r *AccountRepository) Create(a *models.Account) (err error) {
 //
 // Select some records from the resource, no use of ORP Get as it returns single record only
 //
-func (r *AccountRepository) ReadBulkByPartialKey(a *models.Account) (accounts []models.Account, err error) {
+func (r *DbAccountRepository) ReadBulkByPartialKey(a *models.Account, q *common.ListQuery) (accounts []models.Account, total int64, err error) {
 	log.Printf("Selecting from SAP_ACCOUNTS: %#v", *a)
 
 	// prepeare query binding partial key value set
 	var records = []models.Account{}
 	var query string
-	var binding map[string]interface{}
+	var binding = map[string]interface{}{}
 	columns := []string{
 		"STATUS",
 		"RELEASE_ID",
@@ -147,6 +194,8 @@ func (r *AccountRepository) ReadBulkByPartialKey(a *models.Account) (accounts []
 		"RELEASE_DATE",
 		"RELEASE_OWNER",
 		"REC_VERSION",
+		"REJECT_REASON",
+		"REJECT_OWNER",
 	}
 	if a.Status != "" && a.ReleaseId != "" {
 		query = fmt.Sprintf(`
@@ -165,16 +214,34 @@ SELECT %s
 FROM SAP_ACCOUNTS Q
 WHERE (STATUS IN ('W', 'C') AND RELEASE_ID = 0) 
    OR (STATUS = 'P' AND RELEASE_ID = 
-      (SELECT NVL(MAX(RELEASE_ID), 0) 
+      (SELECT %s 
          FROM SAP_ACCOUNTS SQ 
         WHERE SQ.BSCS_ACCOUNT = Q.BSCS_ACCOUNT))
-`, strings.Join(columns, ", "))
+`, strings.Join(columns, ", "), dialect().Nvl("MAX(RELEASE_ID)", "0"))
+	}
+
+	// Apply filters, sorting and paging of the list
+	if q == nil {
+		q = &common.ListQuery{}
+	}
+	query, count, err := listQuery(query, binding, q, accountQueryColumns, "BSCS_ACCOUNT, STATUS")
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// Do query by dynamicly built key
-	_, err = r.Dbmap.Select(&records, query, binding)
+	err = selectPage(r.Dbmap, &records, query, binding)
 	if err != nil {
-		return nil, fmt.Errorf("Error in select from SAP_ACCOUNTS: " + err.Error())
+		return nil, 0, fmt.Errorf("Error in select from SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	// Count all records of the list if only a page of it was selected
+	total = int64(len(records))
+	if q.Limit > 0 || q.Offset > 0 {
+		total, err = r.Dbmap.SelectInt(count, binding)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error in select from SAP_ACCOUNTS: %s", err.Error())
+		}
 	}
 
 	// Take care of dates presentation
@@ -195,7 +262,88 @@ WHERE (STATUS IN ('W', 'C') AND RELEASE_ID = 0)
 
 	accounts = records
 
-	log.Printf("Selected from SAP_ACCOUNTS records: %d %#v", len(records), records)
+	log.Printf("Selected from SAP_ACCOUNTS records: %d of %d %#v", len(records), total, records)
+
+	return
+}
+
+//
+// Select one record from the resource SAP_ACCOUNTS using primary key
+//
+func (r *DbAccountRepository) ReadByPrimaryKey(a *models.Account) (count int64, err error) {
+	log.Printf("Selecting from SAP_ACCOUNTS by key: %#v", *a)
+
+	var record interface{}
+	if r.t != nil {
+		record, err = r.t.Get(models.Account{}, a.Status, a.ReleaseId, a.BscsAccount)
+	} else {
+		record, err = r.Dbmap.Get(models.Account{}, a.Status, a.ReleaseId, a.BscsAccount)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Error in select from SAP_ACCOUNTS: %s", err.Error())
+	} else if record == nil {
+		log.Printf("Selected from SAP_ACCOUNTS records: 0")
+		return 0, nil
+	}
+
+	*a = *record.(*models.Account)
+	count = 1
+
+	// Take care of dates presentation
+	if !a.ValidFromDate.IsZero() {
+		a.ValidFromDateStr = a.ValidFromDate.Format(common.CutOffDateFormat)
+	}
+	if !a.EntryDate.IsZero() {
+		a.EntryDateStr = a.EntryDate.Format(common.ModelDateFormat)
+	}
+	if !a.UpdateDate.IsZero() {
+		a.UpdateDateStr = a.UpdateDate.Format(common.ModelDateFormat)
+	}
+	if !a.ReleaseDate.IsZero() {
+		a.ReleaseDateStr = a.ReleaseDate.Format(common.ModelDateFormat)
+	}
+
+	log.Printf("Selected from SAP_ACCOUNTS: %#v", *a)
+
+	return
+}
+
+//
+// Select REC_VERSION of one record using primary key, in a transaction
+// the record stays locked till commit so the version can not change
+//
+func (r *DbAccountRepository) ReadVersionByPrimaryKey(a *models.Account) (version int, count int64, err error) {
+	log.Printf("Selecting REC_VERSION from SAP_ACCOUNTS: %#v", *a)
+
+	query := `
+SELECT REC_VERSION
+  FROM SAP_ACCOUNTS
+ WHERE STATUS = :status
+   AND RELEASE_ID = :release_id
+   AND BSCS_ACCOUNT = :bscs_account`
+	binding := map[string]interface{}{
+		"status":       a.Status,
+		"release_id":   a.ReleaseId,
+		"bscs_account": a.BscsAccount,
+	}
+
+	var rv sql.NullInt64
+	if r.t != nil {
+		rv, err = r.t.SelectNullInt(query+" FOR UPDATE", binding)
+	} else {
+		rv, err = r.Dbmap.SelectNullInt(query, binding)
+	}
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("Error in select from SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	if rv.Valid {
+		version, count = int(rv.Int64), 1
+	}
+
+	log.Printf("Selected REC_VERSION from SAP_ACCOUNTS: %d, count: %d", version, count)
 
 	return
 }
@@ -203,7 +351,7 @@ WHERE (STATUS IN ('W', 'C') AND RELEASE_ID = 0)
 //
 // Update one record in resource SAP_ACCOUNTS using primary key
 //
-func (r *AccountRepository) UpdateByPrimaryKey(a *models.Account) (count int64, err error) {
+func (r *DbAccountRepository) UpdateByPrimaryKey(a *models.Account) (count int64, err error) {
 	log.Printf("Updating SAP_ACCOUNTS: %#v", *a)
 
 	var stmt = `
@@ -219,6 +367,7 @@ WHERE STATUS = :8
   AND RELEASE_ID = :9
   AND BSCS_ACCOUNT = :10
 `
+	stmt = dialect().Rebind(stmt)
 
 	var rs sql.Result
 	if r.t != nil {
@@ -264,7 +413,7 @@ WHERE STATUS = :8
 //
 // Update one attribute of the record in resource SAP_ACCOUNTS using primary key
 //
-func (r *AccountRepository) UpdateAttributeByPrimaryKey(a *models.Account, attribute string, value interface{}) (count int64, err error) {
+func (r *DbAccountRepository) UpdateAttributeByPrimaryKey(a *models.Account, attribute string, value interface{}) (count int64, err error) {
 	log.Printf("Updating SAP_ACCOUNTS: %s <- %v %T %#v with key: %#v", attribute, value, value, value, *a)
 
 	// make dynamic sql statement
@@ -295,7 +444,7 @@ WHERE STATUS = :4
 		return 0, fmt.Errorf("No column name for attribute: %s", attribute)
 	}
 
-	stmt = fmt.Sprintf(stmt, colname)
+	stmt = dialect().Rebind(fmt.Sprintf(stmt, colname))
 
 	// run it
 	var rs sql.Result
@@ -331,10 +480,55 @@ WHERE STATUS = :4
 	return
 }
 
+//
+// Move the record in status C back to W with the reason of the rejection
+//
+func (r *DbAccountRepository) Reject(a *models.Account, reason string) (count int64, err error) {
+	log.Printf("Rejecting SAP_ACCOUNTS: %s %#v", reason, *a)
+
+	var stmt = `
+UPDATE SAP_ACCOUNTS 
+SET STATUS = 'W', 
+    REJECT_REASON = :1, 
+    REJECT_OWNER = :2, 
+    UPDATE_DATE = :3, 
+    UPDATE_OWNER = :4
+WHERE STATUS = 'C'
+  AND RELEASE_ID = 0
+  AND BSCS_ACCOUNT = :5
+`
+	stmt = dialect().Rebind(stmt)
+
+	var rs sql.Result
+	if r.t != nil {
+		rs, err = r.t.Exec(stmt, reason, r.Owner, time.Now(), r.Owner, a.BscsAccount)
+	} else {
+		rs, err = r.Dbmap.Exec(stmt, reason, r.Owner, time.Now(), r.Owner, a.BscsAccount)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	count, err = rs.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	if count > 0 {
+		a.Status, a.ReleaseId = "W", "0"
+		a.RejectReason, a.RejectOwner = reason, r.Owner
+	}
+
+	log.Printf("Rejected SAP_ACCOUNTS records: %d", count)
+
+	return
+}
+
 //
 // Delete one record from resource SAP_ACCOUNTS using primary key
 //
-func (r *AccountRepository) DeleteByPrimaryKey(a *models.Account) (count int64, err error) {
+func (r *DbAccountRepository) DeleteByPrimaryKey(a *models.Account) (count int64, err error) {
 	log.Printf("Deleting from SAP_ACCOUNTS: %#v", *a)
 
 	if r.t != nil {
@@ -355,11 +549,11 @@ func (r *AccountRepository) DeleteByPrimaryKey(a *models.Account) (count int64,
 //
 // Select max
 //
-func (r *AccountRepository) GetMaxRelease() (release int64, err error) {
+func (r *DbAccountRepository) GetMaxRelease() (release int64, err error) {
 	log.Printf("Selecting MAX(RELEASE_ID) from SAP_ACCOUNTS")
 
 	// do query
-	query := "SELECT NVL(MAX(RELEASE_ID), 0) FROM SAP_ACCOUNTS"
+	query := fmt.Sprintf("SELECT %s FROM SAP_ACCOUNTS", dialect().Nvl("MAX(RELEASE_ID)", "0"))
 	release, err = r.Dbmap.SelectInt(query)
 	if err != nil {
 		return 0, fmt.Errorf("Error in MAX(RELEASE_ID) FROM SAP_ACCOUNTS: %s", err.Error())
@@ -373,19 +567,30 @@ func (r *AccountRepository) GetMaxRelease() (release int64, err error) {
 //
 // Release
 //
-func (r *AccountRepository) SetStatusRelease(from, into string, release, releaseNew int64) (count int64, err error) {
+func (r *DbAccountRepository) SetStatusRelease(from, into string, release, releaseNew int64) (count int64, err error) {
 	log.Printf("Set STATUS, RELEASE for SAP_ACCOUNTS")
 
+	// released entries get the date and owner, revoked ones lose them
+	var releaseDate, releaseOwner interface{}
+	if into != "W" {
+		releaseDate, releaseOwner = time.Now(), r.Owner
+	}
+
 	// do update
 	stmt := `
 UPDATE SAP_ACCOUNTS 
 SET STATUS = :1, 
-    RELEASE_ID = :2 
-WHERE STATUS = :3
-  AND RELEASE_ID = :4
+    RELEASE_ID = :2,
+    RELEASE_DATE = :3,
+    RELEASE_OWNER = :4,
+    REJECT_REASON = NULL,
+    REJECT_OWNER = NULL
+WHERE STATUS = :5
+  AND RELEASE_ID = :6
 `
+	stmt = dialect().Rebind(stmt)
 	var rs sql.Result
-	rs, err = r.t.Exec(stmt, into, releaseNew, from, release)
+	rs, err = r.t.Exec(stmt, into, releaseNew, releaseDate, releaseOwner, from, release)
 	if err != nil {
 		return 0, fmt.Errorf("Error in update SAP_ACCOUNTS: %s", err.Error())
 	}
@@ -403,7 +608,7 @@ WHERE STATUS = :3
 //
 // Purge
 //
-func (r *AccountRepository) DeleteAll() (count int64, err error) {
+func (r *DbAccountRepository) DeleteAll() (count int64, err error) {
 	log.Printf("Purging SAP_ACCOUNTS")
 
 	// do query
@@ -432,12 +637,12 @@ func (r *AccountRepository) DeleteAll() (count int64, err error) {
 //
 // Used for validation of the lower bound of the account package
 //
-func (r *AccountRepository) GetMinValidDate(status string, release int64) (ts time.Time, err error) {
-	query := `
-SELECT NVL(MIN(VALID_FROM_DATE), SYSDATE)
+func (r *DbAccountRepository) GetMinValidDate(status string, release int64) (ts time.Time, err error) {
+	query := fmt.Sprintf(`
+SELECT %s
   FROM SAP_ACCOUNTS 
  WHERE STATUS = :status 
-   AND RELEASE_ID = :release_id`
+   AND RELEASE_ID = :release_id`, dialect().Nvl("MIN(VALID_FROM_DATE)", dialect().Sysdate()))
 
 	binding := map[string]interface{}{
 		"status":     status,
@@ -453,9 +658,9 @@ SELECT NVL(MIN(VALID_FROM_DATE), SYSDATE)
 }
 
 //
-// Read logs of the account
+// Read logs of the account in the order of operations, all accounts if empty
 //
-func (r *AccountRepository) ReadLog(account string) (logs []models.AccountLog, err error) {
+func (r *DbAccountRepository) ReadLog(account string) (logs []models.AccountLog, err error) {
 	var records = []models.AccountLog{}
 	var query string
 	var binding map[string]interface{}
@@ -463,6 +668,7 @@ func (r *AccountRepository) ReadLog(account string) (logs []models.AccountLog, e
 		"OPCODE",
 		"OPDATE",
 		"STATUS",
+		"RELEASE_ID",
 		"BSCS_ACCOUNT",
 		"OFI_SAP_ACCOUNT",
 		"VALID_FROM_DATE",
@@ -476,12 +682,17 @@ func (r *AccountRepository) ReadLog(account string) (logs []models.AccountLog, e
 		"RELEASE_DATE",
 		"RELEASE_OWNER",
 		"REC_VERSION",
+		"REJECT_REASON",
+		"REJECT_OWNER",
 	}
 
-	query = fmt.Sprintf(`SELECT %s FROM SAP_ACCOUNTS_LOG WHERE BSCS_ACCOUNT = :account`, strings.Join(columns, ","))
-	binding = map[string]interface{}{
-		"account": account,
+	query = fmt.Sprintf(`SELECT %s FROM SAP_ACCOUNTS_LOG`, strings.Join(columns, ","))
+	binding = map[string]interface{}{}
+	if account != "" {
+		query += ` WHERE BSCS_ACCOUNT = :account`
+		binding["account"] = account
 	}
+	query += ` ORDER BY OPDATE, REC_VERSION`
 
 	_, err = r.Dbmap.Select(&records, query, binding)
 	if err != nil {
@@ -502,6 +713,7 @@ func (r *AccountRepository) ReadLog(account string) (logs []models.AccountLog, e
 		if !r.ReleaseDate.IsZero() {
 			records[i].ReleaseDateStr = r.ReleaseDate.Format(common.ModelDateFormat)
 		}
+		records[i].Id = records[i].LogId()
 	}
 
 	logs = records
@@ -510,3 +722,104 @@ func (r *AccountRepository) ReadLog(account string) (logs []models.AccountLog, e
 
 	return
 }
+
+//
+// Write the restore of the account in Work to SAP_ACCOUNTS_LOG, the trigger writes
+// the insert or update of the row, this one tells it was a restore
+//
+func (r *DbAccountRepository) LogRestore(a *models.Account) (err error) {
+	entry := &models.AccountLog{
+		OpCode:  models.OpRestore,
+		OpDate:  time.Now(),
+		Account: *a,
+	}
+	entry.UpdateDate = entry.OpDate
+	entry.UpdateOwner = r.Owner
+
+	if r.t != nil {
+		err = r.t.Insert(entry)
+	} else {
+		err = r.Dbmap.Insert(entry)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in insert to SAP_ACCOUNTS_LOG: %s", err.Error())
+	}
+
+	log.Printf("Inserted to SAP_ACCOUNTS_LOG: %#v", *entry)
+
+	return
+}
+
+//
+// Read the accounts of all releases in production valid on the date,
+// the valid date not set is valid from the release, all accounts if empty,
+// all the versions if the date is zero
+//
+func (r *DbAccountRepository) ReadProduction(asOf time.Time, account string) (accounts []models.Account, err error) {
+	var records = []models.Account{}
+	columns := []string{
+		"STATUS",
+		"RELEASE_ID",
+		"BSCS_ACCOUNT",
+		"OFI_SAP_ACCOUNT",
+		"VALID_FROM_DATE",
+		"VAT_CODE_IND",
+		"OFI_SAP_WBS_CODE",
+		"CIT_MARKER_VAT_FLAG",
+		"ENTRY_DATE",
+		"ENTRY_OWNER",
+		"UPDATE_DATE",
+		"UPDATE_OWNER",
+		"RELEASE_DATE",
+		"RELEASE_OWNER",
+		"REC_VERSION",
+		"REJECT_REASON",
+		"REJECT_OWNER",
+	}
+
+	query := fmt.Sprintf(`
+SELECT %s
+FROM SAP_ACCOUNTS
+WHERE STATUS = 'P'`, strings.Join(columns, ", "))
+	binding := map[string]interface{}{}
+	if !asOf.IsZero() {
+		query += `
+AND (VALID_FROM_DATE IS NULL OR VALID_FROM_DATE <= :as_of)`
+		binding["as_of"] = asOf
+	}
+	if account != "" {
+		query += `
+AND BSCS_ACCOUNT = :account`
+		binding["account"] = account
+	}
+	query += `
+ORDER BY BSCS_ACCOUNT, RELEASE_ID`
+
+	_, err = r.Dbmap.Select(&records, query, binding)
+	if err != nil {
+		return nil, fmt.Errorf("Error in select from SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	// Take care of dates presentation
+	for i, r := range records {
+		if !r.ValidFromDate.IsZero() {
+			records[i].ValidFromDateStr = r.ValidFromDate.Format(common.CutOffDateFormat)
+		}
+		if !r.EntryDate.IsZero() {
+			records[i].EntryDateStr = r.EntryDate.Format(common.ModelDateFormat)
+		}
+		if !r.UpdateDate.IsZero() {
+			records[i].UpdateDateStr = r.UpdateDate.Format(common.ModelDateFormat)
+		}
+		if !r.ReleaseDate.IsZero() {
+			records[i].ReleaseDateStr = r.ReleaseDate.Format(common.ModelDateFormat)
+		}
+	}
+
+	accounts = records
+
+	log.Printf("Selected from SAP_ACCOUNTS in production as of: %s records: %d", asOf.Format(common.CutOffDateFormat), len(records))
+
+	return
+}
@@ -0,0 +1,340 @@
+/*
+
+PACKAGE: Data access layer for Approval -> SAP_APPROVALS table
+
+It keeps the requests of the release which are to be approved
+or rejected by other user with the role doing the release.
+Each step of the request is recorded in SAP_APPROVALS_LOG
+with the user, the role, the date and the comment.
+
+*/
+
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	_ "gopkg.in/goracle.v2"
+	"gopkg.in/gorp.v2"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+//
+// Operations on SAP_APPROVALS the controllers depend on
+//
+type ApprovalRepository interface {
+	Close()
+	Commit() error
+	Rollback()
+	Create(a *models.Approval) error
+	Decide(a *models.Approval, state, role, comment string) (int64, error)
+	ReadByPrimaryKey(a *models.Approval) (int64, error)
+	ReadAll(state string) ([]models.Approval, error)
+}
+
+//
+// Pepository being handled by request
+//
+type DbApprovalRepository struct {
+	Repository
+}
+
+//
+// Creates new repository on the configured backend
+//
+func NewApprovalRepository(user string, trans bool) (ApprovalRepository, error) {
+	if common.AppConfig.Backend == common.BackendMemory {
+		return newMemApprovalRepository(user, trans), nil
+	}
+
+	r, err := newDbApprovalRepository(user, trans)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+//
+// Creates new repository using existing db connection
+//
+func newDbApprovalRepository(user string, trans bool) (r *DbApprovalRepository, err error) {
+	log.Printf("Creating new repository for user: %s", user)
+
+	if db, err := common.GetDbSession(); err != nil {
+		return nil, err
+	} else {
+		dbmap := initRepository(db)
+		addApprovalTables(dbmap)
+		r = &DbApprovalRepository{
+			Repository{
+				Owner: user,
+				Db:    db,
+				Dbmap: dbmap,
+			},
+		}
+		if trans {
+			r.t, err = dbmap.Begin()
+			if err != nil {
+				return nil, err
+			}
+		}
+		r.m.Lock()
+	}
+
+	return
+}
+
+// associate the structures with the tables
+func addApprovalTables(dbmap *gorp.DbMap) {
+	dbmap.AddTableWithName(models.Approval{}, "SAP_APPROVALS").
+		SetKeys(false, "APPROVAL_ID")
+	dbmap.AddTableWithName(models.ApprovalStep{}, "SAP_APPROVALS_LOG")
+}
+
+func (r *DbApprovalRepository) Close() {
+	r.m.Unlock()
+}
+
+func (r *DbApprovalRepository) Commit() error {
+	if r.t != nil {
+		if err := r.t.Commit(); err != nil {
+			return fmt.Errorf("Commit error: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (r *DbApprovalRepository) Rollback() {
+	if r.t != nil {
+		err := r.t.Rollback()
+		if err != nil {
+			log.Printf("Rollback error: %s", err.Error())
+		}
+	}
+}
+
+var approvalColumns = []string{
+	"APPROVAL_ID",
+	"FROM_STATUS",
+	"INTO_STATUS",
+	"RELEASE_ID",
+	"STATE",
+	"REQUEST_DATE",
+	"REQUEST_OWNER",
+	"REQUEST_ROLE",
+	"REQUEST_COMMENT",
+	"REC_VERSION",
+}
+
+// Request with the steps ordered by date
+func presentApproval(a *models.Approval, steps []models.ApprovalStep) {
+	if !a.RequestDate.IsZero() {
+		a.RequestDateStr = a.RequestDate.Format(common.ModelDateFormat)
+	}
+
+	a.Steps = []models.ApprovalStep{}
+	for _, s := range steps {
+		if s.ApprovalId == a.ApprovalId {
+			s.StepDateStr = s.StepDate.Format(common.ModelDateFormat)
+			a.Steps = append(a.Steps, s)
+		}
+	}
+}
+
+// record the step of the request, must be called in transaction
+func (r *DbApprovalRepository) writeStep(id int64, step, role, comment string) (err error) {
+	s := &models.ApprovalStep{
+		ApprovalId: id,
+		Step:       step,
+		StepDate:   time.Now(),
+		StepOwner:  r.Owner,
+		StepRole:   role,
+		Comment:    comment,
+	}
+
+	if r.t != nil {
+		err = r.t.Insert(s)
+	} else {
+		err = r.Dbmap.Insert(s)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in insert to SAP_APPROVALS_LOG: %s", err.Error())
+	}
+
+	return
+}
+
+//
+// Insert the request of the release with the next id, the owner is the requesting user
+//
+func (r *DbApprovalRepository) Create(a *models.Approval) (err error) {
+	log.Printf("Inserting into SAP_APPROVALS: %#v", *a)
+
+	// the id is taken from the sequence so concurrent requests get own ones
+	query := dialect().NextId("SAP_APPROVALS", "APPROVAL_ID")
+	var id int64
+	if r.t != nil {
+		id, err = r.t.SelectInt(query)
+	} else {
+		id, err = r.Dbmap.SelectInt(query)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in select of the next APPROVAL_ID: %s", err.Error())
+	}
+
+	a.ApprovalId = id
+	a.State = models.ApprovalRequested
+	a.RequestDate, a.RequestOwner = time.Now(), r.Owner
+	a.RecVersion = 0
+
+	if r.t != nil {
+		err = r.t.Insert(a)
+	} else {
+		err = r.Dbmap.Insert(a)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in insert to SAP_APPROVALS: %s", err.Error())
+	}
+
+	if err = r.writeStep(a.ApprovalId, models.ApprovalRequested, a.RequestRole, a.Comment); err != nil {
+		return
+	}
+
+	presentApproval(a, []models.ApprovalStep{})
+
+	log.Printf("Inserted into SAP_APPROVALS: %#v", *a)
+
+	return
+}
+
+//
+// Approve or reject the request still pending, the count is 0 if it is not pending
+//
+func (r *DbApprovalRepository) Decide(a *models.Approval, state, role, comment string) (count int64, err error) {
+	log.Printf("Updating SAP_APPROVALS: %s %#v", state, *a)
+
+	stmt := dialect().Rebind(`
+UPDATE SAP_APPROVALS
+SET STATE = :1,
+    RELEASE_ID = :2,
+    REC_VERSION = REC_VERSION + 1
+WHERE APPROVAL_ID = :3
+  AND STATE = :4
+`)
+
+	var rs sql.Result
+	if r.t != nil {
+		rs, err = r.t.Exec(stmt, state, a.ReleaseId, a.ApprovalId, models.ApprovalRequested)
+	} else {
+		rs, err = r.Dbmap.Exec(stmt, state, a.ReleaseId, a.ApprovalId, models.ApprovalRequested)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Error in update SAP_APPROVALS: %s", err.Error())
+	}
+
+	if count, _ = rs.RowsAffected(); count == 0 {
+		log.Printf("Updated SAP_APPROVALS records: 0")
+		return
+	}
+
+	if err = r.writeStep(a.ApprovalId, state, role, comment); err != nil {
+		return 0, err
+	}
+
+	a.State = state
+	a.RecVersion++
+
+	log.Printf("Updated SAP_APPROVALS: %#v", *a)
+
+	return
+}
+
+//
+// Select the request with its steps by the id
+//
+func (r *DbApprovalRepository) ReadByPrimaryKey(a *models.Approval) (count int64, err error) {
+	log.Printf("Selecting from SAP_APPROVALS by key: %d", a.ApprovalId)
+
+	records, err := r.read("WHERE APPROVAL_ID = :approval_id", map[string]interface{}{"approval_id": a.ApprovalId})
+	if err != nil {
+		return 0, err
+	} else if len(records) == 0 {
+		log.Printf("Selected from SAP_APPROVALS records: 0")
+		return 0, nil
+	}
+
+	*a = records[0]
+	count = 1
+
+	log.Printf("Selected from SAP_APPROVALS: %#v", *a)
+
+	return
+}
+
+//
+// Select all requests in the state or all of them if the state is empty
+//
+func (r *DbApprovalRepository) ReadAll(state string) (approvals []models.Approval, err error) {
+	log.Printf("Selecting from SAP_APPROVALS in state: %s", state)
+
+	if state != "" {
+		approvals, err = r.read("WHERE STATE = :state", map[string]interface{}{"state": state})
+	} else {
+		approvals, err = r.read("", map[string]interface{}{})
+	}
+
+	if err == nil {
+		log.Printf("Selected from SAP_APPROVALS records: %d", len(approvals))
+	}
+
+	return
+}
+
+// requests matching the condition with their steps
+func (r *DbApprovalRepository) read(where string, binding map[string]interface{}) (approvals []models.Approval, err error) {
+	approvals = []models.Approval{}
+	query := fmt.Sprintf("SELECT %s FROM SAP_APPROVALS %s ORDER BY APPROVAL_ID", strings.Join(approvalColumns, ", "), where)
+	if r.t != nil {
+		_, err = r.t.Select(&approvals, query, binding)
+	} else {
+		_, err = r.Dbmap.Select(&approvals, query, binding)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error in select from SAP_APPROVALS: %s", err.Error())
+	}
+
+	steps := []models.ApprovalStep{}
+	query = fmt.Sprintf(`
+SELECT APPROVAL_ID, STEP, STEP_DATE, STEP_OWNER, STEP_ROLE, STEP_COMMENT
+  FROM SAP_APPROVALS_LOG
+ WHERE APPROVAL_ID IN (SELECT APPROVAL_ID FROM SAP_APPROVALS %s)
+ ORDER BY STEP_DATE`, where)
+	if r.t != nil {
+		_, err = r.t.Select(&steps, query, binding)
+	} else {
+		_, err = r.Dbmap.Select(&steps, query, binding)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error in select from SAP_APPROVALS_LOG: %s", err.Error())
+	}
+
+	for i := range approvals {
+		presentApproval(&approvals[i], steps)
+	}
+
+	return
+}
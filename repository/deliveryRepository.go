@@ -0,0 +1,239 @@
+/*
+
+PACKAGE: Data access layer for Delivery -> SAP_RELEASE_DELIVERIES table
+
+It keeps the status of the last delivery of the interface file
+of each release to SAP: the target, the file name and checksum,
+the number of attempts and the acknowledgement received from SAP.
+
+*/
+
+package repository
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	_ "gopkg.in/goracle.v2"
+	"gopkg.in/gorp.v2"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+//
+// Operations on SAP_RELEASE_DELIVERIES the controllers depend on
+//
+type DeliveryRepository interface {
+	Close()
+	Commit() error
+	Rollback()
+	Save(d *models.Delivery) error
+	ReadByPrimaryKey(d *models.Delivery) (int64, error)
+	ReadAll() ([]models.Delivery, error)
+}
+
+//
+// Pepository being handled by request
+//
+type DbDeliveryRepository struct {
+	Repository
+}
+
+//
+// Creates new repository on the configured backend
+//
+func NewDeliveryRepository(user string, trans bool) (DeliveryRepository, error) {
+	if common.AppConfig.Backend == common.BackendMemory {
+		return newMemDeliveryRepository(user, trans), nil
+	}
+
+	r, err := newDbDeliveryRepository(user, trans)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+//
+// Creates new repository using existing db connection
+//
+func newDbDeliveryRepository(user string, trans bool) (r *DbDeliveryRepository, err error) {
+	log.Printf("Creating new repository for user: %s", user)
+
+	if db, err := common.GetDbSession(); err != nil {
+		return nil, err
+	} else {
+		dbmap := initRepository(db)
+		addDeliveryTables(dbmap)
+		r = &DbDeliveryRepository{
+			Repository{
+				Owner: user,
+				Db:    db,
+				Dbmap: dbmap,
+			},
+		}
+		if trans {
+			r.t, err = dbmap.Begin()
+			if err != nil {
+				return nil, err
+			}
+		}
+		r.m.Lock()
+	}
+
+	return
+}
+
+// associate the structures with the tables
+func addDeliveryTables(dbmap *gorp.DbMap) {
+	dbmap.AddTableWithName(models.Delivery{}, "SAP_RELEASE_DELIVERIES").
+		SetKeys(false, "RELEASE_ID")
+}
+
+func (r *DbDeliveryRepository) Close() {
+	r.m.Unlock()
+}
+
+func (r *DbDeliveryRepository) Commit() error {
+	if r.t != nil {
+		if err := r.t.Commit(); err != nil {
+			return fmt.Errorf("Commit error: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (r *DbDeliveryRepository) Rollback() {
+	if r.t != nil {
+		err := r.t.Rollback()
+		if err != nil {
+			log.Printf("Rollback error: %s", err.Error())
+		}
+	}
+}
+
+var deliveryColumns = []string{
+	"RELEASE_ID",
+	"FORMAT",
+	"TARGET",
+	"FILE_NAME",
+	"CHECKSUM",
+	"STATUS",
+	"ATTEMPTS",
+	"MESSAGE",
+	"SENT_DATE",
+	"ACK_DATE",
+	"ACK_OWNER",
+	"REC_VERSION",
+}
+
+// Take care of dates presentation
+func presentDelivery(d *models.Delivery) {
+	d.SentDateStr, d.AckDateStr = "", ""
+	if !d.SentDate.IsZero() {
+		d.SentDateStr = d.SentDate.Format(common.ModelDateFormat)
+	}
+	if !d.AckDate.IsZero() {
+		d.AckDateStr = d.AckDate.Format(common.ModelDateFormat)
+	}
+}
+
+//
+// Update the delivery of the release or insert it when it is the first one
+//
+func (r *DbDeliveryRepository) Save(d *models.Delivery) (err error) {
+	log.Printf("Saving in SAP_RELEASE_DELIVERIES: %#v", *d)
+
+	d.RecVersion++
+
+	var count int64
+	if r.t != nil {
+		count, err = r.t.Update(d)
+	} else {
+		count, err = r.Dbmap.Update(d)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in update SAP_RELEASE_DELIVERIES: %s", err.Error())
+	}
+
+	if count == 0 {
+		d.RecVersion = 0
+		if r.t != nil {
+			err = r.t.Insert(d)
+		} else {
+			err = r.Dbmap.Insert(d)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Error in insert to SAP_RELEASE_DELIVERIES: %s", err.Error())
+		}
+	}
+
+	presentDelivery(d)
+
+	log.Printf("Saved in SAP_RELEASE_DELIVERIES: %#v", *d)
+
+	return
+}
+
+//
+// Select the delivery of the release
+//
+func (r *DbDeliveryRepository) ReadByPrimaryKey(d *models.Delivery) (count int64, err error) {
+	log.Printf("Selecting from SAP_RELEASE_DELIVERIES by key: %s", d.ReleaseId)
+
+	records, err := r.read("WHERE RELEASE_ID = :release_id", map[string]interface{}{"release_id": d.ReleaseId})
+	if err != nil {
+		return 0, err
+	} else if len(records) == 0 {
+		log.Printf("Selected from SAP_RELEASE_DELIVERIES records: 0")
+		return 0, nil
+	}
+
+	*d = records[0]
+	count = 1
+
+	log.Printf("Selected from SAP_RELEASE_DELIVERIES: %#v", *d)
+
+	return
+}
+
+//
+// Select the deliveries of all releases
+//
+func (r *DbDeliveryRepository) ReadAll() (deliveries []models.Delivery, err error) {
+	log.Printf("Selecting from SAP_RELEASE_DELIVERIES")
+
+	if deliveries, err = r.read("", map[string]interface{}{}); err == nil {
+		log.Printf("Selected from SAP_RELEASE_DELIVERIES records: %d", len(deliveries))
+	}
+
+	return
+}
+
+// deliveries matching the condition ordered by release
+func (r *DbDeliveryRepository) read(where string, binding map[string]interface{}) (deliveries []models.Delivery, err error) {
+	deliveries = []models.Delivery{}
+	query := fmt.Sprintf("SELECT %s FROM SAP_RELEASE_DELIVERIES %s ORDER BY RELEASE_ID", strings.Join(deliveryColumns, ", "), where)
+	if r.t != nil {
+		_, err = r.t.Select(&deliveries, query, binding)
+	} else {
+		_, err = r.Dbmap.Select(&deliveries, query, binding)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error in select from SAP_RELEASE_DELIVERIES: %s", err.Error())
+	}
+
+	// Take care of dates presentation
+	for i := range deliveries {
+		presentDelivery(&deliveries[i])
+	}
+
+	return
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/gorp.v2"
+	"sam-api/common"
+)
+
+//
+// SQL differences of the supported databases. The statements are written
+// in Oracle syntax with positional :1, :2 binds and they are rebound
+// to the target database before execution. Named :name binds are
+// expanded by GORP for all dialects.
+//
+type sqlDialect interface {
+	Gorp() gorp.Dialect
+	Nvl(expr, value string) string
+	Sysdate() string
+	Rebind(query string) string
+	Page(query string, offset, limit int) string
+	NextId(table, column string) string
+}
+
+type oracleDialect struct{}
+
+func (oracleDialect) Gorp() gorp.Dialect {
+	return &gorp.OracleDialect{}
+}
+
+func (oracleDialect) Nvl(expr, value string) string {
+	return "NVL(" + expr + ", " + value + ")"
+}
+
+func (oracleDialect) Sysdate() string {
+	return "SYSDATE"
+}
+
+func (oracleDialect) Rebind(query string) string {
+	return query
+}
+
+//
+// Page of the ordered query numbered by ROWNUM as the row limiting clause
+// is not known before Oracle 12c, the rows get the extra column PAGE_ROW
+//
+func (oracleDialect) Page(query string, offset, limit int) string {
+	if offset <= 0 && limit <= 0 {
+		return query
+	}
+
+	query = fmt.Sprintf("SELECT P.*, ROWNUM PAGE_ROW FROM (%s) P", query)
+	if limit > 0 {
+		query += fmt.Sprintf(" WHERE ROWNUM <= %d", offset + limit)
+	}
+
+	return fmt.Sprintf("SELECT * FROM (%s) WHERE PAGE_ROW > %d", query, offset)
+}
+
+// the sequence of the table is named with suffix _SEQ
+func (oracleDialect) NextId(table, column string) string {
+	return "SELECT " + table + "_SEQ.NEXTVAL FROM DUAL"
+}
+
+type postgresDialect struct{}
+
+// positional Oracle binds :1, :2, ...
+var positionalBind = regexp.MustCompile(`:([0-9]+)`)
+
+//
+// Table names are mapped in upper case like in Oracle but PostgreSQL
+// folds unquoted names to lower case so GORP must quote them that way
+//
+type postgresGorpDialect struct {
+	gorp.PostgresDialect
+}
+
+func (d postgresGorpDialect) QuotedTableForQuery(schema string, table string) string {
+	return d.PostgresDialect.QuotedTableForQuery(strings.ToLower(schema), strings.ToLower(table))
+}
+
+func (postgresDialect) Gorp() gorp.Dialect {
+	return postgresGorpDialect{gorp.PostgresDialect{LowercaseFields: true}}
+}
+
+func (postgresDialect) Nvl(expr, value string) string {
+	return "COALESCE(" + expr + ", " + value + ")"
+}
+
+func (postgresDialect) Sysdate() string {
+	return "CURRENT_TIMESTAMP"
+}
+
+func (postgresDialect) Rebind(query string) string {
+	return positionalBind.ReplaceAllString(query, "$$$1")
+}
+
+func (postgresDialect) Page(query string, offset, limit int) string {
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	if offset > 0 {
+		query += fmt.Sprintf(" OFFSET %d", offset)
+	}
+
+	return query
+}
+
+// the column is SERIAL so it has own sequence
+func (postgresDialect) NextId(table, column string) string {
+	return "SELECT nextval(pg_get_serial_sequence('" + strings.ToLower(table) + "', '" + strings.ToLower(column) + "'))"
+}
+
+//
+// Dialect of the configured backend
+//
+func dialect() sqlDialect {
+	if common.AppConfig.Backend == common.BackendPostgres {
+		return postgresDialect{}
+	}
+
+	return oracleDialect{}
+}
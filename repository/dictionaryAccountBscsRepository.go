@@ -26,17 +26,41 @@ import (
 	"sam-api/models"
 )
 
+//
+// Operations on GLACCOUNTS the controllers depend on
+//
+type DictionaryAccountBscsRepository interface {
+	Close()
+	ReadAll(q *common.ListQuery) ([]models.DictionaryAccountBscs, int64, error)
+}
+
 //
 // Pepository being handled by request
 //
-type DictionaryAccountBscsRepository struct {
+type DbDictionaryAccountBscsRepository struct {
 	Repository
 }
 
+//
+// Creates new repository on the configured backend
+//
+func NewDictionaryAccountBscsRepository(user string) (DictionaryAccountBscsRepository, error) {
+	if common.AppConfig.Backend == common.BackendMemory {
+		return newMemDictionaryAccountBscsRepository(user), nil
+	}
+
+	r, err := newDbDictionaryAccountBscsRepository(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
 //
 // Creates new repository using existing db connection
 //
-func NewDictionaryAccountBscsRepository(user string) (r *DictionaryAccountBscsRepository, err error) {
+func newDbDictionaryAccountBscsRepository(user string) (r *DbDictionaryAccountBscsRepository, err error) {
 	log.Printf("Creating new repository: user:" + user)
 
 	if db, err := common.GetDbSession(); err != nil {
@@ -45,7 +69,7 @@ func NewDictionaryAccountBscsRepository(user string) (r *DictionaryAccountBscsRe
 		dbmap := initRepository(db)
 		dbmap.AddTableWithName(models.DictionaryAccountBscs{}, "GLACCOUNTS").
 			SetKeys(false, "GLACODE")
-		r = &DictionaryAccountBscsRepository{			
+		r = &DbDictionaryAccountBscsRepository{			
 			Repository{
 				Owner: user,
 				Db:    db,
@@ -58,14 +82,14 @@ func NewDictionaryAccountBscsRepository(user string) (r *DictionaryAccountBscsRe
 	return
 }
 
-func (r *DictionaryAccountBscsRepository) Close() {
+func (r *DbDictionaryAccountBscsRepository) Close() {
 	r.m.Unlock()
 }
 
 //
 // Select all records from the backend table, no use of ORP Get as it returns single record
 //
-func (r *DictionaryAccountBscsRepository) ReadAll() (entries []models.DictionaryAccountBscs, err error) {
+func (r *DbDictionaryAccountBscsRepository) ReadAll(q *common.ListQuery) (entries []models.DictionaryAccountBscs, total int64, err error) {
 	log.Printf("Selecting from: GLACCOUNTS")
 
 	columns := []string{
@@ -80,11 +104,30 @@ func (r *DictionaryAccountBscsRepository) ReadAll() (entries []models.Dictionary
 	}
 	query := fmt.Sprintf("SELECT %s FROM GLACCOUNTS", strings.Join(columns,","))
 
+	// Apply filters, sorting and paging of the list
+	if q == nil {
+		q = &common.ListQuery{}
+	}
+	binding := map[string]interface{}{}
+	query, count, err := listQuery(query, binding, q, dictionaryAccountBscsQueryColumns, "GLACODE")
+	if err != nil {
+		return nil, 0, err
+	}
+
 	// do query
 	records := []models.DictionaryAccountBscs{}
-	_, err = r.Dbmap.Select(&records, query)
+	err = selectPage(r.Dbmap, &records, query, binding)
 	if err != nil {
-		return nil, fmt.Errorf("Error in select from GLACCOUNTS: %s", err.Error())
+		return nil, 0, fmt.Errorf("Error in select from GLACCOUNTS: %s", err.Error())
+	}
+
+	// Count all records of the list if only a page of it was selected
+	total = int64(len(records))
+	if q.Limit > 0 || q.Offset > 0 {
+		total, err = r.Dbmap.SelectInt(count, binding)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error in select from GLACCOUNTS: %s", err.Error())
+		}
 	}
 	
 	// Take care of dates presentation
@@ -99,7 +142,7 @@ func (r *DictionaryAccountBscsRepository) ReadAll() (entries []models.Dictionary
 	
 	entries = records
 
-	log.Printf("Selected from GLACCOUNTS records: %d %#v", len(records), records)
+	log.Printf("Selected from GLACCOUNTS records: %d of %d %#v", len(records), total, records)
 	
 	return
 }
@@ -10,6 +10,7 @@ by the context.
 The following CRUD access methods are available:
 
   - ReadAll
+  - Merge
 
 */
 
@@ -28,17 +29,44 @@ import (
 	"sam-api/models"
 )
 
+//
+// Operations on SAP_OFI_ACCOUNTS the controllers depend on
+//
+type DictionaryAccountSapRepository interface {
+	Close()
+	Create(d *models.DictionaryAccountSap) error
+	ReadAll(q *common.ListQuery) ([]models.DictionaryAccountSap, int64, error)
+	DeleteAll() (int64, error)
+	Merge(imported map[string]string, apply bool) (*models.DictionaryAccountSapMerge, error)
+}
+
 //
 // Pepository being handled by request
 //
-type DictionaryAccountSapRepository struct {
+type DbDictionaryAccountSapRepository struct {
 	Repository
 }
 
+//
+// Creates new repository on the configured backend
+//
+func NewDictionaryAccountSapRepository(user string) (DictionaryAccountSapRepository, error) {
+	if common.AppConfig.Backend == common.BackendMemory {
+		return newMemDictionaryAccountSapRepository(user), nil
+	}
+
+	r, err := newDbDictionaryAccountSapRepository(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
 //
 // Creates new repository using existing db connection
 //
-func NewDictionaryAccountSapRepository(user string) (r *DictionaryAccountSapRepository, err error) {
+func newDbDictionaryAccountSapRepository(user string) (r *DbDictionaryAccountSapRepository, err error) {
 	log.Printf("Creating new repository: user:" + user)
 
 	if db, err := common.GetDbSession(); err != nil {
@@ -47,7 +75,7 @@ func NewDictionaryAccountSapRepository(user string) (r *DictionaryAccountSapRepo
 		dbmap := initRepository(db)
 		dbmap.AddTableWithName(models.DictionaryAccountSap{}, "SAP_OFI_ACCOUNTS").
 			SetKeys(false, "SAP_OFI_ACCOUNT")
-		r = &DictionaryAccountSapRepository{
+		r = &DbDictionaryAccountSapRepository{
 			Repository{
 				Owner: user,
 				Db:    db,
@@ -60,14 +88,25 @@ func NewDictionaryAccountSapRepository(user string) (r *DictionaryAccountSapRepo
 	return
 }
 
-func (r *DictionaryAccountSapRepository) Close() {
+func (r *DbDictionaryAccountSapRepository) Close() {
 	r.m.Unlock()
 }
 
+var dictionaryAccountSapColumns = []string{
+	"SAP_OFI_ACCOUNT",
+	"NAME",
+	"STATUS",
+	"ENTRY_DATE",
+	"ENTRY_OWNER",
+	"UPDATE_DATE",
+	"UPDATE_OWNER",
+	"REC_VERSION",
+}
+
 //
 // Insert new record to the backend table
 //
-func (r *DictionaryAccountSapRepository) Create(d *models.DictionaryAccountSap) (err error) {
+func (r *DbDictionaryAccountSapRepository) Create(d *models.DictionaryAccountSap) (err error) {
 	log.Printf("Inserting to SAP_OFI_ACCOUNTS: %#v", *d)
 
 	d.EntryDate = time.Now()
@@ -89,26 +128,35 @@ func (r *DictionaryAccountSapRepository) Create(d *models.DictionaryAccountSap)
 //
 // Select all records from the backend table, no use of ORP Get as it returns single record
 //
-func (r *DictionaryAccountSapRepository) ReadAll() (entries []models.DictionaryAccountSap, err error) {
+func (r *DbDictionaryAccountSapRepository) ReadAll(q *common.ListQuery) (entries []models.DictionaryAccountSap, total int64, err error) {
 	log.Printf("Selecting from SAP_OFI_ACCOUNTS")
 
-	columns := []string{
-		"SAP_OFI_ACCOUNT",
-		"NAME",
-		"STATUS",
-		"ENTRY_DATE",
-		"ENTRY_OWNER",
-		"UPDATE_DATE",
-		"UPDATE_OWNER",
-		"REC_VERSION",
-	}	
-	query := fmt.Sprintf("SELECT %s FROM SAP_OFI_ACCOUNTS", strings.Join(columns, ","))
+	query := fmt.Sprintf("SELECT %s FROM SAP_OFI_ACCOUNTS", strings.Join(dictionaryAccountSapColumns, ","))
+
+	// Apply filters, sorting and paging of the list
+	if q == nil {
+		q = &common.ListQuery{}
+	}
+	binding := map[string]interface{}{}
+	query, count, err := listQuery(query, binding, q, dictionaryAccountSapQueryColumns, "SAP_OFI_ACCOUNT")
+	if err != nil {
+		return nil, 0, err
+	}
 
 	// do query
 	records := []models.DictionaryAccountSap{}
-	_, err = r.Dbmap.Select(&records, query)
+	err = selectPage(r.Dbmap, &records, query, binding)
 	if err != nil {
-		return nil, fmt.Errorf("Error in select from SAP_OFI_ACCOUNTS: " + err.Error())
+		return nil, 0, fmt.Errorf("Error in select from SAP_OFI_ACCOUNTS: %s", err.Error())
+	}
+
+	// Count all records of the list if only a page of it was selected
+	total = int64(len(records))
+	if q.Limit > 0 || q.Offset > 0 {
+		total, err = r.Dbmap.SelectInt(count, binding)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error in select from SAP_OFI_ACCOUNTS: %s", err.Error())
+		}
 	}
 	
 	// Take care of dates presentation
@@ -123,7 +171,7 @@ func (r *DictionaryAccountSapRepository) ReadAll() (entries []models.DictionaryA
 	
 	entries = records
 
-	log.Printf("Selected from SAP_OFI_ACCOUNTS records: %d %#v", len(records), records)
+	log.Printf("Selected from SAP_OFI_ACCOUNTS records: %d of %d %#v", len(records), total, records)
 	
 	return
 }
@@ -131,7 +179,7 @@ func (r *DictionaryAccountSapRepository) ReadAll() (entries []models.DictionaryA
 //
 // Delete all records from resource
 //
-func (r *DictionaryAccountSapRepository) DeleteAll() (count int64, err error) {
+func (r *DbDictionaryAccountSapRepository) DeleteAll() (count int64, err error) {
 	log.Printf("Deleting from: SAP_OFI_ACCOUNTS")
 
 	var rs sql.Result
@@ -149,3 +197,77 @@ func (r *DictionaryAccountSapRepository) DeleteAll() (count int64, err error) {
 	
 	return
 }
+
+//
+// Merge the imported accounts with their names into the dictionary in one
+// transaction: the new ones are inserted, the renamed or inactive ones are
+// updated and the ones missing are deactivated, only the difference is
+// returned if the merge is not applied
+//
+func (r *DbDictionaryAccountSapRepository) Merge(imported map[string]string, apply bool) (m *models.DictionaryAccountSapMerge, err error) {
+	log.Printf("Merging into SAP_OFI_ACCOUNTS records: %d apply: %t", len(imported), apply)
+
+	t, err := r.Dbmap.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	current := []models.DictionaryAccountSap{}
+	query := fmt.Sprintf("SELECT %s FROM SAP_OFI_ACCOUNTS", strings.Join(dictionaryAccountSapColumns, ","))
+	if _, err = t.Select(&current, query); err != nil {
+		t.Rollback()
+		return nil, fmt.Errorf("Error in select from SAP_OFI_ACCOUNTS: %s", err.Error())
+	}
+
+	m = models.MergeDictionaryAccountSap(current, imported)
+	if !apply {
+		t.Rollback()
+		return
+	}
+
+	now := time.Now()
+	for _, c := range m.Inserted {
+		d := &models.DictionaryAccountSap{
+			Account:    c.Account,
+			Name:       c.Name,
+			Status:     models.DictionaryAccountSapActive,
+			EntryDate:  now,
+			EntryOwner: r.Owner,
+		}
+		if err = t.Insert(d); err != nil {
+			t.Rollback()
+			return nil, fmt.Errorf("Error in insert to SAP_OFI_ACCOUNTS: %s", err.Error())
+		}
+	}
+
+	stmt := dialect().Rebind(`
+UPDATE SAP_OFI_ACCOUNTS
+SET NAME = :1,
+    STATUS = :2,
+    UPDATE_DATE = :3,
+    UPDATE_OWNER = :4,
+    REC_VERSION = REC_VERSION + 1
+WHERE SAP_OFI_ACCOUNT = :5
+`)
+	for _, c := range m.Updated {
+		if _, err = t.Exec(stmt, c.Name, models.DictionaryAccountSapActive, now, r.Owner, c.Account); err != nil {
+			t.Rollback()
+			return nil, fmt.Errorf("Error in update SAP_OFI_ACCOUNTS: %s", err.Error())
+		}
+	}
+	for _, c := range m.Removed {
+		if _, err = t.Exec(stmt, c.Name, models.DictionaryAccountSapInactive, now, r.Owner, c.Account); err != nil {
+			t.Rollback()
+			return nil, fmt.Errorf("Error in update SAP_OFI_ACCOUNTS: %s", err.Error())
+		}
+	}
+
+	if err = t.Commit(); err != nil {
+		return nil, fmt.Errorf("Error in commit of SAP_OFI_ACCOUNTS: %s", err.Error())
+	}
+
+	log.Printf("Merged into SAP_OFI_ACCOUNTS inserted: %d updated: %d removed: %d unchanged: %d",
+		len(m.Inserted), len(m.Updated), len(m.Removed), m.Unchanged)
+
+	return
+}
@@ -27,17 +27,48 @@ import (
 	"sam-api/models"
 )
 
+//
+// Operations on CUSTOMER_SEGMENT the controllers depend on
+//
+type DictionarySegmentRepository interface {
+	Close()
+	Commit() error
+	Rollback()
+	Create(s *models.DictionarySegment) error
+	ReadAll() ([]models.DictionarySegment, error)
+	DeleteAll() (int64, error)
+	UpdateByPrimaryKey(s *models.DictionarySegment) (int64, error)
+	DeleteByPrimaryKey(s *models.DictionarySegment) (int64, error)
+	UpdateAttributeByPrimaryKey(s *models.DictionarySegment, attribute string, value interface{}) (int64, error)
+}
+
 //
 // Pepository being handled by request
 //
-type DictionarySegmentRepository struct {
+type DbDictionarySegmentRepository struct {
 	Repository
 }
 
+//
+// Creates new repository on the configured backend
+//
+func NewDictionarySegmentRepository(user string, trans bool) (DictionarySegmentRepository, error) {
+	if common.AppConfig.Backend == common.BackendMemory {
+		return newMemDictionarySegmentRepository(user, trans), nil
+	}
+
+	r, err := newDbDictionarySegmentRepository(user, trans)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
 //
 // Creates new repository using existing db connection
 //
-func NewDictionarySegmentRepository(user string, trans bool) (r *DictionarySegmentRepository, err error) {
+func newDbDictionarySegmentRepository(user string, trans bool) (r *DbDictionarySegmentRepository, err error) {
 	log.Printf("Creating new repository: user:" + user)
 
 	if db, err := common.GetDbSession(); err != nil {
@@ -46,7 +77,7 @@ func NewDictionarySegmentRepository(user string, trans bool) (r *DictionarySegme
 		dbmap := initRepository(db)
 		dbmap.AddTableWithName(models.DictionarySegment{}, "CUSTOMER_SEGMENT").
 			SetKeys(false, "CSTRADEREF")
-		r = &DictionarySegmentRepository{
+		r = &DbDictionarySegmentRepository{
 			Repository{
 				Owner: user,
 				Db:    db,
@@ -65,20 +96,21 @@ func NewDictionarySegmentRepository(user string, trans bool) (r *DictionarySegme
 	return
 }
 
-func (r *DictionarySegmentRepository) Close() {
+func (r *DbDictionarySegmentRepository) Close() {
 	r.m.Unlock()
 }
 
-func (r *DictionarySegmentRepository) Commit() {
+func (r *DbDictionarySegmentRepository) Commit() error {
 	if r.t != nil {
-		err := r.t.Commit()
-		if err != nil {
-			log.Printf("Commit error: %s", err.Error())
+		if err := r.t.Commit(); err != nil {
+			return fmt.Errorf("Commit error: %s", err.Error())
 		}
 	}
+
+	return nil
 }
 
-func (r *DictionarySegmentRepository) Rollback() {
+func (r *DbDictionarySegmentRepository) Rollback() {
 	if r.t != nil {
 		err := r.t.Rollback()
 		if err != nil {
@@ -90,7 +122,7 @@ func (r *DictionarySegmentRepository) Rollback() {
 //
 // Insert new record to the backend table
 //
-func (r *DictionarySegmentRepository) Create(s *models.DictionarySegment) (err error) {
+func (r *DbDictionarySegmentRepository) Create(s *models.DictionarySegment) (err error) {
 	log.Printf("Inserting to CUSTOMER_SEGMENT: %#v", *s)
 
 	s.EntryDate = time.Now()
@@ -116,7 +148,7 @@ func (r *DictionarySegmentRepository) Create(s *models.DictionarySegment) (err e
 //
 // Select one or all dbrecords from the backend table, no use of ORP Get as it returns single record
 //
-func (r *DictionarySegmentRepository) ReadAll() (segments []models.DictionarySegment, err error) {
+func (r *DbDictionarySegmentRepository) ReadAll() (segments []models.DictionarySegment, err error) {
 	log.Printf("Selecting from CUSTOMER_SEGMENT")
 
 	columns := []string{
@@ -158,7 +190,7 @@ func (r *DictionarySegmentRepository) ReadAll() (segments []models.DictionarySeg
 //
 // Delete all records from resource
 //
-func (r *DictionarySegmentRepository) DeleteAll() (count int64, err error) {
+func (r *DbDictionarySegmentRepository) DeleteAll() (count int64, err error) {
 	log.Printf("Deleting from CUSTOMER_SEGMENT")
 
 	var rs sql.Result
@@ -180,7 +212,7 @@ func (r *DictionarySegmentRepository) DeleteAll() (count int64, err error) {
 //
 // Update one record to the backend table
 //
-func (r *DictionarySegmentRepository) UpdateByPrimaryKey(s *models.DictionarySegment) (count int64, err error) {
+func (r *DbDictionarySegmentRepository) UpdateByPrimaryKey(s *models.DictionarySegment) (count int64, err error) {
 	log.Printf("Updating CUSTOMER_SEGMENT: %#v", *s)
 
 	record := *s
@@ -206,7 +238,7 @@ func (r *DictionarySegmentRepository) UpdateByPrimaryKey(s *models.DictionarySeg
 //
 // Delete some records from resource
 //
-func (r *DictionarySegmentRepository) DeleteByPrimaryKey(s *models.DictionarySegment) (count int64, err error) {
+func (r *DbDictionarySegmentRepository) DeleteByPrimaryKey(s *models.DictionarySegment) (count int64, err error) {
 	log.Printf("Deleting from CUSTOMER_SEGMENT: %#v", *s)
 
 	// Do delete by primary key
@@ -228,7 +260,7 @@ func (r *DictionarySegmentRepository) DeleteByPrimaryKey(s *models.DictionarySeg
 //
 // Update one attribute of the record in resource using primary key
 //
-func (r *DictionarySegmentRepository) UpdateAttributeByPrimaryKey(a *models.DictionarySegment, attribute string, value interface{}) (count int64, err error) {
+func (r *DbDictionarySegmentRepository) UpdateAttributeByPrimaryKey(a *models.DictionarySegment, attribute string, value interface{}) (count int64, err error) {
 	log.Printf("Updating CUSTOMER_SEGMENT: %s <- %v %T %#v with key: %#v", attribute, value, value, value, *a)
 
 	// make dynamic sql statement
@@ -251,7 +283,7 @@ WHERE CSTRADEREF = :4
 		return 0, fmt.Errorf("No column name for attribute: %s", attribute)
 	}
 	
-	stmt = fmt.Sprintf(stmt, colname)
+	stmt = dialect().Rebind(fmt.Sprintf(stmt, colname))
 
 	// run it
 	var rs sql.Result
@@ -0,0 +1,588 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+//
+// Pepository being handled by request
+//
+type MemAccountRepository struct {
+	memRepository
+}
+
+//
+// Creates new repository using in-memory tables
+//
+func newMemAccountRepository(user string, trans bool) (r *MemAccountRepository) {
+	log.Printf("Creating new in-memory repository for user: %s", user)
+
+	r = &MemAccountRepository{
+		memRepository{
+			Owner: user,
+			tx:    newMemTransaction(trans),
+		},
+	}
+	r.m.Lock()
+
+	return
+}
+
+func accountKeyOf(a *models.Account) accountKey {
+	return accountKey{a.Status, memRelease(a.ReleaseId), a.BscsAccount}
+}
+
+// emulation of the after triggers, must be called with store locked
+func (r *MemAccountRepository) writeLog(opcode string, a models.Account) {
+	n := len(store.accountLogs)
+	store.accountLogs = append(store.accountLogs, models.AccountLog{OpCode: opcode, OpDate: time.Now(), Account: a})
+	r.journal(func() {
+		store.accountLogs = store.accountLogs[:n]
+	})
+}
+
+// store row under the key, must be called with store locked
+func (r *MemAccountRepository) put(k accountKey, a models.Account) {
+	old, exists := store.accounts[k]
+	store.accounts[k] = a
+	r.journal(func() {
+		if exists {
+			store.accounts[k] = old
+		} else {
+			delete(store.accounts, k)
+		}
+	})
+}
+
+// remove row with the key, must be called with store locked
+func (r *MemAccountRepository) remove(k accountKey) {
+	old, exists := store.accounts[k]
+	if !exists {
+		return
+	}
+	delete(store.accounts, k)
+	r.journal(func() {
+		store.accounts[k] = old
+	})
+}
+
+// Take care of dates presentation
+func presentAccount(a *models.Account) {
+	if !a.ValidFromDate.IsZero() {
+		a.ValidFromDateStr = a.ValidFromDate.Format(common.CutOffDateFormat)
+	}
+	if !a.EntryDate.IsZero() {
+		a.EntryDateStr = a.EntryDate.Format(common.ModelDateFormat)
+	}
+	if !a.UpdateDate.IsZero() {
+		a.UpdateDateStr = a.UpdateDate.Format(common.ModelDateFormat)
+	}
+	if !a.ReleaseDate.IsZero() {
+		a.ReleaseDateStr = a.ReleaseDate.Format(common.ModelDateFormat)
+	}
+}
+
+//
+// Insert new record to the resource SAP_ACCOUNTS
+//
+func (r *MemAccountRepository) Create(a *models.Account) (err error) {
+	log.Printf("Inserting to SAP_ACCOUNTS: %s %#v", r.Owner, *a)
+
+	// default value
+	if a.ReleaseId == "" {
+		a.ReleaseId = "0"
+	}
+
+	// default value
+	if a.Status == "" {
+		a.Status = "W"
+	}
+
+	if a.ValidFromDateStr != "" {
+		a.ValidFromDate, err = time.Parse(common.CutOffDateFormat, a.ValidFromDateStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	a.EntryDate = time.Now()
+	a.EntryOwner = r.Owner
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := accountKeyOf(a)
+	if _, exists := store.accounts[k]; exists {
+		return fmt.Errorf("Error in insert to SAP_ACCOUNTS: unique constraint violated: %#v", k)
+	}
+	if err = memCheckValidDate(a.Status, a.ValidFromDate); err != nil {
+		return fmt.Errorf("Error in insert to SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	r.put(k, *a)
+	r.writeLog("I", *a)
+
+	a.EntryDateStr = a.EntryDate.Format(common.ModelDateFormat)
+
+	log.Printf("Inserted to SAP_ACCOUNTS: %#v", *a)
+
+	return
+}
+
+//
+// Select some records from the resource by status and release or all active ones
+//
+func (r *MemAccountRepository) ReadBulkByPartialKey(a *models.Account, q *common.ListQuery) (accounts []models.Account, total int64, err error) {
+	log.Printf("Selecting from SAP_ACCOUNTS: %#v", *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.Account{}
+	if a.Status != "" && a.ReleaseId != "" {
+		if _, err = strconv.ParseInt(a.ReleaseId, 10, 64); err != nil {
+			return nil, 0, fmt.Errorf("Error in select from SAP_ACCOUNTS: ORA-01722: invalid number")
+		}
+		release := memRelease(a.ReleaseId)
+		for k, v := range store.accounts {
+			if k.Status == a.Status && k.ReleaseId == release {
+				records = append(records, v)
+			}
+		}
+	} else {
+		last := make(map[string]int64)
+		for k := range store.accounts {
+			if id, _ := strconv.ParseInt(k.ReleaseId, 10, 64); id > last[k.BscsAccount] {
+				last[k.BscsAccount] = id
+			}
+		}
+		for k, v := range store.accounts {
+			id, _ := strconv.ParseInt(k.ReleaseId, 10, 64)
+			if ((k.Status == "W" || k.Status == "C") && id == 0) ||
+				(k.Status == "P" && id == last[k.BscsAccount]) {
+				records = append(records, v)
+			}
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].BscsAccount < records[j].BscsAccount ||
+			(records[i].BscsAccount == records[j].BscsAccount && records[i].Status < records[j].Status)
+	})
+
+	// Apply filters, sorting and paging of the list
+	if q == nil {
+		q = &common.ListQuery{}
+	}
+	if total, err = memListQuery(&records, q, accountQueryColumns); err != nil {
+		return nil, 0, err
+	}
+
+	for i := range records {
+		presentAccount(&records[i])
+	}
+
+	accounts = records
+
+	log.Printf("Selected from SAP_ACCOUNTS records: %d of %d %#v", len(records), total, records)
+
+	return
+}
+
+//
+// Select one record from the resource SAP_ACCOUNTS using primary key
+//
+func (r *MemAccountRepository) ReadByPrimaryKey(a *models.Account) (count int64, err error) {
+	log.Printf("Selecting from SAP_ACCOUNTS by key: %#v", *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if record, exists := store.accounts[accountKeyOf(a)]; exists {
+		*a = record
+		presentAccount(a)
+		count = 1
+	}
+
+	log.Printf("Selected from SAP_ACCOUNTS records: %d", count)
+
+	return
+}
+
+//
+// Select REC_VERSION of one record using primary key
+//
+func (r *MemAccountRepository) ReadVersionByPrimaryKey(a *models.Account) (version int, count int64, err error) {
+	log.Printf("Selecting REC_VERSION from SAP_ACCOUNTS: %#v", *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if record, exists := store.accounts[accountKeyOf(a)]; exists {
+		version, count = record.RecVersion, 1
+	}
+
+	log.Printf("Selected REC_VERSION from SAP_ACCOUNTS: %d, count: %d", version, count)
+
+	return
+}
+
+//
+// Update one record in resource SAP_ACCOUNTS using primary key
+//
+func (r *MemAccountRepository) UpdateByPrimaryKey(a *models.Account) (count int64, err error) {
+	log.Printf("Updating SAP_ACCOUNTS: %#v", *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := accountKeyOf(a)
+	record, exists := store.accounts[k]
+	if !exists {
+		return 0, nil
+	}
+
+	record.OfiSapAccount = a.OfiSapAccount
+	record.ValidFromDate = a.ValidFromDate
+	record.VatCodeInd = a.VatCodeInd
+	record.OfiSapWbsCode = a.OfiSapWbsCode
+	record.CitMarkerVatFlag = a.CitMarkerVatFlag
+	record.UpdateDate = time.Now()
+	record.UpdateOwner = r.Owner
+	record.RecVersion++
+	if err = memCheckValidDate(record.Status, record.ValidFromDate); err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	r.put(k, record)
+	r.writeLog("U", record)
+	count = 1
+
+	log.Printf("Updated SAP_ACCOUNTS records: %d", count)
+
+	return
+}
+
+//
+// Update one attribute of the record in resource SAP_ACCOUNTS using primary key
+//
+func (r *MemAccountRepository) UpdateAttributeByPrimaryKey(a *models.Account, attribute string, value interface{}) (count int64, err error) {
+	log.Printf("Updating SAP_ACCOUNTS: %s <- %v %T %#v with key: %#v", attribute, value, value, value, *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := accountKeyOf(a)
+	record, exists := store.accounts[k]
+
+	switch attribute {
+	case "status":
+		record.Status = memString(value)
+	case "releaseId":
+		record.ReleaseId = memRelease(memString(value))
+	case "bscsAccount":
+		record.BscsAccount = memString(value)
+	case "ofiSapAccount":
+		record.OfiSapAccount = memString(value)
+	case "validFromDate":
+		record.ValidFromDate, err = memTime(value)
+	case "vatCodeInd":
+		record.VatCodeInd = memString(value)
+	case "ofiSapWbsCode":
+		record.OfiSapWbsCode = memString(value)
+	case "citMarkerVatFlag":
+		record.CitMarkerVatFlag, err = memInt(value)
+	case "recVersion":
+		record.RecVersion, err = memInt(value)
+	default:
+		return 0, fmt.Errorf("No column name for attribute: %s", attribute)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	record.UpdateDate = time.Now()
+	record.UpdateOwner = r.Owner
+	record.RecVersion++
+	if err = memCheckValidDate(record.Status, record.ValidFromDate); err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	// key may be changed as well
+	nk := accountKeyOf(&record)
+	if nk != k {
+		if _, exists := store.accounts[nk]; exists {
+			return 0, fmt.Errorf("Error in update of SAP_ACCOUNTS: unique constraint violated: %#v", nk)
+		}
+		r.remove(k)
+	}
+	r.put(nk, record)
+	r.writeLog("U", record)
+	count = 1
+
+	log.Printf("Updated SAP_ACCOUNTS: %s <- %v %T %#v with key: %#v, count: %d", attribute, value, value, value, a, count)
+
+	return
+}
+
+//
+// Move the record in status C back to W with the reason of the rejection
+//
+func (r *MemAccountRepository) Reject(a *models.Account, reason string) (count int64, err error) {
+	log.Printf("Rejecting SAP_ACCOUNTS: %s %#v", reason, *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := accountKey{"C", "0", a.BscsAccount}
+	record, exists := store.accounts[k]
+	if !exists {
+		return 0, nil
+	}
+
+	record.Status = "W"
+	record.RejectReason, record.RejectOwner = reason, r.Owner
+	record.UpdateDate = time.Now()
+	record.UpdateOwner = r.Owner
+	record.RecVersion++
+
+	nk := accountKeyOf(&record)
+	if _, exists := store.accounts[nk]; exists {
+		return 0, fmt.Errorf("Error in update of SAP_ACCOUNTS: unique constraint violated: %#v", nk)
+	}
+	r.remove(k)
+	r.put(nk, record)
+	r.writeLog("U", record)
+	count = 1
+
+	presentAccount(&record)
+	*a = record
+
+	log.Printf("Rejected SAP_ACCOUNTS records: %d", count)
+
+	return
+}
+
+//
+// Delete one record from resource SAP_ACCOUNTS using primary key
+//
+func (r *MemAccountRepository) DeleteByPrimaryKey(a *models.Account) (count int64, err error) {
+	log.Printf("Deleting from SAP_ACCOUNTS: %#v", *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := accountKeyOf(a)
+	record, exists := store.accounts[k]
+	if !exists {
+		return 0, nil
+	}
+	if err = memCheckValidDate(record.Status, record.ValidFromDate); err != nil {
+		return 0, fmt.Errorf("Error in delete from SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	r.remove(k)
+	r.writeLog("D", record)
+	count = 1
+
+	log.Printf("Deleted from SAP_ACCOUNTS records: %d", count)
+
+	return
+}
+
+//
+// Select max
+//
+func (r *MemAccountRepository) GetMaxRelease() (release int64, err error) {
+	log.Printf("Selecting MAX(RELEASE_ID) from SAP_ACCOUNTS")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	for k := range store.accounts {
+		if id, _ := strconv.ParseInt(k.ReleaseId, 10, 64); id > release {
+			release = id
+		}
+	}
+
+	log.Printf("Selected MAX(RELEASE_ID) FROM SAP_ACCOUNTS: %d", release)
+
+	return
+}
+
+//
+// Release
+//
+func (r *MemAccountRepository) SetStatusRelease(from, into string, release, releaseNew int64) (count int64, err error) {
+	log.Printf("Set STATUS, RELEASE for SAP_ACCOUNTS")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if err = memFault("SAP_ACCOUNTS", "SetStatusRelease"); err != nil {
+		return 0, fmt.Errorf("Error in update SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	// statement is atomic so check all the rows first
+	var keys []accountKey
+	for k, v := range store.accounts {
+		if k.Status == from && k.ReleaseId == strconv.FormatInt(release, 10) {
+			if err = memCheckValidDate(into, v.ValidFromDate); err != nil {
+				return 0, fmt.Errorf("Error in update SAP_ACCOUNTS: %s", err.Error())
+			}
+			nk := accountKey{into, strconv.FormatInt(releaseNew, 10), k.BscsAccount}
+			if _, exists := store.accounts[nk]; exists && nk != k {
+				return 0, fmt.Errorf("Error in update SAP_ACCOUNTS: unique constraint violated: %#v", nk)
+			}
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range keys {
+		record := store.accounts[k]
+		record.Status = into
+		record.ReleaseId = strconv.FormatInt(releaseNew, 10)
+		if into != "W" {
+			record.ReleaseDate, record.ReleaseOwner = time.Now(), r.Owner
+		} else {
+			record.ReleaseDate, record.ReleaseOwner = time.Time{}, ""
+		}
+		record.RejectReason, record.RejectOwner = "", ""
+		record.RecVersion++
+		r.remove(k)
+		r.put(accountKeyOf(&record), record)
+		r.writeLog("U", record)
+		count++
+	}
+
+	log.Printf("Updated SAP_ACCOUNTS records: %d", count)
+
+	return
+}
+
+//
+// Purge
+//
+func (r *MemAccountRepository) DeleteAll() (count int64, err error) {
+	log.Printf("Purging SAP_ACCOUNTS")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	for _, v := range store.accounts {
+		if err = memCheckValidDate(v.Status, v.ValidFromDate); err != nil {
+			return 0, fmt.Errorf("Error in delete from SAP_ACCOUNTS: %s", err.Error())
+		}
+	}
+
+	for k, v := range store.accounts {
+		r.remove(k)
+		r.writeLog("D", v)
+		count++
+	}
+
+	log.Printf("Deleted SAP_ACCOUNTS records: %d", count)
+
+	return
+}
+
+//
+// Used for validation of the lower bound of the account package
+//
+func (r *MemAccountRepository) GetMinValidDate(status string, release int64) (ts time.Time, err error) {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	for k, v := range store.accounts {
+		if k.Status == status && k.ReleaseId == strconv.FormatInt(release, 10) && !v.ValidFromDate.IsZero() {
+			if ts.IsZero() || v.ValidFromDate.Before(ts) {
+				ts = v.ValidFromDate
+			}
+		}
+	}
+
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	return
+}
+
+//
+// Read logs of the account in the order of operations, all accounts if empty
+//
+func (r *MemAccountRepository) ReadLog(account string) (logs []models.AccountLog, err error) {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.AccountLog{}
+	for _, l := range store.accountLogs {
+		if account == "" || l.BscsAccount == account {
+			presentAccount(&l.Account)
+			l.Id = l.LogId()
+			records = append(records, l)
+		}
+	}
+
+	logs = records
+
+	log.Printf("Selected from SAP_ACCOUNTS_LOG records: %d %#v", len(records), records)
+
+	return
+}
+
+//
+// Write the restore of the account in Work to the log next to the operation of the row
+//
+func (r *MemAccountRepository) LogRestore(a *models.Account) (err error) {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	restored := *a
+	restored.UpdateDate = time.Now()
+	restored.UpdateOwner = r.Owner
+	r.writeLog(models.OpRestore, restored)
+
+	log.Printf("Inserted to SAP_ACCOUNTS_LOG: %#v", restored)
+
+	return
+}
+
+//
+// Read the accounts of all releases in production valid on the date,
+// all the versions if the date is zero
+//
+func (r *MemAccountRepository) ReadProduction(asOf time.Time, account string) (accounts []models.Account, err error) {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.Account{}
+	for k, v := range store.accounts {
+		if k.Status == "P" && (asOf.IsZero() || !v.ValidFromDate.After(asOf)) && (account == "" || k.BscsAccount == account) {
+			presentAccount(&v)
+			records = append(records, v)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].BscsAccount < records[j].BscsAccount ||
+			(records[i].BscsAccount == records[j].BscsAccount && memReleaseLess(records[i].ReleaseId, records[j].ReleaseId))
+	})
+	accounts = records
+
+	log.Printf("Selected from SAP_ACCOUNTS in production as of: %s records: %d", asOf.Format(common.CutOffDateFormat), len(records))
+
+	return
+}
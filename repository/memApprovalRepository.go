@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"sam-api/models"
+)
+
+//
+// Pepository being handled by request
+//
+type MemApprovalRepository struct {
+	memRepository
+}
+
+//
+// Creates new repository using in-memory tables
+//
+func newMemApprovalRepository(user string, trans bool) (r *MemApprovalRepository) {
+	log.Printf("Creating new in-memory repository for user: %s", user)
+
+	r = &MemApprovalRepository{
+		memRepository{
+			Owner: user,
+			tx:    newMemTransaction(trans),
+		},
+	}
+	r.m.Lock()
+
+	return
+}
+
+// store row under the key, must be called with store locked
+func (r *MemApprovalRepository) put(k int64, a models.Approval) {
+	old, exists := store.approvals[k]
+	store.approvals[k] = a
+	r.journal(func() {
+		if exists {
+			store.approvals[k] = old
+		} else {
+			delete(store.approvals, k)
+		}
+	})
+}
+
+// record the step of the request, must be called with store locked
+func (r *MemApprovalRepository) writeStep(id int64, step, role, comment string) {
+	n := len(store.approvalSteps)
+	store.approvalSteps = append(store.approvalSteps, models.ApprovalStep{
+		ApprovalId: id,
+		Step:       step,
+		StepDate:   time.Now(),
+		StepOwner:  r.Owner,
+		StepRole:   role,
+		Comment:    comment,
+	})
+	r.journal(func() {
+		store.approvalSteps = store.approvalSteps[:n]
+	})
+}
+
+//
+// Insert the request of the release with the next id, the owner is the requesting user
+//
+func (r *MemApprovalRepository) Create(a *models.Approval) (err error) {
+	log.Printf("Inserting into SAP_APPROVALS: %#v", *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	var id int64
+	for k := range store.approvals {
+		if k > id {
+			id = k
+		}
+	}
+
+	a.ApprovalId = id + 1
+	a.State = models.ApprovalRequested
+	a.RequestDate, a.RequestOwner = time.Now(), r.Owner
+	a.RecVersion = 0
+
+	r.put(a.ApprovalId, *a)
+	r.writeStep(a.ApprovalId, models.ApprovalRequested, a.RequestRole, a.Comment)
+
+	presentApproval(a, []models.ApprovalStep{})
+
+	log.Printf("Inserted into SAP_APPROVALS: %#v", *a)
+
+	return
+}
+
+//
+// Approve or reject the request still pending, the count is 0 if it is not pending
+//
+func (r *MemApprovalRepository) Decide(a *models.Approval, state, role, comment string) (count int64, err error) {
+	log.Printf("Updating SAP_APPROVALS: %s %#v", state, *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	record, exists := store.approvals[a.ApprovalId]
+	if !exists || record.State != models.ApprovalRequested {
+		log.Printf("Updated SAP_APPROVALS records: 0")
+		return 0, nil
+	}
+
+	record.State = state
+	record.ReleaseId = a.ReleaseId
+	record.RecVersion++
+	r.put(a.ApprovalId, record)
+	r.writeStep(a.ApprovalId, state, role, comment)
+
+	a.State, a.RecVersion = record.State, record.RecVersion
+	count = 1
+
+	log.Printf("Updated SAP_APPROVALS: %#v", *a)
+
+	return
+}
+
+//
+// Select the request with its steps by the id
+//
+func (r *MemApprovalRepository) ReadByPrimaryKey(a *models.Approval) (count int64, err error) {
+	log.Printf("Selecting from SAP_APPROVALS by key: %d", a.ApprovalId)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	record, exists := store.approvals[a.ApprovalId]
+	if !exists {
+		log.Printf("Selected from SAP_APPROVALS records: 0")
+		return 0, nil
+	}
+
+	*a = record
+	presentApproval(a, store.approvalSteps)
+	count = 1
+
+	log.Printf("Selected from SAP_APPROVALS: %#v", *a)
+
+	return
+}
+
+//
+// Select all requests in the state or all of them if the state is empty
+//
+func (r *MemApprovalRepository) ReadAll(state string) (approvals []models.Approval, err error) {
+	log.Printf("Selecting from SAP_APPROVALS in state: %s", state)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	approvals = []models.Approval{}
+	for _, a := range store.approvals {
+		if state == "" || a.State == state {
+			presentApproval(&a, store.approvalSteps)
+			approvals = append(approvals, a)
+		}
+	}
+
+	sort.Slice(approvals, func(i, j int) bool {
+		return approvals[i].ApprovalId < approvals[j].ApprovalId
+	})
+
+	log.Printf("Selected from SAP_APPROVALS records: %d", len(approvals))
+
+	return
+}
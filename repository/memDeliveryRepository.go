@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"log"
+	"sort"
+
+	"sam-api/models"
+)
+
+//
+// Pepository being handled by request
+//
+type MemDeliveryRepository struct {
+	memRepository
+}
+
+//
+// Creates new repository using in-memory tables
+//
+func newMemDeliveryRepository(user string, trans bool) (r *MemDeliveryRepository) {
+	log.Printf("Creating new in-memory repository for user: %s", user)
+
+	r = &MemDeliveryRepository{
+		memRepository{
+			Owner: user,
+			tx:    newMemTransaction(trans),
+		},
+	}
+	r.m.Lock()
+
+	return
+}
+
+// store row under the key, must be called with store locked
+func (r *MemDeliveryRepository) put(k string, d models.Delivery) {
+	old, exists := store.deliveries[k]
+	store.deliveries[k] = d
+	r.journal(func() {
+		if exists {
+			store.deliveries[k] = old
+		} else {
+			delete(store.deliveries, k)
+		}
+	})
+}
+
+//
+// Update the delivery of the release or insert it when it is the first one
+//
+func (r *MemDeliveryRepository) Save(d *models.Delivery) (err error) {
+	log.Printf("Saving in SAP_RELEASE_DELIVERIES: %#v", *d)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := memRelease(d.ReleaseId)
+	if record, exists := store.deliveries[k]; exists {
+		d.RecVersion = record.RecVersion + 1
+	} else {
+		d.RecVersion = 0
+	}
+	d.ReleaseId = k
+
+	r.put(k, *d)
+
+	presentDelivery(d)
+
+	log.Printf("Saved in SAP_RELEASE_DELIVERIES: %#v", *d)
+
+	return
+}
+
+//
+// Select the delivery of the release
+//
+func (r *MemDeliveryRepository) ReadByPrimaryKey(d *models.Delivery) (count int64, err error) {
+	log.Printf("Selecting from SAP_RELEASE_DELIVERIES by key: %s", d.ReleaseId)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	record, exists := store.deliveries[memRelease(d.ReleaseId)]
+	if !exists {
+		log.Printf("Selected from SAP_RELEASE_DELIVERIES records: 0")
+		return 0, nil
+	}
+
+	*d = record
+	presentDelivery(d)
+	count = 1
+
+	log.Printf("Selected from SAP_RELEASE_DELIVERIES: %#v", *d)
+
+	return
+}
+
+//
+// Select the deliveries of all releases
+//
+func (r *MemDeliveryRepository) ReadAll() (deliveries []models.Delivery, err error) {
+	log.Printf("Selecting from SAP_RELEASE_DELIVERIES")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	deliveries = []models.Delivery{}
+	for _, d := range store.deliveries {
+		presentDelivery(&d)
+		deliveries = append(deliveries, d)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return memReleaseLess(deliveries[i].ReleaseId, deliveries[j].ReleaseId)
+	})
+
+	log.Printf("Selected from SAP_RELEASE_DELIVERIES records: %d", len(deliveries))
+
+	return
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"log"
+	"sort"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+//
+// Pepository being handled by request
+//
+type MemDictionaryAccountBscsRepository struct {
+	memRepository
+}
+
+//
+// Creates new repository using in-memory tables
+//
+func newMemDictionaryAccountBscsRepository(user string) (r *MemDictionaryAccountBscsRepository) {
+	log.Printf("Creating new in-memory repository: user:%s", user)
+
+	r = &MemDictionaryAccountBscsRepository{
+		memRepository{
+			Owner: user,
+		},
+	}
+	r.m.Lock()
+
+	return
+}
+
+//
+// GLACCOUNTS is a view on BSCS so it may be only loaded
+//
+func LoadMemDictionaryAccountBscs(entries []models.DictionaryAccountBscs) {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	for _, e := range entries {
+		store.accountsBscs[e.Account] = e
+	}
+}
+
+//
+// Select all records from the backend table
+//
+func (r *MemDictionaryAccountBscsRepository) ReadAll(q *common.ListQuery) (entries []models.DictionaryAccountBscs, total int64, err error) {
+	log.Printf("Selecting from: GLACCOUNTS")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.DictionaryAccountBscs{}
+	for _, e := range store.accountsBscs {
+		if !e.EntryDate.IsZero() {
+			e.EntryDateStr = e.EntryDate.Format(common.ModelDateFormat)
+		}
+		if !e.UpdateDate.IsZero() {
+			e.UpdateDateStr = e.UpdateDate.Format(common.ModelDateFormat)
+		}
+		records = append(records, e)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Account < records[j].Account
+	})
+
+	// Apply filters, sorting and paging of the list
+	if q == nil {
+		q = &common.ListQuery{}
+	}
+	if total, err = memListQuery(&records, q, dictionaryAccountBscsQueryColumns); err != nil {
+		return nil, 0, err
+	}
+
+	entries = records
+
+	log.Printf("Selected from GLACCOUNTS records: %d of %d %#v", len(records), total, records)
+
+	return
+}
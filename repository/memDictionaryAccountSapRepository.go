@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+//
+// Pepository being handled by request
+//
+type MemDictionaryAccountSapRepository struct {
+	memRepository
+}
+
+//
+// Creates new repository using in-memory tables
+//
+func newMemDictionaryAccountSapRepository(user string) (r *MemDictionaryAccountSapRepository) {
+	log.Printf("Creating new in-memory repository: user:%s", user)
+
+	r = &MemDictionaryAccountSapRepository{
+		memRepository{
+			Owner: user,
+		},
+	}
+	r.m.Lock()
+
+	return
+}
+
+//
+// Insert new record to the backend table
+//
+func (r *MemDictionaryAccountSapRepository) Create(d *models.DictionaryAccountSap) (err error) {
+	log.Printf("Inserting to SAP_OFI_ACCOUNTS: %#v", *d)
+
+	d.EntryDate = time.Now()
+	d.EntryOwner = r.Owner
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if _, exists := store.accountsSap[d.Account]; exists {
+		return fmt.Errorf("Error in insert to SAP_OFI_ACCOUNTS: unique constraint violated: %s", d.Account)
+	}
+
+	store.accountsSap[d.Account] = *d
+
+	d.EntryDateStr = d.EntryDate.Format(common.ModelDateFormat)
+
+	log.Printf("Inserted to SAP_OFI_ACCOUNTS: %#v", *d)
+
+	return
+}
+
+//
+// Select all records from the backend table
+//
+func (r *MemDictionaryAccountSapRepository) ReadAll(q *common.ListQuery) (entries []models.DictionaryAccountSap, total int64, err error) {
+	log.Printf("Selecting from SAP_OFI_ACCOUNTS")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.DictionaryAccountSap{}
+	for _, e := range store.accountsSap {
+		if !e.EntryDate.IsZero() {
+			e.EntryDateStr = e.EntryDate.Format(common.ModelDateFormat)
+		}
+		if !e.UpdateDate.IsZero() {
+			e.UpdateDateStr = e.UpdateDate.Format(common.ModelDateFormat)
+		}
+		records = append(records, e)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Account < records[j].Account
+	})
+
+	// Apply filters, sorting and paging of the list
+	if q == nil {
+		q = &common.ListQuery{}
+	}
+	if total, err = memListQuery(&records, q, dictionaryAccountSapQueryColumns); err != nil {
+		return nil, 0, err
+	}
+
+	entries = records
+
+	log.Printf("Selected from SAP_OFI_ACCOUNTS records: %d of %d %#v", len(records), total, records)
+
+	return
+}
+
+//
+// Delete all records from resource
+//
+func (r *MemDictionaryAccountSapRepository) DeleteAll() (count int64, err error) {
+	log.Printf("Deleting from: SAP_OFI_ACCOUNTS")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	count = int64(len(store.accountsSap))
+	store.accountsSap = make(map[string]models.DictionaryAccountSap)
+
+	log.Printf("Deleted SAP_OFI_ACCOUNTS records: %d", count)
+
+	return
+}
+
+//
+// Merge the imported accounts with their names into the dictionary at once:
+// the new ones are inserted, the renamed or inactive ones are updated and
+// the ones missing are deactivated, only the difference is returned if the
+// merge is not applied
+//
+func (r *MemDictionaryAccountSapRepository) Merge(imported map[string]string, apply bool) (m *models.DictionaryAccountSapMerge, err error) {
+	log.Printf("Merging into SAP_OFI_ACCOUNTS records: %d apply: %t", len(imported), apply)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	current := []models.DictionaryAccountSap{}
+	for _, e := range store.accountsSap {
+		current = append(current, e)
+	}
+
+	m = models.MergeDictionaryAccountSap(current, imported)
+	if !apply {
+		return
+	}
+
+	now := time.Now()
+	for _, c := range m.Inserted {
+		store.accountsSap[c.Account] = models.DictionaryAccountSap{
+			Account:    c.Account,
+			Name:       c.Name,
+			Status:     models.DictionaryAccountSapActive,
+			EntryDate:  now,
+			EntryOwner: r.Owner,
+		}
+	}
+
+	update := func(c models.DictionaryAccountSapChange, status string) {
+		e := store.accountsSap[c.Account]
+		e.Name, e.Status = c.Name, status
+		e.UpdateDate, e.UpdateOwner = now, r.Owner
+		e.RecVersion++
+		store.accountsSap[c.Account] = e
+	}
+	for _, c := range m.Updated {
+		update(c, models.DictionaryAccountSapActive)
+	}
+	for _, c := range m.Removed {
+		update(c, models.DictionaryAccountSapInactive)
+	}
+
+	log.Printf("Merged into SAP_OFI_ACCOUNTS inserted: %d updated: %d removed: %d unchanged: %d",
+		len(m.Inserted), len(m.Updated), len(m.Removed), m.Unchanged)
+
+	return
+}
@@ -0,0 +1,228 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+//
+// Pepository being handled by request
+//
+type MemDictionarySegmentRepository struct {
+	memRepository
+}
+
+//
+// Creates new repository using in-memory tables
+//
+func newMemDictionarySegmentRepository(user string, trans bool) (r *MemDictionarySegmentRepository) {
+	log.Printf("Creating new in-memory repository: user:%s", user)
+
+	r = &MemDictionarySegmentRepository{
+		memRepository{
+			Owner: user,
+			tx:    newMemTransaction(trans),
+		},
+	}
+	r.m.Lock()
+
+	return
+}
+
+// store row under the key, must be called with store locked
+func (r *MemDictionarySegmentRepository) put(k string, s models.DictionarySegment) {
+	old, exists := store.segments[k]
+	store.segments[k] = s
+	r.journal(func() {
+		if exists {
+			store.segments[k] = old
+		} else {
+			delete(store.segments, k)
+		}
+	})
+}
+
+// remove row with the key, must be called with store locked
+func (r *MemDictionarySegmentRepository) remove(k string) {
+	old, exists := store.segments[k]
+	if !exists {
+		return
+	}
+	delete(store.segments, k)
+	r.journal(func() {
+		store.segments[k] = old
+	})
+}
+
+//
+// Insert new record to the backend table
+//
+func (r *MemDictionarySegmentRepository) Create(s *models.DictionarySegment) (err error) {
+	log.Printf("Inserting to CUSTOMER_SEGMENT: %#v", *s)
+
+	s.EntryDate = time.Now()
+	s.EntryOwner = r.Owner
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if _, exists := store.segments[s.CsTradeRef]; exists {
+		return fmt.Errorf("Error in insert to CUSTOMER_SEGMENT: unique constraint violated: %s", s.CsTradeRef)
+	}
+
+	r.put(s.CsTradeRef, *s)
+
+	s.EntryDateStr = s.EntryDate.Format(common.ModelDateFormat)
+
+	log.Printf("Inserted to CUSTOMER_SEGMENT: %#v", *s)
+
+	return
+}
+
+//
+// Select all records from the backend table
+//
+func (r *MemDictionarySegmentRepository) ReadAll() (segments []models.DictionarySegment, err error) {
+	log.Printf("Selecting from CUSTOMER_SEGMENT")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.DictionarySegment{}
+	for _, s := range store.segments {
+		if !s.EntryDate.IsZero() {
+			s.EntryDateStr = s.EntryDate.Format(common.ModelDateFormat)
+		}
+		if !s.UpdateDate.IsZero() {
+			s.UpdateDateStr = s.UpdateDate.Format(common.ModelDateFormat)
+		}
+		records = append(records, s)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].CsTradeRef < records[j].CsTradeRef
+	})
+
+	segments = records
+
+	log.Printf("Selected from CUSTOMER_SEGMENT records: %d %#v", len(records), records)
+
+	return
+}
+
+//
+// Delete all records from resource
+//
+func (r *MemDictionarySegmentRepository) DeleteAll() (count int64, err error) {
+	log.Printf("Deleting from CUSTOMER_SEGMENT")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	for k := range store.segments {
+		r.remove(k)
+		count++
+	}
+
+	log.Printf("Deleted CUSTOMER_SEGMENT records: %d", count)
+
+	return
+}
+
+//
+// Update one record to the backend table
+//
+func (r *MemDictionarySegmentRepository) UpdateByPrimaryKey(s *models.DictionarySegment) (count int64, err error) {
+	log.Printf("Updating CUSTOMER_SEGMENT: %#v", *s)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if _, exists := store.segments[s.CsTradeRef]; !exists {
+		return 0, nil
+	}
+
+	record := *s
+	record.UpdateDate = time.Now()
+	record.UpdateOwner = r.Owner
+
+	r.put(record.CsTradeRef, record)
+	count = 1
+
+	log.Printf("Updated CUSTOMER_SEGMENT records: %d", count)
+
+	return
+}
+
+//
+// Delete some records from resource
+//
+func (r *MemDictionarySegmentRepository) DeleteByPrimaryKey(s *models.DictionarySegment) (count int64, err error) {
+	log.Printf("Deleting from CUSTOMER_SEGMENT: %#v", *s)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if _, exists := store.segments[s.CsTradeRef]; !exists {
+		return 0, nil
+	}
+
+	r.remove(s.CsTradeRef)
+	count = 1
+
+	log.Printf("Deleted CUSTOMER_SEGMENT records: %d", count)
+
+	return
+}
+
+//
+// Update one attribute of the record in resource using primary key
+//
+func (r *MemDictionarySegmentRepository) UpdateAttributeByPrimaryKey(a *models.DictionarySegment, attribute string, value interface{}) (count int64, err error) {
+	log.Printf("Updating CUSTOMER_SEGMENT: %s <- %v %T %#v with key: %#v", attribute, value, value, value, *a)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	record, exists := store.segments[a.CsTradeRef]
+
+	switch attribute {
+	case "segmCategory":
+		record.SegmCategory = memString(value)
+	case "csTradeRef":
+		record.CsTradeRef = memString(value)
+	case "revVersion":
+		record.RecVersion, err = memInt(value)
+	default:
+		return 0, fmt.Errorf("No column name for attribute: %s", attribute)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Error in update of CUSTOMER_SEGMENT: %s", err.Error())
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	record.UpdateDate = time.Now()
+	record.UpdateOwner = r.Owner
+
+	if record.CsTradeRef != a.CsTradeRef {
+		if _, exists := store.segments[record.CsTradeRef]; exists {
+			return 0, fmt.Errorf("Error in update of CUSTOMER_SEGMENT: unique constraint violated: %s", record.CsTradeRef)
+		}
+		r.remove(a.CsTradeRef)
+	}
+	r.put(record.CsTradeRef, record)
+	count = 1
+
+	log.Printf("Updated CUSTOMER_SEGMENT: %s <- %v %T %#v with key: %#v, count: %d", attribute, value, value, value, a, count)
+
+	return
+}
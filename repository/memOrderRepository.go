@@ -0,0 +1,595 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+//
+// Pepository being handled by request
+//
+type MemOrderRepository struct {
+	memRepository
+}
+
+//
+// Creates new repository using in-memory tables
+//
+func newMemOrderRepository(user string, trans bool) (r *MemOrderRepository) {
+	log.Printf("Creating new in-memory repository for user: %s", user)
+
+	r = &MemOrderRepository{
+		memRepository{
+			Owner: user,
+			tx:    newMemTransaction(trans),
+		},
+	}
+	r.m.Lock()
+
+	return
+}
+
+func orderKeyOf(o *models.Order) orderKey {
+	return orderKey{o.Status, memRelease(o.ReleaseId), o.BscsAccount, o.SegmentCode}
+}
+
+// emulation of the after triggers, must be called with store locked
+func (r *MemOrderRepository) writeLog(opcode string, o models.Order) {
+	n := len(store.orderLogs)
+	store.orderLogs = append(store.orderLogs, models.OrderLog{OpCode: opcode, OpDate: time.Now(), Order: o})
+	r.journal(func() {
+		store.orderLogs = store.orderLogs[:n]
+	})
+}
+
+// store row under the key, must be called with store locked
+func (r *MemOrderRepository) put(k orderKey, o models.Order) {
+	old, exists := store.orders[k]
+	store.orders[k] = o
+	r.journal(func() {
+		if exists {
+			store.orders[k] = old
+		} else {
+			delete(store.orders, k)
+		}
+	})
+}
+
+// remove row with the key, must be called with store locked
+func (r *MemOrderRepository) remove(k orderKey) {
+	old, exists := store.orders[k]
+	if !exists {
+		return
+	}
+	delete(store.orders, k)
+	r.journal(func() {
+		store.orders[k] = old
+	})
+}
+
+// Take care of dates presentation
+func presentOrder(o *models.Order) {
+	if !o.ValidFromDate.IsZero() {
+		o.ValidFromDateStr = o.ValidFromDate.Format(common.CutOffDateFormat)
+	}
+	if !o.EntryDate.IsZero() {
+		o.EntryDateStr = o.EntryDate.Format(common.ModelDateFormat)
+	}
+	if !o.UpdateDate.IsZero() {
+		o.UpdateDateStr = o.UpdateDate.Format(common.ModelDateFormat)
+	}
+	if !o.ReleaseDate.IsZero() {
+		o.ReleaseDateStr = o.ReleaseDate.Format(common.ModelDateFormat)
+	}
+}
+
+//
+// Insert new record to the resource SAP_ACC_SEGM_ORDER_NUMBERS
+//
+func (r *MemOrderRepository) Create(o *models.Order) (err error) {
+	log.Printf("Inserting to SAP_ACC_SEGM_ORDER_NUMBERS: %s %#v", r.Owner, *o)
+
+	// default value
+	if o.ReleaseId == "" {
+		o.ReleaseId = "0"
+	}
+
+	// default value
+	if o.Status == "" {
+		o.Status = "W"
+	}
+
+	if o.ValidFromDateStr != "" {
+		o.ValidFromDate, err = time.Parse(common.CutOffDateFormat, o.ValidFromDateStr)
+		if err != nil {
+			return err
+		}
+	}
+
+	o.EntryDate = time.Now()
+	o.EntryOwner = r.Owner
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := orderKeyOf(o)
+	if _, exists := store.orders[k]; exists {
+		return fmt.Errorf("Error in insert to SAP_ACC_SEGM_ORDER_NUMBERS: unique constraint violated: %#v", k)
+	}
+	if err = memCheckValidDate(o.Status, o.ValidFromDate); err != nil {
+		return fmt.Errorf("Error in insert to SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	r.put(k, *o)
+	r.writeLog("I", *o)
+
+	o.EntryDateStr = o.EntryDate.Format(common.ModelDateFormat)
+
+	log.Printf("Inserted to SAP_ACC_SEGM_ORDER_NUMBERS: %#v", *o)
+
+	return
+}
+
+//
+// Select some records from the resource by status and release or all active ones
+//
+func (r *MemOrderRepository) ReadBulkByPartialKey(o *models.Order, q *common.ListQuery) (orders []models.Order, total int64, err error) {
+	log.Printf("Selecting from SAP_ACC_SEGM_ORDER_NUMBERS with: %#v", *o)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.Order{}
+	if o.Status != "" && o.ReleaseId != "" {
+		if _, err = strconv.ParseInt(o.ReleaseId, 10, 64); err != nil {
+			return nil, 0, fmt.Errorf("Error in select from SAP_ACC_SEGM_ORDER_NUMBERS: ORA-01722: invalid number")
+		}
+		release := memRelease(o.ReleaseId)
+		for k, v := range store.orders {
+			if k.Status == o.Status && k.ReleaseId == release {
+				records = append(records, v)
+			}
+		}
+	} else {
+		last := make(map[[2]string]int64)
+		for k := range store.orders {
+			ak := [2]string{k.BscsAccount, k.SegmentCode}
+			if id, _ := strconv.ParseInt(k.ReleaseId, 10, 64); id > last[ak] {
+				last[ak] = id
+			}
+		}
+		for k, v := range store.orders {
+			id, _ := strconv.ParseInt(k.ReleaseId, 10, 64)
+			if ((k.Status == "W" || k.Status == "C") && id == 0) ||
+				(k.Status == "P" && id == last[[2]string{k.BscsAccount, k.SegmentCode}]) {
+				records = append(records, v)
+			}
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.BscsAccount != b.BscsAccount {
+			return a.BscsAccount < b.BscsAccount
+		}
+		if a.SegmentCode != b.SegmentCode {
+			return a.SegmentCode < b.SegmentCode
+		}
+		return a.Status < b.Status
+	})
+
+	// Apply filters, sorting and paging of the list
+	if q == nil {
+		q = &common.ListQuery{}
+	}
+	if total, err = memListQuery(&records, q, orderQueryColumns); err != nil {
+		return nil, 0, err
+	}
+
+	for i := range records {
+		presentOrder(&records[i])
+	}
+
+	orders = records
+
+	log.Printf("Selected SAP_ACC_SEGM_ORDER_NUMBERS records: %d of %d %#v", len(records), total, records)
+
+	return
+}
+
+//
+// Select one record from the resource SAP_ACC_SEGM_ORDER_NUMBERS using primary key
+//
+func (r *MemOrderRepository) ReadByPrimaryKey(o *models.Order) (count int64, err error) {
+	log.Printf("Selecting from SAP_ACC_SEGM_ORDER_NUMBERS by key: %#v", *o)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if record, exists := store.orders[orderKeyOf(o)]; exists {
+		*o = record
+		presentOrder(o)
+		count = 1
+	}
+
+	log.Printf("Selected SAP_ACC_SEGM_ORDER_NUMBERS records: %d", count)
+
+	return
+}
+
+//
+// Select REC_VERSION of one record using primary key
+//
+func (r *MemOrderRepository) ReadVersionByPrimaryKey(o *models.Order) (version int, count int64, err error) {
+	log.Printf("Selecting REC_VERSION from SAP_ACC_SEGM_ORDER_NUMBERS: %#v", *o)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if record, exists := store.orders[orderKeyOf(o)]; exists {
+		version, count = record.RecVersion, 1
+	}
+
+	log.Printf("Selected REC_VERSION from SAP_ACC_SEGM_ORDER_NUMBERS: %d, count: %d", version, count)
+
+	return
+}
+
+//
+// Update one record in resource SAP_ACC_SEGM_ORDER_NUMBERS using primary key
+//
+func (r *MemOrderRepository) UpdateByPrimaryKey(o *models.Order) (count int64, err error) {
+	log.Printf("Updating SAP_ACC_SEGM_ORDER_NUMBERS with: %#v", *o)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := orderKeyOf(o)
+	record, exists := store.orders[k]
+	if !exists {
+		return 0, nil
+	}
+
+	record.ValidFromDate = o.ValidFromDate
+	record.OrderNumber = o.OrderNumber
+	record.UpdateDate = time.Now()
+	record.UpdateOwner = r.Owner
+	record.RecVersion++
+	if err = memCheckValidDate(record.Status, record.ValidFromDate); err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	r.put(k, record)
+	r.writeLog("U", record)
+	count = 1
+
+	log.Printf("Updated SAP_ACC_SEGM_ORDER_NUMBERS records: %d", count)
+
+	return
+}
+
+//
+// Update one column of the record in resource SAP_ACC_SEGM_ORDER_NUMBERS using primary key
+//
+func (r *MemOrderRepository) UpdateAttributeByPrimaryKey(o *models.Order, attribute string, value interface{}) (count int64, err error) {
+	log.Printf("Updating SAP_ACC_SEGM_ORDER_NUMBERS: %s <- %v with key: %#v", attribute, value, *o)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := orderKeyOf(o)
+	record, exists := store.orders[k]
+	version := record.RecVersion
+
+	switch attribute {
+	case "status":
+		record.Status = memString(value)
+	case "releaseId":
+		record.ReleaseId = memRelease(memString(value))
+	case "bscsAccount":
+		record.BscsAccount = memString(value)
+	case "segmentCode":
+		record.SegmentCode = memString(value)
+	case "orderNumber":
+		record.OrderNumber = memString(value)
+	case "validFromDate":
+		record.ValidFromDate, err = memTime(value)
+	case "recVersion":
+		_, err = memInt(value)
+	default:
+		return 0, fmt.Errorf("No column name for attribute: %s", attribute)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	// trigger always takes the old version as base
+	record.UpdateDate = time.Now()
+	record.UpdateOwner = r.Owner
+	record.RecVersion = version + 1
+	if err = memCheckValidDate(record.Status, record.ValidFromDate); err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	// key may be changed as well
+	nk := orderKeyOf(&record)
+	if nk != k {
+		if _, exists := store.orders[nk]; exists {
+			return 0, fmt.Errorf("Error in update of SAP_ACC_SEGM_ORDER_NUMBERS: unique constraint violated: %#v", nk)
+		}
+		r.remove(k)
+	}
+	r.put(nk, record)
+	r.writeLog("U", record)
+	count = 1
+
+	log.Printf("Updated: SAP_ACC_SEGM_ORDER_NUMBERS records: %d", count)
+
+	return
+}
+
+//
+// Move the record in status C back to W with the reason of the rejection
+//
+func (r *MemOrderRepository) Reject(o *models.Order, reason string) (count int64, err error) {
+	log.Printf("Rejecting SAP_ACC_SEGM_ORDER_NUMBERS: %s %#v", reason, *o)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := orderKey{"C", "0", o.BscsAccount, o.SegmentCode}
+	record, exists := store.orders[k]
+	if !exists {
+		return 0, nil
+	}
+
+	record.Status = "W"
+	record.RejectReason, record.RejectOwner = reason, r.Owner
+	record.UpdateDate = time.Now()
+	record.UpdateOwner = r.Owner
+	record.RecVersion++
+
+	nk := orderKeyOf(&record)
+	if _, exists := store.orders[nk]; exists {
+		return 0, fmt.Errorf("Error in update of SAP_ACC_SEGM_ORDER_NUMBERS: unique constraint violated: %#v", nk)
+	}
+	r.remove(k)
+	r.put(nk, record)
+	r.writeLog("U", record)
+	count = 1
+
+	presentOrder(&record)
+	*o = record
+
+	log.Printf("Rejected SAP_ACC_SEGM_ORDER_NUMBERS records: %d", count)
+
+	return
+}
+
+//
+// Delete some records from resource SAP_ACC_SEGM_ORDER_NUMBERS
+//
+func (r *MemOrderRepository) DeleteByPrimaryKey(o *models.Order) (count int64, err error) {
+	log.Printf("Deleting from SAP_ACC_SEGM_ORDER_NUMBERS: %#v", *o)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := orderKeyOf(o)
+	record, exists := store.orders[k]
+	if !exists {
+		return 0, nil
+	}
+	if err = memCheckValidDate(record.Status, record.ValidFromDate); err != nil {
+		return 0, fmt.Errorf("Error in delete from SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	r.remove(k)
+	r.writeLog("D", record)
+	count = 1
+
+	log.Printf("Deleted SAP_ACC_SEGM_ORDER_NUMBERS records: %d", count)
+
+	return
+}
+
+//
+// Select max
+//
+func (r *MemOrderRepository) GetMaxRelease() (release int64, err error) {
+	log.Printf("Selecting MAX(RELEASE_ID) from SAP_ACC_SEGM_ORDER_NUMBERS")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	for k := range store.orders {
+		if id, _ := strconv.ParseInt(k.ReleaseId, 10, 64); id > release {
+			release = id
+		}
+	}
+
+	return
+}
+
+//
+// Release
+//
+func (r *MemOrderRepository) SetStatusRelease(from, into string, release, releaseNew int64) (count int64, err error) {
+	log.Printf("Set RELEASE, STATUS for SAP_ACC_SEGM_ORDER_NUMBERS")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if err = memFault("SAP_ACC_SEGM_ORDER_NUMBERS", "SetStatusRelease"); err != nil {
+		return 0, fmt.Errorf("Error in update SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	// statement is atomic so check all the rows first
+	var keys []orderKey
+	for k, v := range store.orders {
+		if k.Status == from && k.ReleaseId == strconv.FormatInt(release, 10) {
+			if err = memCheckValidDate(into, v.ValidFromDate); err != nil {
+				return 0, fmt.Errorf("Error in update SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+			}
+			nk := orderKey{into, strconv.FormatInt(releaseNew, 10), k.BscsAccount, k.SegmentCode}
+			if _, exists := store.orders[nk]; exists && nk != k {
+				return 0, fmt.Errorf("Error in update SAP_ACC_SEGM_ORDER_NUMBERS: unique constraint violated: %#v", nk)
+			}
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range keys {
+		record := store.orders[k]
+		record.Status = into
+		record.ReleaseId = strconv.FormatInt(releaseNew, 10)
+		if into != "W" {
+			record.ReleaseDate, record.ReleaseOwner = time.Now(), r.Owner
+		} else {
+			record.ReleaseDate, record.ReleaseOwner = time.Time{}, ""
+		}
+		record.RejectReason, record.RejectOwner = "", ""
+		record.RecVersion++
+		r.remove(k)
+		r.put(orderKeyOf(&record), record)
+		r.writeLog("U", record)
+		count++
+	}
+
+	log.Printf("Updated SAP_ACC_SEGM_ORDER_NUMBERS records: %d", count)
+
+	return
+}
+
+//
+// Purge
+//
+func (r *MemOrderRepository) DeleteAll() (count int64, err error) {
+	log.Printf("Deleting from SAP_ACC_SEGM_ORDER_NUMBERS")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	for _, v := range store.orders {
+		if err = memCheckValidDate(v.Status, v.ValidFromDate); err != nil {
+			return 0, fmt.Errorf("Error in delete from SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+		}
+	}
+
+	for k, v := range store.orders {
+		r.remove(k)
+		r.writeLog("D", v)
+		count++
+	}
+
+	log.Printf("Deleted from SAP_ACC_SEGM_ORDER_NUMBERS records: %d", count)
+
+	return
+}
+
+//
+// Used for validation of the lower bound of the order package
+//
+func (r *MemOrderRepository) GetMinValidDate(status string, release int64) (ts time.Time, err error) {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	for k, v := range store.orders {
+		if k.Status == status && k.ReleaseId == strconv.FormatInt(release, 10) && !v.ValidFromDate.IsZero() {
+			if ts.IsZero() || v.ValidFromDate.Before(ts) {
+				ts = v.ValidFromDate
+			}
+		}
+	}
+
+	if ts.IsZero() {
+		ts = time.Now()
+	}
+
+	return
+}
+
+// Read logs of the account orders in the order of operations, all accounts if empty
+// Read logs of the account orders
+//
+func (r *MemOrderRepository) ReadLog(account string) (logs []models.OrderLog, err error) {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.OrderLog{}
+	for _, l := range store.orderLogs {
+		if account == "" || l.BscsAccount == account {
+			presentOrder(&l.Order)
+			l.Id = l.LogId()
+			records = append(records, l)
+		}
+	}
+
+	logs = records
+
+	log.Printf("Loaded SAP_ACC_SEGM_ORDER_NUMBERS_LOG records: %d %#v", len(records), records)
+
+	return
+}
+
+//
+// Write the restore of the order in Work to the log next to the operation of the row
+//
+func (r *MemOrderRepository) LogRestore(o *models.Order) (err error) {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	restored := *o
+	restored.UpdateDate = time.Now()
+	restored.UpdateOwner = r.Owner
+	r.writeLog(models.OpRestore, restored)
+
+	log.Printf("Inserted to SAP_ACC_SEGM_ORDER_NUMBERS_LOG: %#v", restored)
+
+	return
+}
+
+//
+// Read the orders of all releases in production valid on the date,
+// all the versions if the date is zero
+//
+func (r *MemOrderRepository) ReadProduction(asOf time.Time, account, segment string) (orders []models.Order, err error) {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.Order{}
+	for k, v := range store.orders {
+		if k.Status == "P" && (asOf.IsZero() || !v.ValidFromDate.After(asOf)) &&
+			(account == "" || k.BscsAccount == account) && (segment == "" || k.SegmentCode == segment) {
+			presentOrder(&v)
+			records = append(records, v)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.BscsAccount != b.BscsAccount {
+			return a.BscsAccount < b.BscsAccount
+		}
+		if a.SegmentCode != b.SegmentCode {
+			return a.SegmentCode < b.SegmentCode
+		}
+		return memReleaseLess(a.ReleaseId, b.ReleaseId)
+	})
+	orders = records
+
+	log.Printf("Selected from SAP_ACC_SEGM_ORDER_NUMBERS in production as of: %s records: %d", asOf.Format(common.CutOffDateFormat), len(records))
+
+	return
+}
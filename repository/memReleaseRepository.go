@@ -0,0 +1,195 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"sam-api/models"
+)
+
+//
+// Pepository being handled by request
+//
+type MemReleaseRepository struct {
+	memRepository
+}
+
+//
+// Creates new repository using in-memory tables
+//
+func newMemReleaseRepository(user string, trans bool) (r *MemReleaseRepository) {
+	log.Printf("Creating new in-memory repository for user: %s", user)
+
+	r = &MemReleaseRepository{
+		memRepository{
+			Owner: user,
+			tx:    newMemTransaction(trans),
+		},
+	}
+	r.m.Lock()
+
+	return
+}
+
+// store row under the key, must be called with store locked
+func (r *MemReleaseRepository) put(k string, rel models.Release) {
+	old, exists := store.releases[k]
+	store.releases[k] = rel
+	r.journal(func() {
+		if exists {
+			store.releases[k] = old
+		} else {
+			delete(store.releases, k)
+		}
+	})
+}
+
+// record the operation in the history, must be called with store locked
+func (r *MemReleaseRepository) writeLog(opcode, release string, accounts, orders int64) {
+	n := len(store.releaseLogs)
+	store.releaseLogs = append(store.releaseLogs, models.ReleaseLog{
+		ReleaseId: release,
+		OpCode:    opcode,
+		OpDate:    time.Now(),
+		OpOwner:   r.Owner,
+		Accounts:  accounts,
+		Orders:    orders,
+	})
+	r.journal(func() {
+		store.releaseLogs = store.releaseLogs[:n]
+	})
+}
+
+//
+// Insert the release or update it when appended or released again after revoke,
+// opcode N is the new release, A is append to the existing one
+//
+func (r *MemReleaseRepository) Register(rel *models.Release, opcode string, accounts, orders int64) (err error) {
+	log.Printf("Registering in SAP_RELEASES: %s %#v", opcode, *rel)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	k := memRelease(rel.ReleaseId)
+	if record, exists := store.releases[k]; !exists {
+		rel.ReleaseId = k
+		rel.ReleaseDate, rel.ReleaseOwner = time.Now(), r.Owner
+		rel.RecVersion = 0
+	} else {
+		if opcode == "N" || record.ReleaseDate.IsZero() {
+			record.FromStatus, record.IntoStatus = rel.FromStatus, rel.IntoStatus
+			record.ReleaseDate, record.ReleaseOwner = time.Now(), r.Owner
+		}
+		if rel.Description != "" {
+			record.Description = rel.Description
+		}
+		record.RevokeDate, record.RevokeOwner = time.Time{}, ""
+		record.RecVersion++
+		*rel = record
+	}
+
+	r.put(k, *rel)
+	r.writeLog(opcode, k, accounts, orders)
+
+	presentRelease(rel)
+
+	log.Printf("Registered in SAP_RELEASES: %#v", *rel)
+
+	return
+}
+
+//
+// Mark the release revoked and record it in the history
+//
+func (r *MemReleaseRepository) Revoke(rel *models.Release, accounts, orders int64) (err error) {
+	log.Printf("Revoking in SAP_RELEASES: %#v", *rel)
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	if err = memFault("SAP_RELEASES", "Revoke"); err != nil {
+		return fmt.Errorf("Error in update SAP_RELEASES: %s", err.Error())
+	}
+
+	k := memRelease(rel.ReleaseId)
+	record, exists := store.releases[k]
+	if exists {
+		record.RecVersion++
+	} else {
+		// released before the registry existed
+		record = models.Release{ReleaseId: k, FromStatus: "C", IntoStatus: "P"}
+	}
+	record.RevokeDate, record.RevokeOwner = time.Now(), r.Owner
+	*rel = record
+
+	r.put(k, *rel)
+	r.writeLog("R", k, accounts, orders)
+
+	presentRelease(rel)
+
+	log.Printf("Revoked in SAP_RELEASES: %#v", *rel)
+
+	return
+}
+
+// number of entries and earliest valid date per release
+func memReleaseStat(stats map[string]*releaseStat, release string, validFromDate time.Time) {
+	if id, _ := strconv.ParseInt(release, 10, 64); id <= 0 {
+		return
+	}
+
+	s, exists := stats[release]
+	if !exists {
+		s = &releaseStat{ReleaseId: release}
+		stats[release] = s
+	}
+	s.Records++
+	if !validFromDate.IsZero() && (s.ValidFromDate.IsZero() || validFromDate.Before(s.ValidFromDate)) {
+		s.ValidFromDate = validFromDate
+	}
+}
+
+//
+// Select all releases with the numbers of entries and history
+//
+func (r *MemReleaseRepository) ReadAll() (releases []models.Release, err error) {
+	log.Printf("Selecting from SAP_RELEASES")
+
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	records := []models.Release{}
+	for _, rel := range store.releases {
+		records = append(records, rel)
+	}
+
+	logs := append([]models.ReleaseLog{}, store.releaseLogs...)
+
+	accountStats := make(map[string]*releaseStat)
+	for k, a := range store.accounts {
+		memReleaseStat(accountStats, k.ReleaseId, a.ValidFromDate)
+	}
+
+	orderStats := make(map[string]*releaseStat)
+	for k, o := range store.orders {
+		memReleaseStat(orderStats, k.ReleaseId, o.ValidFromDate)
+	}
+
+	accounts := []releaseStat{}
+	for _, s := range accountStats {
+		accounts = append(accounts, *s)
+	}
+
+	orders := []releaseStat{}
+	for _, s := range orderStats {
+		orders = append(orders, *s)
+	}
+
+	releases = releaseCatalogue(records, logs, accounts, orders)
+
+	log.Printf("Selected from SAP_RELEASES records: %d", len(releases))
+
+	return
+}
@@ -0,0 +1,207 @@
+/*
+
+PACKAGE: In-memory data access layer
+
+It keeps all the tables in the process memory so that the API can be
+run and unit tested without the Oracle database. The table triggers
+are emulated: REC_VERSION is incremented on update, the *_LOG tables
+receive I, U, D records and entries of Production with the valid date
+in the past can't be changed.
+
+Transactions are kept as undo journal of the repository or of the unit
+of work, the changes are visible to other repositories before the commit.
+
+*/
+
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+type (
+	accountKey struct {
+		Status, ReleaseId, BscsAccount string
+	}
+
+	orderKey struct {
+		Status, ReleaseId, BscsAccount, SegmentCode string
+	}
+
+	memStore struct {
+		m             sync.Mutex
+		accounts      map[accountKey]models.Account
+		accountLogs   []models.AccountLog
+		orders        map[orderKey]models.Order
+		orderLogs     []models.OrderLog
+		segments      map[string]models.DictionarySegment
+		accountsBscs  map[string]models.DictionaryAccountBscs
+		accountsSap   map[string]models.DictionaryAccountSap
+		releases      map[string]models.Release
+		releaseLogs   []models.ReleaseLog
+		approvals     map[int64]models.Approval
+		approvalSteps []models.ApprovalStep
+		deliveries    map[string]models.Delivery
+	}
+)
+
+// Tables shared by all in-memory repositories
+var store = memStore{
+	accounts:     make(map[accountKey]models.Account),
+	orders:       make(map[orderKey]models.Order),
+	segments:     make(map[string]models.DictionarySegment),
+	accountsBscs: make(map[string]models.DictionaryAccountBscs),
+	accountsSap:  make(map[string]models.DictionaryAccountSap),
+	releases:     make(map[string]models.Release),
+	approvals:    make(map[int64]models.Approval),
+	deliveries:   make(map[string]models.Delivery),
+}
+
+//
+// Undo journal of the transaction, shared by the repositories of the unit of work
+//
+type memTransaction struct {
+	undo []func()
+}
+
+// journal of the transaction if the repository is transactional
+func newMemTransaction(trans bool) *memTransaction {
+	if trans {
+		return &memTransaction{}
+	}
+
+	return nil
+}
+
+func (t *memTransaction) commit() {
+	t.undo = nil
+}
+
+func (t *memTransaction) rollback() {
+	store.m.Lock()
+	defer store.m.Unlock()
+
+	for i := len(t.undo) - 1; i >= 0; i-- {
+		t.undo[i]()
+	}
+	t.undo = nil
+}
+
+//
+// Pepository being handled by request
+//
+type memRepository struct {
+	Owner string
+	tx    *memTransaction
+	m     sync.RWMutex
+}
+
+func (r *memRepository) Close() {
+	r.m.Unlock()
+}
+
+func (r *memRepository) Commit() error {
+	if r.tx != nil {
+		r.tx.commit()
+	}
+
+	return nil
+}
+
+func (r *memRepository) Rollback() {
+	if r.tx != nil {
+		r.tx.rollback()
+	}
+}
+
+// register compensation of the change, must be called with store locked
+func (r *memRepository) journal(f func()) {
+	if r.tx != nil {
+		r.tx.undo = append(r.tx.undo, f)
+	}
+}
+
+// RELEASE_ID is a number in the database so "00" and "0" are the same key
+func memRelease(release string) string {
+	if id, err := strconv.ParseInt(release, 10, 64); err == nil {
+		return strconv.FormatInt(id, 10)
+	}
+
+	return release
+}
+
+// numeric order of the releases
+func memReleaseLess(a, b string) bool {
+	x, _ := strconv.ParseInt(a, 10, 64)
+	y, _ := strconv.ParseInt(b, 10, 64)
+
+	return x < y
+}
+
+//
+// Failure of the statement on the in-memory table set by the tests to see
+// the transaction rolled back, called with the table and the operation
+//
+var MemFault func(table, operation string) error
+
+// injected failure of the statement if any
+func memFault(table, operation string) error {
+	if MemFault == nil {
+		return nil
+	}
+
+	return MemFault(table, operation)
+}
+
+// emulation of the before triggers
+func memCheckValidDate(status string, ts time.Time) error {
+	if status == "P" && !ts.IsZero() && ts.Before(time.Now()) {
+		return fmt.Errorf("ORA-20000: Valid Date in the past, validation error")
+	}
+
+	return nil
+}
+
+// conversions of the dynamic json attribute values
+func memString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	return fmt.Sprintf("%v", value)
+}
+
+func memInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		return int(v), nil
+	case float64:
+		return int(v), nil
+	case string:
+		return strconv.Atoi(v)
+	}
+
+	return 0, fmt.Errorf("Invalid number value: %v", value)
+}
+
+func memTime(value interface{}) (ts time.Time, err error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		if ts, err = time.Parse(common.CutOffDateFormat, v); err == nil {
+			return
+		}
+		return time.Parse(time.RFC3339, v)
+	}
+
+	return ts, fmt.Errorf("Invalid date value: %v", value)
+}
@@ -14,10 +14,34 @@ import (
 	"sam-api/models"
 )
 
+//
+// Operations on SAP_ACC_SEGM_ORDER_NUMBERS the controllers depend on
+//
+type OrderRepository interface {
+	Close()
+	Commit() error
+	Rollback()
+	Create(o *models.Order) error
+	ReadBulkByPartialKey(o *models.Order, q *common.ListQuery) ([]models.Order, int64, error)
+	ReadByPrimaryKey(o *models.Order) (int64, error)
+	ReadVersionByPrimaryKey(o *models.Order) (int, int64, error)
+	UpdateByPrimaryKey(o *models.Order) (int64, error)
+	UpdateAttributeByPrimaryKey(o *models.Order, attribute string, value interface{}) (int64, error)
+	Reject(o *models.Order, reason string) (int64, error)
+	DeleteByPrimaryKey(o *models.Order) (int64, error)
+	GetMaxRelease() (int64, error)
+	SetStatusRelease(from, into string, release, releaseNew int64) (int64, error)
+	DeleteAll() (int64, error)
+	GetMinValidDate(status string, release int64) (time.Time, error)
+	ReadLog(account string) ([]models.OrderLog, error)
+	ReadProduction(asOf time.Time, account, segment string) ([]models.Order, error)
+	LogRestore(o *models.Order) error
+}
+
 //
 // Pepository being handled by request
 //
-type OrderRepository struct {
+type DbOrderRepository struct {
 	Repository
 }
 
@@ -31,24 +55,34 @@ func initOrderRepository(db *sql.DB) (dbmap *gorp.DbMap) {
 	return dbmap
 }
 
+//
+// Creates new repository on the configured backend
+//
+func NewOrderRepository(user string, trans bool) (OrderRepository, error) {
+	if common.AppConfig.Backend == common.BackendMemory {
+		return newMemOrderRepository(user, trans), nil
+	}
+
+	r, err := newDbOrderRepository(user, trans)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
 //
 // Creates new repository using existing db connection
 //
-func NewOrderRepository(user string, trans bool) (r *OrderRepository, err error) {
+func newDbOrderRepository(user string, trans bool) (r *DbOrderRepository, err error) {
 	log.Printf("Creating new repository for user: %s", user)
 
 	if db, err := common.GetDbSession(); err != nil {
 		return nil, err
 	} else {
 		dbmap := initRepository(db)
-		dbmap.AddTableWithName(models.Order{}, "SAP_ACC_SEGM_ORDER_NUMBERS").
-			SetKeys(false, "RELEASE_ID").
-			SetKeys(false, "STATUS").
-			SetKeys(false, "BSCS_ACCOUNT").
-			SetKeys(false, "SEGMENT_CODE")
-		dbmap.AddTableWithName(models.OrderLog{}, "SAP_ACC_SEGM_ORDER_NUMBERS_LOG").
-			SetKeys(false, "BSCS_ACCOUNT")
-		r = &OrderRepository{
+		addOrderTables(dbmap)
+		r = &DbOrderRepository{
 			Repository{
 				Owner: user,
 				Db:    db,
@@ -67,20 +101,32 @@ func NewOrderRepository(user string, trans bool) (r *OrderRepository, err error)
 	return
 }
 
-func (r *OrderRepository) Close() {
+// associate the structures with the tables
+func addOrderTables(dbmap *gorp.DbMap) {
+	dbmap.AddTableWithName(models.Order{}, "SAP_ACC_SEGM_ORDER_NUMBERS").
+		SetKeys(false, "RELEASE_ID").
+		SetKeys(false, "STATUS").
+		SetKeys(false, "BSCS_ACCOUNT").
+		SetKeys(false, "SEGMENT_CODE")
+	dbmap.AddTableWithName(models.OrderLog{}, "SAP_ACC_SEGM_ORDER_NUMBERS_LOG").
+		SetKeys(false, "BSCS_ACCOUNT")
+}
+
+func (r *DbOrderRepository) Close() {
 	r.m.Unlock()
 }
 
-func (r *OrderRepository) Commit() {
+func (r *DbOrderRepository) Commit() error {
 	if r.t != nil {
-		err := r.t.Commit()
-		if err != nil {
-			log.Printf("Commit error: %s", err.Error())
+		if err := r.t.Commit(); err != nil {
+			return fmt.Errorf("Commit error: %s", err.Error())
 		}
 	}
+
+	return nil
 }
 
-func (r *OrderRepository) Rollback() {
+func (r *DbOrderRepository) Rollback() {
 	if r.t != nil {
 		err := r.t.Rollback()
 		if err != nil {
@@ -92,7 +138,7 @@ func (r *OrderRepository) Rollback() {
 //
 // Insert new record to the resource SAP_ACC_SEGM_ORDER_NUMBERS
 //
-func (r *OrderRepository) Create(o *models.Order) (err error) {
+func (r *DbOrderRepository) Create(o *models.Order) (err error) {
 	log.Printf("Inserting to SAP_ACC_SEGM_ORDER_NUMBERS: %s %#v", r.Owner, *o)
 
 	// default value
@@ -136,13 +182,13 @@ func (r *OrderRepository) Create(o *models.Order) (err error) {
 //
 // Select some records from the resource SAP_ACC_SEGM_ORDER_NUMBERS, no use of ORP Get  as it returns single record
 //
-func (r *OrderRepository) ReadBulkByPartialKey(o *models.Order) (orders []models.Order, err error) {
+func (r *DbOrderRepository) ReadBulkByPartialKey(o *models.Order, q *common.ListQuery) (orders []models.Order, total int64, err error) {
 	log.Printf("Selecting from SAP_ACC_SEGM_ORDER_NUMBERS with: %#v", *o)
 
 	// Prepeare query binding partial key value set
 	var records = []models.Order{}
 	var query string
-	var binding map[string]interface{}
+	var binding = map[string]interface{}{}
 	columns := []string{
 		"STATUS",
 		"RELEASE_ID",
@@ -157,6 +203,8 @@ func (r *OrderRepository) ReadBulkByPartialKey(o *models.Order) (orders []models
 		"RELEASE_DATE",
 		"RELEASE_OWNER",
 		"REC_VERSION",
+		"REJECT_REASON",
+		"REJECT_OWNER",
 	}
 
 	if o.Status != "" && o.ReleaseId != "" {
@@ -176,17 +224,35 @@ SELECT %s
  FROM SAP_ACC_SEGM_ORDER_NUMBERS Q
 WHERE (STATUS IN ('W', 'C') AND RELEASE_ID = 0) 
    OR (STATUS = 'P' AND RELEASE_ID = 
-      (SELECT NVL(MAX(RELEASE_ID), 0) 
+      (SELECT %s 
          FROM SAP_ACC_SEGM_ORDER_NUMBERS SQ 
         WHERE SQ.BSCS_ACCOUNT = Q.BSCS_ACCOUNT 
           AND SQ.SEGMENT_CODE = Q.SEGMENT_CODE))`,
-			strings.Join(columns, ","))
+			strings.Join(columns, ","), dialect().Nvl("MAX(RELEASE_ID)", "0"))
+	}
+
+	// Apply filters, sorting and paging of the list
+	if q == nil {
+		q = &common.ListQuery{}
+	}
+	query, count, err := listQuery(query, binding, q, orderQueryColumns, "BSCS_ACCOUNT, SEGMENT_CODE, STATUS")
+	if err != nil {
+		return nil, 0, err
 	}
 
 	// Do query by parameteric key
-	_, err = r.Dbmap.Select(&records, query, binding)
+	err = selectPage(r.Dbmap, &records, query, binding)
 	if err != nil {
-		return nil, fmt.Errorf("Error in select from SAP_ACC_SEGM_ORDER_NUMBERS: " + err.Error())
+		return nil, 0, fmt.Errorf("Error in select from SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	// Count all records of the list if only a page of it was selected
+	total = int64(len(records))
+	if q.Limit > 0 || q.Offset > 0 {
+		total, err = r.Dbmap.SelectInt(count, binding)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Error in select from SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+		}
 	}
 
 	// Take care of dates presentation
@@ -207,15 +273,98 @@ WHERE (STATUS IN ('W', 'C') AND RELEASE_ID = 0)
 
 	orders = records
 
-	log.Printf("Selected SAP_ACC_SEGM_ORDER_NUMBERS records: %d %#v", len(records), records)
+	log.Printf("Selected SAP_ACC_SEGM_ORDER_NUMBERS records: %d of %d %#v", len(records), total, records)
 	
 	return
 }
 
+//
+// Select one record from the resource SAP_ACC_SEGM_ORDER_NUMBERS using primary key
+//
+func (r *DbOrderRepository) ReadByPrimaryKey(o *models.Order) (count int64, err error) {
+	log.Printf("Selecting from SAP_ACC_SEGM_ORDER_NUMBERS by key: %#v", *o)
+
+	var record interface{}
+	if r.t != nil {
+		record, err = r.t.Get(models.Order{}, o.Status, o.ReleaseId, o.BscsAccount, o.SegmentCode)
+	} else {
+		record, err = r.Dbmap.Get(models.Order{}, o.Status, o.ReleaseId, o.BscsAccount, o.SegmentCode)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Error in select from SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	} else if record == nil {
+		log.Printf("Selected SAP_ACC_SEGM_ORDER_NUMBERS records: 0")
+		return 0, nil
+	}
+
+	*o = *record.(*models.Order)
+	count = 1
+
+	// Take care of dates presentation
+	if !o.ValidFromDate.IsZero() {
+		o.ValidFromDateStr = o.ValidFromDate.Format(common.CutOffDateFormat)
+	}
+	if !o.EntryDate.IsZero() {
+		o.EntryDateStr = o.EntryDate.Format(common.ModelDateFormat)
+	}
+	if !o.UpdateDate.IsZero() {
+		o.UpdateDateStr = o.UpdateDate.Format(common.ModelDateFormat)
+	}
+	if !o.ReleaseDate.IsZero() {
+		o.ReleaseDateStr = o.ReleaseDate.Format(common.ModelDateFormat)
+	}
+
+	log.Printf("Selected from SAP_ACC_SEGM_ORDER_NUMBERS: %#v", *o)
+
+	return
+}
+
+//
+// Select REC_VERSION of one record using primary key, in a transaction
+// the record stays locked till commit so the version can not change
+//
+func (r *DbOrderRepository) ReadVersionByPrimaryKey(o *models.Order) (version int, count int64, err error) {
+	log.Printf("Selecting REC_VERSION from SAP_ACC_SEGM_ORDER_NUMBERS: %#v", *o)
+
+	query := `
+SELECT REC_VERSION
+  FROM SAP_ACC_SEGM_ORDER_NUMBERS
+ WHERE STATUS = :status
+   AND RELEASE_ID = :release_id
+   AND BSCS_ACCOUNT = :bscs_account
+   AND SEGMENT_CODE = :segment_code`
+	binding := map[string]interface{}{
+		"status":       o.Status,
+		"release_id":   o.ReleaseId,
+		"bscs_account": o.BscsAccount,
+		"segment_code": o.SegmentCode,
+	}
+
+	var rv sql.NullInt64
+	if r.t != nil {
+		rv, err = r.t.SelectNullInt(query+" FOR UPDATE", binding)
+	} else {
+		rv, err = r.Dbmap.SelectNullInt(query, binding)
+	}
+
+	if err != nil {
+		return 0, 0, fmt.Errorf("Error in select from SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	if rv.Valid {
+		version, count = int(rv.Int64), 1
+	}
+
+	log.Printf("Selected REC_VERSION from SAP_ACC_SEGM_ORDER_NUMBERS: %d, count: %d", version, count)
+
+	return
+}
+
 //
 // Update one record in resource SAP_ACCOUNTS using primary key
 //
-func (r *OrderRepository) UpdateByPrimaryKey(a *models.Order) (count int64, err error) {
+func (r *DbOrderRepository) UpdateByPrimaryKey(a *models.Order) (count int64, err error) {
 	log.Printf("Updating SAP_ACC_SEGM_ORDER_NUMBERS with: %#v", *a)
 
 	// build dynamic sql statement
@@ -230,6 +379,7 @@ WHERE STATUS = :5
   AND BSCS_ACCOUNT = :7
   AND SEGMENT_CODE = :8
 `
+	stmt = dialect().Rebind(stmt)
 	// run it
 	var rs sql.Result
 	if r.t != nil {
@@ -271,7 +421,7 @@ WHERE STATUS = :5
 //
 // Update one column of the record in resource SAP_ACCOUNTS using primary key
 //
-func (r *OrderRepository) UpdateAttributeByPrimaryKey(o *models.Order, attribute string, value interface{}) (count int64, err error) {
+func (r *DbOrderRepository) UpdateAttributeByPrimaryKey(o *models.Order, attribute string, value interface{}) (count int64, err error) {
 	log.Printf("Updating SAP_ACC_SEGM_ORDER_NUMBERS: %s <- %v with key: %#v", attribute, value, *o)
 
 	// build dynamic sql statement
@@ -301,7 +451,7 @@ WHERE STATUS = :4
 		return 0, fmt.Errorf("No column name for attribute: %s", attribute)
 	}
 
-	stmt = fmt.Sprintf(stmt, colname)
+	stmt = dialect().Rebind(fmt.Sprintf(stmt, colname))
 
 	// run it
 	var rs sql.Result
@@ -339,10 +489,56 @@ WHERE STATUS = :4
 	return
 }
 
+//
+// Move the record in status C back to W with the reason of the rejection
+//
+func (r *DbOrderRepository) Reject(o *models.Order, reason string) (count int64, err error) {
+	log.Printf("Rejecting SAP_ACC_SEGM_ORDER_NUMBERS: %s %#v", reason, *o)
+
+	var stmt = `
+UPDATE SAP_ACC_SEGM_ORDER_NUMBERS
+SET STATUS = 'W',
+	REJECT_REASON = :1,
+	REJECT_OWNER = :2,
+	UPDATE_DATE = :3,
+	UPDATE_OWNER = :4
+WHERE STATUS = 'C'
+  AND RELEASE_ID = 0
+  AND BSCS_ACCOUNT = :5
+  AND SEGMENT_CODE = :6
+`
+	stmt = dialect().Rebind(stmt)
+
+	var rs sql.Result
+	if r.t != nil {
+		rs, err = r.t.Exec(stmt, reason, r.Owner, time.Now(), r.Owner, o.BscsAccount, o.SegmentCode)
+	} else {
+		rs, err = r.Dbmap.Exec(stmt, reason, r.Owner, time.Now(), r.Owner, o.BscsAccount, o.SegmentCode)
+	}
+
+	if err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	count, err = rs.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("Error in update of SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	if count > 0 {
+		o.Status, o.ReleaseId = "W", "0"
+		o.RejectReason, o.RejectOwner = reason, r.Owner
+	}
+
+	log.Printf("Rejected SAP_ACC_SEGM_ORDER_NUMBERS records: %d", count)
+
+	return
+}
+
 //
 // Delete some records from resource SAP_ACC_SEGM_ORDER_NUMBERS
 //
-func (r *OrderRepository) DeleteByPrimaryKey(o *models.Order) (count int64, err error) {
+func (r *DbOrderRepository) DeleteByPrimaryKey(o *models.Order) (count int64, err error) {
 	log.Printf("Deleting from SAP_ACC_SEGM_ORDER_NUMBERS: %#v", *o)
 
 	// Do delete by primary key
@@ -364,7 +560,7 @@ func (r *OrderRepository) DeleteByPrimaryKey(o *models.Order) (count int64, err
 //
 // Select max
 //
-func (r *OrderRepository) GetMaxRelease() (release int64, err error) {
+func (r *DbOrderRepository) GetMaxRelease() (release int64, err error) {
 	log.Printf("Selecting MAX(RELEASE_ID) from SAP_ACC_SEGM_ORDER_NUMBERS")
 
 	// do query on max but table may be empty
@@ -377,23 +573,34 @@ func (r *OrderRepository) GetMaxRelease() (release int64, err error) {
 //
 // Release
 //
-func (r *OrderRepository) SetStatusRelease(from, into string, release, releaseNew int64) (count int64, err error) {
+func (r *DbOrderRepository) SetStatusRelease(from, into string, release, releaseNew int64) (count int64, err error) {
 	log.Printf("Set RELEASE, STATUS for SAP_ACC_SEGM_ORDER_NUMBERS")
 
+	// released entries get the date and owner, revoked ones lose them
+	var releaseDate, releaseOwner interface{}
+	if into != "W" {
+		releaseDate, releaseOwner = time.Now(), r.Owner
+	}
+
 	// do update
 	stmt := `
 UPDATE SAP_ACC_SEGM_ORDER_NUMBERS
 SET STATUS = :1,
-	RELEASE_ID = :2
-WHERE STATUS = :3
-AND RELEASE_ID = :4
+	RELEASE_ID = :2,
+	RELEASE_DATE = :3,
+	RELEASE_OWNER = :4,
+	REJECT_REASON = NULL,
+	REJECT_OWNER = NULL
+WHERE STATUS = :5
+AND RELEASE_ID = :6
 `
+	stmt = dialect().Rebind(stmt)
 	
 	var rs sql.Result
 	if r.t != nil {
-		rs, err = r.t.Exec(stmt, into, releaseNew, from, release)
+		rs, err = r.t.Exec(stmt, into, releaseNew, releaseDate, releaseOwner, from, release)
 	} else {
-		rs, err = r.Dbmap.Exec(stmt, into, releaseNew, from, release)
+		rs, err = r.Dbmap.Exec(stmt, into, releaseNew, releaseDate, releaseOwner, from, release)
 	}
 
 	if err != nil {
@@ -413,7 +620,7 @@ AND RELEASE_ID = :4
 //
 // Purge
 //
-func (r *OrderRepository) DeleteAll() (count int64, err error) {
+func (r *DbOrderRepository) DeleteAll() (count int64, err error) {
 	log.Printf("Deleting from SAP_ACC_SEGM_ORDER_NUMBERS")
 
 	// do query
@@ -442,12 +649,12 @@ func (r *OrderRepository) DeleteAll() (count int64, err error) {
 //
 // Used for validation of the lowwr bound of the order package
 //
-func (r *OrderRepository) GetMinValidDate(status string, release int64) (ts time.Time, err error) {
-	query := `
-SELECT NVL(MIN(VALID_FROM_DATE), SYSDATE)
+func (r *DbOrderRepository) GetMinValidDate(status string, release int64) (ts time.Time, err error) {
+	query := fmt.Sprintf(`
+SELECT %s
 FROM SAP_ACC_SEGM_ORDER_NUMBERS
 WHERE STATUS = :status 
-AND RELEASE_ID = :release`
+AND RELEASE_ID = :release`, dialect().Nvl("MIN(VALID_FROM_DATE)", dialect().Sysdate()))
 
 	binding := map[string]interface{}{
 		"release": release,
@@ -463,9 +670,9 @@ AND RELEASE_ID = :release`
 }
 
 //
-// Read logs of the account orders
+// Read logs of the account orders in the order of operations, all accounts if empty
 //
-func (r *OrderRepository) ReadLog(account string) (logs []models.OrderLog, err error) {
+func (r *DbOrderRepository) ReadLog(account string) (logs []models.OrderLog, err error) {
 	var records = []models.OrderLog{}
 	var query string
 	var binding map[string]interface{}
@@ -485,12 +692,17 @@ func (r *OrderRepository) ReadLog(account string) (logs []models.OrderLog, err e
 		"RELEASE_DATE",
 		"RELEASE_OWNER",
 		"REC_VERSION",
+		"REJECT_REASON",
+		"REJECT_OWNER",
 	}
 
-	query = fmt.Sprintf(`SELECT %s FROM SAP_ACC_SEGM_ORDER_NUMBERS_LOG WHERE BSCS_ACCOUNT = :account`, strings.Join(columns, ","))
-	binding = map[string]interface{}{
-		"account": account,
+	query = fmt.Sprintf(`SELECT %s FROM SAP_ACC_SEGM_ORDER_NUMBERS_LOG`, strings.Join(columns, ","))
+	binding = map[string]interface{}{}
+	if account != "" {
+		query += ` WHERE BSCS_ACCOUNT = :account`
+		binding["account"] = account
 	}
+	query += ` ORDER BY OPDATE, REC_VERSION`
 	
 	_, err = r.Dbmap.Select(&records, query, binding)
 	if err != nil {
@@ -511,6 +723,7 @@ func (r *OrderRepository) ReadLog(account string) (logs []models.OrderLog, err e
 		if !r.ReleaseDate.IsZero() {
 			records[i].ReleaseDateStr = r.ReleaseDate.Format(common.ModelDateFormat)
 		}
+		records[i].Id = records[i].LogId()
 	}
 
 	logs = records
@@ -519,3 +732,107 @@ func (r *OrderRepository) ReadLog(account string) (logs []models.OrderLog, err e
 
 	return
 }
+
+//
+// Write the restore of the order in Work to SAP_ACC_SEGM_ORDER_NUMBERS_LOG, the trigger writes
+// the insert or update of the row, this one tells it was a restore
+//
+func (r *DbOrderRepository) LogRestore(o *models.Order) (err error) {
+	entry := &models.OrderLog{
+		OpCode: models.OpRestore,
+		OpDate: time.Now(),
+		Order:  *o,
+	}
+	entry.UpdateDate = entry.OpDate
+	entry.UpdateOwner = r.Owner
+
+	if r.t != nil {
+		err = r.t.Insert(entry)
+	} else {
+		err = r.Dbmap.Insert(entry)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in insert to SAP_ACC_SEGM_ORDER_NUMBERS_LOG: %s", err.Error())
+	}
+
+	log.Printf("Inserted to SAP_ACC_SEGM_ORDER_NUMBERS_LOG: %#v", *entry)
+
+	return
+}
+
+//
+// Read the orders of all releases in production valid on the date,
+// the valid date not set is valid from the release, all accounts
+// and segments if empty, all the versions if the date is zero
+//
+func (r *DbOrderRepository) ReadProduction(asOf time.Time, account, segment string) (orders []models.Order, err error) {
+	var records = []models.Order{}
+	columns := []string{
+		"STATUS",
+		"RELEASE_ID",
+		"BSCS_ACCOUNT",
+		"SEGMENT_CODE",
+		"ORDER_NUMBER",
+		"VALID_FROM_DATE",
+		"ENTRY_DATE",
+		"ENTRY_OWNER",
+		"UPDATE_DATE",
+		"UPDATE_OWNER",
+		"RELEASE_DATE",
+		"RELEASE_OWNER",
+		"REC_VERSION",
+		"REJECT_REASON",
+		"REJECT_OWNER",
+	}
+
+	query := fmt.Sprintf(`
+SELECT %s
+FROM SAP_ACC_SEGM_ORDER_NUMBERS
+WHERE STATUS = 'P'`, strings.Join(columns, ", "))
+	binding := map[string]interface{}{}
+	if !asOf.IsZero() {
+		query += `
+AND (VALID_FROM_DATE IS NULL OR VALID_FROM_DATE <= :as_of)`
+		binding["as_of"] = asOf
+	}
+	if account != "" {
+		query += `
+AND BSCS_ACCOUNT = :account`
+		binding["account"] = account
+	}
+	if segment != "" {
+		query += `
+AND SEGMENT_CODE = :segment`
+		binding["segment"] = segment
+	}
+	query += `
+ORDER BY BSCS_ACCOUNT, SEGMENT_CODE, RELEASE_ID`
+
+	_, err = r.Dbmap.Select(&records, query, binding)
+	if err != nil {
+		return nil, fmt.Errorf("Error in select from SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	// Take care of dates presentation
+	for i, r := range records {
+		if !r.ValidFromDate.IsZero() {
+			records[i].ValidFromDateStr = r.ValidFromDate.Format(common.CutOffDateFormat)
+		}
+		if !r.EntryDate.IsZero() {
+			records[i].EntryDateStr = r.EntryDate.Format(common.ModelDateFormat)
+		}
+		if !r.UpdateDate.IsZero() {
+			records[i].UpdateDateStr = r.UpdateDate.Format(common.ModelDateFormat)
+		}
+		if !r.ReleaseDate.IsZero() {
+			records[i].ReleaseDateStr = r.ReleaseDate.Format(common.ModelDateFormat)
+		}
+	}
+
+	orders = records
+
+	log.Printf("Selected from SAP_ACC_SEGM_ORDER_NUMBERS in production as of: %s records: %d", asOf.Format(common.CutOffDateFormat), len(records))
+
+	return
+}
@@ -0,0 +1,317 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/gorp.v2"
+	"sam-api/common"
+)
+
+// kinds of the columns deciding how the filter values are bound and compared
+const (
+	columnString = iota
+	columnNumber
+	columnDate
+)
+
+//
+// Column of the table the list query attribute is mapped to,
+// only the mapped attributes can be used in filters and sorting
+//
+type queryColumn struct {
+	Name string
+	Kind int
+}
+
+var accountQueryColumns = map[string]queryColumn{
+	"status":           {"STATUS", columnString},
+	"releaseId":        {"RELEASE_ID", columnNumber},
+	"bscsAccount":      {"BSCS_ACCOUNT", columnString},
+	"ofiSapAccount":    {"OFI_SAP_ACCOUNT", columnString},
+	"validFromDate":    {"VALID_FROM_DATE", columnDate},
+	"vatCodeInd":       {"VAT_CODE_IND", columnString},
+	"ofiSapWbsCode":    {"OFI_SAP_WBS_CODE", columnString},
+	"citMarkerVatFlag": {"CIT_MARKER_VAT_FLAG", columnNumber},
+	"entryDate":        {"ENTRY_DATE", columnDate},
+	"entryOwner":       {"ENTRY_OWNER", columnString},
+	"updateDate":       {"UPDATE_DATE", columnDate},
+	"releaseDate":      {"RELEASE_DATE", columnDate},
+	"recVersion":       {"REC_VERSION", columnNumber},
+	"rejectReason":     {"REJECT_REASON", columnString},
+	"rejectOwner":      {"REJECT_OWNER", columnString},
+}
+
+var orderQueryColumns = map[string]queryColumn{
+	"status":        {"STATUS", columnString},
+	"releaseId":     {"RELEASE_ID", columnNumber},
+	"bscsAccount":   {"BSCS_ACCOUNT", columnString},
+	"segmentCode":   {"SEGMENT_CODE", columnString},
+	"orderNumber":   {"ORDER_NUMBER", columnString},
+	"validFromDate": {"VALID_FROM_DATE", columnDate},
+	"entryDate":     {"ENTRY_DATE", columnDate},
+	"entryOwner":    {"ENTRY_OWNER", columnString},
+	"updateDate":    {"UPDATE_DATE", columnDate},
+	"releaseDate":   {"RELEASE_DATE", columnDate},
+	"recVersion":    {"REC_VERSION", columnNumber},
+	"rejectReason":  {"REJECT_REASON", columnString},
+	"rejectOwner":   {"REJECT_OWNER", columnString},
+}
+
+var dictionaryAccountBscsQueryColumns = map[string]queryColumn{
+	"account":    {"GLACODE", columnString},
+	"name":       {"GLADESC", columnString},
+	"type":       {"GLATYPE", columnString},
+	"active":     {"GLACTIVE", columnString},
+	"entryDate":  {"ENTRY_DATE", columnDate},
+	"entryOwner": {"ENTRY_OWNER", columnString},
+	"updateDate": {"UPDATE_DATE", columnDate},
+}
+
+var dictionaryAccountSapQueryColumns = map[string]queryColumn{
+	"sapOfiAccount": {"SAP_OFI_ACCOUNT", columnString},
+	"name":          {"NAME", columnString},
+	"status":        {"STATUS", columnString},
+	"entryDate":     {"ENTRY_DATE", columnDate},
+	"entryOwner":    {"ENTRY_OWNER", columnString},
+	"updateDate":    {"UPDATE_DATE", columnDate},
+	"recVersion":    {"REC_VERSION", columnNumber},
+}
+
+// filter value converted to the type of the column
+func (c queryColumn) value(v string) (interface{}, error) {
+	switch c.Kind {
+	case columnNumber:
+		if n, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return nil, common.InvalidQueryError{Reason: fmt.Sprintf("Invalid number: %s", v)}
+		} else {
+			return n, nil
+		}
+
+	case columnDate:
+		if t, err := time.Parse(common.CutOffDateFormat, v); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse(common.ModelDateFormat, v); err == nil {
+			return t, nil
+		}
+		return nil, common.InvalidQueryError{Reason: fmt.Sprintf("Invalid date: %s", v)}
+	}
+
+	return v, nil
+}
+
+// column of the attribute used in the list query
+func lookupQueryColumn(columns map[string]queryColumn, attribute string) (queryColumn, error) {
+	if c, ok := columns[attribute]; ok {
+		return c, nil
+	}
+
+	return queryColumn{}, common.InvalidQueryError{Reason: fmt.Sprintf("Unknown attribute: %s", attribute)}
+}
+
+//
+// Builds statements selecting a page of the base query result and counting
+// all its rows matching the filters. The filter values are bound by name,
+// the column names are taken from the mapping only so nothing from the
+// request gets into the statement text.
+//
+func listQuery(base string, binding map[string]interface{}, q *common.ListQuery, columns map[string]queryColumn, order string) (query, count string, err error) {
+	var where, orderBy []string
+	for i, f := range q.Filters {
+		c, err := lookupQueryColumn(columns, f.Attribute)
+		if err != nil {
+			return "", "", err
+		}
+		name := fmt.Sprintf("filter%d", i)
+		if binding[name], err = c.value(f.Value); err != nil {
+			return "", "", err
+		}
+		op := f.Op
+		if op == "!=" {
+			op = "<>"
+		}
+		where = append(where, fmt.Sprintf("%s %s :%s", c.Name, op, name))
+	}
+
+	for _, k := range q.Sort {
+		c, err := lookupQueryColumn(columns, k.Attribute)
+		if err != nil {
+			return "", "", err
+		}
+		if k.Desc {
+			orderBy = append(orderBy, c.Name+" DESC")
+		} else {
+			orderBy = append(orderBy, c.Name)
+		}
+	}
+	orderBy = append(orderBy, order)
+
+	from := fmt.Sprintf("FROM (%s) T", base)
+	if len(where) > 0 {
+		from += "\nWHERE " + strings.Join(where, "\n  AND ")
+	}
+
+	query = dialect().Page(fmt.Sprintf("SELECT * %s\nORDER BY %s", from, strings.Join(orderBy, ", ")), q.Offset, q.Limit)
+	count = fmt.Sprintf("SELECT COUNT(*) %s", from)
+
+	return
+}
+
+//
+// Select of the list query, the column numbering the rows of the page
+// is not mapped to the records so GORP reports it as non fatal error
+//
+func selectPage(dbmap *gorp.DbMap, records interface{}, query string, binding map[string]interface{}) (err error) {
+	if _, err = dbmap.Select(records, query, binding); err != nil && gorp.NonFatalError(err) {
+		err = nil
+	}
+
+	return
+}
+
+// field of the record mapped to the column with db tag
+func memColumnValue(record reflect.Value, c queryColumn) interface{} {
+	t := record.Type()
+	for i := 0; i < t.NumField(); i++ {
+		if strings.Split(t.Field(i).Tag.Get("db"), ",")[0] == c.Name {
+			return record.Field(i).Interface()
+		}
+	}
+
+	return nil
+}
+
+// -1, 0, 1 as the record value is less, equal or greater than the other one
+func memCompare(c queryColumn, v, other interface{}) int {
+	switch c.Kind {
+	case columnNumber:
+		var a, b int64
+		for i, x := range []interface{}{v, other} {
+			var n int64
+			switch x.(type) {
+			case int:
+				n = int64(x.(int))
+			case int64:
+				n = x.(int64)
+			case string:
+				n, _ = strconv.ParseInt(x.(string), 10, 64)
+			}
+			if i == 0 {
+				a = n
+			} else {
+				b = n
+			}
+		}
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+
+	case columnDate:
+		a, b := v.(time.Time), other.(time.Time)
+		if a.Before(b) {
+			return -1
+		} else if a.After(b) {
+			return 1
+		}
+
+	default:
+		return strings.Compare(fmt.Sprintf("%v", v), fmt.Sprintf("%v", other))
+	}
+
+	return 0
+}
+
+//
+// Applies the filters, sorting and paging of the list query on the records
+// of the in-memory table, it returns the count of all matching records
+//
+func memListQuery(records interface{}, q *common.ListQuery, columns map[string]queryColumn) (total int64, err error) {
+	slice := reflect.ValueOf(records).Elem()
+
+	filters := make([]queryColumn, len(q.Filters))
+	values := make([]interface{}, len(q.Filters))
+	for i, f := range q.Filters {
+		if filters[i], err = lookupQueryColumn(columns, f.Attribute); err != nil {
+			return 0, err
+		}
+		if values[i], err = filters[i].value(f.Value); err != nil {
+			return 0, err
+		}
+	}
+
+	// filter in place
+	matching := 0
+	for i := 0; i < slice.Len(); i++ {
+		match := true
+		for n, f := range q.Filters {
+			rc := memCompare(filters[n], memColumnValue(slice.Index(i), filters[n]), values[n])
+			switch f.Op {
+			case "=":
+				match = rc == 0
+			case "!=":
+				match = rc != 0
+			case ">":
+				match = rc > 0
+			case ">=":
+				match = rc >= 0
+			case "<":
+				match = rc < 0
+			case "<=":
+				match = rc <= 0
+			}
+			if !match {
+				break
+			}
+		}
+		if match {
+			slice.Index(matching).Set(slice.Index(i))
+			matching++
+		}
+	}
+	slice.Set(slice.Slice(0, matching))
+	total = int64(matching)
+
+	// records are in the default order already so only the keys given are used
+	keys := make([]queryColumn, len(q.Sort))
+	for i, k := range q.Sort {
+		if keys[i], err = lookupQueryColumn(columns, k.Attribute); err != nil {
+			return 0, err
+		}
+	}
+	if len(keys) > 0 {
+		sorted := reflect.MakeSlice(slice.Type(), slice.Len(), slice.Len())
+		reflect.Copy(sorted, slice)
+		sort.SliceStable(sorted.Interface(), func(i, j int) bool {
+			for n, c := range keys {
+				rc := memCompare(c, memColumnValue(sorted.Index(i), c), memColumnValue(sorted.Index(j), c))
+				if q.Sort[n].Desc {
+					rc = -rc
+				}
+				if rc != 0 {
+					return rc < 0
+				}
+			}
+			return false
+		})
+		slice.Set(sorted)
+	}
+
+	// the page
+	from, to := q.Offset, slice.Len()
+	if from > to {
+		from = to
+	}
+	if q.Limit > 0 && from+q.Limit < to {
+		to = from + q.Limit
+	}
+	slice.Set(slice.Slice(from, to))
+
+	return
+}
@@ -0,0 +1,420 @@
+/*
+
+PACKAGE: Data access layer for Release -> SAP_RELEASES table
+
+It keeps the registry of the releases of SAP_ACCOUNTS and
+SAP_ACC_SEGM_ORDER_NUMBERS entries. Each release, append
+and revoke is recorded in SAP_RELEASES_LOG so the history
+of the release is available. The numbers of entries and
+the earliest valid date are taken from the released tables,
+the releases done before the registry existed are listed
+as well.
+
+*/
+
+package repository
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"database/sql"
+	_ "gopkg.in/goracle.v2"
+	"gopkg.in/gorp.v2"
+
+	"sam-api/common"
+	"sam-api/models"
+)
+
+//
+// Operations on SAP_RELEASES the controllers depend on
+//
+type ReleaseRepository interface {
+	Close()
+	Commit() error
+	Rollback()
+	Register(rel *models.Release, opcode string, accounts, orders int64) error
+	Revoke(rel *models.Release, accounts, orders int64) error
+	ReadAll() ([]models.Release, error)
+}
+
+//
+// Pepository being handled by request
+//
+type DbReleaseRepository struct {
+	Repository
+}
+
+// Number of entries and earliest valid date of the release in the released table
+type releaseStat struct {
+	ReleaseId     string    `db:"RELEASE_ID"`
+	Records       int64     `db:"RECORDS"`
+	ValidFromDate time.Time `db:"VALID_FROM_DATE"`
+}
+
+//
+// Creates new repository on the configured backend
+//
+func NewReleaseRepository(user string, trans bool) (ReleaseRepository, error) {
+	if common.AppConfig.Backend == common.BackendMemory {
+		return newMemReleaseRepository(user, trans), nil
+	}
+
+	r, err := newDbReleaseRepository(user, trans)
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+//
+// Creates new repository using existing db connection
+//
+func newDbReleaseRepository(user string, trans bool) (r *DbReleaseRepository, err error) {
+	log.Printf("Creating new repository for user: %s", user)
+
+	if db, err := common.GetDbSession(); err != nil {
+		return nil, err
+	} else {
+		dbmap := initRepository(db)
+		addReleaseTables(dbmap)
+		r = &DbReleaseRepository{
+			Repository{
+				Owner: user,
+				Db:    db,
+				Dbmap: dbmap,
+			},
+		}
+		if trans {
+			r.t, err = dbmap.Begin()
+			if err != nil {
+				return nil, err
+			}
+		}
+		r.m.Lock()
+	}
+
+	return
+}
+
+// associate the structures with the tables
+func addReleaseTables(dbmap *gorp.DbMap) {
+	dbmap.AddTableWithName(models.Release{}, "SAP_RELEASES").
+		SetKeys(false, "RELEASE_ID")
+	dbmap.AddTableWithName(models.ReleaseLog{}, "SAP_RELEASES_LOG")
+}
+
+func (r *DbReleaseRepository) Close() {
+	r.m.Unlock()
+}
+
+func (r *DbReleaseRepository) Commit() error {
+	if r.t != nil {
+		if err := r.t.Commit(); err != nil {
+			return fmt.Errorf("Commit error: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (r *DbReleaseRepository) Rollback() {
+	if r.t != nil {
+		err := r.t.Rollback()
+		if err != nil {
+			log.Printf("Rollback error: %s", err.Error())
+		}
+	}
+}
+
+var releaseColumns = []string{
+	"RELEASE_ID",
+	"FROM_STATUS",
+	"INTO_STATUS",
+	"DESCRIPTION",
+	"RELEASE_DATE",
+	"RELEASE_OWNER",
+	"REVOKE_DATE",
+	"REVOKE_OWNER",
+	"REC_VERSION",
+}
+
+// Take care of dates presentation
+func presentRelease(rel *models.Release) {
+	if !rel.ReleaseDate.IsZero() {
+		rel.ReleaseDateStr = rel.ReleaseDate.Format(common.ModelDateFormat)
+	}
+	if !rel.RevokeDate.IsZero() {
+		rel.RevokeDateStr = rel.RevokeDate.Format(common.ModelDateFormat)
+	}
+}
+
+// Release with the numbers of entries, earliest valid date and history, ordered by id
+func releaseCatalogue(releases []models.Release, logs []models.ReleaseLog, accounts, orders []releaseStat) (catalogue []models.Release) {
+	index := make(map[string]*models.Release)
+	validFrom := make(map[string]time.Time)
+
+	entry := func(id string) *models.Release {
+		id = memRelease(id)
+		if _, exists := index[id]; !exists {
+			// released before the registry existed
+			index[id] = &models.Release{ReleaseId: id, FromStatus: "C", IntoStatus: "P"}
+		}
+		return index[id]
+	}
+
+	stat := func(s releaseStat) {
+		if v, exists := validFrom[memRelease(s.ReleaseId)]; !s.ValidFromDate.IsZero() && (!exists || s.ValidFromDate.Before(v)) {
+			validFrom[memRelease(s.ReleaseId)] = s.ValidFromDate
+		}
+	}
+
+	for i := range releases {
+		rel := releases[i]
+		rel.ReleaseId = memRelease(rel.ReleaseId)
+		index[rel.ReleaseId] = &rel
+	}
+
+	for _, l := range logs {
+		l.OpDateStr = l.OpDate.Format(common.ModelDateFormat)
+		rel := entry(l.ReleaseId)
+		rel.History = append(rel.History, l)
+	}
+
+	for _, s := range accounts {
+		entry(s.ReleaseId).Accounts = s.Records
+		stat(s)
+	}
+
+	for _, s := range orders {
+		entry(s.ReleaseId).Orders = s.Records
+		stat(s)
+	}
+
+	catalogue = []models.Release{}
+	for id, rel := range index {
+		presentRelease(rel)
+		if v, exists := validFrom[id]; exists {
+			rel.ValidFromDateStr = v.Format(common.CutOffDateFormat)
+		}
+		catalogue = append(catalogue, *rel)
+	}
+
+	sort.Slice(catalogue, func(i, j int) bool {
+		a, _ := strconv.ParseInt(catalogue[i].ReleaseId, 10, 64)
+		b, _ := strconv.ParseInt(catalogue[j].ReleaseId, 10, 64)
+		return a < b
+	})
+
+	return
+}
+
+// record the operation in the history, must be called in transaction
+func (r *DbReleaseRepository) writeLog(opcode, release string, accounts, orders int64) (err error) {
+	l := &models.ReleaseLog{
+		ReleaseId: release,
+		OpCode:    opcode,
+		OpDate:    time.Now(),
+		OpOwner:   r.Owner,
+		Accounts:  accounts,
+		Orders:    orders,
+	}
+
+	if r.t != nil {
+		err = r.t.Insert(l)
+	} else {
+		err = r.Dbmap.Insert(l)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in insert to SAP_RELEASES_LOG: %s", err.Error())
+	}
+
+	return
+}
+
+//
+// Insert the release or update it when appended or released again after revoke,
+// opcode N is the new release, A is append to the existing one
+//
+func (r *DbReleaseRepository) Register(rel *models.Release, opcode string, accounts, orders int64) (err error) {
+	log.Printf("Registering in SAP_RELEASES: %s %#v", opcode, *rel)
+
+	query := fmt.Sprintf("SELECT %s FROM SAP_RELEASES WHERE RELEASE_ID = :release_id", strings.Join(releaseColumns, ", "))
+	binding := map[string]interface{}{
+		"release_id": rel.ReleaseId,
+	}
+
+	records := []models.Release{}
+	if r.t != nil {
+		_, err = r.t.Select(&records, query, binding)
+	} else {
+		_, err = r.Dbmap.Select(&records, query, binding)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in select from SAP_RELEASES: %s", err.Error())
+	}
+
+	var stmt string
+	var args []interface{}
+	if len(records) == 0 {
+		rel.ReleaseDate, rel.ReleaseOwner = time.Now(), r.Owner
+		stmt = `
+INSERT INTO SAP_RELEASES
+(RELEASE_ID, FROM_STATUS, INTO_STATUS, DESCRIPTION, RELEASE_DATE, RELEASE_OWNER, REC_VERSION)
+VALUES (:1, :2, :3, :4, :5, :6, 0)
+`
+		args = []interface{}{rel.ReleaseId, rel.FromStatus, rel.IntoStatus, rel.Description, rel.ReleaseDate, rel.ReleaseOwner}
+	} else {
+		record := records[0]
+		if opcode == "N" || record.ReleaseDate.IsZero() {
+			record.FromStatus, record.IntoStatus = rel.FromStatus, rel.IntoStatus
+			record.ReleaseDate, record.ReleaseOwner = time.Now(), r.Owner
+		}
+		if rel.Description != "" {
+			record.Description = rel.Description
+		}
+		record.RevokeDate, record.RevokeOwner = time.Time{}, ""
+		record.RecVersion++
+		*rel = record
+		stmt = `
+UPDATE SAP_RELEASES
+SET FROM_STATUS = :1,
+    INTO_STATUS = :2,
+    DESCRIPTION = :3,
+    RELEASE_DATE = :4,
+    RELEASE_OWNER = :5,
+    REVOKE_DATE = NULL,
+    REVOKE_OWNER = NULL,
+    REC_VERSION = REC_VERSION + 1
+WHERE RELEASE_ID = :6
+`
+		args = []interface{}{rel.FromStatus, rel.IntoStatus, rel.Description, rel.ReleaseDate, rel.ReleaseOwner, rel.ReleaseId}
+	}
+
+	stmt = dialect().Rebind(stmt)
+	if r.t != nil {
+		_, err = r.t.Exec(stmt, args...)
+	} else {
+		_, err = r.Dbmap.Exec(stmt, args...)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in update SAP_RELEASES: %s", err.Error())
+	}
+
+	if err = r.writeLog(opcode, rel.ReleaseId, accounts, orders); err != nil {
+		return
+	}
+
+	presentRelease(rel)
+
+	log.Printf("Registered in SAP_RELEASES: %#v", *rel)
+
+	return
+}
+
+//
+// Mark the release revoked and record it in the history
+//
+func (r *DbReleaseRepository) Revoke(rel *models.Release, accounts, orders int64) (err error) {
+	log.Printf("Revoking in SAP_RELEASES: %#v", *rel)
+
+	rel.RevokeDate, rel.RevokeOwner = time.Now(), r.Owner
+
+	stmt := dialect().Rebind(`
+UPDATE SAP_RELEASES
+SET REVOKE_DATE = :1,
+    REVOKE_OWNER = :2,
+    REC_VERSION = REC_VERSION + 1
+WHERE RELEASE_ID = :3
+`)
+
+	var rs sql.Result
+	if r.t != nil {
+		rs, err = r.t.Exec(stmt, rel.RevokeDate, rel.RevokeOwner, rel.ReleaseId)
+	} else {
+		rs, err = r.Dbmap.Exec(stmt, rel.RevokeDate, rel.RevokeOwner, rel.ReleaseId)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Error in update SAP_RELEASES: %s", err.Error())
+	}
+
+	// released before the registry existed
+	if count, _ := rs.RowsAffected(); count == 0 {
+		stmt = dialect().Rebind(`
+INSERT INTO SAP_RELEASES
+(RELEASE_ID, FROM_STATUS, INTO_STATUS, REVOKE_DATE, REVOKE_OWNER, REC_VERSION)
+VALUES (:1, 'C', 'P', :2, :3, 0)
+`)
+		if r.t != nil {
+			_, err = r.t.Exec(stmt, rel.ReleaseId, rel.RevokeDate, rel.RevokeOwner)
+		} else {
+			_, err = r.Dbmap.Exec(stmt, rel.ReleaseId, rel.RevokeDate, rel.RevokeOwner)
+		}
+
+		if err != nil {
+			return fmt.Errorf("Error in insert to SAP_RELEASES: %s", err.Error())
+		}
+	}
+
+	if err = r.writeLog("R", rel.ReleaseId, accounts, orders); err != nil {
+		return
+	}
+
+	presentRelease(rel)
+
+	log.Printf("Revoked in SAP_RELEASES: %#v", *rel)
+
+	return
+}
+
+//
+// Select all releases with the numbers of entries and history
+//
+func (r *DbReleaseRepository) ReadAll() (releases []models.Release, err error) {
+	log.Printf("Selecting from SAP_RELEASES")
+
+	records := []models.Release{}
+	query := fmt.Sprintf("SELECT %s FROM SAP_RELEASES", strings.Join(releaseColumns, ", "))
+	if _, err = r.Dbmap.Select(&records, query); err != nil {
+		return nil, fmt.Errorf("Error in select from SAP_RELEASES: %s", err.Error())
+	}
+
+	logs := []models.ReleaseLog{}
+	query = "SELECT RELEASE_ID, OPCODE, OPDATE, OPOWNER, ACCOUNTS, ORDERS FROM SAP_RELEASES_LOG ORDER BY OPDATE"
+	if _, err = r.Dbmap.Select(&logs, query); err != nil {
+		return nil, fmt.Errorf("Error in select from SAP_RELEASES_LOG: %s", err.Error())
+	}
+
+	stats := `
+SELECT RELEASE_ID,
+       COUNT(*) AS RECORDS,
+       MIN(VALID_FROM_DATE) AS VALID_FROM_DATE
+  FROM %s
+ WHERE RELEASE_ID > 0
+ GROUP BY RELEASE_ID`
+
+	accounts := []releaseStat{}
+	if _, err = r.Dbmap.Select(&accounts, fmt.Sprintf(stats, "SAP_ACCOUNTS")); err != nil {
+		return nil, fmt.Errorf("Error in select from SAP_ACCOUNTS: %s", err.Error())
+	}
+
+	orders := []releaseStat{}
+	if _, err = r.Dbmap.Select(&orders, fmt.Sprintf(stats, "SAP_ACC_SEGM_ORDER_NUMBERS")); err != nil {
+		return nil, fmt.Errorf("Error in select from SAP_ACC_SEGM_ORDER_NUMBERS: %s", err.Error())
+	}
+
+	releases = releaseCatalogue(records, logs, accounts, orders)
+
+	log.Printf("Selected from SAP_RELEASES records: %d", len(releases))
+
+	return
+}
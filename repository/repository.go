@@ -5,6 +5,7 @@ import (
 	"log"
 	"os"
 	"sync"
+	"time"
 	
 	"database/sql"
 	_ "gopkg.in/goracle.v2"
@@ -30,8 +31,9 @@ func initRepository(db *sql.DB) *gorp.DbMap {
 	log.Printf("Initializing repository")
 
 	dbmap := &gorp.DbMap{
-		Db:      db,
-		Dialect: &gorp.OracleDialect{},
+		Db:            db,
+		Dialect:       dialect().Gorp(),
+		TypeConverter: nullConverter{},
 	}
 
 	if !common.TestRun {
@@ -42,3 +44,45 @@ func initRepository(db *sql.DB) *gorp.DbMap {
 
 	return dbmap
 }
+
+//
+// Columns not set like the release, revoke and reject ones of the entries
+// being in Work are NULL, they are read as empty strings and zero dates
+// as the models keep them in plain types
+//
+type nullConverter struct{}
+
+func (nullConverter) ToDb(val interface{}) (interface{}, error) {
+	return val, nil
+}
+
+func (nullConverter) FromDb(target interface{}) (gorp.CustomScanner, bool) {
+	switch target.(type) {
+	case *string:
+		return gorp.CustomScanner{Holder: new(*string), Target: target, Binder: bindNullString}, true
+	case *time.Time:
+		return gorp.CustomScanner{Holder: new(*time.Time), Target: target, Binder: bindNullTime}, true
+	}
+
+	return gorp.CustomScanner{}, false
+}
+
+func bindNullString(holder, target interface{}) error {
+	if s := *holder.(**string); s != nil {
+		*target.(*string) = *s
+	} else {
+		*target.(*string) = ""
+	}
+
+	return nil
+}
+
+func bindNullTime(holder, target interface{}) error {
+	if t := *holder.(**time.Time); t != nil {
+		*target.(*time.Time) = *t
+	} else {
+		*target.(*time.Time) = time.Time{}
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+/*
+
+PACKAGE: Unit of work of the data access layer
+
+It makes the repositories of Account, Order, Release and Approval
+share one transaction so that the operations changing all of them
+like the release or revoke are applied completely or not at all. The
+repositories of the unit of work must not be committed or rolled
+back by themselves, only the unit of work is.
+
+*/
+
+package repository
+
+import (
+	"fmt"
+	"log"
+
+	"sam-api/common"
+)
+
+//
+// Repositories sharing one transaction
+//
+type UnitOfWork interface {
+	Accounts() AccountRepository
+	Orders() OrderRepository
+	Releases() ReleaseRepository
+	Approvals() ApprovalRepository
+	Commit() error
+	Rollback()
+	Close()
+}
+
+//
+// Unit of work in the database sharing one gorp transaction
+//
+type DbUnitOfWork struct {
+	Repository
+	accounts  *DbAccountRepository
+	orders    *DbOrderRepository
+	releases  *DbReleaseRepository
+	approvals *DbApprovalRepository
+}
+
+//
+// Unit of work in memory sharing one undo journal
+//
+type MemUnitOfWork struct {
+	memRepository
+	accounts  *MemAccountRepository
+	orders    *MemOrderRepository
+	releases  *MemReleaseRepository
+	approvals *MemApprovalRepository
+}
+
+//
+// Creates new unit of work on the configured backend with the transaction started
+//
+func NewUnitOfWork(user string) (UnitOfWork, error) {
+	if common.AppConfig.Backend == common.BackendMemory {
+		return newMemUnitOfWork(user), nil
+	}
+
+	u, err := newDbUnitOfWork(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+//
+// Creates new unit of work using existing db connection
+//
+func newDbUnitOfWork(user string) (u *DbUnitOfWork, err error) {
+	log.Printf("Creating new unit of work for user: %s", user)
+
+	db, err := common.GetDbSession()
+	if err != nil {
+		return nil, err
+	}
+
+	dbmap := initRepository(db)
+	addAccountTables(dbmap)
+	addOrderTables(dbmap)
+	addReleaseTables(dbmap)
+	addApprovalTables(dbmap)
+
+	t, err := dbmap.Begin()
+	if err != nil {
+		return nil, err
+	}
+
+	repository := func() Repository {
+		return Repository{
+			Owner: user,
+			Db:    db,
+			Dbmap: dbmap,
+			t:     t,
+		}
+	}
+
+	u = &DbUnitOfWork{
+		Repository: repository(),
+		accounts:   &DbAccountRepository{repository()},
+		orders:     &DbOrderRepository{repository()},
+		releases:   &DbReleaseRepository{repository()},
+		approvals:  &DbApprovalRepository{repository()},
+	}
+	u.m.Lock()
+
+	return
+}
+
+func (u *DbUnitOfWork) Accounts() AccountRepository {
+	return u.accounts
+}
+
+func (u *DbUnitOfWork) Orders() OrderRepository {
+	return u.orders
+}
+
+func (u *DbUnitOfWork) Releases() ReleaseRepository {
+	return u.releases
+}
+
+func (u *DbUnitOfWork) Approvals() ApprovalRepository {
+	return u.approvals
+}
+
+func (u *DbUnitOfWork) Close() {
+	u.m.Unlock()
+}
+
+func (u *DbUnitOfWork) Commit() error {
+	if err := u.t.Commit(); err != nil {
+		return fmt.Errorf("Commit error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (u *DbUnitOfWork) Rollback() {
+	if err := u.t.Rollback(); err != nil {
+		log.Printf("Rollback error: %s", err.Error())
+	}
+}
+
+//
+// Creates new unit of work using in-memory tables
+//
+func newMemUnitOfWork(user string) (u *MemUnitOfWork) {
+	log.Printf("Creating new in-memory unit of work for user: %s", user)
+
+	tx := newMemTransaction(true)
+	u = &MemUnitOfWork{
+		memRepository: memRepository{Owner: user, tx: tx},
+		accounts:      &MemAccountRepository{memRepository{Owner: user, tx: tx}},
+		orders:        &MemOrderRepository{memRepository{Owner: user, tx: tx}},
+		releases:      &MemReleaseRepository{memRepository{Owner: user, tx: tx}},
+		approvals:     &MemApprovalRepository{memRepository{Owner: user, tx: tx}},
+	}
+	u.m.Lock()
+
+	return
+}
+
+func (u *MemUnitOfWork) Accounts() AccountRepository {
+	return u.accounts
+}
+
+func (u *MemUnitOfWork) Orders() OrderRepository {
+	return u.orders
+}
+
+func (u *MemUnitOfWork) Releases() ReleaseRepository {
+	return u.releases
+}
+
+func (u *MemUnitOfWork) Approvals() ApprovalRepository {
+	return u.approvals
+}
+
+func (u *MemUnitOfWork) Commit() error {
+	u.tx.commit()
+
+	return nil
+}
@@ -19,6 +19,8 @@ type (
 	// Reply
 	AccountsReplyResource struct {
 		Count int64            `json:"count"`
+		Total int64            `json:"total"`
+		Next  string           `json:"next,omitempty"`
 		Data  []models.Account `json:"data"`
 	}
 
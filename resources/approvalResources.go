@@ -0,0 +1,24 @@
+package resources
+
+import (
+	"sam-api/models"
+)
+
+//Models for logical model resources envelopes
+type (
+	// request to raise, approve or reject with the comment
+	ApprovalRequestResource struct {
+		Data models.Approval `json:"data"`
+	}
+
+	// reply with one object
+	ApprovalReplyResource struct {
+		Data models.Approval `json:"data"`
+	}
+
+	// reply with many objects
+	ApprovalsReplyResource struct {
+		Count int64             `json:"count"`
+		Data  []models.Approval `json:"data"`
+	}
+)
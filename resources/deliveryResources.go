@@ -0,0 +1,27 @@
+package resources
+
+import (
+	"sam-api/models"
+)
+
+//Models for logical model resources envelopes
+type (
+	// reply with one object
+	DeliveryReplyResource struct {
+		Data models.Delivery `json:"data"`
+	}
+
+	// reply with many objects
+	DeliveriesReplyResource struct {
+		Count int64             `json:"count"`
+		Data  []models.Delivery `json:"data"`
+	}
+
+	// reply with the lines of the acknowledgement files ingested
+	DeliveryAckReplyResource struct {
+		Files   int64                `json:"files"`
+		Count   int64                `json:"count"`
+		Applied int64                `json:"applied"`
+		Data    []models.DeliveryAck `json:"data"`
+	}
+)
@@ -19,6 +19,8 @@ type (
 	// reply with many objects
 	DictionaryAccountBscssReplyResource struct {
 		Count int64                          `json:"count"`
+		Total int64                          `json:"total"`
+		Next  string                         `json:"next,omitempty"`
 		Data  []models.DictionaryAccountBscs `json:"data"`
 	}
 )
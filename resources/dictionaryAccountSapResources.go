@@ -19,6 +19,20 @@ type (
 	// reply with many objects
 	DictionaryAccountSapsReplyResource struct {
 		Count int64                         `json:"count"`
+		Total int64                         `json:"total"`
+		Next  string                        `json:"next,omitempty"`
 		Data  []models.DictionaryAccountSap `json:"data"`
 	}
+
+	// reply with the difference of the imported SAP master and the dictionary
+	DictionaryAccountSapMergeReplyResource struct {
+		Profile   string                           `json:"profile"`
+		Applied   bool                             `json:"applied"`
+		Inserted  int64                            `json:"inserted"`
+		Updated   int64                            `json:"updated"`
+		Removed   int64                            `json:"removed"`
+		Unchanged int64                            `json:"unchanged"`
+		Errors    []ImportErrorResource            `json:"errors"`
+		Data      models.DictionaryAccountSapMerge `json:"data"`
+	}
 )
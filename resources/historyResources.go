@@ -0,0 +1,14 @@
+package resources
+
+import (
+	"sam-api/models"
+)
+
+//Models for logical model resources envelopes
+type (
+	// reply with the changes of the entries in the order of operations
+	HistoryReplyResource struct {
+		Count int64                 `json:"count"`
+		Data  []models.HistoryEntry `json:"data"`
+	}
+)
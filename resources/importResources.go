@@ -0,0 +1,20 @@
+package resources
+
+//Models for logical model resources envelopes
+type (
+	// error of one row of the imported file
+	ImportErrorResource struct {
+		Sheet string `json:"sheet,omitempty"`
+		Row   int    `json:"row"`
+		Key   string `json:"key,omitempty"`
+		Error string `json:"error"`
+	}
+
+	// reply with the report of the import
+	ImportReplyResource struct {
+		Count    int64                 `json:"count"`
+		Imported int64                 `json:"imported"`
+		DryRun   bool                  `json:"dryRun"`
+		Errors   []ImportErrorResource `json:"errors"`
+	}
+)
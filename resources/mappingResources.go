@@ -0,0 +1,15 @@
+package resources
+
+import (
+	"sam-api/models"
+)
+
+//Models for logical model resources envelopes
+type (
+	// reply with the mapping effective on the date
+	MappingReplyResource struct {
+		AsOf  string                `json:"asOf"`
+		Count int64                 `json:"count"`
+		Data  []models.MappingEntry `json:"data"`
+	}
+)
@@ -19,12 +19,20 @@ type (
 	// Reply
 	OrdersReplyResource struct {
 		Count int64          `json:"count"`
+		Total int64          `json:"total"`
+		Next  string         `json:"next,omitempty"`
 		Data  []models.Order `json:"data"`
 	}
 
 	// Reply with logs
 	OrderLogsReplyResource struct {
-		Count int64               `json:"count"`
+		Count int64             `json:"count"`
 		Data  []models.OrderLog `json:"data"`
 	}
+
+	// Reply with the accounts and segments missing the order or its number
+	OrderCompletenessReplyResource struct {
+		Count int64             `json:"count"`
+		Data  []models.OrderGap `json:"data"`
+	}
 )
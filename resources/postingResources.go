@@ -0,0 +1,18 @@
+package resources
+
+import (
+	"sam-api/models"
+)
+
+//Models for logical model resources envelopes
+type (
+	// reply with the SAP OFI upload file, the reject file and the control totals
+	PostingTranslateReplyResource struct {
+		Lines      int64                 `json:"lines"`
+		Posted     int64                 `json:"posted"`
+		Rejected   int64                 `json:"rejected"`
+		Totals     []models.PostingTotal `json:"totals"`
+		OfiFile    string                `json:"ofiFile"`
+		RejectFile string                `json:"rejectFile"`
+	}
+)
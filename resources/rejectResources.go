@@ -0,0 +1,13 @@
+package resources
+
+import (
+	"sam-api/models"
+)
+
+//Models for logical model resources envelopes
+type (
+	// request to reject one entry or the list of them with the reason
+	RejectRequestResource struct {
+		Data models.Reject `json:"data"`
+	}
+)
@@ -0,0 +1,35 @@
+package resources
+
+import (
+	"sam-api/models"
+)
+
+//Models for logical model resources envelopes
+type (
+	// request, optional payload of the release
+	ReleaseRequestResource struct {
+		Data models.Release `json:"data"`
+	}
+
+	// reply with many objects
+	ReleasesReplyResource struct {
+		Count int64            `json:"count"`
+		Data  []models.Release `json:"data"`
+	}
+
+	// reply with the violations found by the check of release or revocation
+	ReleaseViolationsReplyResource struct {
+		Count int64                     `json:"count"`
+		Data  []models.ReleaseViolation `json:"data"`
+	}
+
+	// reply with the report of the release dry run
+	ReleaseCheckReplyResource struct {
+		Data models.ReleaseCheck `json:"data"`
+	}
+
+	// reply with the report of the release copied into Work
+	ReleaseCloneReplyResource struct {
+		Data models.ReleaseClone `json:"data"`
+	}
+)
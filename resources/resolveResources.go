@@ -0,0 +1,20 @@
+package resources
+
+import (
+	"sam-api/models"
+)
+
+//Models for logical model resources envelopes
+type (
+	// request with the postings to be resolved
+	ResolveRequestResource struct {
+		Data []models.ResolveLookup `json:"data"`
+	}
+
+	// reply with the results in the order of the postings
+	ResolveReplyResource struct {
+		Count    int64                  `json:"count"`
+		Unmapped int64                  `json:"unmapped"`
+		Data     []models.ResolveResult `json:"data"`
+	}
+)
@@ -0,0 +1,13 @@
+package resources
+
+import (
+	"sam-api/models"
+)
+
+//Models for logical model resources envelopes
+type (
+	// request with the id of the log entry to be restored
+	RestoreRequestResource struct {
+		Data models.Restore `json:"data"`
+	}
+)
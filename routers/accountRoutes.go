@@ -19,16 +19,27 @@ func SetAccountRoutes(router *mux.Router) *mux.Router {
 	accountRouter.HandleFunc("/api/account", controllers.AccountCreateOne).Methods("POST").Name("account")
 	accountRouter.HandleFunc("/api/account", controllers.AccountReadActiveAll).Methods("GET").Name("account")
 	accountRouter.HandleFunc("/api/account", controllers.AccountDeleteAll).Methods("DELETE").Name("account")
+	accountRouter.HandleFunc("/api/account/import", controllers.AccountImport).Methods("POST").Name("account-import")
 	accountRouter.HandleFunc("/api/account/{status:[WCP]}/{release:[A-Za-z0-9]+}", controllers.AccountReadSome).Methods("GET").Name("account-status-release")
+	accountRouter.HandleFunc("/api/account/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}", controllers.AccountReadOne).Methods("GET").Name("account-status-release-account")
 	accountRouter.HandleFunc("/api/account/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}", controllers.AccountUpdateOne).Methods("PUT").Name("account-status-release-account")
 	accountRouter.HandleFunc("/api/account/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}", controllers.AccountUpdateAttributes).Methods("PATCH").Name("account-status-release-account")
 	accountRouter.HandleFunc("/api/account/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}", controllers.AccountDeleteOne).Methods("DELETE").Name("account-status-release-account")
+	accountRouter.HandleFunc("/api/account/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}/reject", controllers.AccountReject).Methods("POST").Name("account-status-release-account-reject")
+	accountRouter.HandleFunc("/api/account/reject", controllers.AccountRejectBulk).Methods("POST").Name("account-reject")
 	accountRouter.HandleFunc("/api/account/log/{account:[A-Za-z0-9]+}", controllers.AccountReadLog).Methods("GET").Name("account-log")
+	accountRouter.HandleFunc("/api/account/log/{account:[A-Za-z0-9]+}/restore", controllers.AccountRestore).Methods("POST").Name("account-log-restore")
+	accountRouter.HandleFunc("/api/account/history", controllers.AccountReadHistory).Methods("GET").Name("account-history")
 
 	// Handle CORS
 	accountRouter.HandleFunc("/api/account/log/{account:[A-Za-z0-9]+}", common.WithCors).Methods("OPTIONS")
+	accountRouter.HandleFunc("/api/account/log/{account:[A-Za-z0-9]+}/restore", common.WithCors).Methods("OPTIONS")
+	accountRouter.HandleFunc("/api/account/history", common.WithCors).Methods("OPTIONS")
+	accountRouter.HandleFunc("/api/account/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}/reject", common.WithCors).Methods("OPTIONS")
+	accountRouter.HandleFunc("/api/account/reject", common.WithCors).Methods("OPTIONS")
 	accountRouter.HandleFunc("/api/account/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}", common.WithCors).Methods("OPTIONS")	
 	accountRouter.HandleFunc("/api/account/{status:[WCP]}/{release:[A-Za-z0-9]+}", common.WithCors).Methods("OPTIONS")
+	accountRouter.HandleFunc("/api/account/import", common.WithCors).Methods("OPTIONS")
 	accountRouter.HandleFunc("/api/account", common.WithCors).Methods("OPTIONS")
 	
 	// login required before access
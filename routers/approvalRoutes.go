@@ -0,0 +1,40 @@
+package routers
+
+import (
+	"github.com/codegangsta/negroni"
+	"github.com/gorilla/mux"
+
+	"sam-api/common"
+	"sam-api/controllers"
+	"sam-api/valid"
+)
+
+// Access metods for resource Approval
+func SetApprovalRoutes(router *mux.Router) *mux.Router {
+	approvalRouter := mux.NewRouter()
+
+	// approval access routes
+	approvalRouter.HandleFunc("/api/approval", controllers.ApprovalCreate).Methods("POST").Name("approval")
+	approvalRouter.HandleFunc("/api/approval", controllers.ApprovalReadAll).Methods("GET").Name("approval")
+	approvalRouter.HandleFunc("/api/approval/pending", controllers.ApprovalReadPending).Methods("GET").Name("approval-pending")
+	approvalRouter.HandleFunc("/api/approval/{id:[0-9]+}", controllers.ApprovalReadOne).Methods("GET").Name("approval-id")
+	approvalRouter.HandleFunc("/api/approval/{id:[0-9]+}/approve", controllers.ApprovalApprove).Methods("POST").Name("approval-approve")
+	approvalRouter.HandleFunc("/api/approval/{id:[0-9]+}/reject", controllers.ApprovalReject).Methods("POST").Name("approval-reject")
+
+	// handle CORS
+	approvalRouter.HandleFunc("/api/approval", common.WithCors).Methods("OPTIONS")
+	approvalRouter.HandleFunc("/api/approval/pending", common.WithCors).Methods("OPTIONS")
+	approvalRouter.HandleFunc("/api/approval/{id:[0-9]+}", common.WithCors).Methods("OPTIONS")
+	approvalRouter.HandleFunc("/api/approval/{id:[0-9]+}/approve", common.WithCors).Methods("OPTIONS")
+	approvalRouter.HandleFunc("/api/approval/{id:[0-9]+}/reject", common.WithCors).Methods("OPTIONS")
+
+	// login required before access
+	router.PathPrefix("/api/approval").Handler(negroni.New(
+		negroni.HandlerFunc(common.WithAuthorize),
+		negroni.HandlerFunc(common.WithLog),
+		negroni.HandlerFunc(valid.WithRelease),
+		negroni.Wrap(approvalRouter),
+	))
+
+	return router
+}
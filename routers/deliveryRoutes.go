@@ -0,0 +1,34 @@
+package routers
+
+import (
+	"github.com/codegangsta/negroni"
+	"github.com/gorilla/mux"
+
+	"sam-api/common"
+	"sam-api/controllers"
+)
+
+// Access metods for the delivery of the release files to SAP
+func SetDeliveryRoutes(router *mux.Router) *mux.Router {
+	deliveryRouter := mux.NewRouter()
+
+	// delivery access routes
+	deliveryRouter.HandleFunc("/api/delivery", controllers.DeliveryReadAll).Methods("GET").Name("delivery-read-all")
+	deliveryRouter.HandleFunc("/api/delivery/ack", controllers.DeliveryAck).Methods("POST").Name("delivery-ack")
+	deliveryRouter.HandleFunc("/api/delivery/{release}", controllers.DeliveryRead).Methods("GET").Name("delivery-read")
+	deliveryRouter.HandleFunc("/api/delivery/{release}", controllers.DeliverySend).Methods("POST").Name("delivery-send")
+
+	// handle CORS
+	deliveryRouter.HandleFunc("/api/delivery", common.WithCors).Methods("OPTIONS")
+	deliveryRouter.HandleFunc("/api/delivery/ack", common.WithCors).Methods("OPTIONS")
+	deliveryRouter.HandleFunc("/api/delivery/{release}", common.WithCors).Methods("OPTIONS")
+
+	// login required before access
+	router.PathPrefix("/api/delivery").Handler(negroni.New(
+		negroni.HandlerFunc(common.WithAuthorize),
+		negroni.HandlerFunc(common.WithLog),
+		negroni.Wrap(deliveryRouter),
+	))
+
+	return router
+}
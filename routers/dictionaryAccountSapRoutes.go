@@ -14,6 +14,7 @@ func SetDictionaryAccountSapRoutes(router *mux.Router) *mux.Router {
 	dictionaryRouter := mux.NewRouter()
 
 	// segment access routes
+	dictionaryRouter.HandleFunc("/api/dictionary/account/sap/preview", controllers.DictionaryAccountSapPreviewExcel).Methods("POST").HeadersRegexp("Content-Type", "application/xlsx").Name("dictionary-account-sap-preview")
 	dictionaryRouter.HandleFunc("/api/dictionary/account/sap", controllers.DictionaryAccountSapCreateExcel).Methods("POST").HeadersRegexp("Content-Type", "application/xlsx").Name("dictionary-account-sap")
 	dictionaryRouter.HandleFunc("/api/dictionary/account/sap", controllers.DictionaryAccountSapCreate).Methods("POST").HeadersRegexp("Content-Type", "application/json").Name("dictionary-account-sap")
 	dictionaryRouter.HandleFunc("/api/dictionary/account/sap", controllers.DictionaryAccountSapReadAll).Methods("GET").Name("dictionary-account-sap")
@@ -21,6 +22,7 @@ func SetDictionaryAccountSapRoutes(router *mux.Router) *mux.Router {
 
 	// Handle CORS
 	dictionaryRouter.HandleFunc("/api/dictionary/account/sap", common.WithCors).Methods("OPTIONS")
+	dictionaryRouter.HandleFunc("/api/dictionary/account/sap/preview", common.WithCors).Methods("OPTIONS")
 
 	// login required before access
 	router.PathPrefix("/api/dictionary/account/sap").Handler(negroni.New(
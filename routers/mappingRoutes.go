@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"github.com/codegangsta/negroni"
+	"github.com/gorilla/mux"
+
+	"sam-api/common"
+	"sam-api/controllers"
+)
+
+// Access metods for resource Mapping, read only
+func SetMappingRoutes(router *mux.Router) *mux.Router {
+	mappingRouter := mux.NewRouter()
+
+	// mapping access routes
+	mappingRouter.HandleFunc("/api/mapping", controllers.MappingRead).Methods("GET").Name("mapping")
+
+	// handle CORS
+	mappingRouter.HandleFunc("/api/mapping", common.WithCors).Methods("OPTIONS")
+
+	// login required before access
+	router.PathPrefix("/api/mapping").Handler(negroni.New(
+		negroni.HandlerFunc(common.WithAuthorize),
+		negroni.HandlerFunc(common.WithLog),
+		negroni.Wrap(mappingRouter),
+	))
+
+	return router
+}
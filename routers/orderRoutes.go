@@ -19,16 +19,29 @@ func SetOrderRoutes(router *mux.Router) *mux.Router {
 	orderRouter.HandleFunc("/api/order", controllers.OrderCreateOne).Methods("POST").Name("order")
 	orderRouter.HandleFunc("/api/order", controllers.OrderReadActiveAll).Methods("GET").Name("order")	
 	orderRouter.HandleFunc("/api/order", controllers.OrderDeleteAll).Methods("DELETE").Name("order")
+	orderRouter.HandleFunc("/api/order/import", controllers.OrderImport).Methods("POST").Name("order-import")
+	orderRouter.HandleFunc("/api/order/completeness", controllers.OrderCompleteness).Methods("GET").Name("order-completeness")
 	orderRouter.HandleFunc("/api/order/{status:[WCP]}/{release:[A-Za-z0-9]+}", controllers.OrderReadSome).Methods("GET").Name("order-status-release")
+	orderRouter.HandleFunc("/api/order/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}/{segment:[A-Za-z0-9]+}", controllers.OrderReadOne).Methods("GET").Name("order-status-release-account-segment")
 	orderRouter.HandleFunc("/api/order/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}/{segment:[A-Za-z0-9]+}", controllers.OrderUpdateOne).Methods("PUT").Name("order-status-release-account-segment")
 	orderRouter.HandleFunc("/api/order/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}/{segment:[A-Za-z0-9]+}", controllers.OrderUpdateAttributes).Methods("PATCH").Name("order-status-release-account-segment")
 	orderRouter.HandleFunc("/api/order/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}/{segment:[A-Za-z0-9]+}", controllers.OrderDeleteOne).Methods("DELETE").Name("order-status-release-account-segment")
+	orderRouter.HandleFunc("/api/order/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}/{segment:[A-Za-z0-9]+}/reject", controllers.OrderReject).Methods("POST").Name("order-status-release-account-segment-reject")
+	orderRouter.HandleFunc("/api/order/reject", controllers.OrderRejectBulk).Methods("POST").Name("order-reject")
 	orderRouter.HandleFunc("/api/order/log/{account:[A-Za-z0-9]+}", controllers.OrderReadLog).Name("order-log")
+	orderRouter.HandleFunc("/api/order/log/{account:[A-Za-z0-9]+}/restore", controllers.OrderRestore).Methods("POST").Name("order-log-restore")
+	orderRouter.HandleFunc("/api/order/history", controllers.OrderReadHistory).Methods("GET").Name("order-history")
 	
 	// Handle CORS
 	orderRouter.HandleFunc("/api/order/log/{account:[A-Za-z0-9]+}", common.WithCors).Methods("OPTIONS")
+	orderRouter.HandleFunc("/api/order/log/{account:[A-Za-z0-9]+}/restore", common.WithCors).Methods("OPTIONS")
+	orderRouter.HandleFunc("/api/order/history", common.WithCors).Methods("OPTIONS")
+	orderRouter.HandleFunc("/api/order/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}/{segment:[A-Za-z0-9]+}/reject", common.WithCors).Methods("OPTIONS")
+	orderRouter.HandleFunc("/api/order/reject", common.WithCors).Methods("OPTIONS")
 	orderRouter.HandleFunc("/api/order/{status:[WCP]}/{release:[A-Za-z0-9]+}/{account:[A-Za-z0-9]+}/{segment:[A-Za-z0-9]+}", common.WithCors).Methods("OPTIONS")
 	orderRouter.HandleFunc("/api/order/{status:[WCP]}/{release:[A-Za-z0-9]+}", common.WithCors).Methods("OPTIONS")
+	orderRouter.HandleFunc("/api/order/import", common.WithCors).Methods("OPTIONS")
+	orderRouter.HandleFunc("/api/order/completeness", common.WithCors).Methods("OPTIONS")
 	orderRouter.HandleFunc("/api/order", common.WithCors).Methods("OPTIONS")
 
 	// login required before access
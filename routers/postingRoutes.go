@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"github.com/codegangsta/negroni"
+	"github.com/gorilla/mux"
+
+	"sam-api/common"
+	"sam-api/controllers"
+)
+
+// Access metods for the translation of BSCS postings
+func SetPostingRoutes(router *mux.Router) *mux.Router {
+	postingRouter := mux.NewRouter()
+
+	// posting access routes
+	postingRouter.HandleFunc("/api/posting/translate", controllers.PostingTranslate).Methods("POST").Name("posting-translate")
+
+	// handle CORS
+	postingRouter.HandleFunc("/api/posting/translate", common.WithCors).Methods("OPTIONS")
+
+	// login required before access
+	router.PathPrefix("/api/posting").Handler(negroni.New(
+		negroni.HandlerFunc(common.WithAuthorize),
+		negroni.HandlerFunc(common.WithLog),
+		negroni.Wrap(postingRouter),
+	))
+
+	return router
+}
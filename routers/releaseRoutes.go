@@ -14,13 +14,23 @@ func SetReleaseRoutes(router *mux.Router) *mux.Router {
 	releaseRouter := mux.NewRouter()
 
 	// segment access routes
+	releaseRouter.HandleFunc("/api/release", controllers.ReleaseReadAll).Methods("GET").Name("release-read")
+	releaseRouter.HandleFunc("/api/release/diff", controllers.ReleaseDiff).Methods("GET").Name("release-diff")
+	releaseRouter.HandleFunc("/api/release/check", controllers.ReleaseCheck).Methods("GET").Name("release-check")
 	releaseRouter.HandleFunc("/api/release/new", controllers.ReleaseNew).Methods("POST").Name("release-new")
 	releaseRouter.HandleFunc("/api/release/{release:[A-Za-z0-9]+}", controllers.ReleaseAppend).Methods("POST").Name("release-id")
 	releaseRouter.HandleFunc("/api/release/{release:[A-Za-z0-9]+}", controllers.ReleaseRevoke).Methods("DELETE").Name("release-id")	
+	releaseRouter.HandleFunc("/api/release/{release:[A-Za-z0-9]+}/clone", controllers.ReleaseClone).Methods("POST").Name("release-id-clone")
+	releaseRouter.HandleFunc("/api/release/{release:[A-Za-z0-9]+}/export", controllers.ReleaseExport).Methods("GET").Name("release-id-export")
 
 	// handle CORS
+	releaseRouter.HandleFunc("/api/release/{release:[A-Za-z0-9]+}/clone", common.WithCors).Methods("OPTIONS")
+	releaseRouter.HandleFunc("/api/release/{release:[A-Za-z0-9]+}/export", common.WithCors).Methods("OPTIONS")
 	releaseRouter.HandleFunc("/api/release/{release:[A-Za-z0-9]+}", common.WithCors).Methods("OPTIONS")
 	releaseRouter.HandleFunc("/api/release/new", common.WithCors).Methods("OPTIONS")
+	releaseRouter.HandleFunc("/api/release", common.WithCors).Methods("OPTIONS")
+	releaseRouter.HandleFunc("/api/release/diff", common.WithCors).Methods("OPTIONS")
+	releaseRouter.HandleFunc("/api/release/check", common.WithCors).Methods("OPTIONS")
 
 	// login required before access
 	router.PathPrefix("/api/release").Handler(negroni.New(
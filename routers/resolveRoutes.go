@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"github.com/codegangsta/negroni"
+	"github.com/gorilla/mux"
+
+	"sam-api/common"
+	"sam-api/controllers"
+)
+
+// Access metods for the resolution of postings by the services
+func SetResolveRoutes(router *mux.Router) *mux.Router {
+	resolveRouter := mux.NewRouter()
+
+	// resolve access routes
+	resolveRouter.HandleFunc("/api/resolve", controllers.Resolve).Methods("POST").Name("resolve")
+
+	// handle CORS
+	resolveRouter.HandleFunc("/api/resolve", common.WithCors).Methods("OPTIONS")
+
+	// service credentials required instead of the user login
+	router.PathPrefix("/api/resolve").Handler(negroni.New(
+		negroni.HandlerFunc(common.WithServiceAuthorize),
+		negroni.HandlerFunc(common.WithLog),
+		negroni.Wrap(resolveRouter),
+	))
+
+	return router
+}
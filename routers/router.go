@@ -12,10 +12,15 @@ func InitRoutes() *mux.Router {
 	router = SetUserRoutes(router)
 	router = SetAccountRoutes(router)
 	router = SetReleaseRoutes(router)
+	router = SetApprovalRoutes(router)
 	router = SetDictionaryAccountBscsRoutes(router)
 	router = SetDictionaryAccountSapRoutes(router)
 	router = SetOrderRoutes(router)
 	router = SetDictionarySegmentRoutes(router)
+	router = SetMappingRoutes(router)
+	router = SetResolveRoutes(router)
+	router = SetPostingRoutes(router)
+	router = SetDeliveryRoutes(router)
 
 	return router
 }
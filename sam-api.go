@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
 	"os"
@@ -12,6 +15,7 @@ import (
 	"github.com/codegangsta/negroni"
 
 	"sam-api/common"
+	"sam-api/controllers"
 	"sam-api/routers"
 )
 
@@ -32,10 +36,74 @@ func init() {
 	common.FlagsInit()
 }
 
+//
+// Command translate: BSCS GL posting extract into SAP OFI upload file and
+// reject file with the mappings in production, the control totals printed
+//
+func translate(args []string) int {
+	fs := flag.NewFlagSet("translate", flag.ExitOnError)
+	in := fs.String("in", "", "BSCS GL posting extract in CSV")
+	out := fs.String("out", "", "SAP OFI upload file")
+	rejects := fs.String("rejects", "", "Reject file of the lines not mapped")
+	currency := fs.String("currency", "", "Currency of the lines without it")
+	fs.Parse(args)
+	if *in == "" || *out == "" || *rejects == "" {
+		fmt.Fprintf(os.Stderr, "Usage: %s translate -in file -out file -rejects file [-currency code]\n", os.Args[0])
+		return 2
+	}
+
+	// the environment is taken from config and env variables only
+	os.Args = os.Args[:1]
+	common.EnvInit(version, build, level)
+	common.StartUpRepository()
+
+	file, err := os.Open(*in)
+	if err != nil {
+		log.Printf("Error opening postings: %s", err.Error())
+		return 1
+	}
+	defer file.Close()
+
+	t, err := controllers.TranslatePostingFile("SAMAPI", file, *currency)
+	if err != nil {
+		log.Printf("Error in translation of postings: %s", err.Error())
+		return 1
+	}
+
+	ofi, err := t.ToOfiCsv()
+	if err == nil {
+		err = ioutil.WriteFile(*out, ofi, 0644)
+	}
+	if err != nil {
+		log.Printf("Error writing SAP OFI file: %s", err.Error())
+		return 1
+	}
+	reject, err := t.ToRejectCsv()
+	if err == nil {
+		err = ioutil.WriteFile(*rejects, reject, 0644)
+	}
+	if err != nil {
+		log.Printf("Error writing reject file: %s", err.Error())
+		return 1
+	}
+
+	fmt.Printf("Lines: %d posted: %d rejected: %d\n", t.Lines, len(t.Postings), len(t.Rejects))
+	for _, total := range t.Totals {
+		fmt.Printf("%s %s %d %s\n", total.OfiSapAccount, total.Currency, total.Count, total.Amount)
+	}
+
+	return 0
+}
+
 //
 // SAP API server, may panic causing stop on init, all handlers guarded with recover
 //
 func main() {
+	// Batch commands run instead of the server
+	if len(os.Args) > 1 && os.Args[1] == "translate" {
+		os.Exit(translate(os.Args[2:]))
+	}
+
 	// Initialisation of the environment
 	common.EnvInit(version, build, level)
 
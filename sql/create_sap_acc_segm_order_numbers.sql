@@ -15,7 +15,9 @@ CREATE TABLE "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS" (
 	UPDATE_OWNER VARCHAR2(16),
 	RELEASE_DATE DATE,
 	RELEASE_OWNER VARCHAR2(16),
-    REC_VERSION INTEGER	 DEFAULT 0												  
+    REC_VERSION INTEGER	 DEFAULT 0,
+    REJECT_REASON VARCHAR2(256),
+    REJECT_OWNER VARCHAR2(16)
 ) SEGMENT CREATION IMMEDIATE 
 PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255 
 NOCOMPRESS NOLOGGING
@@ -29,6 +31,8 @@ COMMENT ON COLUMN "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS"."RELEASE_ID" IS 'Runni
 COMMENT ON COLUMN "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS"."BSCS_ACCOUNT" IS 'BSCS GL account code used for booking, source of the mapping';
 COMMENT ON COLUMN "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS"."SEGMENT_CODE" IS 'Customer segment code from CUSTOMER_SEGMENT dictionary table, all values to be used';
 COMMENT ON COLUMN "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS"."ORDER_NUMBER" IS 'Number of the order to be filled in by Control';
+COMMENT ON COLUMN "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS"."REJECT_REASON" IS 'Reason of the rejection by Control moving the entry back to Work';
+COMMENT ON COLUMN "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS"."REJECT_OWNER" IS 'User who rejected the entry back to Work';
 
 --------------------------------------------------------
 --  DDL for Index
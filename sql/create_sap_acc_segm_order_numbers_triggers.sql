@@ -36,7 +36,9 @@ BEGIN
 	UPDATE_OWNER,
 	RELEASE_DATE,
 	RELEASE_OWNER,
-	REC_VERSION
+	REC_VERSION,
+	REJECT_REASON,
+	REJECT_OWNER
    )
    VALUES
    (
@@ -54,7 +56,9 @@ BEGIN
 	:new.UPDATE_OWNER,
 	:new.RELEASE_DATE,
 	:new.RELEASE_OWNER,
-	:new.REC_VERSION
+	:new.REC_VERSION,
+	:new.REJECT_REASON,
+	:new.REJECT_OWNER
    );
 END;
 /
@@ -97,7 +101,9 @@ BEGIN
 	UPDATE_OWNER,
 	RELEASE_DATE,
 	RELEASE_OWNER,
-	REC_VERSION
+	REC_VERSION,
+	REJECT_REASON,
+	REJECT_OWNER
    )
    VALUES
    (
@@ -115,7 +121,9 @@ BEGIN
 	:new.UPDATE_OWNER,
 	:new.RELEASE_DATE,
 	:new.RELEASE_OWNER,
-	:new.REC_VERSION
+	:new.REC_VERSION,
+	:new.REJECT_REASON,
+	:new.REJECT_OWNER
    );
 END;
 /
@@ -156,7 +164,9 @@ BEGIN
 	UPDATE_OWNER,
 	RELEASE_DATE,
 	RELEASE_OWNER,
-	REC_VERSION
+	REC_VERSION,
+	REJECT_REASON,
+	REJECT_OWNER
    )
    VALUES
    (
@@ -174,7 +184,9 @@ BEGIN
 	:old.UPDATE_OWNER,
 	:old.RELEASE_DATE,
 	:old.RELEASE_OWNER,
-	:old.REC_VERSION
+	:old.REC_VERSION,
+	:old.REJECT_REASON,
+	:old.REJECT_OWNER
    );
 END;
 /
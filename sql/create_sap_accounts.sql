@@ -17,7 +17,9 @@ CREATE TABLE "CGSYSADM"."SAP_ACCOUNTS" (
 	   UPDATE_OWNER VARCHAR2(16),	   
 	   RELEASE_DATE DATE,
 	   RELEASE_OWNER VARCHAR(16),
-       REC_VERSION INTEGER  DEFAULT 0	   
+       REC_VERSION INTEGER  DEFAULT 0,
+       REJECT_REASON VARCHAR2(256),
+       REJECT_OWNER VARCHAR2(16)
 ) SEGMENT CREATION IMMEDIATE 
 PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255 
 NOCOMPRESS NOLOGGING
@@ -34,6 +36,8 @@ COMMENT ON COLUMN "CGSYSADM"."SAP_ACCOUNTS"."VALID_FROM_DATE" IS 'Validity of th
 COMMENT ON COLUMN "CGSYSADM"."SAP_ACCOUNTS"."VAT_CODE_IND" IS 'Business property: VAR code indicator';
 COMMENT ON COLUMN "CGSYSADM"."SAP_ACCOUNTS"."OFI_SAP_WBS_CODE" IS 'Business property: SAP WBS code';
 COMMENT ON COLUMN "CGSYSADM"."SAP_ACCOUNTS"."CIT_MARKER_VAT_FLAG" IS 'Business property:  SAP marker vAt flag';
+COMMENT ON COLUMN "CGSYSADM"."SAP_ACCOUNTS"."REJECT_REASON" IS 'Reason of the rejection by Control moving the entry back to Work';
+COMMENT ON COLUMN "CGSYSADM"."SAP_ACCOUNTS"."REJECT_OWNER" IS 'User who rejected the entry back to Work';
 COMMENT ON TABLE "CGSYSADM"."SAP_ACCOUNTS"  IS 'BSCS GL account to SAP IFI account mapping table';
 
 --------------------------------------------------------
@@ -21,7 +21,9 @@ CREATE TABLE "CGSYSADM"."SAP_ACCOUNTS_LOG" (
 	   UPDATE_OWNER VARCHAR2(16),	   
 	   RELEASE_DATE DATE,
 	   RELEASE_OWNER VARCHAR(16),
-	   REC_VERSION INTEGER
+	   REC_VERSION INTEGER,
+	   REJECT_REASON VARCHAR2(256),
+	   REJECT_OWNER VARCHAR2(16)
 ) SEGMENT CREATION IMMEDIATE 
 PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255 
 NOCOMPRESS NOLOGGING
@@ -38,7 +38,9 @@ BEGIN
 	   UPDATE_OWNER,
 	   RELEASE_DATE,
 	   RELEASE_OWNER,
-	   REC_VERSION
+	   REC_VERSION,
+	   REJECT_REASON,
+	   REJECT_OWNER
    )
    VALUES
    (
@@ -58,7 +60,9 @@ BEGIN
 	   :new.UPDATE_OWNER,
 	   :new.RELEASE_DATE,
 	   :new.RELEASE_OWNER,
-	   :new.REC_VERSION
+	   :new.REC_VERSION,
+	   :new.REJECT_REASON,
+	   :new.REJECT_OWNER
    );
 END;
 /
@@ -103,7 +107,9 @@ BEGIN
 	   UPDATE_OWNER,
 	   RELEASE_DATE,
 	   RELEASE_OWNER,
-	   REC_VERSION
+	   REC_VERSION,
+	   REJECT_REASON,
+	   REJECT_OWNER
    )
    VALUES
    (
@@ -123,7 +129,9 @@ BEGIN
 	   :new.UPDATE_OWNER,
 	   :new.RELEASE_DATE,
 	   :new.RELEASE_OWNER,
-	   :new.REC_VERSION
+	   :new.REC_VERSION,
+	   :new.REJECT_REASON,
+	   :new.REJECT_OWNER
    );
 END;
 /
@@ -166,7 +174,9 @@ BEGIN
 	   UPDATE_OWNER,
 	   RELEASE_DATE,
 	   RELEASE_OWNER,
-	   REC_VERSION
+	   REC_VERSION,
+	   REJECT_REASON,
+	   REJECT_OWNER
    )
    VALUES
    (
@@ -186,7 +196,9 @@ BEGIN
 	   :old.UPDATE_OWNER,
 	   :old.RELEASE_DATE,
 	   :old.RELEASE_OWNER,
-	   :old.REC_VERSION
+	   :old.REC_VERSION,
+	   :old.REJECT_REASON,
+	   :old.REJECT_OWNER
    );
 END;
 /
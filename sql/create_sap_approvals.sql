@@ -0,0 +1,96 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE "CGSYSADM"."SAP_APPROVALS" (
+	   APPROVAL_ID INTEGER,
+	   FROM_STATUS CHAR(1),
+	   INTO_STATUS CHAR(1),
+	   RELEASE_ID VARCHAR2(8),
+	   STATE CHAR(1),
+	   REQUEST_DATE DATE,
+	   REQUEST_OWNER VARCHAR2(16),
+	   REQUEST_ROLE VARCHAR2(16),
+	   REQUEST_COMMENT VARCHAR2(256),
+	   REC_VERSION INTEGER DEFAULT 0
+) SEGMENT CREATION IMMEDIATE 
+PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255 
+NOCOMPRESS NOLOGGING
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ;
+
+COMMENT ON COLUMN "CGSYSADM"."SAP_APPROVALS"."APPROVAL_ID" IS 'Running identifier of the release request';
+COMMENT ON COLUMN "CGSYSADM"."SAP_APPROVALS"."FROM_STATUS" IS 'Status of the entries before the release';
+COMMENT ON COLUMN "CGSYSADM"."SAP_APPROVALS"."INTO_STATUS" IS 'Status of the entries after the release';
+COMMENT ON COLUMN "CGSYSADM"."SAP_APPROVALS"."RELEASE_ID" IS 'Requested release: new, last or its id, the id of the release when approved';
+COMMENT ON COLUMN "CGSYSADM"."SAP_APPROVALS"."STATE" IS 'R like Requested, A like Approved, J like reJected';
+COMMENT ON TABLE "CGSYSADM"."SAP_APPROVALS"  IS 'Requests of the release approved by other user';
+
+--------------------------------------------------------
+--  DDL for Constraints
+--------------------------------------------------------
+
+ALTER TABLE "CGSYSADM"."SAP_APPROVALS"
+ADD CONSTRAINT "PK_SAP_APPROVALS_IDX" PRIMARY KEY ("APPROVAL_ID")
+USING INDEX PCTFREE 10 INITRANS 2 MAXTRANS 255 COMPUTE STATISTICS NOLOGGING 
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ENABLE;
+
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE "CGSYSADM"."SAP_APPROVALS_LOG" (
+	   APPROVAL_ID INTEGER,
+	   STEP CHAR(1),
+	   STEP_DATE DATE,
+	   STEP_OWNER VARCHAR2(16),
+	   STEP_ROLE VARCHAR2(16),
+	   STEP_COMMENT VARCHAR2(256)
+) SEGMENT CREATION IMMEDIATE 
+PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255 
+NOCOMPRESS NOLOGGING
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ;
+
+COMMENT ON COLUMN "CGSYSADM"."SAP_APPROVALS_LOG"."STEP" IS 'R like Request, A like Approve, J like reJect';
+COMMENT ON COLUMN "CGSYSADM"."SAP_APPROVALS_LOG"."STEP_ROLE" IS 'Role of the user doing the step';
+COMMENT ON TABLE "CGSYSADM"."SAP_APPROVALS_LOG"  IS 'Steps of the release requests';
+
+CREATE INDEX "CGSYSADM"."PK_SAP_APPROVALS_LOG_IDX" ON "CGSYSADM"."SAP_APPROVALS_LOG" ("APPROVAL_ID") 
+PCTFREE 10 INITRANS 2 MAXTRANS 255 COMPUTE STATISTICS NOLOGGING 
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ;
+
+--------------------------------------------------------
+--  DDL for Sequence
+--------------------------------------------------------
+
+CREATE SEQUENCE "CGSYSADM"."SAP_APPROVALS_SEQ" START WITH 1 INCREMENT BY 1 NOCACHE;
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE ON "CGSYSADM"."SAP_APPROVALS" TO SAMAPI;
+GRANT SELECT, INSERT ON "CGSYSADM"."SAP_APPROVALS_LOG" TO SAMAPI;
+GRANT SELECT ON "CGSYSADM"."SAP_APPROVALS_SEQ" TO SAMAPI;
+
+--------------------------------------------------------
+--  DDL for Synoyms
+--------------------------------------------------------
+
+CREATE OR REPLACE PUBLIC SYNONYM SAP_APPROVALS FOR "CGSYSADM"."SAP_APPROVALS";
+CREATE OR REPLACE PUBLIC SYNONYM SAP_APPROVALS_LOG FOR "CGSYSADM"."SAP_APPROVALS_LOG";
+CREATE OR REPLACE PUBLIC SYNONYM SAP_APPROVALS_SEQ FOR "CGSYSADM"."SAP_APPROVALS_SEQ";
+
+QUIT
+/
@@ -0,0 +1,59 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE "CGSYSADM"."SAP_RELEASE_DELIVERIES" (
+	   RELEASE_ID INTEGER,
+	   FORMAT VARCHAR2(8),
+	   TARGET VARCHAR2(256),
+	   FILE_NAME VARCHAR2(64),
+	   CHECKSUM VARCHAR2(8),
+	   STATUS VARCHAR2(16),
+	   ATTEMPTS INTEGER DEFAULT 0,
+	   MESSAGE VARCHAR2(256),
+	   SENT_DATE DATE,
+	   ACK_DATE DATE,
+	   ACK_OWNER VARCHAR2(16),
+	   REC_VERSION INTEGER DEFAULT 0
+) SEGMENT CREATION IMMEDIATE 
+PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255 
+NOCOMPRESS NOLOGGING
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ;
+
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASE_DELIVERIES"."RELEASE_ID" IS 'Release of the interface file as in SAP_RELEASES';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASE_DELIVERIES"."TARGET" IS 'Drop directory or SFTP location without the password';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASE_DELIVERIES"."CHECKSUM" IS 'Checksum of the trailer of the interface file';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASE_DELIVERIES"."STATUS" IS 'Pending, Sent, Failed, Delivered or Rejected by SAP';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASE_DELIVERIES"."ATTEMPTS" IS 'Number of the attempts of the last delivery';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASE_DELIVERIES"."MESSAGE" IS 'Last error of the delivery or the message of the acknowledgement';
+COMMENT ON TABLE "CGSYSADM"."SAP_RELEASE_DELIVERIES"  IS 'Delivery of the interface files of the releases to SAP';
+
+--------------------------------------------------------
+--  DDL for Constraints
+--------------------------------------------------------
+
+ALTER TABLE "CGSYSADM"."SAP_RELEASE_DELIVERIES"
+ADD CONSTRAINT "PK_SAP_RELEASE_DELIVERIES_IDX" PRIMARY KEY ("RELEASE_ID")
+USING INDEX PCTFREE 10 INITRANS 2 MAXTRANS 255 COMPUTE STATISTICS NOLOGGING 
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ENABLE;
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE ON "CGSYSADM"."SAP_RELEASE_DELIVERIES" TO SAMAPI;
+
+--------------------------------------------------------
+--  DDL for Synoyms
+--------------------------------------------------------
+
+CREATE OR REPLACE PUBLIC SYNONYM SAP_RELEASE_DELIVERIES FOR "CGSYSADM"."SAP_RELEASE_DELIVERIES";
+
+QUIT
+/
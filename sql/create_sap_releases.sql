@@ -0,0 +1,88 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE "CGSYSADM"."SAP_RELEASES" (
+	   RELEASE_ID INTEGER,
+	   FROM_STATUS CHAR(1),
+	   INTO_STATUS CHAR(1),
+	   DESCRIPTION VARCHAR2(256),
+	   RELEASE_DATE DATE,
+	   RELEASE_OWNER VARCHAR2(16),
+	   REVOKE_DATE DATE,
+	   REVOKE_OWNER VARCHAR2(16),
+	   REC_VERSION INTEGER DEFAULT 0
+) SEGMENT CREATION IMMEDIATE 
+PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255 
+NOCOMPRESS NOLOGGING
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ;
+
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASES"."RELEASE_ID" IS 'Running identifier of the release as in SAP_ACCOUNTS and SAP_ACC_SEGM_ORDER_NUMBERS';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASES"."FROM_STATUS" IS 'Status of the entries before the release';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASES"."INTO_STATUS" IS 'Status of the entries after the release';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASES"."DESCRIPTION" IS 'Optional description given by the user doing the release';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASES"."REVOKE_DATE" IS 'Date of the last revocation, empty if the release is in Production';
+COMMENT ON TABLE "CGSYSADM"."SAP_RELEASES"  IS 'Registry of the releases of the account and order mapping';
+
+--------------------------------------------------------
+--  DDL for Constraints
+--------------------------------------------------------
+
+ALTER TABLE "CGSYSADM"."SAP_RELEASES"
+ADD CONSTRAINT "PK_SAP_RELEASES_IDX" PRIMARY KEY ("RELEASE_ID")
+USING INDEX PCTFREE 10 INITRANS 2 MAXTRANS 255 COMPUTE STATISTICS NOLOGGING 
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ENABLE;
+
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE "CGSYSADM"."SAP_RELEASES_LOG" (
+	   RELEASE_ID INTEGER,
+	   OPCODE CHAR(1),
+	   OPDATE DATE,
+	   OPOWNER VARCHAR2(16),
+	   ACCOUNTS INTEGER,
+	   ORDERS INTEGER
+) SEGMENT CREATION IMMEDIATE 
+PCTFREE 10 PCTUSED 40 INITRANS 1 MAXTRANS 255 
+NOCOMPRESS NOLOGGING
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ;
+
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASES_LOG"."OPCODE" IS 'N like New release, A like Append to release, R like Revoke';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASES_LOG"."ACCOUNTS" IS 'Number of the accounts moved by the operation';
+COMMENT ON COLUMN "CGSYSADM"."SAP_RELEASES_LOG"."ORDERS" IS 'Number of the orders moved by the operation';
+COMMENT ON TABLE "CGSYSADM"."SAP_RELEASES_LOG"  IS 'History of the operations on the releases';
+
+CREATE INDEX "CGSYSADM"."PK_SAP_RELEASES_LOG_IDX" ON "CGSYSADM"."SAP_RELEASES_LOG" ("RELEASE_ID") 
+PCTFREE 10 INITRANS 2 MAXTRANS 255 COMPUTE STATISTICS NOLOGGING 
+STORAGE(INITIAL 65536 NEXT 1048576 MINEXTENTS 1 MAXEXTENTS 2147483645
+PCTINCREASE 0 FREELISTS 1 FREELIST GROUPS 1
+BUFFER_POOL DEFAULT FLASH_CACHE DEFAULT CELL_FLASH_CACHE DEFAULT)
+TABLESPACE "DATA_ROT" ;
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE ON "CGSYSADM"."SAP_RELEASES" TO SAMAPI;
+GRANT SELECT, INSERT ON "CGSYSADM"."SAP_RELEASES_LOG" TO SAMAPI;
+
+--------------------------------------------------------
+--  DDL for Synoyms
+--------------------------------------------------------
+
+CREATE OR REPLACE PUBLIC SYNONYM SAP_RELEASES FOR "CGSYSADM"."SAP_RELEASES";
+CREATE OR REPLACE PUBLIC SYNONYM SAP_RELEASES_LOG FOR "CGSYSADM"."SAP_RELEASES_LOG";
+
+QUIT
+/
@@ -1,3 +1,15 @@
+DROP TABLE "CGSYSADM"."SAP_RELEASE_DELIVERIES";
+
+DROP TABLE "CGSYSADM"."SAP_APPROVALS_LOG";
+
+DROP TABLE "CGSYSADM"."SAP_APPROVALS";
+
+DROP SEQUENCE "CGSYSADM"."SAP_APPROVALS_SEQ";
+
+DROP TABLE "CGSYSADM"."SAP_RELEASES_LOG";
+
+DROP TABLE "CGSYSADM"."SAP_RELEASES";
+
 DROP TABLE "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS";
 
 DROP TABLE "CGSYSADM"."SAP_ACCOUNTS";
@@ -6,6 +6,25 @@ sqlplus ${ORA} @create_user_samapi.sql
 
 # make tables in CGSYSADM schema to be accessed by SAMAPI
 ORA="CGSYSADM/cgsysadm17@XE"
+
+# upgrade of the tables installed before, the data is kept
+if [ "$1" = "upgrade" ]; then
+	sqlplus ${ORA} @upgrade_sap_reject.sql
+	sqlplus ${ORA} @upgrade_create_table.sql SAP_RELEASES create_sap_releases.sql
+	sqlplus ${ORA} @upgrade_create_table.sql SAP_APPROVALS create_sap_approvals.sql
+	sqlplus ${ORA} @upgrade_sap_approvals_seq.sql
+	sqlplus ${ORA} @upgrade_create_table.sql SAP_RELEASE_DELIVERIES create_sap_release_deliveries.sql
+	sqlplus ${ORA} @create_sap_accounts_triggers.sql
+	sqlplus ${ORA} @create_sap_acc_segm_order_numbers_triggers.sql
+	exit 0
+fi
+
+# the SAP account may be still created in SAP when AccountReference is staged
+if [ "$1" = "staged" ]; then
+	sqlplus ${ORA} @staged_sap_accounts_fk_ofi.sql
+	exit 0
+fi
+
 sqlplus ${ORA} @dropall.sql
 sqlplus ${ORA} @create_sap_ofi_bscs_glaccounts_table.sql
 sqlplus ${ORA} @create_sap_ofi_accounts.sql
@@ -18,6 +37,9 @@ sqlplus ${ORA} @create_sap_acc_segm_order_numbers.sql
 sqlplus ${ORA} @create_sap_acc_segm_order_numbers_log.sql
 sqlplus ${ORA} @create_sap_acc_segm_order_numbers_triggers.sql
 sqlplus ${ORA} @create_sap_acc_segm_order_numbers_p_view.sql
+sqlplus ${ORA} @create_sap_releases.sql
+sqlplus ${ORA} @create_sap_release_deliveries.sql
+sqlplus ${ORA} @create_sap_approvals.sql
 
 
 
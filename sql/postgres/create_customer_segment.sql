@@ -0,0 +1,23 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE cgsysadm.customer_segment (
+	CSTRADEREF VARCHAR(8) NOT NULL,
+	SEGM_CATEGORY VARCHAR(8) NOT NULL,
+	ENTRY_DATE TIMESTAMP(0),
+	ENTRY_OWNER VARCHAR(16),
+	UPDATE_DATE TIMESTAMP(0),
+	UPDATE_OWNER VARCHAR(16),
+	REC_VERSION INTEGER DEFAULT 0,
+	CONSTRAINT PK_CUST_SEGM_IDX PRIMARY KEY (CSTRADEREF)
+);
+
+COMMENT ON COLUMN cgsysadm.customer_segment.CSTRADEREF IS 'Customer segment group codes for SAP account orders';
+COMMENT ON TABLE cgsysadm.customer_segment IS 'Customer segment codes dictionary for SAP account orders';
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON cgsysadm.customer_segment TO samapi;
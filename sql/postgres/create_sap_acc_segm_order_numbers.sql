@@ -0,0 +1,48 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE cgsysadm.sap_acc_segm_order_numbers (
+	STATUS CHAR(1) NOT NULL,
+	RELEASE_ID INTEGER NOT NULL,
+	BSCS_ACCOUNT VARCHAR(32) NOT NULL,
+	SEGMENT_CODE VARCHAR(32) NOT NULL,
+	VALID_FROM_DATE TIMESTAMP(0),
+	ORDER_NUMBER VARCHAR(32),
+	ENTRY_DATE TIMESTAMP(0) NOT NULL,
+	ENTRY_OWNER VARCHAR(16) NOT NULL,
+	UPDATE_DATE TIMESTAMP(0),
+	UPDATE_OWNER VARCHAR(16),
+	RELEASE_DATE TIMESTAMP(0),
+	RELEASE_OWNER VARCHAR(16),
+	REC_VERSION INTEGER DEFAULT 0,
+	REJECT_REASON VARCHAR(256),
+	REJECT_OWNER VARCHAR(16),
+	CONSTRAINT PK_SAP_ACCT_SEGM_IDX PRIMARY KEY (STATUS, RELEASE_ID, BSCS_ACCOUNT, SEGMENT_CODE)
+);
+
+COMMENT ON COLUMN cgsysadm.sap_acc_segm_order_numbers.STATUS IS 'Status of the entry, W like Working, C like Controlled, P like Production';
+COMMENT ON COLUMN cgsysadm.sap_acc_segm_order_numbers.RELEASE_ID IS 'Running identifier of the release, 0 for Work, else for Production release versions';
+COMMENT ON COLUMN cgsysadm.sap_acc_segm_order_numbers.BSCS_ACCOUNT IS 'BSCS GL account code used for booking, source of the mapping';
+COMMENT ON COLUMN cgsysadm.sap_acc_segm_order_numbers.SEGMENT_CODE IS 'Customer segment code from CUSTOMER_SEGMENT dictionary table, all values to be used';
+COMMENT ON COLUMN cgsysadm.sap_acc_segm_order_numbers.ORDER_NUMBER IS 'Number of the order to be filled in by Control';
+
+--------------------------------------------------------
+--  DDL for Constraints
+--------------------------------------------------------
+
+ALTER TABLE cgsysadm.sap_acc_segm_order_numbers
+ADD CONSTRAINT FK_SAP_ACC_SEGM_ORD_SEGM
+FOREIGN KEY (SEGMENT_CODE)
+REFERENCES cgsysadm.customer_segment (CSTRADEREF);
+
+ALTER TABLE cgsysadm.sap_acc_segm_order_numbers
+ADD CONSTRAINT FK_SAP_ACC_SEGM_ORD_BSCS_ACC
+FOREIGN KEY (STATUS, RELEASE_ID, BSCS_ACCOUNT)
+REFERENCES cgsysadm.sap_accounts (STATUS, RELEASE_ID, BSCS_ACCOUNT);
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON cgsysadm.sap_acc_segm_order_numbers TO samapi;
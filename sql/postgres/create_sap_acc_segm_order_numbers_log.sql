@@ -0,0 +1,37 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+DROP TABLE IF EXISTS cgsysadm.sap_acc_segm_order_numbers_log;
+
+CREATE TABLE cgsysadm.sap_acc_segm_order_numbers_log (
+	OPCODE CHAR(1),
+	OPDATE TIMESTAMP(0),
+	STATUS CHAR(1),
+	RELEASE_ID INTEGER,
+	BSCS_ACCOUNT VARCHAR(32),
+	SEGMENT_CODE VARCHAR(32),
+	VALID_FROM_DATE TIMESTAMP(0),
+	ORDER_NUMBER VARCHAR(32),
+	ENTRY_DATE TIMESTAMP(0),
+	ENTRY_OWNER VARCHAR(16),
+	UPDATE_DATE TIMESTAMP(0),
+	UPDATE_OWNER VARCHAR(16),
+	RELEASE_DATE TIMESTAMP(0),
+	RELEASE_OWNER VARCHAR(16),
+	REC_VERSION INTEGER,
+	REJECT_REASON VARCHAR(256),
+	REJECT_OWNER VARCHAR(16)
+);
+
+--------------------------------------------------------
+--  DDL for Index
+--------------------------------------------------------
+
+CREATE INDEX PK_SAP_ACCT_SEGM_LOG_IDX ON cgsysadm.sap_acc_segm_order_numbers_log (BSCS_ACCOUNT);
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT ON cgsysadm.sap_acc_segm_order_numbers_log TO samapi;
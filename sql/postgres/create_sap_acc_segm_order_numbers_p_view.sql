@@ -0,0 +1,24 @@
+--------------------------------------------------------
+--  DDL for View
+--------------------------------------------------------
+
+CREATE OR REPLACE VIEW cgsysadm.sap_acc_segm_order_numbers_p
+AS
+SELECT
+	STATUS,
+	RELEASE_ID,
+	BSCS_ACCOUNT,
+	SEGMENT_CODE,
+	VALID_FROM_DATE,
+	ORDER_NUMBER,
+	ENTRY_DATE,
+	ENTRY_OWNER,
+	UPDATE_DATE,
+	UPDATE_OWNER,
+	RELEASE_DATE,
+	RELEASE_OWNER
+FROM cgsysadm.sap_acc_segm_order_numbers Q
+WHERE STATUS = 'P'
+AND RELEASE_ID = (SELECT MAX(RELEASE_ID) FROM cgsysadm.sap_acc_segm_order_numbers SQ WHERE SQ.BSCS_ACCOUNT = Q.BSCS_ACCOUNT AND SQ.SEGMENT_CODE = Q.SEGMENT_CODE);
+
+GRANT SELECT ON cgsysadm.sap_acc_segm_order_numbers_p TO samapi;
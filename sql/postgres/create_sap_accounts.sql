@@ -0,0 +1,54 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE cgsysadm.sap_accounts (
+	   STATUS CHAR(1) NOT NULL,
+	   RELEASE_ID INTEGER NOT NULL,
+	   BSCS_ACCOUNT VARCHAR(32) NOT NULL,
+	   OFI_SAP_ACCOUNT VARCHAR(32),
+	   VALID_FROM_DATE TIMESTAMP(0),
+	   VAT_CODE_IND VARCHAR(32),
+	   OFI_SAP_WBS_CODE VARCHAR(32),
+	   CIT_MARKER_VAT_FLAG INTEGER,
+	   ENTRY_DATE TIMESTAMP(0) NOT NULL,
+	   ENTRY_OWNER VARCHAR(16) NOT NULL,
+	   UPDATE_DATE TIMESTAMP(0),
+	   UPDATE_OWNER VARCHAR(16),
+	   RELEASE_DATE TIMESTAMP(0),
+	   RELEASE_OWNER VARCHAR(16),
+	   REC_VERSION INTEGER DEFAULT 0,
+	   REJECT_REASON VARCHAR(256),
+	   REJECT_OWNER VARCHAR(16),
+	   CONSTRAINT PK_SAP_ACCT_IDX PRIMARY KEY (STATUS, RELEASE_ID, BSCS_ACCOUNT)
+);
+
+COMMENT ON COLUMN cgsysadm.sap_accounts.STATUS IS 'Status of the entry, W like Working, C like Controller, P like Production';
+COMMENT ON COLUMN cgsysadm.sap_accounts.RELEASE_ID IS 'Running identifier of the release, 0 for Work, else for Production release versions';
+COMMENT ON COLUMN cgsysadm.sap_accounts.BSCS_ACCOUNT IS 'BSCS GL account code used for booking, source of the mapping';
+COMMENT ON COLUMN cgsysadm.sap_accounts.OFI_SAP_ACCOUNT IS 'OFI SAP account number, destination of the mapping';
+COMMENT ON COLUMN cgsysadm.sap_accounts.VALID_FROM_DATE IS 'Validity of the mapping, must be runded down to the 1st day of the month';
+COMMENT ON COLUMN cgsysadm.sap_accounts.VAT_CODE_IND IS 'Business property: VAR code indicator';
+COMMENT ON COLUMN cgsysadm.sap_accounts.OFI_SAP_WBS_CODE IS 'Business property: SAP WBS code';
+COMMENT ON COLUMN cgsysadm.sap_accounts.CIT_MARKER_VAT_FLAG IS 'Business property:  SAP marker vAt flag';
+COMMENT ON TABLE cgsysadm.sap_accounts IS 'BSCS GL account to SAP IFI account mapping table';
+
+--------------------------------------------------------
+--  DDL for Constraints
+--------------------------------------------------------
+
+ALTER TABLE cgsysadm.sap_accounts
+ADD CONSTRAINT FK_SAP_ACC_OFI
+FOREIGN KEY (OFI_SAP_ACCOUNT)
+REFERENCES cgsysadm.sap_ofi_accounts (SAP_OFI_ACCOUNT);
+
+ALTER TABLE cgsysadm.sap_accounts
+ADD CONSTRAINT FK_SAP_ACC_BSCS
+FOREIGN KEY (BSCS_ACCOUNT)
+REFERENCES cgsysadm.glaccounts (GLACODE);
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON cgsysadm.sap_accounts TO samapi;
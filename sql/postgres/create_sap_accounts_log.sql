@@ -0,0 +1,39 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+DROP TABLE IF EXISTS cgsysadm.sap_accounts_log;
+
+CREATE TABLE cgsysadm.sap_accounts_log (
+	   OPCODE CHAR(1),
+	   OPDATE TIMESTAMP(0),
+	   STATUS CHAR(1),
+	   RELEASE_ID INTEGER,
+	   BSCS_ACCOUNT VARCHAR(32),
+	   OFI_SAP_ACCOUNT VARCHAR(32),
+	   VALID_FROM_DATE TIMESTAMP(0),
+	   VAT_CODE_IND VARCHAR(32),
+	   OFI_SAP_WBS_CODE VARCHAR(32),
+	   CIT_MARKER_VAT_FLAG INTEGER,
+	   ENTRY_DATE TIMESTAMP(0),
+	   ENTRY_OWNER VARCHAR(16),
+	   UPDATE_DATE TIMESTAMP(0),
+	   UPDATE_OWNER VARCHAR(16),
+	   RELEASE_DATE TIMESTAMP(0),
+	   RELEASE_OWNER VARCHAR(16),
+	   REC_VERSION INTEGER,
+	   REJECT_REASON VARCHAR(256),
+	   REJECT_OWNER VARCHAR(16)
+);
+
+--------------------------------------------------------
+--  DDL for Index
+--------------------------------------------------------
+
+CREATE INDEX PK_SAP_ACCT_LOG_IDX ON cgsysadm.sap_accounts_log (BSCS_ACCOUNT);
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT ON cgsysadm.sap_accounts_log TO samapi;
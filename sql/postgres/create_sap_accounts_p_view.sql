@@ -0,0 +1,26 @@
+--------------------------------------------------------
+--  DDL for View
+--------------------------------------------------------
+
+CREATE OR REPLACE VIEW cgsysadm.sap_accounts_p
+AS
+SELECT
+ 	   STATUS,
+	   RELEASE_ID,
+	   BSCS_ACCOUNT,
+	   OFI_SAP_ACCOUNT,
+	   VALID_FROM_DATE,
+	   VAT_CODE_IND,
+	   OFI_SAP_WBS_CODE,
+	   CIT_MARKER_VAT_FLAG,
+	   ENTRY_DATE,
+	   ENTRY_OWNER,
+	   UPDATE_DATE,
+	   UPDATE_OWNER,
+	   RELEASE_DATE,
+	   RELEASE_OWNER
+FROM cgsysadm.sap_accounts Q
+WHERE STATUS = 'P'
+AND RELEASE_ID = (SELECT MAX(RELEASE_ID) FROM cgsysadm.sap_accounts SQ WHERE SQ.BSCS_ACCOUNT = Q.BSCS_ACCOUNT);
+
+GRANT SELECT ON cgsysadm.sap_accounts_p TO samapi;
@@ -0,0 +1,93 @@
+--------------------------------------------------------
+--  DDL for Triggers
+--------------------------------------------------------
+
+CREATE OR REPLACE FUNCTION cgsysadm.sap_accounts_bef() RETURNS trigger AS $$
+BEGIN
+   IF TG_OP = 'DELETE' THEN
+      IF OLD.STATUS = 'P' AND OLD.VALID_FROM_DATE < LOCALTIMESTAMP THEN
+         RAISE EXCEPTION 'ORA-20000: Valid Date in the past, validation error';
+      END IF;
+      RETURN OLD;
+   END IF;
+   IF NEW.STATUS = 'P' AND NEW.VALID_FROM_DATE < LOCALTIMESTAMP THEN
+      RAISE EXCEPTION 'ORA-20000: Valid Date in the past, validation error';
+   END IF;
+   IF TG_OP = 'UPDATE' THEN
+      NEW.REC_VERSION := NEW.REC_VERSION + 1;
+   END IF;
+   NEW.VALID_FROM_DATE := date_trunc('day', NEW.VALID_FROM_DATE);
+   RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION cgsysadm.sap_accounts_aft() RETURNS trigger AS $$
+DECLARE
+   r cgsysadm.sap_accounts%ROWTYPE;
+BEGIN
+   IF TG_OP = 'DELETE' THEN
+      r := OLD;
+   ELSE
+      r := NEW;
+   END IF;
+   INSERT INTO cgsysadm.sap_accounts_log
+   (
+       OPCODE,
+	   OPDATE,
+ 	   STATUS,
+	   RELEASE_ID,
+	   BSCS_ACCOUNT,
+	   OFI_SAP_ACCOUNT,
+	   VALID_FROM_DATE,
+	   VAT_CODE_IND,
+	   OFI_SAP_WBS_CODE,
+	   CIT_MARKER_VAT_FLAG,
+	   ENTRY_DATE,
+	   ENTRY_OWNER,
+	   UPDATE_DATE,
+	   UPDATE_OWNER,
+	   RELEASE_DATE,
+	   RELEASE_OWNER,
+	   REC_VERSION,
+	   REJECT_REASON,
+	   REJECT_OWNER
+   )
+   VALUES
+   (
+       substr(TG_OP, 1, 1),
+	   LOCALTIMESTAMP,
+ 	   r.STATUS,
+	   r.RELEASE_ID,
+	   r.BSCS_ACCOUNT,
+	   r.OFI_SAP_ACCOUNT,
+	   r.VALID_FROM_DATE,
+	   r.VAT_CODE_IND,
+	   r.OFI_SAP_WBS_CODE,
+	   r.CIT_MARKER_VAT_FLAG,
+	   r.ENTRY_DATE,
+	   r.ENTRY_OWNER,
+	   r.UPDATE_DATE,
+	   r.UPDATE_OWNER,
+	   r.RELEASE_DATE,
+	   r.RELEASE_OWNER,
+	   r.REC_VERSION,
+	   r.REJECT_REASON,
+	   r.REJECT_OWNER
+   );
+   RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS sap_accounts_bef ON cgsysadm.sap_accounts;
+
+CREATE TRIGGER sap_accounts_bef
+BEFORE INSERT OR UPDATE OR DELETE
+   ON cgsysadm.sap_accounts
+   FOR EACH ROW EXECUTE PROCEDURE cgsysadm.sap_accounts_bef();
+
+DROP TRIGGER IF EXISTS sap_accounts_aft ON cgsysadm.sap_accounts;
+
+CREATE TRIGGER sap_accounts_aft
+AFTER INSERT OR UPDATE OR DELETE
+   ON cgsysadm.sap_accounts
+   FOR EACH ROW EXECUTE PROCEDURE cgsysadm.sap_accounts_aft();
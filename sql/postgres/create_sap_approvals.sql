@@ -0,0 +1,51 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE cgsysadm.sap_approvals (
+	   APPROVAL_ID SERIAL NOT NULL,
+	   FROM_STATUS CHAR(1),
+	   INTO_STATUS CHAR(1),
+	   RELEASE_ID VARCHAR(8),
+	   STATE CHAR(1),
+	   REQUEST_DATE TIMESTAMP(0),
+	   REQUEST_OWNER VARCHAR(16),
+	   REQUEST_ROLE VARCHAR(16),
+	   REQUEST_COMMENT VARCHAR(256),
+	   REC_VERSION INTEGER DEFAULT 0,
+	   CONSTRAINT PK_SAP_APPROVALS_IDX PRIMARY KEY (APPROVAL_ID)
+);
+
+COMMENT ON COLUMN cgsysadm.sap_approvals.APPROVAL_ID IS 'Running identifier of the release request';
+COMMENT ON COLUMN cgsysadm.sap_approvals.FROM_STATUS IS 'Status of the entries before the release';
+COMMENT ON COLUMN cgsysadm.sap_approvals.INTO_STATUS IS 'Status of the entries after the release';
+COMMENT ON COLUMN cgsysadm.sap_approvals.RELEASE_ID IS 'Requested release: new, last or its id, the id of the release when approved';
+COMMENT ON COLUMN cgsysadm.sap_approvals.STATE IS 'R like Requested, A like Approved, J like reJected';
+COMMENT ON TABLE cgsysadm.sap_approvals IS 'Requests of the release approved by other user';
+
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE cgsysadm.sap_approvals_log (
+	   APPROVAL_ID INTEGER,
+	   STEP CHAR(1),
+	   STEP_DATE TIMESTAMP(0),
+	   STEP_OWNER VARCHAR(16),
+	   STEP_ROLE VARCHAR(16),
+	   STEP_COMMENT VARCHAR(256)
+);
+
+COMMENT ON COLUMN cgsysadm.sap_approvals_log.STEP IS 'R like Request, A like Approve, J like reJect';
+COMMENT ON COLUMN cgsysadm.sap_approvals_log.STEP_ROLE IS 'Role of the user doing the step';
+COMMENT ON TABLE cgsysadm.sap_approvals_log IS 'Steps of the release requests';
+
+CREATE INDEX PK_SAP_APPROVALS_LOG_IDX ON cgsysadm.sap_approvals_log (APPROVAL_ID);
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE ON cgsysadm.sap_approvals TO samapi;
+GRANT SELECT, INSERT ON cgsysadm.sap_approvals_log TO samapi;
+GRANT USAGE ON SEQUENCE cgsysadm.sap_approvals_approval_id_seq TO samapi;
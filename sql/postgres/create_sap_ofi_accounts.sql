@@ -0,0 +1,28 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+DROP TABLE IF EXISTS cgsysadm.sap_ofi_accounts CASCADE;
+
+CREATE TABLE cgsysadm.sap_ofi_accounts (
+	   SAP_OFI_ACCOUNT VARCHAR(32) NOT NULL,
+	   NAME VARCHAR(255),
+	   STATUS VARCHAR(8),
+	   ENTRY_DATE TIMESTAMP(0) NOT NULL,
+	   ENTRY_OWNER VARCHAR(16) NOT NULL,
+	   UPDATE_DATE TIMESTAMP(0),
+	   UPDATE_OWNER VARCHAR(16),
+	   REC_VERSION INTEGER DEFAULT 0,
+	   CONSTRAINT PK_SAP_OFI_ACCT_IDX PRIMARY KEY (SAP_OFI_ACCOUNT)
+);
+
+COMMENT ON COLUMN cgsysadm.sap_ofi_accounts.SAP_OFI_ACCOUNT IS 'SAP OFI account code';
+COMMENT ON COLUMN cgsysadm.sap_ofi_accounts.NAME IS 'SAP OFI account description';
+COMMENT ON COLUMN cgsysadm.sap_ofi_accounts.STATUS IS 'SAP OFI account status';
+COMMENT ON TABLE cgsysadm.sap_ofi_accounts IS 'SAP OFI account dictionary';
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON cgsysadm.sap_ofi_accounts TO samapi;
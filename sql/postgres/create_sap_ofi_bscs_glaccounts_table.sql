@@ -0,0 +1,23 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+DROP TABLE IF EXISTS cgsysadm.glaccounts CASCADE;
+
+CREATE TABLE cgsysadm.glaccounts (
+	   GLACODE VARCHAR(30) NOT NULL,
+	   GLADESC VARCHAR(60) NOT NULL,
+	   GLATYPE VARCHAR(2) NOT NULL,
+	   GLACTIVE VARCHAR(1) NOT NULL,
+	   ENTRY_DATE TIMESTAMP(0) NOT NULL,
+	   ENTRY_OWNER VARCHAR(16) NOT NULL,
+	   UPDATE_DATE TIMESTAMP(0),
+	   UPDATE_OWNER VARCHAR(16),
+	   CONSTRAINT PK_GL_ACCT_IDX PRIMARY KEY (GLACODE)
+);
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE, DELETE ON cgsysadm.glaccounts TO samapi;
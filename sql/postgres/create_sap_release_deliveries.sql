@@ -0,0 +1,33 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE cgsysadm.sap_release_deliveries (
+	   RELEASE_ID INTEGER NOT NULL,
+	   FORMAT VARCHAR(8),
+	   TARGET VARCHAR(256),
+	   FILE_NAME VARCHAR(64),
+	   CHECKSUM VARCHAR(8),
+	   STATUS VARCHAR(16),
+	   ATTEMPTS INTEGER DEFAULT 0,
+	   MESSAGE VARCHAR(256),
+	   SENT_DATE TIMESTAMP(0),
+	   ACK_DATE TIMESTAMP(0),
+	   ACK_OWNER VARCHAR(16),
+	   REC_VERSION INTEGER DEFAULT 0,
+	   CONSTRAINT PK_SAP_RELEASE_DELIVERIES_IDX PRIMARY KEY (RELEASE_ID)
+);
+
+COMMENT ON COLUMN cgsysadm.sap_release_deliveries.RELEASE_ID IS 'Release of the interface file as in SAP_RELEASES';
+COMMENT ON COLUMN cgsysadm.sap_release_deliveries.TARGET IS 'Drop directory or SFTP location without the password';
+COMMENT ON COLUMN cgsysadm.sap_release_deliveries.CHECKSUM IS 'Checksum of the trailer of the interface file';
+COMMENT ON COLUMN cgsysadm.sap_release_deliveries.STATUS IS 'Pending, Sent, Failed, Delivered or Rejected by SAP';
+COMMENT ON COLUMN cgsysadm.sap_release_deliveries.ATTEMPTS IS 'Number of the attempts of the last delivery';
+COMMENT ON COLUMN cgsysadm.sap_release_deliveries.MESSAGE IS 'Last error of the delivery or the message of the acknowledgement';
+COMMENT ON TABLE cgsysadm.sap_release_deliveries IS 'Delivery of the interface files of the releases to SAP';
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE ON cgsysadm.sap_release_deliveries TO samapi;
@@ -0,0 +1,50 @@
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE cgsysadm.sap_releases (
+	   RELEASE_ID INTEGER NOT NULL,
+	   FROM_STATUS CHAR(1),
+	   INTO_STATUS CHAR(1),
+	   DESCRIPTION VARCHAR(256),
+	   RELEASE_DATE TIMESTAMP(0),
+	   RELEASE_OWNER VARCHAR(16),
+	   REVOKE_DATE TIMESTAMP(0),
+	   REVOKE_OWNER VARCHAR(16),
+	   REC_VERSION INTEGER DEFAULT 0,
+	   CONSTRAINT PK_SAP_RELEASES_IDX PRIMARY KEY (RELEASE_ID)
+);
+
+COMMENT ON COLUMN cgsysadm.sap_releases.RELEASE_ID IS 'Running identifier of the release as in SAP_ACCOUNTS and SAP_ACC_SEGM_ORDER_NUMBERS';
+COMMENT ON COLUMN cgsysadm.sap_releases.FROM_STATUS IS 'Status of the entries before the release';
+COMMENT ON COLUMN cgsysadm.sap_releases.INTO_STATUS IS 'Status of the entries after the release';
+COMMENT ON COLUMN cgsysadm.sap_releases.DESCRIPTION IS 'Optional description given by the user doing the release';
+COMMENT ON COLUMN cgsysadm.sap_releases.REVOKE_DATE IS 'Date of the last revocation, empty if the release is in Production';
+COMMENT ON TABLE cgsysadm.sap_releases IS 'Registry of the releases of the account and order mapping';
+
+--------------------------------------------------------
+--  DDL for Table
+--------------------------------------------------------
+
+CREATE TABLE cgsysadm.sap_releases_log (
+	   RELEASE_ID INTEGER,
+	   OPCODE CHAR(1),
+	   OPDATE TIMESTAMP(0),
+	   OPOWNER VARCHAR(16),
+	   ACCOUNTS INTEGER,
+	   ORDERS INTEGER
+);
+
+COMMENT ON COLUMN cgsysadm.sap_releases_log.OPCODE IS 'N like New release, A like Append to release, R like Revoke';
+COMMENT ON COLUMN cgsysadm.sap_releases_log.ACCOUNTS IS 'Number of the accounts moved by the operation';
+COMMENT ON COLUMN cgsysadm.sap_releases_log.ORDERS IS 'Number of the orders moved by the operation';
+COMMENT ON TABLE cgsysadm.sap_releases_log IS 'History of the operations on the releases';
+
+CREATE INDEX PK_SAP_RELEASES_LOG_IDX ON cgsysadm.sap_releases_log (RELEASE_ID);
+
+--------------------------------------------------------
+--  DDL for Grants
+--------------------------------------------------------
+
+GRANT SELECT, INSERT, UPDATE ON cgsysadm.sap_releases TO samapi;
+GRANT SELECT, INSERT ON cgsysadm.sap_releases_log TO samapi;
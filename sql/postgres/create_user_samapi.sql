@@ -0,0 +1,16 @@
+--------------------------------------------------------
+--  Roles and schema, PostgreSQL equivalent of CGSYSADM/SAMAPI
+--------------------------------------------------------
+
+DROP SCHEMA IF EXISTS cgsysadm CASCADE;
+
+DROP ROLE IF EXISTS samapi;
+
+CREATE ROLE samapi LOGIN PASSWORD 'SAMAPI';
+
+CREATE SCHEMA cgsysadm;
+
+GRANT USAGE ON SCHEMA cgsysadm TO samapi;
+
+-- replaces public synonyms of Oracle
+ALTER ROLE samapi SET search_path = cgsysadm;
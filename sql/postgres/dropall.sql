@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS cgsysadm.sap_release_deliveries CASCADE;
+
+DROP TABLE IF EXISTS cgsysadm.sap_approvals_log CASCADE;
+
+DROP TABLE IF EXISTS cgsysadm.sap_approvals CASCADE;
+
+DROP TABLE IF EXISTS cgsysadm.sap_releases_log CASCADE;
+
+DROP TABLE IF EXISTS cgsysadm.sap_releases CASCADE;
+
+DROP TABLE IF EXISTS cgsysadm.sap_acc_segm_order_numbers CASCADE;
+
+DROP TABLE IF EXISTS cgsysadm.sap_accounts CASCADE;
+
+DROP TABLE IF EXISTS cgsysadm.customer_segment CASCADE;
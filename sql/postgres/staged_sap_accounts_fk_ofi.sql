@@ -0,0 +1,7 @@
+--------------------------------------------------------
+--  Installation with AccountReference being staged, the
+--  OFI_SAP_ACCOUNT may be still created in SAP so it is
+--  checked by the API only, the foreign key is dropped
+--------------------------------------------------------
+
+ALTER TABLE cgsysadm.sap_accounts DROP CONSTRAINT IF EXISTS FK_SAP_ACC_OFI;
@@ -0,0 +1,10 @@
+--------------------------------------------------------
+--  Installation with AccountReference being staged, the
+--  OFI_SAP_ACCOUNT may be still created in SAP so it is
+--  checked by the API only, the foreign key is disabled
+--------------------------------------------------------
+
+ALTER TABLE "CGSYSADM"."SAP_ACCOUNTS" DISABLE CONSTRAINT FK_SAP_ACC_OFI;
+
+QUIT
+/
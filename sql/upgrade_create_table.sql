@@ -0,0 +1,18 @@
+--------------------------------------------------------
+--  Upgrade of the schema installed before the table of
+--  the first parameter was added, it is created by the
+--  script of the second parameter only if it does not
+--  exist yet so the upgrade may be run again
+--------------------------------------------------------
+
+SET VERIFY OFF
+COLUMN SCRIPT NEW_VALUE SCRIPT NOPRINT
+
+SELECT DECODE(COUNT(*), 0, '&2', 'upgrade_skip.sql') SCRIPT
+FROM USER_TABLES
+WHERE TABLE_NAME = UPPER('&1');
+
+@&SCRIPT
+
+QUIT
+/
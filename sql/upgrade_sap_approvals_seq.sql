@@ -0,0 +1,25 @@
+--------------------------------------------------------
+--  Upgrade of the schema installed before the approval
+--  ids were taken from the sequence, it starts after the
+--  last request and it is created only if it does not
+--  exist yet so the upgrade may be run again
+--------------------------------------------------------
+
+DECLARE
+	n INTEGER;
+	id INTEGER;
+BEGIN
+	SELECT COUNT(*) INTO n FROM USER_SEQUENCES WHERE SEQUENCE_NAME = 'SAP_APPROVALS_SEQ';
+	IF n = 0 THEN
+		SELECT NVL(MAX(APPROVAL_ID), 0) + 1 INTO id FROM "CGSYSADM"."SAP_APPROVALS";
+		EXECUTE IMMEDIATE 'CREATE SEQUENCE "CGSYSADM"."SAP_APPROVALS_SEQ" START WITH ' || id || ' INCREMENT BY 1 NOCACHE';
+	END IF;
+END;
+/
+
+GRANT SELECT ON "CGSYSADM"."SAP_APPROVALS_SEQ" TO SAMAPI;
+
+CREATE OR REPLACE PUBLIC SYNONYM SAP_APPROVALS_SEQ FOR "CGSYSADM"."SAP_APPROVALS_SEQ";
+
+QUIT
+/
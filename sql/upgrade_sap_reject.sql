@@ -0,0 +1,34 @@
+--------------------------------------------------------
+--  Upgrade of the schema installed before the rejection
+--  of the entries back to Work, the triggers must be
+--  created again to copy the columns into the logs
+--------------------------------------------------------
+
+ALTER TABLE "CGSYSADM"."SAP_ACCOUNTS" ADD (
+       REJECT_REASON VARCHAR2(256),
+       REJECT_OWNER VARCHAR2(16)
+);
+
+COMMENT ON COLUMN "CGSYSADM"."SAP_ACCOUNTS"."REJECT_REASON" IS 'Reason of the rejection by Control moving the entry back to Work';
+COMMENT ON COLUMN "CGSYSADM"."SAP_ACCOUNTS"."REJECT_OWNER" IS 'User who rejected the entry back to Work';
+
+ALTER TABLE "CGSYSADM"."SAP_ACCOUNTS_LOG" ADD (
+	   REJECT_REASON VARCHAR2(256),
+	   REJECT_OWNER VARCHAR2(16)
+);
+
+ALTER TABLE "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS" ADD (
+    REJECT_REASON VARCHAR2(256),
+    REJECT_OWNER VARCHAR2(16)
+);
+
+COMMENT ON COLUMN "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS"."REJECT_REASON" IS 'Reason of the rejection by Control moving the entry back to Work';
+COMMENT ON COLUMN "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS"."REJECT_OWNER" IS 'User who rejected the entry back to Work';
+
+ALTER TABLE "CGSYSADM"."SAP_ACC_SEGM_ORDER_NUMBERS_LOG" ADD (
+	REJECT_REASON VARCHAR2(256),
+	REJECT_OWNER VARCHAR2(16)
+);
+
+QUIT
+/
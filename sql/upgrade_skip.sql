@@ -0,0 +1,6 @@
+--------------------------------------------------------
+--  Nothing to be upgraded
+--------------------------------------------------------
+
+QUIT
+/
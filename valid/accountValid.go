@@ -15,6 +15,18 @@ func WithAccount(w http.ResponseWriter, r *http.Request, next http.HandlerFunc)
 		return
 	}
 
+	// rejection has its own check
+	if isReject(r) {
+		withReject(w, r, next)
+		return
+	}
+
+	// restore has its own check
+	if isRestore(r) {
+		withRestore(w, r, next)
+		return
+	}
+
 	// all other methods
 
 	defer func() {
@@ -37,73 +49,7 @@ func WithAccount(w http.ResponseWriter, r *http.Request, next http.HandlerFunc)
 
 		// Check for invalidation cases on particular json fields
 		for k, v := range *data {
-			// release id must be unsigned integer or last
-			if k == "releaseId" && !validRelease(v) {
-				info = "Invalid value of the field releaseId"
-				ok = false
-				break
-			}
-
-			switch r.Method {
-			case "POST":
-				// Only 0 release orders can be created
-				if k == "releaseId" && v != "0" {
-					info = "Invalid value of release, only 0 allowed"
-					ok = false
-					break
-				}
-
-				// Only orders in W status can be created
-				if k == "status" && v != "W" {
-					info = "Invalid value of status, only W allowed"
-					ok = false
-					break
-				}
-				
-				// Control can not create accounts
-				if role == "Control" {
-					info = "Invalid role Control"
-					ok = false;
-				}
-				
-				// Booker can not set attribute ofiSapWbsCode
-				if role == "Booker" && k == "ofiSapWbsCode" && !common.EmptyValue(v){
-					info = "Invalid role Booker accessing field ofiSapWbsCode"
-					ok = false
-				}
-
-				// validFromDate must be the 1st of month in future
-				if k == "validFromDate" && !common.IsCutOffDate(v) {
-					info = "Invalid value of validFromDate"
-					ok = false
-					break
-				}
-				
-			case "PUT": fallthrough
-			case "PATCH":
-				// Booker can not set attribute ofiSapWbsCode
-				if role == "Booker" {
-					if common.MemberOf(k, "ofiSapWbsCode") {
-						info = "Invalid role Booker accessing field ofiSapWbsCode belonging to Control"
-						ok = false
-					}
-				}
-				
-				// Control can set only attribute ofiSapWbsCode or status, releaseId to move from C to W or P
-				if role == "Control" {
-					if !common.MemberOf(k, "ofiSapWbsCode", "status", "releaseId") {	
-						info = "Invalid role Control accessing field " + k
-						ok = false
-					}
-				}
-				
-				// validFromDate must be the 1st of month in future
-				if k == "validFromDate" && !common.IsCutOffDate(v) {
-					info = "Invalid value of validFromDate"
-					ok = false
-					break
-				}
-			}
+			ok, info = CheckAccountAttribute(r.Method, role, k, v)
 
 			log.Printf("Access check: %s %s %s %#v: %v %s", role, r.Method, k, v, ok, info)
 			if !ok {
@@ -122,3 +68,82 @@ func WithAccount(w http.ResponseWriter, r *http.Request, next http.HandlerFunc)
 
 	next(w, r)
 }
+
+//
+// Check of one attribute of the account payload sent by the role with
+// the method, the bulk import checks each of its rows the same way
+//
+func CheckAccountAttribute(method, role, k string, v interface{}) (ok bool, info string) {
+	ok = true
+
+	// release id must be unsigned integer or last
+	if k == "releaseId" && !validRelease(v) {
+		info = "Invalid value of the field releaseId"
+		ok = false
+		return
+	}
+
+	switch method {
+	case "POST":
+		// Only 0 release orders can be created
+		if k == "releaseId" && v != "0" {
+			info = "Invalid value of release, only 0 allowed"
+			ok = false
+			break
+		}
+
+		// Only orders in W status can be created
+		if k == "status" && v != "W" {
+			info = "Invalid value of status, only W allowed"
+			ok = false
+			break
+		}
+		
+		// Control can not create accounts
+		if role == "Control" {
+			info = "Invalid role Control"
+			ok = false;
+		}
+		
+		// Booker can not set attribute ofiSapWbsCode
+		if role == "Booker" && k == "ofiSapWbsCode" && !common.EmptyValue(v){
+			info = "Invalid role Booker accessing field ofiSapWbsCode"
+			ok = false
+		}
+
+		// validFromDate must be the 1st of month in future
+		if k == "validFromDate" && !common.IsCutOffDate(v) {
+			info = "Invalid value of validFromDate"
+			ok = false
+			break
+		}
+		
+	case "PUT": fallthrough
+	case "PATCH":
+		// Booker can not set attribute ofiSapWbsCode
+		if role == "Booker" {
+			if common.MemberOf(k, "ofiSapWbsCode") {
+				info = "Invalid role Booker accessing field ofiSapWbsCode belonging to Control"
+				ok = false
+			}
+		}
+		
+		// Control can set only attribute ofiSapWbsCode or status, releaseId to move from C to W or P,
+		// recVersion is a precondition only
+		if role == "Control" {
+			if !common.MemberOf(k, "ofiSapWbsCode", "status", "releaseId", "recVersion") {	
+				info = "Invalid role Control accessing field " + k
+				ok = false
+			}
+		}
+		
+		// validFromDate must be the 1st of month in future
+		if k == "validFromDate" && !common.IsCutOffDate(v) {
+			info = "Invalid value of validFromDate"
+			ok = false
+			break
+		}
+	}
+
+	return
+}
@@ -15,6 +15,18 @@ func WithOrder(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 		return
 	}
 
+	// rejection has its own check
+	if isReject(r) {
+		withReject(w, r, next)
+		return
+	}
+
+	// restore has its own check
+	if isRestore(r) {
+		withRestore(w, r, next)
+		return
+	}
+
 	// all other methods
 	
 	defer func() {
@@ -36,64 +48,8 @@ func WithOrder(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 		}
 
 		for k, v := range *data {
-			// release id must be unsigned integer or last			
-			if k == "releaseId" && !validRelease(v) {
-				info = "Invalid value of the field releaseId"
-				ok = false
-				break
-			}
-			
-			switch r.Method {
-			case "POST":
-				// Only 0 release orders can be create
-				if k == "releaseId" && v != "0" {
-					info = "Invalid value of release, only 0 allowed"
-					ok = false
-					break
-				}
+			ok, info = CheckOrderAttribute(r.Method, role, k, v)
 
-				// Only orders in W status can be created
-				if k == "status" && v != "W" {
-					info = "Invalid value of status, only W allowed"
-					ok = false
-					break
-				}
-				
-				// Booker can not set attribute orderNmber
-				if role == "Booker" && k == "orderNmber" && !common.EmptyValue(v){
-					info = "Invalid role Booker accessing " + k
-					ok = false
-				}
-				
-				// validFromDate must be the 1st of month in future
-				if k == "validFromDate" && !common.IsCutOffDate(v) {
-					info = "Invalid value of validFromDate"
-					ok = false
-					break
-				}				
-				
-			case "PUT": fallthrough
-			case "PATCH":
-				// Booker can not set attribute orderNumber
-				if role == "Booker" && k == "orderNumber" {
-					info = "Invalid role Booker accessing " + k
-					ok = false
-				}
-				
-				// Control can set only one attribute orderNumber
-				if role == "Control" && k != "orderNumber" {
-					info = "Invalid role Booker accessing " + k
-					ok = false
-				}
-
-				// validFromDate must be the 1st of month in future
-				if k == "validFromDate" && !common.IsCutOffDate(v) {
-					info = "Invalid value of validFromDate"
-					ok = false
-					break
-				}				
-			}
-			
 			log.Printf("Access check: %s %s %s %#v: %v", role, r.Method, k, v, ok)
 			if !ok {
 				break
@@ -118,3 +74,71 @@ func WithOrder(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
 	
 	next(w, r)
 }
+
+//
+// Check of one attribute of the order payload sent by the role with
+// the method, the bulk import checks each of its rows the same way
+//
+func CheckOrderAttribute(method, role, k string, v interface{}) (ok bool, info string) {
+	ok = true
+
+	// release id must be unsigned integer or last			
+	if k == "releaseId" && !validRelease(v) {
+		info = "Invalid value of the field releaseId"
+		ok = false
+		return
+	}
+	
+	switch method {
+	case "POST":
+		// Only 0 release orders can be create
+		if k == "releaseId" && v != "0" {
+			info = "Invalid value of release, only 0 allowed"
+			ok = false
+			break
+		}
+
+		// Only orders in W status can be created
+		if k == "status" && v != "W" {
+			info = "Invalid value of status, only W allowed"
+			ok = false
+			break
+		}
+		
+		// Booker can not set attribute orderNmber
+		if role == "Booker" && k == "orderNmber" && !common.EmptyValue(v){
+			info = "Invalid role Booker accessing " + k
+			ok = false
+		}
+		
+		// validFromDate must be the 1st of month in future
+		if k == "validFromDate" && !common.IsCutOffDate(v) {
+			info = "Invalid value of validFromDate"
+			ok = false
+			break
+		}				
+		
+	case "PUT": fallthrough
+	case "PATCH":
+		// Booker can not set attribute orderNumber
+		if role == "Booker" && k == "orderNumber" {
+			info = "Invalid role Booker accessing " + k
+			ok = false
+		}
+		
+		// Control can set only one attribute orderNumber, recVersion is a precondition only
+		if role == "Control" && !common.MemberOf(k, "orderNumber", "recVersion") {
+			info = "Invalid role Booker accessing " + k
+			ok = false
+		}
+
+		// validFromDate must be the 1st of month in future
+		if k == "validFromDate" && !common.IsCutOffDate(v) {
+			info = "Invalid value of validFromDate"
+			ok = false
+			break
+		}				
+	}
+
+	return
+}
@@ -1,9 +1,34 @@
 package valid
 
 import (
+	"log"
 	"net/http"
+	"strings"
+
+	"sam-api/common"
 )
 
 func WithRelease(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	// clone of the release into W has its own check
+	if r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/clone") {
+		withClone(w, r, next)
+		return
+	}
+
+	next(w, r)
+}
+
+// only Booker may fill Work with the clone of the release
+func withClone(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	role := r.Header.Get("role")
+	if role != "Booker" {
+		info := "Invalid role " + role + ", only Booker may clone the release"
+		log.Printf("Validation error: %s", info)
+		common.DisplayAppError(w, common.ValidationError, info, http.StatusForbidden)
+		return
+	}
+
+	log.Printf("Validation status: %v", true)
+
 	next(w, r)
 }
@@ -1,8 +1,10 @@
 package valid
 
 import (
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	
 	"github.com/gorilla/mux"
 
@@ -106,4 +108,48 @@ func validSegmentWithPanic(r *http.Request) {
 		panic("Invalid segment value: " + segment)
 	}
 }
-	
+
+// rejection of the entries back to W, the payload has the reason only
+func isReject(r *http.Request) bool {
+	return r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/reject")
+}
+
+// only Control may reject the entries
+func withReject(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	role := r.Header.Get("role")
+	if role != "Control" {
+		info := "Invalid role " + role + ", only Control may reject"
+		log.Printf("Validation error: %s", info)
+		common.DisplayAppError(w, common.ValidationError, info, http.StatusForbidden)
+		return
+	}
+
+	log.Printf("Validation status: %v", true)
+
+	next(w, r)
+}
+
+// restore of the entry in Work from the log, the payload has the id of the log entry only
+func isRestore(r *http.Request) bool {
+	return r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/restore")
+}
+
+// the restored attributes are checked for the role like the ones of POST or PATCH by the controller
+func withRestore(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
+	data, _, err := common.GetAttributesWithValues(r)
+	if err != nil {
+		common.DisplayAppError(w, err, "Cant get attributes of request payload", http.StatusInternalServerError)
+		return
+	}
+
+	if id, ok := (*data)["id"].(string); !ok || strings.TrimSpace(id) == "" {
+		info := "Missing id of the log entry"
+		log.Printf("Validation error: %s", info)
+		common.DisplayAppError(w, common.ValidationError, info, http.StatusForbidden)
+		return
+	}
+
+	log.Printf("Validation status: %v", true)
+
+	next(w, r)
+}